@@ -2,7 +2,9 @@ package main
 
 import (
 	"log"
+	_ "time/tzdata" // Embed the time zone database, the runtime image has none
 
+	"github.com/cpp-cyber/proclone/internal/api/grpcapi"
 	"github.com/cpp-cyber/proclone/internal/api/handlers"
 	"github.com/cpp-cyber/proclone/internal/api/middleware"
 	"github.com/cpp-cyber/proclone/internal/api/routes"
@@ -19,6 +21,10 @@ type Config struct {
 	Port          string `envconfig:"PORT" default:":8080"`
 	SessionSecret string `envconfig:"SESSION_SECRET" default:"default-secret-key"`
 	FrontendURL   string `envconfig:"FRONTEND_URL" default:"http://localhost:3000"`
+	GRPCPort      string `envconfig:"GRPC_PORT"` // The gRPC API is disabled when empty
+	GRPCAuthToken string `envconfig:"GRPC_AUTH_TOKEN"`
+	GRPCTLSCert   string `envconfig:"GRPC_TLS_CERT"` // Without a certificate and key the gRPC API only listens on localhost
+	GRPCTLSKey    string `envconfig:"GRPC_TLS_KEY"`
 }
 
 // init the environment
@@ -71,6 +77,18 @@ func main() {
 		log.Fatalf("Failed to initialize cloning handler: %v", err)
 	}
 
-	routes.RegisterRoutes(r, authHandler, proxmoxHandler, cloningHandler)
+	eventsHandler := handlers.NewEventsHandler(cloningHandler, config.FrontendURL)
+	consoleHandler := handlers.NewConsoleHandler(cloningHandler, config.FrontendURL)
+
+	routes.RegisterRoutes(r, authHandler, proxmoxHandler, cloningHandler, eventsHandler, consoleHandler)
+
+	if config.GRPCPort != "" {
+		go func() {
+			if err := grpcapi.ListenAndServe(config.GRPCPort, config.GRPCAuthToken, config.GRPCTLSCert, config.GRPCTLSKey, cloningHandler.Service); err != nil {
+				log.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
 	r.Run(config.Port)
 }
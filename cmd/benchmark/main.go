@@ -0,0 +1,127 @@
+// Command benchmark measures end-to-end clone times of a template against the Proxmox
+// cluster in the environment configuration, which should be a staging cluster. Each
+// run clones the template, removes the pods it created, and records the timings in
+// the clone_benchmarks table. The command exits non-zero when the mean duration is
+// slower than the recent history of the template by more than the tolerance.
+//
+//	benchmark -template bench-small -target benchuser -targets 4 -runs 3 -label $(git rev-parse --short HEAD)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/cpp-cyber/proclone/internal/cloning"
+	"github.com/cpp-cyber/proclone/internal/ldap"
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	template := flag.String("template", "", "template to clone (required)")
+	target := flag.String("target", "", "user or group that owns the benchmark pods (required)")
+	isGroup := flag.Bool("group", false, "treat the target as a group")
+	numTargets := flag.Int("targets", 1, "pods cloned per run")
+	runs := flag.Int("runs", 1, "number of benchmark runs")
+	label := flag.String("label", "", "label recorded with the results, such as a git revision")
+	tolerance := flag.Float64("tolerance", 0.2, "allowed slowdown over the baseline before reporting a regression")
+	flag.Parse()
+
+	if *template == "" || *target == "" || *numTargets < 1 || *runs < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables from system")
+	}
+
+	cloningService, err := newCloningService()
+	if err != nil {
+		log.Fatalf("Failed to initialize cloning service: %v", err)
+	}
+
+	var results []cloning.CloneBenchmark
+	for run := 1; run <= *runs; run++ {
+		targets := make([]cloning.CloneTarget, *numTargets)
+		for i := range targets {
+			targets[i] = cloning.CloneTarget{Name: *target, IsGroup: *isGroup}
+		}
+
+		log.Printf("Run %d/%d: cloning %s for %d targets", run, *runs, *template, *numTargets)
+		result, err := cloningService.BenchmarkCloneTemplate(context.Background(), *label, cloning.CloneRequest{
+			Template:    *template,
+			Targets:     targets,
+			RequestedBy: "benchmark",
+		})
+		if result == nil || result.Status != cloning.BenchmarkStatusCompleted {
+			log.Fatalf("Run %d failed: %v", run, err)
+		}
+		if err != nil {
+			log.Printf("Warning: run %d: %v", run, err)
+		}
+
+		printBenchmark(run, result)
+		results = append(results, *result)
+	}
+
+	comparison, err := cloningService.CompareCloneBenchmarks(results, *tolerance)
+	if err != nil {
+		log.Fatalf("Failed to compare against baseline: %v", err)
+	}
+
+	fmt.Printf("\nMean duration: %dms over %d runs\n", comparison.MeanMS, len(results))
+	if comparison.Samples == 0 {
+		fmt.Println("No previous benchmarks to compare against, results recorded as the baseline")
+		return
+	}
+
+	fmt.Printf("Baseline: %dms (median of %d), change: %+.1f%%\n", comparison.BaselineMS, comparison.Samples, comparison.Change*100)
+	if comparison.Regression {
+		fmt.Printf("REGRESSION: slower than baseline by more than %.0f%%\n", *tolerance*100)
+		os.Exit(1)
+	}
+}
+
+func newCloningService() (*cloning.CloningService, error) {
+	dbClient, err := tools.NewDBClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database client: %w", err)
+	}
+
+	proxmoxService, err := proxmox.NewService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Proxmox service: %w", err)
+	}
+
+	ldapService, err := ldap.NewLDAPService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LDAP service: %w", err)
+	}
+
+	return cloning.NewCloningService(proxmoxService, dbClient.DB(), ldapService)
+}
+
+func printBenchmark(run int, result *cloning.CloneBenchmark) {
+	fmt.Printf("Run %d: %dms\n", run, result.DurationMS)
+
+	type stage struct {
+		name string
+		ms   int64
+	}
+	var stages []stage
+	for name, ms := range result.Stages {
+		stages = append(stages, stage{name, ms})
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i].ms < stages[j].ms })
+
+	for _, s := range stages {
+		fmt.Printf("  %8dms  %s\n", s.ms, s.name)
+	}
+}
@@ -0,0 +1,214 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/cloning"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+	proclonev1 "github.com/cpp-cyber/proclone/proto/proclone/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// defaultRequester is recorded as the owner of jobs started without a requested_by
+const defaultRequester = "grpc"
+
+// Server implements the Proclone gRPC service on top of the cloning service
+type Server struct {
+	proclonev1.UnimplementedProcloneServer
+	Service *cloning.CloningService
+}
+
+// ListenAndServe serves the gRPC API on the address until the listener fails. Every call
+// must present the shared token as a bearer token in its authorization metadata. Without
+// a TLS certificate and key the token would cross the network in the clear, so the API is
+// then only served on the loopback interface.
+func ListenAndServe(address string, token string, certFile string, keyFile string, service *cloning.CloningService) error {
+	if token == "" {
+		return fmt.Errorf("GRPC_AUTH_TOKEN must be set to serve the gRPC API")
+	}
+
+	options := []grpc.ServerOption{grpc.UnaryInterceptor(authInterceptor(token))}
+	if certFile != "" || keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+		}
+		options = append(options, grpc.Creds(creds))
+	} else {
+		loopback, err := loopbackAddress(address)
+		if err != nil {
+			return err
+		}
+		address = loopback
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	grpcServer := grpc.NewServer(options...)
+	proclonev1.RegisterProcloneServer(grpcServer, &Server{Service: service})
+
+	log.Printf("Starting gRPC server on %s", address)
+	return grpcServer.Serve(listener)
+}
+
+// CloneTemplate starts the deployment in the background, callers follow it with GetJob
+func (s *Server) CloneTemplate(ctx context.Context, req *proclonev1.CloneTemplateRequest) (*proclonev1.CloneTemplateResponse, error) {
+	if req.GetTemplate() == "" {
+		return nil, status.Error(codes.InvalidArgument, "template is required")
+	}
+	if len(req.GetTargets()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one target is required")
+	}
+
+	targets := make([]cloning.CloneTarget, 0, len(req.GetTargets()))
+	for _, target := range req.GetTargets() {
+		if target.GetName() == "" {
+			return nil, status.Error(codes.InvalidArgument, "target name is required")
+		}
+		targets = append(targets, cloning.CloneTarget{Name: target.GetName(), IsGroup: target.GetIsGroup()})
+	}
+
+	// Admins may deploy any template the catalog holds, drafts included
+	publishedTemplates, err := s.Service.DatabaseService.GetPublishedTemplates()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch published templates: %v", err)
+	}
+	if !slices.ContainsFunc(publishedTemplates, func(template cloning.KaminoTemplate) bool { return template.Name == req.GetTemplate() }) {
+		return nil, status.Errorf(codes.NotFound, "template %s not found or not published", req.GetTemplate())
+	}
+
+	requestedBy := req.GetRequestedBy()
+	if requestedBy == "" {
+		requestedBy = defaultRequester
+	}
+
+	log.Printf("gRPC client %s requested cloning of template %s", requestedBy, req.GetTemplate())
+
+	jobID := s.Service.StartCloneTemplate(cloning.CloneRequest{
+		Template:     req.GetTemplate(),
+		Targets:      targets,
+		StartingVMID: int(req.GetStartingVmid()),
+		VMNames:      req.GetVmNames(),
+		RequestedBy:  requestedBy,
+		Priority:     cloning.PriorityAdmin,
+	})
+
+	return &proclonev1.CloneTemplateResponse{JobId: jobID}, nil
+}
+
+func (s *Server) DeletePod(ctx context.Context, req *proclonev1.DeletePodRequest) (*proclonev1.DeletePodResponse, error) {
+	if req.GetPod() == "" {
+		return nil, status.Error(codes.InvalidArgument, "pod is required")
+	}
+
+	log.Printf("gRPC client requested deletion of pod %s", req.GetPod())
+
+	if err := s.Service.DeletePod(req.GetPod()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete pod: %v", err)
+	}
+
+	return &proclonev1.DeletePodResponse{}, nil
+}
+
+func (s *Server) ListPods(ctx context.Context, req *proclonev1.ListPodsRequest) (*proclonev1.ListPodsResponse, error) {
+	pods, err := s.Service.FilterPods(cloning.PodFilter{Owner: req.GetOwner()})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list pods: %v", err)
+	}
+
+	response := &proclonev1.ListPodsResponse{Pods: make([]*proclonev1.Pod, 0, len(pods))}
+	for _, pod := range pods {
+		vms := make([]*proclonev1.VM, 0, len(pod.VMs))
+		for _, vm := range pod.VMs {
+			vms = append(vms, &proclonev1.VM{
+				Vmid:   int32(vm.VmId),
+				Name:   vm.Name,
+				Node:   vm.NodeName,
+				Status: vm.RunningStatus,
+			})
+		}
+		response.Pods = append(response.Pods, &proclonev1.Pod{
+			Name:     pod.Name,
+			Template: cloning.PodTemplateName(pod.Name),
+			Vms:      vms,
+			Archived: pod.Archived,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *Server) GetJob(ctx context.Context, req *proclonev1.GetJobRequest) (*proclonev1.Job, error) {
+	job, ok := s.Service.Jobs.Get(req.GetId())
+	if !ok {
+		return nil, status.Error(codes.NotFound, "job not found")
+	}
+
+	return jobToProto(job), nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// authInterceptor rejects calls that do not carry the shared token
+func authInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			presented, found := strings.CutPrefix(value, "Bearer ")
+			if found && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing token")
+	}
+}
+
+// loopbackAddress binds an address without a host to the loopback interface and rejects
+// any other host, used when the API is served without TLS
+func loopbackAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid gRPC address %q: %w", address, err)
+	}
+
+	if host == "" {
+		log.Printf("GRPC_TLS_CERT and GRPC_TLS_KEY are not set, serving the gRPC API on localhost only")
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("GRPC_TLS_CERT and GRPC_TLS_KEY must be set to serve the gRPC API on %s", host)
+	}
+
+	return address, nil
+}
+
+func jobToProto(job jobs.Job) *proclonev1.Job {
+	return &proclonev1.Job{
+		Id:          job.ID,
+		Type:        job.Type,
+		Owner:       job.Owner,
+		Description: job.Description,
+		Status:      job.Status,
+		Progress:    int32(job.Progress),
+		Message:     job.Message,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt.Unix(),
+		UpdatedAt:   job.UpdatedAt.Unix(),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/cpp-cyber/proclone/internal/api/auth"
+	"github.com/cpp-cyber/proclone/internal/audit"
 	"github.com/cpp-cyber/proclone/internal/ldap"
 	"github.com/cpp-cyber/proclone/internal/proxmox"
 	"github.com/gin-contrib/sessions"
@@ -33,12 +34,19 @@ func NewAuthHandler() (*AuthHandler, error) {
 		return nil, fmt.Errorf("failed to create proxmox service: %w", err)
 	}
 
+	accessReviewService, err := audit.NewAccessReviewService(ldapService, proxmoxService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access review service: %w", err)
+	}
+	go accessReviewService.RunPeriodicReviews()
+
 	log.Println("Auth handler initialized")
 
 	return &AuthHandler{
-		authService:    authService,
-		ldapService:    ldapService,
-		proxmoxService: proxmoxService,
+		authService:         authService,
+		ldapService:         ldapService,
+		proxmoxService:      proxmoxService,
+		accessReviewService: accessReviewService,
 	}, nil
 }
 
@@ -374,6 +382,18 @@ func (h *AuthHandler) GetGroupsHandler(c *gin.Context) {
 	})
 }
 
+// ADMIN: GetGroupCacheStatsHandler returns the group membership cache metrics
+func (h *AuthHandler) GetGroupCacheStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ldap.GetGroupCacheStats())
+}
+
+// ADMIN: InvalidateGroupCacheHandler drops cached group memberships, for membership
+// changes made directly in the directory
+func (h *AuthHandler) InvalidateGroupCacheHandler(c *gin.Context) {
+	ldap.InvalidateGroupCache()
+	c.JSON(http.StatusOK, gin.H{"message": "Group membership cache invalidated"})
+}
+
 // ADMIN: CreateGroupsHandler creates new group(s)
 func (h *AuthHandler) CreateGroupsHandler(c *gin.Context) {
 	var req GroupsRequest
@@ -502,3 +522,29 @@ func (h *AuthHandler) RemoveUsersHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Users removed from group successfully"})
 }
+
+// =================================================
+// Access Review Handlers
+// =================================================
+
+// ADMIN: AccessReviewHandler returns the current access review as JSON, or as a CSV download with ?format=csv
+func (h *AuthHandler) AccessReviewHandler(c *gin.Context) {
+	review, err := h.accessReviewService.GenerateAccessReview()
+	if err != nil {
+		log.Printf("Failed to generate access review: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access review", "details": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		filename := fmt.Sprintf("access-review-%s.csv", review.GeneratedAt.Format("20060102-150405"))
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		if err := review.WriteCSV(c.Writer); err != nil {
+			log.Printf("Failed to write access review CSV: %v", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"review": review})
+}
@@ -1,16 +1,23 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/cpp-cyber/proclone/internal/audit"
 	"github.com/cpp-cyber/proclone/internal/cloning"
 	"github.com/cpp-cyber/proclone/internal/ldap"
 	"github.com/cpp-cyber/proclone/internal/proxmox"
 	"github.com/cpp-cyber/proclone/internal/tools"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
 	"github.com/cpp-cyber/proclone/internal/tools/sse"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -43,6 +50,8 @@ func NewCloningHandler() (*CloningHandler, error) {
 	}
 	log.Println("Cloning manager initialized")
 
+	cloningService.StartBackgroundWorkers()
+
 	return &CloningHandler{
 		Service:  cloningService,
 		dbClient: dbClient,
@@ -80,6 +89,22 @@ func (ch *CloningHandler) CloneTemplateHandler(c *gin.Context) {
 		}
 	}
 
+	// Templates scoped to an organization or to groups are only available to their members,
+	// and drafts to their publisher, admins can deploy any of them
+	isAdmin, _ := session.Get("isAdmin").(bool)
+	if templateFound && !isAdmin {
+		canUse, err := ch.Service.CanUseTemplate(username, req.Template)
+		if err != nil {
+			log.Printf("Error checking access to template %s for user %s: %v", req.Template, username, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to verify template access",
+				"details": err.Error(),
+			})
+			return
+		}
+		templateFound = canUse
+	}
+
 	if !templateFound {
 		log.Printf("Template %s not found or not published", req.Template)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -89,10 +114,55 @@ func (ch *CloningHandler) CloneTemplateHandler(c *gin.Context) {
 		return
 	}
 
-	// Check for existing deployments before starting SSE
-	targetPoolName := fmt.Sprintf("%s_%s", req.Template, username)
-	isValid, err := ch.Service.ValidateCloneRequest(targetPoolName, username)
-	if err != nil {
+	// Check for existing deployments and quota before starting SSE
+	target := cloning.CloneTarget{Name: username, IsGroup: false}
+
+	if req.DryRun {
+		ch.planCloneTemplate(c, cloning.CloneRequest{
+			Template:                 req.Template,
+			Targets:                  []cloning.CloneTarget{target},
+			CheckExistingDeployments: true,
+			VMNames:                  req.VMNames,
+		})
+		return
+	}
+
+	// Repeated submissions with the same Idempotency-Key, such as a double-clicked deploy,
+	// are answered from the first request instead of cloning again
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if len(idempotencyKey) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid Idempotency-Key",
+			"details": "Idempotency-Key must be at most 255 characters",
+		})
+		return
+	}
+	if idempotencyKey != "" {
+		if existing, claimed := ch.Service.ClaimIdempotencyKey(username, idempotencyKey, req.Template); !claimed {
+			ch.respondDuplicateClone(c, username, req.Template, existing)
+			return
+		}
+	}
+
+	if err := ch.Service.ValidateCloneRequest(req.Template, target); err != nil {
+		if idempotencyKey != "" {
+			ch.Service.ReleaseIdempotencyKey(username, idempotencyKey)
+		}
+
+		var limitErr *cloning.DeploymentLimitError
+		if errors.As(err, &limitErr) {
+			log.Printf("Clone of template %s blocked for user %s: %v", req.Template, username, err)
+			response := gin.H{
+				"error":   "Deployment not allowed",
+				"details": limitErr.Error(),
+			}
+			if !limitErr.NextAllowed.IsZero() {
+				response["next_allowed"] = limitErr.NextAllowed.UTC()
+			}
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+
 		log.Printf("Error validating deployment for user %s: %v", username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to validate existing deployments",
@@ -100,18 +170,14 @@ func (ch *CloningHandler) CloneTemplateHandler(c *gin.Context) {
 		})
 		return
 	}
-	if !isValid {
-		log.Printf("Template %s is already deployed for user %s or they have exceeded deployment limits", req.Template, username)
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "Deployment not allowed",
-			"details": fmt.Sprintf("Template %s is already deployed for %s or they have exceeded the maximum of 5 deployed pods", req.Template, username),
-		})
-		return
-	}
 
 	// Create new sse object for streaming
 	sseWriter, err := sse.NewWriter(c.Writer)
 	if err != nil {
+		if idempotencyKey != "" {
+			ch.Service.ReleaseIdempotencyKey(username, idempotencyKey)
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to initialize SSE",
 			"details": err.Error(),
@@ -130,16 +196,16 @@ func (ch *CloningHandler) CloneTemplateHandler(c *gin.Context) {
 	cloneReq := cloning.CloneRequest{
 		Template:                 req.Template,
 		CheckExistingDeployments: false, // Already checked above
-		Targets: []cloning.CloneTarget{
-			{
-				Name:    username,
-				IsGroup: false,
-			},
-		},
-		SSE: sseWriter,
+		Targets:                  []cloning.CloneTarget{target},
+		VMNames:                  req.VMNames,
+		UseWarmPool:              len(req.VMNames) == 0, // Warm pods hold every VM of the template
+		IdempotencyKey:           idempotencyKey,
+		RequestedBy:              username,
+		Priority:                 sessionClonePriority(session),
+		SSE:                      sseWriter,
 	}
 
-	if err := ch.Service.CloneTemplate(cloneReq); err != nil {
+	if err := ch.Service.CloneTemplate(context.Background(), cloneReq); err != nil {
 		log.Printf("Error cloning template: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to clone template",
@@ -152,6 +218,44 @@ func (ch *CloningHandler) CloneTemplateHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+// sessionClonePriority returns the clone queue class of the session's user, creators
+// deploy for their classes and are queued as instructors
+func sessionClonePriority(session sessions.Session) cloning.ClonePriority {
+	if isAdmin, _ := session.Get("isAdmin").(bool); isAdmin {
+		return cloning.PriorityAdmin
+	}
+	if isCreator, _ := session.Get("isCreator").(bool); isCreator {
+		return cloning.PriorityInstructor
+	}
+
+	return cloning.PriorityUser
+}
+
+// respondDuplicateClone answers a clone request whose idempotency key was already used
+func (ch *CloningHandler) respondDuplicateClone(c *gin.Context, username string, template string, existing *cloning.IdempotentClone) {
+	if existing.Template != template {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Idempotency-Key reused",
+			"details": fmt.Sprintf("The key was already used to clone template %s", existing.Template),
+		})
+		return
+	}
+
+	log.Printf("Ignoring duplicate clone of template %s for user %s", template, username)
+
+	job, found := ch.Service.Jobs.Get(existing.JobID)
+	if !found || job.Status == jobs.StatusRunning {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Clone already in progress",
+			"details": fmt.Sprintf("A clone of template %s with this Idempotency-Key is already in progress", template),
+			"job_id":  existing.JobID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "duplicate": true, "job_id": existing.JobID})
+}
+
 // ADMIN: BulkCloneTemplateHandler handles POST requests for cloning multiple templates for a list of users
 func (ch *CloningHandler) AdminCloneTemplateHandler(c *gin.Context) {
 	session := sessions.Default(c)
@@ -162,25 +266,32 @@ func (ch *CloningHandler) AdminCloneTemplateHandler(c *gin.Context) {
 		return
 	}
 
-	log.Printf("%s requested bulk cloning of template %s", username, req.Template)
-
-	// Build targets slice from usernames and groups
-	var targets []cloning.CloneTarget
-
-	// Add users as targets
-	for _, user := range req.Usernames {
-		targets = append(targets, cloning.CloneTarget{
-			Name:    user,
-			IsGroup: false,
+	if req.TargetNode != "" && len(req.Nodes) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid placement",
+			"details": "target_node and nodes cannot be combined",
 		})
+		return
 	}
 
-	// Add groups as targets
-	for _, group := range req.Groups {
-		targets = append(targets, cloning.CloneTarget{
-			Name:    group,
-			IsGroup: true,
+	log.Printf("%s requested bulk cloning of template %s", username, req.Template)
+
+	// Build targets slice from usernames and groups
+	targets := buildCloneTargets(req.Usernames, req.Groups)
+
+	if req.DryRun {
+		ch.planCloneTemplate(c, cloning.CloneRequest{
+			Template:     req.Template,
+			Targets:      targets,
+			StartingVMID: req.StartingVMID,
+			CloneMode:    req.CloneMode,
+			Storage:      req.Storage,
+			HAGroup:      req.HAGroup,
+			Nodes:        req.Nodes,
+			TargetNode:   req.TargetNode,
+			VMNames:      req.VMNames,
 		})
+		return
 	}
 
 	// Create new sse object for streaming
@@ -199,11 +310,19 @@ func (ch *CloningHandler) AdminCloneTemplateHandler(c *gin.Context) {
 		Targets:                  targets,
 		CheckExistingDeployments: false,
 		StartingVMID:             req.StartingVMID,
+		CloneMode:                req.CloneMode,
+		Storage:                  req.Storage,
+		HAGroup:                  req.HAGroup,
+		Nodes:                    req.Nodes,
+		TargetNode:               req.TargetNode,
+		VMNames:                  req.VMNames,
+		RequestedBy:              username,
+		Priority:                 cloning.PriorityAdmin,
 		SSE:                      sseWriter,
 	}
 
 	// Perform clone operation
-	err = ch.Service.CloneTemplate(cloneReq)
+	err = ch.Service.CloneTemplate(context.Background(), cloneReq)
 	if err != nil {
 		log.Printf("Admin %s encountered error while bulk cloning template: %v", username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -219,6 +338,21 @@ func (ch *CloningHandler) AdminCloneTemplateHandler(c *gin.Context) {
 	})
 }
 
+// planCloneTemplate responds with the feasibility report of a dry-run clone request
+func (ch *CloningHandler) planCloneTemplate(c *gin.Context, cloneReq cloning.CloneRequest) {
+	plan, err := ch.Service.PlanCloneTemplate(cloneReq)
+	if err != nil {
+		log.Printf("Error planning clone of template %s: %v", cloneReq.Template, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to plan clone",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plan": plan})
+}
+
 // DeletePodHandler handles requests to delete a pod
 func (ch *CloningHandler) DeletePodHandler(c *gin.Context) {
 	session := sessions.Default(c)
@@ -253,266 +387,2923 @@ func (ch *CloningHandler) DeletePodHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Pod deleted successfully"})
 }
 
-func (ch *CloningHandler) AdminDeletePodHandler(c *gin.Context) {
+// PRIVATE: ExportPodVMHandler handles POST requests for exporting a VM from the user's pod
+func (ch *CloningHandler) ExportPodVMHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	var req AdminDeletePodRequest
+	var req ExportPodVMRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	log.Printf("Admin %s requested deletion of pods: %v", username, req.Pods)
+	log.Printf("User %s requested export of VM %d from pod %s", username, req.VMID, req.Pod)
 
-	var errors []error
-	for _, pod := range req.Pods {
-		err := ch.Service.DeletePod(pod)
-		if err != nil {
-			errors = append(errors, fmt.Errorf("failed to delete pod %s: %v", pod, err))
-		}
+	canAccess, err := ch.Service.CanAccessPod(username, req.Pod)
+	if err != nil {
+		log.Printf("Error checking access to pod %s for user %s: %v", req.Pod, username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to verify pod ownership",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !canAccess {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "You do not have permission to export this pod",
+			"details": fmt.Sprintf("Pod %s does not belong to user %s", req.Pod, username),
+		})
+		return
 	}
 
-	if len(errors) > 0 {
-		log.Printf("Admin %s encountered errors while deleting pods: %v", username, errors)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete pods",
-			"details": errors,
+	export, err := ch.Service.RequestPodExport(req.Pod, req.VMID, username)
+	if err != nil {
+		log.Printf("Error exporting VM %d from pod %s: %v", req.VMID, req.Pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to export VM",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Pods deleted successfully"})
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Export started",
+		"export":  export,
+	})
 }
 
-func (ch *CloningHandler) GetUnpublishedTemplatesHandler(c *gin.Context) {
-	templates, err := ch.Service.GetUnpublishedTemplates()
+// PRIVATE: GetPodExportsHandler handles GET requests for listing the user's exports
+func (ch *CloningHandler) GetPodExportsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	exports, err := ch.Service.DatabaseService.GetPodExports(username)
 	if err != nil {
+		log.Printf("Error retrieving exports for user %s: %v", username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve unpublished templates",
+			"error":   "Failed to retrieve exports",
 			"details": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"templates": templates,
-		"count":     len(templates),
+		"exports": exports,
+		"count":   len(exports),
 	})
 }
 
-// PRIVATE: GetPodsHandler handles GET requests for retrieving a user's pods
-func (ch *CloningHandler) GetPodsHandler(c *gin.Context) {
+// PRIVATE: DownloadPodExportHandler handles GET requests for downloading a completed export
+func (ch *CloningHandler) DownloadPodExportHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	pods, err := ch.Service.GetPods(username)
+	path, err := ch.Service.GetPodExportFile(c.Param("token"), username)
 	if err != nil {
-		log.Printf("Error retrieving pods for user %s: %v", username, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pods", "details": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Export not available",
+			"details": err.Error(),
+		})
 		return
 	}
 
-	// Loop through the user's deployed pods and add template information
-	for i := range pods {
-		templateName := strings.Replace(strings.ToLower(pods[i].Name[5:]), fmt.Sprintf("_%s", strings.ToLower(username)), "", 1)
-		templateInfo, err := ch.Service.DatabaseService.GetTemplateInfo(templateName)
-		if err != nil {
-			log.Printf("Error retrieving template info for pod %s: %v", pods[i].Name, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template info for pod", "details": err.Error()})
-			return
-		}
-		pods[i].Template = templateInfo
-	}
-
-	c.JSON(http.StatusOK, gin.H{"pods": pods})
+	c.FileAttachment(path, filepath.Base(path))
 }
 
-// ADMIN: AdminGetPodsHandler handles GET requests for retrieving all pods
-func (ch *CloningHandler) AdminGetPodsHandler(c *gin.Context) {
+func (ch *CloningHandler) AdminDeletePodHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	pods, err := ch.Service.AdminGetPods()
-	if err != nil {
-		log.Printf("Error retrieving all pods for admin %s: %v", username, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pods for user", "details": err.Error()})
+	var req AdminDeletePodRequest
+	if !validateAndBind(c, &req) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pods": pods})
-}
+	log.Printf("Admin %s requested deletion of pods: %v", username, req.Pods)
 
-// PRIVATE: GetTemplatesHandler handles GET requests for retrieving templates
-func (ch *CloningHandler) GetTemplatesHandler(c *gin.Context) {
-	templates, err := ch.Service.DatabaseService.GetTemplates()
-	if err != nil {
-		log.Printf("Error retrieving templates: %v", err)
+	var errors []error
+	for _, pod := range req.Pods {
+		err := ch.Service.DeletePod(pod)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("failed to delete pod %s: %v", pod, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		log.Printf("Admin %s encountered errors while deleting pods: %v", username, errors)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve templates",
-			"details": err.Error(),
+			"error":   "Failed to delete pods",
+			"details": errors,
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"templates": templates,
-		"count":     len(templates),
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Pods deleted successfully"})
 }
 
-// ADMIN: GetPublishedTemplatesHandler handles GET requests for retrieving all templates
-func (ch *CloningHandler) AdminGetTemplatesHandler(c *gin.Context) {
+// ADMIN: AdminDeletePodsByFilterHandler deletes every pod matching a filter, or with
+// dry_run only lists the pods that would be deleted
+func (ch *CloningHandler) AdminDeletePodsByFilterHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	templates, err := ch.Service.DatabaseService.GetPublishedTemplates()
+	var req AdminDeletePodsByFilterRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if req.Template == "" && req.Owner == "" && req.MinAgeHours == 0 && req.MinPodID == 0 && req.MaxPodID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No filter given",
+			"details": "At least one of template, owner, min_age_hours, min_pod_id or max_pod_id is required",
+		})
+		return
+	}
+
+	filter := cloning.PodFilter{
+		Template: req.Template,
+		Owner:    req.Owner,
+		MinAge:   time.Duration(req.MinAgeHours) * time.Hour,
+		MinPodID: req.MinPodID,
+		MaxPodID: req.MaxPodID,
+	}
+	pods, err := ch.Service.FilterPods(filter)
 	if err != nil {
-		log.Printf("Error retrieving all templates for admin %s: %v", username, err)
+		log.Printf("Error filtering pods for admin %s: %v", username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve all templates",
+			"error":   "Failed to filter pods",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"templates": templates,
-		"count":     len(templates),
-	})
-}
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
 
-// PRIVATE: GetTemplateImageHandler handles GET requests for retrieving a template's image
-func (ch *CloningHandler) GetTemplateImageHandler(c *gin.Context) {
-	filename := c.Param("filename")
-	config := ch.Service.DatabaseService.GetTemplateConfig()
-	filePath := filepath.Join(config.UploadDir, filename)
+	if req.DryRun {
+		c.JSON(http.StatusOK, gin.H{"dry_run": true, "pods": names, "count": len(names)})
+		return
+	}
 
-	// Serve the file
-	c.File(filePath)
+	log.Printf("Admin %s requested deletion of %d pods by filter %+v", username, len(names), filter)
+	audit.Record(username, "delete_pods_by_filter", fmt.Sprintf("%d pods", len(names)), strings.Join(names, ","))
+
+	deleted := []string{}
+	var errors []error
+	for _, pod := range names {
+		if err := ch.Service.DeletePod(pod); err != nil {
+			errors = append(errors, fmt.Errorf("failed to delete pod %s: %v", pod, err))
+			continue
+		}
+		deleted = append(deleted, pod)
+	}
+
+	if len(errors) > 0 {
+		log.Printf("Admin %s encountered errors while deleting pods by filter: %v", username, errors)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete pods",
+			"details": errors,
+			"deleted": deleted,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pods deleted successfully", "deleted": deleted, "count": len(deleted)})
 }
 
-// ADMIN: PublishTemplateHandler handles POST requests for publishing a template
-func (ch *CloningHandler) PublishTemplateHandler(c *gin.Context) {
+// ADMIN: RebalanceNodesHandler live-migrates pods off nodes above the memory threshold,
+// or with dry_run only returns the planned migrations
+func (ch *CloningHandler) RebalanceNodesHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	var req PublishTemplateRequest
+	var req RebalanceNodesRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	log.Printf("Admin %s requested publishing of template %s", username, req.Template.Name)
+	if req.DryRun {
+		plan, err := ch.Service.PlanRebalance()
+		if err != nil {
+			log.Printf("Error planning rebalance for admin %s: %v", username, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to plan rebalance",
+				"details": err.Error(),
+			})
+			return
+		}
 
-	if err := ch.Service.PublishTemplate(req.Template); err != nil {
-		log.Printf("Error publishing template for admin %s: %v", username, err)
+		c.JSON(http.StatusOK, gin.H{"dry_run": true, "plan": plan})
+		return
+	}
+
+	plan, jobID, err := ch.Service.RebalanceNodes(username)
+	if err != nil {
+		log.Printf("Error starting rebalance for admin %s: %v", username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to publish template",
+			"error":   "Failed to start rebalance",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Template published successfully",
-	})
-}
+	if jobID == "" {
+		c.JSON(http.StatusOK, gin.H{"message": "No pods need to be moved", "plan": plan})
+		return
+	}
 
-// ADMIN: EditTemplateHandler handles POST requests for editing a published template
-func (ch *CloningHandler) EditTemplateHandler(c *gin.Context) {
-	session := sessions.Default(c)
-	username := session.Get("id").(string)
+	log.Printf("Admin %s started rebalance job %s moving %d pods", username, jobID, len(plan.Moves))
+	audit.Record(username, "rebalance_nodes", fmt.Sprintf("%d pods", len(plan.Moves)), jobID)
 
-	var req PublishTemplateRequest
-	if !validateAndBind(c, &req) {
+	c.JSON(http.StatusAccepted, gin.H{"job_id": jobID, "plan": plan})
+}
+
+// ADMIN: GetIPAMHandler handles GET requests for the WAN subnets allocated to pods and the
+// conflicts found among them
+func (ch *CloningHandler) GetIPAMHandler(c *gin.Context) {
+	report, err := ch.Service.GetIPAMReport()
+	if err != nil {
+		log.Printf("Error building IPAM report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get subnet allocations",
+			"details": err.Error(),
+		})
 		return
 	}
 
-	log.Printf("Admin %s requested editing of template %s", username, req.Template.Name)
+	c.JSON(http.StatusOK, gin.H{"ipam": report})
+}
 
-	if err := ch.Service.DatabaseService.EditTemplate(req.Template); err != nil {
-		log.Printf("Error editing template for admin %s: %v", username, err)
+// ADMIN: GetVLANReportHandler handles GET requests for the utilization of the pod VNets and
+// their VLANs
+func (ch *CloningHandler) GetVLANReportHandler(c *gin.Context) {
+	report, err := ch.Service.GetVLANReport()
+	if err != nil {
+		log.Printf("Error building VLAN report: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to edit template",
+			"error":   "Failed to get VLAN utilization",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Template edited successfully",
-	})
+	c.JSON(http.StatusOK, gin.H{"vlans": report})
 }
 
-// ADMIN: DeleteTemplateHandler handles POST requests for deleting a template
-func (ch *CloningHandler) DeleteTemplateHandler(c *gin.Context) {
+// ADMIN: SetVNetSettingsHandler handles POST requests for changing the VNet prefix, zone,
+// and VLAN base new pods are deployed with
+func (ch *CloningHandler) SetVNetSettingsHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	var req TemplateRequest
+	var req VNetSettingsRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	log.Printf("Admin %s requested deletion of template %s", username, req.Template)
+	log.Printf("Admin %s set VNet settings to prefix %s, zone %q, VLAN base %d", username, req.VNetPrefix, req.Zone, req.VLANBase)
 
-	if err := ch.Service.DatabaseService.DeleteTemplate(req.Template); err != nil {
-		log.Printf("Error deleting template for admin %s: %v", username, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete template",
+	settings := cloning.VNetSettings{VNetPrefix: req.VNetPrefix, Zone: req.Zone, VLANBase: req.VLANBase, UpdatedBy: username}
+	if err := ch.Service.SetVNetSettings(settings); err != nil {
+		log.Printf("Error saving VNet settings: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to save VNet settings",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	audit.Record(username, "set_vnet_settings", req.VNetPrefix, fmt.Sprintf("zone=%s vlan_base=%d", req.Zone, req.VLANBase))
+	c.JSON(http.StatusOK, gin.H{"message": "VNet settings saved successfully"})
+}
+
+// ADMIN: AddVLANReservationHandler handles POST requests for keeping a range of VLAN tags
+// out of pod deployments
+func (ch *CloningHandler) AddVLANReservationHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req VLANReservationRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	reservation, err := ch.Service.AddVLANReservation(cloning.VLANReservation{
+		FirstTag:  req.FirstTag,
+		LastTag:   req.LastTag,
+		Reason:    req.Reason,
+		CreatedBy: username,
+	})
+	if err != nil {
+		log.Printf("Error reserving VLANs %d-%d: %v", req.FirstTag, req.LastTag, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to reserve VLANs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "reserve_vlans", fmt.Sprintf("%d-%d", req.FirstTag, req.LastTag), req.Reason)
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
+
+// ADMIN: DeleteVLANReservationHandler handles POST requests for releasing reserved VLAN tags
+func (ch *CloningHandler) DeleteVLANReservationHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req DeleteVLANReservationRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if err := ch.Service.DeleteVLANReservation(req.ID); err != nil {
+		log.Printf("Error deleting VLAN reservation %d: %v", req.ID, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete VLAN reservation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "release_vlans", strconv.FormatInt(req.ID, 10), "")
+	c.JSON(http.StatusOK, gin.H{"message": "VLAN reservation deleted successfully"})
+}
+
+// ADMIN: GetCapacityHandler reports the free pod IDs, VNets, and WAN subnets and when they
+// are projected to run out
+func (ch *CloningHandler) GetCapacityHandler(c *gin.Context) {
+	report, err := ch.Service.GetCapacityReport()
+	if err != nil {
+		log.Printf("Error building capacity report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get capacity",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"capacity": report})
+}
+
+// ADMIN: SmokeTestHandler clones, starts, and deletes the smoke test template to confirm
+// the deployment pipeline works, reporting the timing of each step
+func (ch *CloningHandler) SmokeTestHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	log.Printf("Admin %s started a smoke test", username)
+	audit.Record(username, "smoke_test", ch.Service.Config.SmokeTestTemplate, "")
+
+	result, err := ch.Service.RunSmokeTest(username)
+	if err != nil {
+		log.Printf("Error running smoke test for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to run smoke test",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+// PRIVATE: SharePodHandler grants another user read-only or full access to one of the user's pods
+func (ch *CloningHandler) SharePodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req SharePodRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s is sharing pod %s with %s (%s access)", username, pod, req.Username, req.Access)
+
+	if err := ch.Service.SharePod(pod, username, req.Username, req.Access); err != nil {
+		log.Printf("Error sharing pod %s with %s: %v", pod, req.Username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to share pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod shared successfully"})
+}
+
+// PRIVATE: UnsharePodHandler revokes a user's shared access to one of the user's pods
+func (ch *CloningHandler) UnsharePodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req UnsharePodRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s is revoking access of %s to pod %s", username, req.Username, pod)
+
+	if err := ch.Service.UnsharePod(pod, req.Username); err != nil {
+		log.Printf("Error revoking access of %s to pod %s: %v", req.Username, pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to revoke pod share",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod share revoked successfully"})
+}
+
+// PRIVATE: GetPodSharesHandler lists the users one of the user's pods is shared with
+func (ch *CloningHandler) GetPodSharesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	shares, err := ch.Service.DatabaseService.GetPodShares(pod)
+	if err != nil {
+		log.Printf("Error retrieving shares for pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pod shares", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+// requirePodOwner writes a forbidden response and returns false unless the pod
+// belongs to the user or one of their groups
+func (ch *CloningHandler) requirePodOwner(c *gin.Context, username string, pod string) bool {
+	canAccess, err := ch.Service.CanAccessPod(username, pod)
+	if err != nil {
+		log.Printf("Error checking access to pod %s for user %s: %v", pod, username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to verify pod ownership",
+			"details": err.Error(),
+		})
+		return false
+	}
+	if !canAccess {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "You do not have permission to manage this pod",
+			"details": fmt.Sprintf("Pod %s does not belong to user %s", pod, username),
+		})
+		return false
+	}
+
+	retained, err := ch.Service.IsPodRetained(pod)
+	if err != nil {
+		log.Printf("Error checking retention of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to verify pod ownership",
+			"details": err.Error(),
+		})
+		return false
+	}
+	if retained {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Pod is retained",
+			"details": fmt.Sprintf("Pod %s is retained and must be restored by an admin", pod),
+		})
+		return false
+	}
+	return true
+}
+
+// PRIVATE: SuspendPodHandler hibernates the running VMs of one of the user's pods to disk
+func (ch *CloningHandler) SuspendPodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested suspension of pod %s", username, pod)
+
+	if err := ch.Service.SuspendPod(pod); err != nil {
+		log.Printf("Error suspending pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to suspend pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod suspended successfully"})
+}
+
+// PRIVATE: ResetPodVMHandler re-clones a single VM of one of the user's pods from its template
+func (ch *CloningHandler) ResetPodVMHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	vmID, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid VM ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested reset of VM %d in pod %s", username, vmID, pod)
+
+	if err := ch.Service.ResetPodVM(pod, vmID); err != nil {
+		log.Printf("Error resetting VM %d in pod %s: %v", vmID, pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reset VM",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "VM reset successfully"})
+}
+
+// PRIVATE: GetPodVMSnapshotsHandler lists the snapshots of a VM in one of the user's pods
+func (ch *CloningHandler) GetPodVMSnapshotsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	vmID, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid VM ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	snapshots, err := ch.Service.GetPodVMSnapshots(pod, vmID)
+	if err != nil {
+		log.Printf("Error retrieving snapshots of VM %d in pod %s: %v", vmID, pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve VM snapshots",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots":     snapshots,
+		"max_snapshots": ch.Service.Config.MaxVMSnapshots,
+	})
+}
+
+// PRIVATE: CreatePodVMSnapshotHandler snapshots a VM in one of the user's pods
+func (ch *CloningHandler) CreatePodVMSnapshotHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	vmID, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid VM ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var req CreateVMSnapshotRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested snapshot %s of VM %d in pod %s", username, req.Name, vmID, pod)
+
+	if err := ch.Service.CreatePodVMSnapshot(c.Request.Context(), pod, vmID, req.Name, req.Description); err != nil {
+		log.Printf("Error creating snapshot %s of VM %d in pod %s: %v", req.Name, vmID, pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create VM snapshot",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "create_vm_snapshot", pod, fmt.Sprintf("%d:%s", vmID, req.Name))
+	c.JSON(http.StatusOK, gin.H{"message": "Snapshot created successfully"})
+}
+
+// PRIVATE: RollbackPodVMSnapshotHandler returns a VM in one of the user's pods to one of
+// its snapshots
+func (ch *CloningHandler) RollbackPodVMSnapshotHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+	snapshot := c.Param("snapshot")
+
+	vmID, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid VM ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested rollback of VM %d in pod %s to snapshot %s", username, vmID, pod, snapshot)
+
+	if err := ch.Service.RollbackPodVMSnapshot(c.Request.Context(), pod, vmID, snapshot); err != nil {
+		log.Printf("Error rolling back VM %d in pod %s to snapshot %s: %v", vmID, pod, snapshot, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to roll back VM snapshot",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "rollback_vm_snapshot", pod, fmt.Sprintf("%d:%s", vmID, snapshot))
+	c.JSON(http.StatusOK, gin.H{"message": "VM rolled back successfully"})
+}
+
+// PRIVATE: GetPodVMMetricsHandler returns the CPU, memory, disk, and network history of a
+// VM in one of the user's pods over the timeframe query parameter, an hour by default
+func (ch *CloningHandler) GetPodVMMetricsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+	timeframe := c.DefaultQuery("timeframe", "hour")
+
+	vmID, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid VM ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !slices.Contains(proxmox.MetricTimeframes, timeframe) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid timeframe",
+			"details": "timeframe must be one of " + strings.Join(proxmox.MetricTimeframes, ", "),
+		})
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	metrics, err := ch.Service.GetPodVMMetrics(pod, vmID, timeframe)
+	if err != nil {
+		log.Printf("Error retrieving metrics of VM %d in pod %s: %v", vmID, pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve VM metrics",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timeframe": timeframe, "metrics": metrics})
+}
+
+// PRIVATE: GetPodHealthHandler reports the health of one of the user's pods
+func (ch *CloningHandler) GetPodHealthHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	health, err := ch.Service.GetPodHealth(pod)
+	if err != nil {
+		log.Printf("Error checking health of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check pod health",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
+
+// PRIVATE: GetPodRouterHandler reports the agent reachability, WAN address, and NAT status
+// of the router of one of the user's pods
+func (ch *CloningHandler) GetPodRouterHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	report, err := ch.Service.GetPodRouterReport(pod)
+	if err != nil {
+		log.Printf("Error checking router of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check pod router",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// PRIVATE: ReconfigurePodRouterHandler runs the router configuration of one of the user's
+// pods again
+func (ch *CloningHandler) ReconfigurePodRouterHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	if err := ch.Service.ReconfigurePodRouter(pod); err != nil {
+		log.Printf("Error reconfiguring router of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reconfigure pod router",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "reconfigure_pod_router", pod, "")
+	c.JSON(http.StatusOK, gin.H{"message": "Pod router reconfigured successfully"})
+}
+
+// PRIVATE: GetPodGuideHandler handles GET requests for the lab guide of the template one
+// of the user's pods was deployed from, returned as sanitized HTML
+func (ch *CloningHandler) GetPodGuideHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	guide, err := ch.Service.GetPodGuide(pod)
+	if err != nil {
+		log.Printf("Error retrieving guide of pod %s: %v", pod, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to retrieve pod guide",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"guide": guide})
+}
+
+// PRIVATE: GetPodPortForwardsHandler handles GET requests for the ports forwarded to one of
+// the user's pods
+func (ch *CloningHandler) GetPodPortForwardsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	forwards, err := ch.Service.GetPodPortForwards(pod)
+	if err != nil {
+		log.Printf("Error retrieving port forwards for pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pod port forwards", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"port_forwards": forwards})
+}
+
+// PRIVATE: AddPodPortForwardHandler handles POST requests for forwarding a port of the WAN
+// address of one of the user's pods to a VM inside the pod
+func (ch *CloningHandler) AddPodPortForwardHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req PodPortForwardRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested port forward %s/%d to %s:%d on pod %s", username, req.Protocol, req.ExternalPort, req.InternalIP, req.InternalPort, pod)
+
+	forward, err := ch.Service.AddPodPortForward(cloning.PodPortForward{
+		Pod:          pod,
+		Protocol:     req.Protocol,
+		ExternalPort: req.ExternalPort,
+		InternalIP:   req.InternalIP,
+		InternalPort: req.InternalPort,
+		Description:  req.Description,
+		CreatedBy:    username,
+	})
+	if err != nil {
+		log.Printf("Error adding port forward to pod %s: %v", pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add pod port forward",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "add_pod_port_forward", pod, fmt.Sprintf("%s/%d -> %s:%d", req.Protocol, req.ExternalPort, req.InternalIP, req.InternalPort))
+	c.JSON(http.StatusOK, gin.H{"port_forward": forward})
+}
+
+// PRIVATE: DeletePodPortForwardHandler handles POST requests for removing a port forward
+// from one of the user's pods
+func (ch *CloningHandler) DeletePodPortForwardHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req DeletePodPortForwardRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested removal of port forward %d from pod %s", username, req.ID, pod)
+
+	if err := ch.Service.DeletePodPortForward(pod, req.ID); err != nil {
+		log.Printf("Error removing port forward %d from pod %s: %v", req.ID, pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete pod port forward",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "delete_pod_port_forward", pod, strconv.FormatInt(req.ID, 10))
+	c.JSON(http.StatusOK, gin.H{"message": "Pod port forward deleted successfully"})
+}
+
+// PRIVATE: GetPodVPNConfigsHandler handles GET requests for the user's VPN configs of one
+// of their pods
+func (ch *CloningHandler) GetPodVPNConfigsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	configs, err := ch.Service.GetPodVPNConfigs(pod, username)
+	if err != nil {
+		log.Printf("Error retrieving VPN configs of pod %s for user %s: %v", pod, username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve VPN configs", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"configs": configs})
+}
+
+// PRIVATE: CreatePodVPNConfigHandler handles POST requests for a WireGuard config giving the
+// user access to one of their pods. The config file is only included in this response.
+func (ch *CloningHandler) CreatePodVPNConfigHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested a VPN config for pod %s", username, pod)
+
+	config, err := ch.Service.CreatePodVPNConfig(pod, username)
+	if err != nil {
+		log.Printf("Error creating VPN config of pod %s for user %s: %v", pod, username, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create VPN config",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "create_vpn_config", pod, config.Address)
+	c.JSON(http.StatusOK, gin.H{"config": config})
+}
+
+// PRIVATE: RevokePodVPNConfigHandler handles POST requests for revoking one of the user's
+// VPN configs
+func (ch *CloningHandler) RevokePodVPNConfigHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req RevokePodVPNConfigRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested revocation of VPN config %d of pod %s", username, req.ID, pod)
+
+	if err := ch.Service.RevokePodVPNConfig(pod, username, req.ID); err != nil {
+		log.Printf("Error revoking VPN config %d of pod %s: %v", req.ID, pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to revoke VPN config",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "revoke_vpn_config", pod, strconv.FormatInt(req.ID, 10))
+	c.JSON(http.StatusOK, gin.H{"message": "VPN config revoked successfully"})
+}
+
+// PRIVATE: ResumePodHandler resumes the suspended VMs of one of the user's pods
+func (ch *CloningHandler) ResumePodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested resumption of pod %s", username, pod)
+
+	if err := ch.Service.ResumePod(pod); err != nil {
+		log.Printf("Error resuming pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to resume pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod resumed successfully"})
+}
+
+// PRIVATE: ArchivePodHandler backs one of the user's pods up and removes its VMs, keeping
+// the work without using cluster resources
+func (ch *CloningHandler) ArchivePodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested archival of pod %s", username, pod)
+
+	jobID, err := ch.Service.ArchivePod(pod, username)
+	if err != nil {
+		log.Printf("Error archiving pod %s: %v", pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to archive pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Pod archive started", "job_id": jobID})
+}
+
+// PRIVATE: RehydratePodHandler restores the VMs of one of the user's archived pods
+func (ch *CloningHandler) RehydratePodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	if !ch.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	log.Printf("User %s requested rehydration of pod %s", username, pod)
+
+	jobID, err := ch.Service.RehydratePod(pod, username)
+	if err != nil {
+		log.Printf("Error rehydrating pod %s: %v", pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to rehydrate pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Pod rehydration started", "job_id": jobID})
+}
+
+// ADMIN: BackupTemplateHandler starts a backup of every VM of a template's pool
+func (ch *CloningHandler) BackupTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("template")
+
+	log.Printf("%s requested backup of template %s", username, templateName)
+
+	jobID, err := ch.Service.BackupPools([]string{"kamino_template_" + templateName}, username)
+	if err != nil {
+		log.Printf("Error backing up template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to back up template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Template backup started", "job_id": jobID})
+}
+
+// ADMIN: GetTemplateBackupsHandler lists the backups of a template's VMs
+func (ch *CloningHandler) GetTemplateBackupsHandler(c *gin.Context) {
+	templateName := c.Param("template")
+
+	backups, err := ch.Service.GetPoolBackups("kamino_template_" + templateName)
+	if err != nil {
+		log.Printf("Error retrieving backups of template %s: %v", templateName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template backups", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backups": backups})
+}
+
+// ADMIN: RestoreTemplateBackupHandler restores a template VM from one of its backups
+func (ch *CloningHandler) RestoreTemplateBackupHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("template")
+
+	var req RestoreBackupRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("%s requested restore of %s into template %s", username, req.VolID, templateName)
+
+	jobID, err := ch.Service.RestorePoolBackup("kamino_template_"+templateName, req.VolID, username)
+	if err != nil {
+		log.Printf("Error restoring %s into template %s: %v", req.VolID, templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to restore template backup",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "restore_template_backup", templateName, req.VolID)
+	c.JSON(http.StatusAccepted, gin.H{"message": "Template restore started", "job_id": jobID})
+}
+
+// ADMIN: AdminBackupPodHandler starts a backup of every VM of a pod
+func (ch *CloningHandler) AdminBackupPodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	log.Printf("Admin %s requested backup of pod %s", username, pod)
+
+	jobID, err := ch.Service.BackupPools([]string{pod}, username)
+	if err != nil {
+		log.Printf("Error backing up pod %s: %v", pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to back up pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Pod backup started", "job_id": jobID})
+}
+
+// ADMIN: AdminGetPodBackupsHandler lists the backups of a pod's VMs
+func (ch *CloningHandler) AdminGetPodBackupsHandler(c *gin.Context) {
+	pod := c.Param("pod")
+
+	backups, err := ch.Service.GetPoolBackups(pod)
+	if err != nil {
+		log.Printf("Error retrieving backups of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pod backups", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backups": backups})
+}
+
+// ADMIN: AdminRestorePodBackupHandler restores a pod VM from one of its backups
+func (ch *CloningHandler) AdminRestorePodBackupHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req RestoreBackupRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested restore of %s into pod %s", username, req.VolID, pod)
+
+	jobID, err := ch.Service.RestorePoolBackup(pod, req.VolID, username)
+	if err != nil {
+		log.Printf("Error restoring %s into pod %s: %v", req.VolID, pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to restore pod backup",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "restore_pod_backup", pod, req.VolID)
+	c.JSON(http.StatusAccepted, gin.H{"message": "Pod restore started", "job_id": jobID})
+}
+
+// ADMIN: AdminTransferPodHandler reassigns a pod to a different user or group
+func (ch *CloningHandler) AdminTransferPodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req TransferPodRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested transfer of pod %s to %s (group: %t)", username, req.Pod, req.NewOwner, req.IsGroup)
+
+	newPod, err := ch.Service.TransferPod(req.Pod, req.NewOwner, req.IsGroup)
+	if err != nil {
+		log.Printf("Failed to transfer pod %s: %v", req.Pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to transfer pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod transferred successfully", "pod": newPod})
+}
+
+// ADMIN: AdminComparePodsHandler reports the differences between two pods of the same template
+func (ch *CloningHandler) AdminComparePodsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req ComparePodsRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested comparison of pods %s and %s", username, req.PodA, req.PodB)
+	if len(req.Files) > 0 {
+		audit.Record(username, "compare_pod_files", req.PodA+","+req.PodB, fmt.Sprintf("%d files", len(req.Files)))
+	}
+
+	comparison, err := ch.Service.ComparePods(req.PodA, req.PodB, req.Files)
+	if err != nil {
+		log.Printf("Failed to compare pods %s and %s: %v", req.PodA, req.PodB, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to compare pods",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// ADMIN: AdminRepairPodHandler re-clones the VMs of a pod that failed to clone
+func (ch *CloningHandler) AdminRepairPodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	log.Printf("Admin %s requested repair of pod %s", username, pod)
+
+	result, err := ch.Service.RepairPod(pod)
+	if err != nil {
+		log.Printf("Failed to repair pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to repair pod",
+			"details": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod repaired successfully", "result": result})
+}
+
+// ADMIN: RepairPodNetworkHandler moves VM NICs of a pod that drifted off the pod VNet back
+// to it, or with dry_run=true only reports them
+func (ch *CloningHandler) RepairPodNetworkHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+	dryRun := c.Query("dry_run") == "true"
+
+	log.Printf("Admin %s requested network repair of pod %s (dry run: %t)", username, pod, dryRun)
+
+	result, err := ch.Service.RepairPodNetwork(pod, dryRun)
+	if err != nil {
+		log.Printf("Failed to repair network of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to repair pod network",
+			"details": err.Error(),
+			"result":  result,
+		})
+		return
+	}
+
+	if !dryRun && len(result.Drift) > 0 {
+		audit.Record(username, "repair_pod_network", pod, fmt.Sprintf("%d NICs moved to %s", len(result.Drift), result.VNet))
+	}
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+// ADMIN: SetPodRateLimitHandler overrides the uplink rate limit of a pod
+func (ch *CloningHandler) SetPodRateLimitHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req SetPodRateLimitRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if err := ch.Service.SetPodRateLimit(pod, req.Rate, username); err != nil {
+		log.Printf("Failed to set rate limit of pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to set pod rate limit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "set_pod_rate_limit", pod, strconv.FormatFloat(req.Rate, 'f', -1, 64))
+	c.JSON(http.StatusOK, gin.H{"message": "Pod rate limit updated successfully"})
+}
+
+func (ch *CloningHandler) GetUnpublishedTemplatesHandler(c *gin.Context) {
+	templates, err := ch.Service.GetUnpublishedTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve unpublished templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// PRIVATE: GetPodsHandler handles GET requests for retrieving a user's pods
+func (ch *CloningHandler) GetPodsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	pods, err := ch.Service.GetPods(username)
+	if err != nil {
+		log.Printf("Error retrieving pods for user %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pods", "details": err.Error()})
+		return
+	}
+
+	// Loop through the user's deployed pods and add template information
+	for i := range pods {
+		templateName := cloning.PodTemplateName(pods[i].Name)
+		templateInfo, err := ch.Service.DatabaseService.GetTemplateInfo(templateName)
+		if err != nil {
+			log.Printf("Error retrieving template info for pod %s: %v", pods[i].Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template info for pod", "details": err.Error()})
+			return
+		}
+		pods[i].Template = templateInfo
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pods": pods})
+}
+
+// ADMIN: AdminGetPodsHandler handles GET requests for retrieving all pods
+func (ch *CloningHandler) AdminGetPodsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	pods, err := ch.Service.AdminGetPods()
+	if err != nil {
+		log.Printf("Error retrieving all pods for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pods for user", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pods": pods})
+}
+
+// PRIVATE: GetTemplatesHandler handles GET requests for retrieving templates, filtered by
+// the category, tag, difficulty, and search query parameters and ordered by sort and order
+func (ch *CloningHandler) GetTemplatesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	filter := cloning.TemplateFilter{
+		Category:   c.Query("category"),
+		Tags:       c.QueryArray("tag"),
+		Difficulty: c.Query("difficulty"),
+		Search:     c.Query("search"),
+		Sort:       c.Query("sort"),
+		Descending: c.Query("order") == "desc",
+	}
+	if !slices.Contains([]string{"", "name", "created_at", "deployments", "difficulty"}, filter.Sort) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid sort",
+			"details": "sort must be one of name, created_at, deployments, or difficulty",
+		})
+		return
+	}
+	if order := c.Query("order"); order != "" && order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid order",
+			"details": "order must be asc or desc",
+		})
+		return
+	}
+
+	templates, err := ch.Service.GetTemplatesForUser(username)
+	if err != nil {
+		log.Printf("Error retrieving templates for user %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve templates",
+			"details": err.Error(),
+		})
+		return
+	}
+	templates = cloning.FilterTemplates(templates, filter)
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// PRIVATE: SearchTemplatesHandler handles GET requests for a full-text search of the templates
+// available to the user, ordered by relevance
+func (ch *CloningHandler) SearchTemplatesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || len(query) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid search query",
+			"details": "q must be between 1 and 200 characters",
+		})
+		return
+	}
+
+	templates, err := ch.Service.SearchTemplatesForUser(username, query)
+	if err != nil {
+		log.Printf("Error searching templates for user %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// ADMIN: GetPublishedTemplatesHandler handles GET requests for retrieving all templates
+func (ch *CloningHandler) AdminGetTemplatesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	templates, err := ch.Service.DatabaseService.GetPublishedTemplates()
+	if err != nil {
+		log.Printf("Error retrieving all templates for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve all templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// PRIVATE: GetTemplateImageHandler handles GET requests for retrieving a template's image,
+// or its thumbnail when the size query parameter gives a thumbnail width
+func (ch *CloningHandler) GetTemplateImageHandler(c *gin.Context) {
+	filename := c.Param("filename")
+
+	width := 0
+	if size := c.Query("size"); size != "" {
+		var err error
+		if width, err = strconv.Atoi(size); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid size",
+				"details": fmt.Sprintf("size must be one of %v", cloning.ThumbnailSizes),
+			})
+			return
+		}
+	}
+
+	filePath, err := ch.Service.DatabaseService.TemplateImagePath(filename, width)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid size",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Serve the file
+	c.File(filePath)
+}
+
+// ADMIN: PublishTemplateHandler handles POST requests for publishing a template
+func (ch *CloningHandler) PublishTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req PublishTemplateRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested publishing of template %s", username, req.Template.Name)
+
+	if err := ch.Service.PublishTemplate(req.Template, username); err != nil {
+		log.Printf("Error publishing template for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to publish template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Template published successfully",
+	})
+}
+
+// ADMIN: ValidateTemplateHandler handles POST requests for checking whether a template will clone cleanly
+func (ch *CloningHandler) ValidateTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req TemplateRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested validation of template %s", username, req.Template)
+
+	validation, err := ch.Service.ValidateTemplate(req.Template)
+	if err != nil {
+		log.Printf("Error validating template %s: %v", req.Template, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to validate template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"validation": validation})
+}
+
+// ADMIN: CreateTemplateFromPodHandler handles POST requests for building a new template from a pod's VMs
+func (ch *CloningHandler) CreateTemplateFromPodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req CreateTemplateFromPodRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested building template %s from pod %s", username, req.Template, pod)
+
+	jobID, err := ch.Service.CreateTemplateFromPod(pod, req.Template, req.Publish, username)
+	if err != nil {
+		log.Printf("Error building template %s from pod %s: %v", req.Template, pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to build template from pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "create_template_from_pod", req.Template, pod)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Template build started", "job_id": jobID})
+}
+
+// ADMIN: ReconcileTemplatesHandler handles GET requests for a report of templates, template
+// pools, and images that are out of step with each other
+func (ch *CloningHandler) ReconcileTemplatesHandler(c *gin.Context) {
+	report, err := ch.Service.ReconcileTemplates()
+	if err != nil {
+		log.Printf("Error reconciling templates: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to reconcile templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// ADMIN: DuplicateTemplateHandler handles POST requests for copying a template pool under a new name
+func (ch *CloningHandler) DuplicateTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("name")
+
+	var req DuplicateTemplateRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested duplicating template %s as %s", username, templateName, req.Name)
+
+	jobID, err := ch.Service.DuplicateTemplate(templateName, req.Name, username)
+	if err != nil {
+		log.Printf("Error duplicating template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to duplicate template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "duplicate_template", templateName, req.Name)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Template duplication started", "job_id": jobID})
+}
+
+// ADMIN: RefreshTemplateResourcesHandler handles POST requests for recomputing the vCPUs,
+// memory, and disk one deployment of a template uses
+func (ch *CloningHandler) RefreshTemplateResourcesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("name")
+
+	resources, err := ch.Service.RefreshTemplateResources(templateName)
+	if err != nil {
+		log.Printf("Error refreshing resources of template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to refresh template resources",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "refresh_template_resources", templateName, "")
+	c.JSON(http.StatusOK, gin.H{"resources": resources})
+}
+
+// ADMIN: GetTemplateStatsHandler handles GET requests for the deployment statistics of a template
+func (ch *CloningHandler) GetTemplateStatsHandler(c *gin.Context) {
+	templateName := c.Param("name")
+
+	stats, err := ch.Service.GetTemplateStats(templateName)
+	if err != nil {
+		log.Printf("Error retrieving stats of template %s: %v", templateName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve template stats",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+// ADMIN: GetTemplateStatsSummaryHandler handles GET requests for the deployment statistics of every template
+func (ch *CloningHandler) GetTemplateStatsSummaryHandler(c *gin.Context) {
+	summary, err := ch.Service.GetTemplateStatsSummary()
+	if err != nil {
+		log.Printf("Error retrieving template stats summary: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve template stats",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"summary": summary})
+}
+
+// ADMIN: EditTemplateHandler handles POST requests for editing a published template
+func (ch *CloningHandler) EditTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req PublishTemplateRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested editing of template %s", username, req.Template.Name)
+
+	if err := ch.Service.EditTemplate(req.Template, username); err != nil {
+		log.Printf("Error editing template for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to edit template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Template edited successfully",
+	})
+}
+
+// ADMIN: GetTemplateDetailHandler handles GET requests for a template with its edit history
+func (ch *CloningHandler) GetTemplateDetailHandler(c *gin.Context) {
+	templateName := c.Param("template")
+
+	template, err := ch.Service.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		log.Printf("Error retrieving template %s: %v", templateName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template", "details": err.Error()})
+		return
+	}
+
+	history, err := ch.Service.DatabaseService.GetTemplateChanges(templateName)
+	if err != nil {
+		log.Printf("Error retrieving history of template %s: %v", templateName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template history", "details": err.Error()})
+		return
+	}
+
+	// Deleted templates are still reported while their history exists
+	if template.Name == "" && len(history) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found", "details": fmt.Sprintf("No template named %s", templateName)})
+		return
+	}
+
+	response := gin.H{"history": history}
+	if template.Name != "" {
+		response["template"] = template
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ADMIN: ExportTemplateManifestHandler handles GET requests for a template's portable
+// manifest, as YAML when format=yaml and JSON otherwise
+func (ch *CloningHandler) ExportTemplateManifestHandler(c *gin.Context) {
+	templateName := c.Param("name")
+
+	manifest, err := ch.Service.ExportTemplateManifest(templateName)
+	if err != nil {
+		log.Printf("Error exporting manifest of template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to export template manifest", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", templateName+"-manifest."+manifestFormat(c)))
+	if manifestFormat(c) == "yaml" {
+		c.YAML(http.StatusOK, manifest)
+		return
+	}
+	c.JSON(http.StatusOK, manifest)
+}
+
+// ADMIN: ImportTemplateManifestHandler handles POST requests for recreating a template
+// definition from a JSON or YAML manifest exported by another instance
+func (ch *CloningHandler) ImportTemplateManifestHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("name")
+
+	var manifest cloning.TemplateManifest
+	if manifestFormat(c) == "yaml" {
+		if err := c.ShouldBindYAML(&manifest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": "Invalid manifest format"})
+			return
+		}
+	} else if !validateAndBind(c, &manifest) {
+		return
+	}
+
+	log.Printf("Admin %s requested import of template %s from manifest", username, templateName)
+
+	warnings, err := ch.Service.ImportTemplateManifest(templateName, manifest, username)
+	if err != nil {
+		log.Printf("Error importing template %s for admin %s: %v", templateName, username, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to import template manifest", "details": err.Error()})
+		return
+	}
+
+	audit.Record(username, "import_template_manifest", templateName, manifest.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Template imported successfully",
+		"warnings": warnings,
+	})
+}
+
+// manifestFormat returns yaml when the request asks for or sends a YAML manifest
+func manifestFormat(c *gin.Context) string {
+	if c.Query("format") == "yaml" || strings.Contains(c.ContentType(), "yaml") {
+		return "yaml"
+	}
+	return "json"
+}
+
+// ADMIN: GetTemplateFlagsHandler handles GET requests for a template's flag placeholders
+func (ch *CloningHandler) GetTemplateFlagsHandler(c *gin.Context) {
+	templateName := c.Param("template")
+
+	flags, err := ch.Service.DatabaseService.GetTemplateFlags(templateName)
+	if err != nil {
+		log.Printf("Error retrieving flags for template %s: %v", templateName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template flags", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// ADMIN: SetTemplateFlagsHandler handles POST requests for replacing a template's flag placeholders
+func (ch *CloningHandler) SetTemplateFlagsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetTemplateFlagsRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("%s requested setting %d flags on template %s", username, len(req.Flags), req.Template)
+
+	var flags []cloning.TemplateFlag
+	for _, flag := range req.Flags {
+		flags = append(flags, cloning.TemplateFlag{
+			Template: req.Template,
+			Name:     flag.Name,
+			VMName:   flag.VMName,
+			Path:     flag.Path,
+		})
+	}
+
+	if err := ch.Service.DatabaseService.SetTemplateFlags(req.Template, flags); err != nil {
+		log.Printf("Error setting flags for template %s: %v", req.Template, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to set template flags",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template flags updated successfully"})
+}
+
+// ADMIN: GetTemplatePlacementRulesHandler handles GET requests for a template's VM placement rules
+func (ch *CloningHandler) GetTemplatePlacementRulesHandler(c *gin.Context) {
+	templateName := c.Param("template")
+
+	rules, err := ch.Service.DatabaseService.GetPlacementRules(templateName)
+	if err != nil {
+		log.Printf("Error retrieving placement rules for template %s: %v", templateName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve placement rules", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// ADMIN: SetTemplatePlacementRulesHandler handles POST requests for replacing a template's VM placement rules
+func (ch *CloningHandler) SetTemplatePlacementRulesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetPlacementRulesRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("%s requested setting %d placement rules on template %s", username, len(req.Rules), req.Template)
+
+	var rules []cloning.PlacementRule
+	for _, rule := range req.Rules {
+		rules = append(rules, cloning.PlacementRule{
+			Type:    rule.Type,
+			VMNames: rule.VMNames,
+		})
+	}
+
+	if err := ch.Service.SetTemplatePlacementRules(req.Template, rules); err != nil {
+		log.Printf("Error setting placement rules for template %s: %v", req.Template, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set placement rules",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Placement rules updated successfully"})
+}
+
+// PRIVATE: SubmitPodFlagHandler handles POST requests for validating a flag found in the user's pod
+func (ch *CloningHandler) SubmitPodFlagHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SubmitFlagRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requirePodOwner(c, username, req.Pod) {
+		return
+	}
+
+	flag, err := ch.Service.ValidatePodFlag(req.Pod, req.Flag, username)
+	if err != nil {
+		log.Printf("Error validating flag for pod %s: %v", req.Pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate flag", "details": err.Error()})
+		return
+	}
+
+	if flag == nil {
+		c.JSON(http.StatusOK, gin.H{"correct": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"correct": true, "flag": flag})
+}
+
+// ADMIN: AdminRetainPodHandler shuts a pod down and removes its users' access for retention
+func (ch *CloningHandler) AdminRetainPodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	log.Printf("Admin %s requested retention of pod %s", username, pod)
+
+	if err := ch.Service.RetainPod(pod, username); err != nil {
+		log.Printf("Failed to retain pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retain pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "retain_pod", pod, "")
+	c.JSON(http.StatusOK, gin.H{"message": "Pod retained successfully"})
+}
+
+// ADMIN: AdminRestorePodHandler returns a retained pod to its owner
+func (ch *CloningHandler) AdminRestorePodHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	log.Printf("Admin %s requested restore of retained pod %s", username, pod)
+
+	if err := ch.Service.RestorePod(pod); err != nil {
+		log.Printf("Failed to restore pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to restore pod",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "restore_pod", pod, "")
+	c.JSON(http.StatusOK, gin.H{"message": "Pod restored successfully"})
+}
+
+// ADMIN: AdminGetPodFlagsHandler handles GET requests for the flag status of a pod
+func (ch *CloningHandler) AdminGetPodFlagsHandler(c *gin.Context) {
+	pod := c.Param("pod")
+
+	flags, err := ch.Service.DatabaseService.GetPodFlags(pod)
+	if err != nil {
+		log.Printf("Error retrieving flags for pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pod flags", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// ADMIN: GetPodFirewallRulesHandler handles GET requests for the firewall rules added to a pod
+func (ch *CloningHandler) GetPodFirewallRulesHandler(c *gin.Context) {
+	pod := c.Param("pod")
+
+	rules, err := ch.Service.GetPodFirewallRules(pod)
+	if err != nil {
+		log.Printf("Error retrieving firewall rules for pod %s: %v", pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pod firewall rules", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// ADMIN: AddPodFirewallRuleHandler handles POST requests for adding a firewall rule to
+// every VM of a pod
+func (ch *CloningHandler) AddPodFirewallRuleHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req PodFirewallRuleRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested firewall rule %s %s %s/%s on pod %s", username, req.Direction, req.Action, req.Protocol, req.Port, pod)
+
+	rule, err := ch.Service.AddPodFirewallRule(cloning.PodFirewallRule{
+		Pod:       pod,
+		Direction: req.Direction,
+		Action:    req.Action,
+		Protocol:  req.Protocol,
+		Port:      req.Port,
+		Source:    req.Source,
+		Comment:   req.Comment,
+		CreatedBy: username,
+	})
+	if err != nil {
+		log.Printf("Error adding firewall rule to pod %s: %v", pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add pod firewall rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "add_pod_firewall_rule", pod, fmt.Sprintf("%s %s %s/%s", req.Direction, req.Action, req.Protocol, req.Port))
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// ADMIN: DeletePodFirewallRuleHandler handles POST requests for removing a firewall rule
+// from every VM of a pod
+func (ch *CloningHandler) DeletePodFirewallRuleHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	var req DeletePodFirewallRuleRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested removal of firewall rule %d from pod %s", username, req.ID, pod)
+
+	if err := ch.Service.DeletePodFirewallRule(pod, req.ID); err != nil {
+		log.Printf("Error removing firewall rule %d from pod %s: %v", req.ID, pod, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete pod firewall rule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "delete_pod_firewall_rule", pod, strconv.FormatInt(req.ID, 10))
+	c.JSON(http.StatusOK, gin.H{"message": "Pod firewall rule deleted successfully"})
+}
+
+// ADMIN: DeleteTemplateHandler handles POST requests for deleting a template
+func (ch *CloningHandler) DeleteTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req TemplateRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested deletion of template %s", username, req.Template)
+
+	if err := ch.Service.DeleteTemplate(req.Template, username); err != nil {
+		log.Printf("Error deleting template for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
 		"message": "Template deleted successfully",
 	})
 }
 
-// ADMIN: ToggleTemplateVisibilityHandler handles POST requests for toggling a template's visibility
-func (ch *CloningHandler) ToggleTemplateVisibilityHandler(c *gin.Context) {
+// ADMIN: ToggleTemplateVisibilityHandler handles POST requests for toggling a template's visibility
+func (ch *CloningHandler) ToggleTemplateVisibilityHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req TemplateRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested toggling visibility of template %s", username, req.Template)
+
+	if err := ch.Service.ToggleTemplateVisibility(req.Template, username); err != nil {
+		log.Printf("Error toggling template visibility for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to toggle template visibility",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Template visibility toggled successfully",
+	})
+}
+
+// ADMIN: UploadTemplateImageHandler handles POST requests for uploading a template's image
+func (ch *CloningHandler) UploadTemplateImageHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	log.Printf("Admin %s requested uploading a template image", username)
+
+	result, err := ch.Service.DatabaseService.UploadTemplateImage(c)
+	if err != nil {
+		log.Printf("Error uploading template image for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to upload template image",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ADMIN: AddTemplateImageHandler handles POST requests for uploading an image to the end of a template's gallery
+func (ch *CloningHandler) AddTemplateImageHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("template")
+
+	log.Printf("Admin %s requested adding an image to template %s", username, templateName)
+
+	images, err := ch.Service.AddTemplateImage(c, templateName, username)
+	if err != nil {
+		log.Printf("Error adding image to template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add template image",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// ADMIN: ReorderTemplateImagesHandler handles POST requests for reordering a template's gallery
+func (ch *CloningHandler) ReorderTemplateImagesHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("template")
+
+	var req ReorderTemplateImagesRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if err := ch.Service.ReorderTemplateImages(templateName, req.Images, username); err != nil {
+		log.Printf("Error reordering images of template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to reorder template images",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": req.Images})
+}
+
+// ADMIN: DeleteTemplateImageHandler handles POST requests for removing an image from a template's gallery
+func (ch *CloningHandler) DeleteTemplateImageHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	templateName := c.Param("template")
+
+	var req TemplateImageRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested deleting image %s of template %s", username, req.Image, templateName)
+
+	if err := ch.Service.DeleteTemplateImage(templateName, req.Image, username); err != nil {
+		log.Printf("Error deleting image of template %s: %v", templateName, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete template image",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template image deleted successfully"})
+}
+
+// ADMIN: ScheduleCloneTemplateHandler handles POST requests for scheduling a bulk clone at a future time
+func (ch *CloningHandler) ScheduleCloneTemplateHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req ScheduleCloneRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if _, err := tools.LoadTimeZone(req.TimeZone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	runAt, err := tools.ParseTimeInZone(req.RunAt, req.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !runAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid schedule",
+			"details": "run_at must be in the future",
+		})
+		return
+	}
+
+	targets := buildCloneTargets(req.Usernames, req.Groups)
+	if len(targets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid schedule",
+			"details": "at least one username or group is required",
+		})
+		return
+	}
+
+	log.Printf("Admin %s scheduled cloning of template %s at %s", username, req.Template, runAt.UTC().Format(time.RFC3339))
+
+	id, err := ch.Service.DatabaseService.CreateScheduledDeployment(cloning.ScheduledDeployment{
+		Template:     req.Template,
+		Targets:      targets,
+		StartingVMID: req.StartingVMID,
+		RunAt:        runAt,
+		TimeZone:     req.TimeZone,
+		CreatedBy:    username,
+	})
+	if err != nil {
+		log.Printf("Error scheduling deployment for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to schedule deployment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Deployment scheduled successfully",
+		"id":      id,
+	})
+}
+
+// ADMIN: GetDeploymentBlackoutsHandler handles GET requests for listing current and upcoming deployment blackouts
+func (ch *CloningHandler) GetDeploymentBlackoutsHandler(c *gin.Context) {
+	blackouts, err := ch.Service.DatabaseService.GetDeploymentBlackouts()
+	if err != nil {
+		log.Printf("Error retrieving deployment blackouts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve deployment blackouts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blackouts": blackouts,
+		"count":     len(blackouts),
+	})
+}
+
+// ADMIN: CreateDeploymentBlackoutHandler handles POST requests for blocking user deployments during a period
+func (ch *CloningHandler) CreateDeploymentBlackoutHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req CreateDeploymentBlackoutRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if _, err := tools.LoadTimeZone(req.TimeZone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid blackout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	startsAt, err := tools.ParseTimeInZone(req.StartsAt, req.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid blackout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	endsAt, err := tools.ParseTimeInZone(req.EndsAt, req.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid blackout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !endsAt.After(startsAt) || !endsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid blackout",
+			"details": "ends_at must be after starts_at and in the future",
+		})
+		return
+	}
+
+	log.Printf("Admin %s blocked deployments from %s to %s", username, startsAt.UTC().Format(time.RFC3339), endsAt.UTC().Format(time.RFC3339))
+
+	id, err := ch.Service.DatabaseService.CreateDeploymentBlackout(cloning.DeploymentBlackout{
+		Reason:    req.Reason,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: username,
+	})
+	if err != nil {
+		log.Printf("Error creating deployment blackout for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create deployment blackout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "create_deployment_blackout", strconv.FormatInt(id, 10),
+		fmt.Sprintf("%s to %s: %s", startsAt.UTC().Format(time.RFC3339), endsAt.UTC().Format(time.RFC3339), req.Reason))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Deployment blackout created successfully",
+		"id":      id,
+	})
+}
+
+// ADMIN: DeleteDeploymentBlackoutHandler handles POST requests for lifting a deployment blackout
+func (ch *CloningHandler) DeleteDeploymentBlackoutHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req DeploymentBlackoutIDRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested deletion of deployment blackout %d", username, req.ID)
+
+	if err := ch.Service.DatabaseService.DeleteDeploymentBlackout(req.ID); err != nil {
+		log.Printf("Error deleting deployment blackout for admin %s: %v", username, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete deployment blackout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "delete_deployment_blackout", strconv.FormatInt(req.ID, 10), "")
+	c.JSON(http.StatusOK, gin.H{"message": "Deployment blackout deleted successfully"})
+}
+
+// ADMIN: GetScheduledDeploymentsHandler handles GET requests for listing scheduled deployments
+func (ch *CloningHandler) GetScheduledDeploymentsHandler(c *gin.Context) {
+	deployments, err := ch.Service.DatabaseService.GetScheduledDeployments()
+	if err != nil {
+		log.Printf("Error retrieving scheduled deployments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve scheduled deployments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedules": deployments,
+		"count":     len(deployments),
+	})
+}
+
+// ADMIN: CancelScheduledDeploymentHandler handles POST requests for cancelling a pending scheduled deployment
+func (ch *CloningHandler) CancelScheduledDeploymentHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req ScheduleIDRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested cancellation of scheduled deployment %d", username, req.ID)
+
+	if err := ch.Service.DatabaseService.CancelScheduledDeployment(req.ID); err != nil {
+		log.Printf("Error cancelling scheduled deployment for admin %s: %v", username, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to cancel scheduled deployment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scheduled deployment cancelled successfully"})
+}
+
+// ADMIN: GetPodQuotasHandler handles GET requests for listing configured pod quotas
+func (ch *CloningHandler) GetPodQuotasHandler(c *gin.Context) {
+	quotas, err := ch.Service.DatabaseService.GetPodQuotas()
+	if err != nil {
+		log.Printf("Error retrieving pod quotas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve pod quotas",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotas":        quotas,
+		"count":         len(quotas),
+		"default_quota": ch.Service.Config.DefaultPodQuota,
+	})
+}
+
+// ADMIN: SetPodQuotaHandler handles POST requests for creating or updating a pod quota
+func (ch *CloningHandler) SetPodQuotaHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetPodQuotaRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s set pod quota for %s (group: %t) to %d", username, req.Target, req.IsGroup, req.MaxPods)
+
+	quota := cloning.PodQuota{
+		Target:  req.Target,
+		IsGroup: req.IsGroup,
+		MaxPods: req.MaxPods,
+	}
+	if err := ch.Service.DatabaseService.SetPodQuota(quota); err != nil {
+		log.Printf("Error setting pod quota for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to set pod quota",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod quota updated successfully"})
+}
+
+// ADMIN: GetWarmPoolsHandler returns the size and ready pods of every warm pool
+func (ch *CloningHandler) GetWarmPoolsHandler(c *gin.Context) {
+	statuses, err := ch.Service.GetWarmPoolStatuses()
+	if err != nil {
+		log.Printf("Error getting warm pools: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get warm pools",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"warm_pools": statuses})
+}
+
+// ADMIN: SetWarmPoolSizeHandler sets how many pre-cloned pods are kept ready for a template
+func (ch *CloningHandler) SetWarmPoolSizeHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetWarmPoolSizeRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s set warm pool size of template %s to %d", username, req.Template, req.Size)
+
+	if err := ch.Service.SetWarmPoolSize(req.Template, req.Size); err != nil {
+		log.Printf("Error setting warm pool size for admin %s: %v", username, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set warm pool size",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Warm pool size updated successfully"})
+}
+
+// ADMIN: DeletePodQuotaHandler handles POST requests for removing a pod quota
+func (ch *CloningHandler) DeletePodQuotaHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req PodQuotaTargetRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested deletion of pod quota for %s (group: %t)", username, req.Target, req.IsGroup)
+
+	if err := ch.Service.DatabaseService.DeletePodQuota(req.Target, req.IsGroup); err != nil {
+		log.Printf("Error deleting pod quota for admin %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete pod quota",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pod quota deleted successfully"})
+}
+
+// ADMIN: GetOrganizationsHandler handles GET requests for listing organizations
+func (ch *CloningHandler) GetOrganizationsHandler(c *gin.Context) {
+	organizations, err := ch.Service.DatabaseService.GetOrganizations()
+	if err != nil {
+		log.Printf("Error retrieving organizations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve organizations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organizations": organizations,
+		"count":         len(organizations),
+	})
+}
+
+// ADMIN: SetOrganizationHandler handles POST requests for creating or updating an organization
+func (ch *CloningHandler) SetOrganizationHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
 
-	var req TemplateRequest
+	var req SetOrganizationRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	log.Printf("Admin %s requested toggling visibility of template %s", username, req.Template)
+	log.Printf("Admin %s set organization %s", username, req.Name)
 
-	if err := ch.Service.DatabaseService.ToggleTemplateVisibility(req.Template); err != nil {
-		log.Printf("Error toggling template visibility for admin %s: %v", username, err)
+	org := cloning.Organization{
+		Name:            req.Name,
+		Description:     req.Description,
+		MemberGroup:     req.MemberGroup,
+		AdminGroup:      req.AdminGroup,
+		MinPodID:        req.MinPodID,
+		MaxPodID:        req.MaxPodID,
+		DefaultPodQuota: req.DefaultPodQuota,
+	}
+	if err := ch.Service.SetOrganization(org); err != nil {
+		log.Printf("Error setting organization for admin %s: %v", username, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set organization",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "set_organization", req.Name, fmt.Sprintf("members=%s admins=%s pod_ids=%d-%d quota=%d",
+		req.MemberGroup, req.AdminGroup, req.MinPodID, req.MaxPodID, req.DefaultPodQuota))
+	c.JSON(http.StatusOK, gin.H{"message": "Organization updated successfully"})
+}
+
+// ADMIN: DeleteOrganizationHandler handles POST requests for removing an organization,
+// its templates return to the shared catalog
+func (ch *CloningHandler) DeleteOrganizationHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req OrganizationNameRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested deletion of organization %s", username, req.Name)
+
+	if err := ch.Service.DatabaseService.DeleteOrganization(req.Name); err != nil {
+		log.Printf("Error deleting organization for admin %s: %v", username, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to toggle template visibility",
+			"error":   "Failed to delete organization",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "delete_organization", req.Name, "")
+	c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+// ADMIN: SetTemplateOrganizationHandler handles POST requests for moving a template into
+// an organization's catalog or back to the shared catalog
+func (ch *CloningHandler) SetTemplateOrganizationHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetTemplateOrganizationRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s set organization of template %s to %q", username, req.Template, req.Organization)
+
+	if err := ch.Service.SetTemplateOrganization(req.Template, req.Organization); err != nil {
+		log.Printf("Error setting organization of template %s: %v", req.Template, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set template organization",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "set_template_organization", req.Template, req.Organization)
+	c.JSON(http.StatusOK, gin.H{"message": "Template organization updated successfully"})
+}
+
+// ADMIN: SetTemplateAllowedGroupsHandler handles POST requests for limiting a template to
+// members of specific groups
+func (ch *CloningHandler) SetTemplateAllowedGroupsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetTemplateAllowedGroupsRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s set allowed groups of template %s to %v", username, req.Template, req.Groups)
+
+	if err := ch.Service.SetTemplateAllowedGroups(req.Template, req.Groups); err != nil {
+		log.Printf("Error setting allowed groups of template %s: %v", req.Template, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set template allowed groups",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "set_template_allowed_groups", req.Template, strings.Join(req.Groups, ","))
+	c.JSON(http.StatusOK, gin.H{"message": "Template allowed groups updated successfully"})
+}
+
+// ADMIN: SetTemplateDeprecatedHandler handles POST requests for deprecating a template or
+// reversing its deprecation
+func (ch *CloningHandler) SetTemplateDeprecatedHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req SetTemplateDeprecatedRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s set deprecation of template %s to %t", username, req.Template, req.Deprecated)
+
+	if err := ch.Service.SetTemplateDeprecated(req.Template, req.Deprecated, username); err != nil {
+		log.Printf("Error setting deprecation of template %s: %v", req.Template, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set template deprecation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "set_template_deprecated", req.Template, strconv.FormatBool(req.Deprecated))
+	c.JSON(http.StatusOK, gin.H{"message": "Template deprecation updated successfully"})
+}
+
+// PRIVATE: GetUserOrganizationsHandler handles GET requests for the organizations the user belongs to
+func (ch *CloningHandler) GetUserOrganizationsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	organizations, err := ch.Service.GetUserOrganizations(username)
+	if err != nil {
+		log.Printf("Error retrieving organizations for user %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve organizations",
 			"details": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Template visibility toggled successfully",
+		"organizations": organizations,
+		"count":         len(organizations),
 	})
 }
 
-// ADMIN: UploadTemplateImageHandler handles POST requests for uploading a template's image
-func (ch *CloningHandler) UploadTemplateImageHandler(c *gin.Context) {
+// PRIVATE: GetOrganizationPodsHandler handles GET requests from organization admins for
+// the deployed pods of their organization's templates
+func (ch *CloningHandler) GetOrganizationPodsHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
+	org := c.Param("org")
 
-	log.Printf("Admin %s requested uploading a template image", username)
+	if !ch.requireOrganizationAdmin(c, username, org) {
+		return
+	}
 
-	result, err := ch.Service.DatabaseService.UploadTemplateImage(c)
+	pods, err := ch.Service.GetOrganizationPods(org)
 	if err != nil {
-		log.Printf("Error uploading template image for admin %s: %v", username, err)
+		log.Printf("Error retrieving pods of organization %s: %v", org, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to upload template image",
+			"error":   "Failed to retrieve organization pods",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, gin.H{
+		"pods":  pods,
+		"count": len(pods),
+	})
+}
+
+// PRIVATE: OrganizationCloneHandler handles requests from organization admins to deploy
+// one of their organization's templates to users and groups
+func (ch *CloningHandler) OrganizationCloneHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	org := c.Param("org")
+
+	var req OrganizationCloneRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requireOrganizationAdmin(c, username, org) {
+		return
+	}
+
+	template, err := ch.Service.DatabaseService.GetTemplateInfo(req.Template)
+	if err != nil {
+		log.Printf("Error fetching template %s: %v", req.Template, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch template",
+			"details": err.Error(),
+		})
+		return
+	}
+	if template.Name == "" || template.Organization != org {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Template not found in organization",
+			"details": fmt.Sprintf("Template %s does not belong to organization %s", req.Template, org),
+		})
+		return
+	}
+
+	// Organization admins may only deploy to their organization, within its quotas
+	targets := buildCloneTargets(req.Usernames, req.Groups)
+	if err := ch.Service.ValidateOrganizationTargets(org, req.Template, targets); err != nil {
+		log.Printf("Organization clone of template %s by %s rejected: %v", req.Template, username, err)
+
+		var limitErr *cloning.DeploymentLimitError
+		switch {
+		case errors.Is(err, cloning.ErrNotOrganizationMember):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Target not in organization",
+				"details": err.Error(),
+			})
+		case errors.As(err, &limitErr):
+			response := gin.H{
+				"error":   "Deployment not allowed",
+				"details": limitErr.Error(),
+			}
+			if !limitErr.NextAllowed.IsZero() {
+				response["next_allowed"] = limitErr.NextAllowed.UTC()
+			}
+			c.JSON(http.StatusConflict, response)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to validate clone targets",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	log.Printf("Organization admin %s requested cloning of template %s for organization %s", username, req.Template, org)
+	audit.Record(username, "organization_clone", req.Template, org)
+
+	sseWriter, err := sse.NewWriter(c.Writer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to initialize SSE",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	cloneReq := cloning.CloneRequest{
+		Template:    req.Template,
+		Targets:     targets,
+		RequestedBy: username,
+		Priority:    cloning.PriorityInstructor,
+		SSE:         sseWriter,
+	}
+
+	if err := ch.Service.CloneTemplate(context.Background(), cloneReq); err != nil {
+		log.Printf("Organization admin %s encountered error while cloning template: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to clone templates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Templates cloned successfully",
+	})
+}
+
+// PRIVATE: OrganizationDeletePodsHandler handles requests from organization admins to
+// delete pods of their organization's templates
+func (ch *CloningHandler) OrganizationDeletePodsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	org := c.Param("org")
+
+	var req AdminDeletePodRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.requireOrganizationAdmin(c, username, org) {
+		return
+	}
+
+	pods, err := ch.Service.GetOrganizationPods(org)
+	if err != nil {
+		log.Printf("Error retrieving pods of organization %s: %v", org, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve organization pods",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for _, pod := range req.Pods {
+		if !slices.ContainsFunc(pods, func(p cloning.Pod) bool { return p.Name == pod }) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "You do not have permission to delete this pod",
+				"details": fmt.Sprintf("Pod %s does not belong to organization %s", pod, org),
+			})
+			return
+		}
+	}
+
+	log.Printf("Organization admin %s requested deletion of pods: %v", username, req.Pods)
+	audit.Record(username, "organization_delete_pods", org, strings.Join(req.Pods, ","))
+
+	var errors []error
+	for _, pod := range req.Pods {
+		if err := ch.Service.DeletePod(pod); err != nil {
+			errors = append(errors, fmt.Errorf("failed to delete pod %s: %v", pod, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		log.Printf("Organization admin %s encountered errors while deleting pods: %v", username, errors)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete pods",
+			"details": errors,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pods deleted successfully"})
+}
+
+// requireOrganizationAdmin writes a forbidden response and returns false unless the user
+// is in the organization's admin group
+func (ch *CloningHandler) requireOrganizationAdmin(c *gin.Context, username string, org string) bool {
+	isAdmin, err := ch.Service.IsOrganizationAdmin(username, org)
+	if err != nil {
+		log.Printf("Error checking admin of organization %s for user %s: %v", org, username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to verify organization admin",
+			"details": err.Error(),
+		})
+		return false
+	}
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "You do not have permission to administer this organization",
+			"details": fmt.Sprintf("User %s is not an admin of organization %s", username, org),
+		})
+		return false
+	}
+
+	return true
+}
+
+// buildCloneTargets builds clone targets from lists of usernames and groups
+func buildCloneTargets(usernames []string, groups []string) []cloning.CloneTarget {
+	var targets []cloning.CloneTarget
+
+	// Add users as targets
+	for _, user := range usernames {
+		targets = append(targets, cloning.CloneTarget{
+			Name:    user,
+			IsGroup: false,
+		})
+	}
+
+	// Add groups as targets
+	for _, group := range groups {
+		targets = append(targets, cloning.CloneTarget{
+			Name:    group,
+			IsGroup: true,
+		})
+	}
+
+	return targets
 }
 
 // HealthCheck checks the database connection health
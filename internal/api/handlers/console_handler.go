@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/audit"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// NewConsoleHandler creates a new console handler that proxies VM consoles of pods
+func NewConsoleHandler(cloningHandler *CloningHandler, allowedOrigin string) *ConsoleHandler {
+	return &ConsoleHandler{
+		cloningHandler: cloningHandler,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  32 * 1024,
+			WriteBufferSize: 32 * 1024,
+			Subprotocols:    []string{"binary"},
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || origin == allowedOrigin
+			},
+		},
+	}
+}
+
+// PRIVATE: CreateConsoleTicketHandler opens a VNC or xterm console proxy for a VM of one
+// of the user's pods and returns the ticket the browser connects with
+func (ch *ConsoleHandler) CreateConsoleTicketHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	vmID, ok := consoleVMID(c)
+	if !ok {
+		return
+	}
+
+	var req ConsoleTicketRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	if !ch.cloningHandler.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	ticket, err := ch.cloningHandler.Service.CreatePodConsoleTicket(pod, vmID, req.Type)
+	if err != nil {
+		log.Printf("Error opening %s console of VM %d in pod %s: %v", req.Type, vmID, pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to open console",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "open_console", pod, fmt.Sprintf("%s console of VM %d", req.Type, vmID))
+	c.JSON(http.StatusOK, ticket)
+}
+
+// PRIVATE: ConsoleWebSocketHandler upgrades the connection to a WebSocket and relays it to
+// the console proxy opened with a ticket from CreateConsoleTicketHandler
+func (ch *ConsoleHandler) ConsoleWebSocketHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+	port := c.Query("port")
+	ticket := c.Query("ticket")
+
+	vmID, ok := consoleVMID(c)
+	if !ok {
+		return
+	}
+	if port == "" || ticket == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": "port and ticket are required",
+		})
+		return
+	}
+
+	if !ch.cloningHandler.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	upstream, err := ch.cloningHandler.Service.DialPodConsole(c.Request.Context(), pod, vmID, port, ticket)
+	if err != nil {
+		log.Printf("Error connecting to console of VM %d in pod %s: %v", vmID, pod, err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to connect to console",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer upstream.Close()
+
+	conn, err := ch.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade console of VM %d for user %s: %v", vmID, username, err)
+		return
+	}
+	defer conn.Close()
+
+	// Relay in both directions until either side closes
+	done := make(chan struct{}, 2)
+	go relayConsole(upstream, conn, done)
+	go relayConsole(conn, upstream, done)
+	<-done
+}
+
+// PRIVATE: DownloadSPICEConfigHandler returns a remote-viewer file for a SPICE connection
+// to a VM of one of the user's pods
+func (ch *ConsoleHandler) DownloadSPICEConfigHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	pod := c.Param("pod")
+
+	vmID, ok := consoleVMID(c)
+	if !ok {
+		return
+	}
+
+	if !ch.cloningHandler.requirePodOwner(c, username, pod) {
+		return
+	}
+
+	config, err := ch.cloningHandler.Service.CreatePodSPICEConfig(pod, vmID)
+	if err != nil {
+		log.Printf("Error creating SPICE config for VM %d in pod %s: %v", vmID, pod, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create SPICE connection",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var file strings.Builder
+	file.WriteString("[virt-viewer]\n")
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		fmt.Fprintf(&file, "%s=%v\n", key, config[key])
+	}
+
+	audit.Record(username, "open_console", pod, fmt.Sprintf("spice console of VM %d", vmID))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%d.vv", vmID))
+	c.Data(http.StatusOK, "application/x-virt-viewer", []byte(file.String()))
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func consoleVMID(c *gin.Context) (int, bool) {
+	vmID, err := strconv.Atoi(c.Param("vmid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid VM ID",
+			"details": err.Error(),
+		})
+		return 0, false
+	}
+	return vmID, true
+}
+
+// relayConsole copies messages from one WebSocket to the other
+func relayConsole(from *websocket.Conn, to *websocket.Conn, done chan<- struct{}) {
+	defer func() { done <- struct{}{} }()
+	for {
+		messageType, data, err := from.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := to.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/cloning"
+	"github.com/cpp-cyber/proclone/internal/tools/events"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// NewEventsHandler creates a new events handler that streams pod lifecycle events
+func NewEventsHandler(cloningHandler *CloningHandler, allowedOrigin string) *EventsHandler {
+	return &EventsHandler{
+		cloningHandler: cloningHandler,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || origin == allowedOrigin
+			},
+		},
+	}
+}
+
+// PRIVATE: EventsHandler upgrades the connection to a WebSocket and streams pod events
+// the user is allowed to see (admins receive every event)
+func (eh *EventsHandler) EventsHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	isAdmin, _ := session.Get("isAdmin").(bool)
+
+	// Resolve the pod owners and shared pods this user may receive events for
+	owners := []string{username}
+	var sharedPods map[string]bool
+	if !isAdmin {
+		ldapService := eh.cloningHandler.Service.LDAPService
+		if userDN, err := ldapService.GetUserDN(username); err == nil {
+			if groups, err := ldapService.GetUserGroups(userDN); err == nil {
+				owners = append(owners, groups...)
+			}
+		}
+		sharedPods = eh.sharedPods(username)
+	}
+
+	conn, err := eh.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade event stream for user %s: %v", username, err)
+		return
+	}
+	defer conn.Close()
+
+	bus := eh.cloningHandler.Service.Events
+	sub := bus.Subscribe()
+	defer bus.Unsubscribe(sub)
+
+	// Read pump to detect client disconnects
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	pingTicker := time.NewTicker(30 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-pingTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+			// Pick up shares granted or revoked since the stream was opened
+			if !isAdmin {
+				sharedPods = eh.sharedPods(username)
+			}
+		case event, ok := <-sub:
+			if !ok {
+				return
+			}
+			if !isAdmin && !eventVisibleTo(event, owners, sharedPods) {
+				continue
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sharedPods returns the pods shared with the user, keyed by lowercased pod name
+func (eh *EventsHandler) sharedPods(username string) map[string]bool {
+	shares, err := eh.cloningHandler.Service.DatabaseService.GetSharesForUser(username)
+	if err != nil {
+		log.Printf("Failed to get pods shared with user %s: %v", username, err)
+		return nil
+	}
+
+	pods := make(map[string]bool, len(shares))
+	for _, share := range shares {
+		pods[strings.ToLower(share.Pod)] = true
+	}
+	return pods
+}
+
+// eventVisibleTo reports whether an event concerns a pod owned by one of the owners or
+// shared with the user
+func eventVisibleTo(event events.Event, owners []string, sharedPods map[string]bool) bool {
+	if sharedPods[strings.ToLower(event.Pod)] {
+		return true
+	}
+	for _, owner := range owners {
+		if cloning.PodOwnedBy(event.Pod, owner) {
+			return true
+		}
+	}
+	return false
+}
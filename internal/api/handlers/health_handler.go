@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/cpp-cyber/proclone/internal/tools"
 	"github.com/gin-gonic/gin"
 )
 
@@ -46,6 +47,59 @@ func HealthCheckHandler(authHandler *AuthHandler, cloningHandler *CloningHandler
 			}
 		}
 
+		// Report Proxmox endpoint failover and circuit breaker state (via cloning handler)
+		if cloningHandler != nil && cloningHandler.Service != nil {
+			healthStatus["services"].(gin.H)["proxmox"] = proxmoxHealth(cloningHandler)
+		}
+
 		c.JSON(statusCode, healthStatus)
 	}
 }
+
+// PUBLIC: ProxmoxHealthHandler handles GET requests for the Proxmox cluster's health as seen
+// by the API, returning 503 while the circuit breaker is failing requests fast
+func ProxmoxHealthHandler(cloningHandler *CloningHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cloningHandler == nil || cloningHandler.Service == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unknown", "error": "Proxmox service is not initialized"})
+			return
+		}
+
+		health := proxmoxHealth(cloningHandler)
+
+		statusCode := http.StatusOK
+		if health["status"] == "unhealthy" {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		c.JSON(statusCode, health)
+	}
+}
+
+// proxmoxHealth summarizes the endpoint and breaker state. The cluster is unhealthy while
+// the breaker is open or no endpoint is healthy, and degraded while some endpoints are
+// down or the breaker is probing.
+func proxmoxHealth(cloningHandler *CloningHandler) gin.H {
+	endpoints := cloningHandler.Service.ProxmoxService.GetEndpointHealth()
+	breaker := cloningHandler.Service.ProxmoxService.GetBreakerState()
+
+	healthyCount := 0
+	for _, endpoint := range endpoints {
+		if endpoint.Healthy {
+			healthyCount++
+		}
+	}
+
+	proxmoxStatus := "healthy"
+	if healthyCount == 0 || breaker.State == tools.BreakerOpen {
+		proxmoxStatus = "unhealthy"
+	} else if healthyCount < len(endpoints) || breaker.State == tools.BreakerHalfOpen {
+		proxmoxStatus = "degraded"
+	}
+
+	return gin.H{
+		"status":    proxmoxStatus,
+		"endpoints": endpoints,
+		"breaker":   breaker,
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/audit"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+	"github.com/cpp-cyber/proclone/internal/tools/sse"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// PRIVATE: GetJobsHandler handles GET requests for the jobs visible to the user
+func (ch *CloningHandler) GetJobsHandler(c *gin.Context) {
+	viewer, err := ch.jobViewer(c)
+	if err != nil {
+		log.Printf("Error resolving job visibility: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve job visibility", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": ch.Service.Jobs.List(viewer)})
+}
+
+// PRIVATE: GetJobHandler handles GET requests for a single job visible to the user
+func (ch *CloningHandler) GetJobHandler(c *gin.Context) {
+	job, ok := ch.visibleJob(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// PRIVATE: StreamJobHandler streams updates of a job visible to the user over SSE
+func (ch *CloningHandler) StreamJobHandler(c *gin.Context) {
+	job, ok := ch.visibleJob(c)
+	if !ok {
+		return
+	}
+
+	sseWriter, err := sse.NewWriter(c.Writer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to initialize SSE",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Subscribe before reading the current state so no update is missed, a job that
+	// finished after the visibility check is then sent as finished
+	sub := ch.Service.Jobs.Subscribe()
+	defer ch.Service.Jobs.Unsubscribe(sub)
+
+	job, ok = ch.Service.Jobs.Get(job.ID)
+	if !ok {
+		return
+	}
+
+	sseWriter.Send(job)
+	if job.Status != jobs.StatusRunning {
+		return
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case update, ok := <-sub:
+			if !ok {
+				return
+			}
+			if update.ID != job.ID {
+				continue
+			}
+			sseWriter.Send(update)
+			if update.Status != jobs.StatusRunning {
+				return
+			}
+		}
+	}
+}
+
+// PRIVATE: CancelJobHandler handles POST requests to cancel a running job. Only the
+// user who started the job, or an admin, may cancel it. Partial work is cleaned up
+// unless keep_partial is set.
+func (ch *CloningHandler) CancelJobHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	isAdmin, _ := session.Get("isAdmin").(bool)
+
+	job, ok := ch.visibleJob(c)
+	if !ok {
+		return
+	}
+
+	if !isAdmin && !strings.EqualFold(job.Owner, username) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the user who started the job can cancel it"})
+		return
+	}
+
+	// The request body is optional
+	var req CancelJobRequest
+	if c.Request.ContentLength > 0 && !validateAndBind(c, &req) {
+		return
+	}
+
+	if err := ch.Service.Jobs.Cancel(job.ID, username, req.KeepPartial); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to cancel job", "details": err.Error()})
+		return
+	}
+
+	audit.Record(username, "cancel_job", job.ID, fmt.Sprintf("%s (keep_partial=%t)", job.Description, req.KeepPartial))
+	log.Printf("%s cancelled job %s (%s)", username, job.ID, job.Description)
+	c.JSON(http.StatusAccepted, gin.H{"success": true, "message": "Job cancellation requested"})
+}
+
+// visibleJob looks up the job in the request path, writing an error response and
+// returning false if it does not exist or is not visible to the user
+func (ch *CloningHandler) visibleJob(c *gin.Context) (jobs.Job, bool) {
+	viewer, err := ch.jobViewer(c)
+	if err != nil {
+		log.Printf("Error resolving job visibility: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve job visibility", "details": err.Error()})
+		return jobs.Job{}, false
+	}
+
+	// Jobs the user can't see are reported as missing to avoid leaking their existence
+	job, ok := ch.Service.Jobs.Get(c.Param("id"))
+	if !ok || !viewer.CanView(job) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found", "details": fmt.Sprintf("No job with id %s", c.Param("id"))})
+		return jobs.Job{}, false
+	}
+
+	return job, true
+}
+
+// jobViewer builds the job visibility rules for the session user. Admins may pass
+// ?as=<username> to see jobs as that user would for the current request.
+func (ch *CloningHandler) jobViewer(c *gin.Context) (jobs.Viewer, error) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+	isAdmin, _ := session.Get("isAdmin").(bool)
+
+	viewer := jobs.Viewer{Username: username, IsAdmin: isAdmin}
+
+	if as := c.Query("as"); as != "" && isAdmin {
+		audit.Record(username, "impersonate_job_view", as, c.Request.URL.Path)
+		viewer = jobs.Viewer{Username: as}
+	}
+
+	if viewer.IsAdmin {
+		return viewer, nil
+	}
+
+	groups, err := ch.Service.LDAPService.GetGroups()
+	if err != nil {
+		return jobs.Viewer{}, fmt.Errorf("failed to get groups: %w", err)
+	}
+	for _, group := range groups {
+		if strings.EqualFold(group.Manager, viewer.Username) {
+			viewer.ManagedGroups = append(viewer.ManagedGroups, group.Name)
+		}
+	}
+
+	return viewer, nil
+}
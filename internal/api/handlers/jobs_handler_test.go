@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/cloning"
+	"github.com/cpp-cyber/proclone/internal/ldap"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeLDAP serves the group listing used to find the groups a viewer manages
+type fakeLDAP struct {
+	ldap.Service
+	groups []ldap.Group
+}
+
+func (f fakeLDAP) GetGroups() ([]ldap.Group, error) {
+	return f.groups, nil
+}
+
+// newJobsRouter serves the job handlers with the session user taken from test headers
+func newJobsRouter(registry *jobs.Registry) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	ch := &CloningHandler{Service: &cloning.CloningService{
+		Jobs:        registry,
+		LDAPService: fakeLDAP{groups: []ldap.Group{{Name: "blue-team", Manager: "carol"}}},
+	}}
+
+	router := gin.New()
+	router.Use(sessions.Sessions("session", cookie.NewStore([]byte("test-secret"))))
+	router.Use(func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("id", c.GetHeader("X-Test-User"))
+		session.Set("isAdmin", c.GetHeader("X-Test-Admin") == "true")
+		c.Next()
+	})
+	router.GET("/jobs", ch.GetJobsHandler)
+	router.GET("/jobs/:id", ch.GetJobHandler)
+	router.GET("/jobs/:id/stream", ch.StreamJobHandler)
+	return router
+}
+
+func serveAs(router *gin.Engine, username string, isAdmin bool, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("X-Test-User", username)
+	if isAdmin {
+		req.Header.Set("X-Test-Admin", "true")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestJobVisibility(t *testing.T) {
+	registry := jobs.NewRegistry()
+	job := registry.Create(jobs.TypeClone, "alice", "Clone for alice", []string{"bob"}, []string{"blue-team"})
+	registry.Finish(job.ID, nil)
+	router := newJobsRouter(registry)
+
+	tests := []struct {
+		name     string
+		username string
+		isAdmin  bool
+		query    string
+		visible  bool
+	}{
+		{name: "owner", username: "alice", visible: true},
+		{name: "user acted for", username: "bob", visible: true},
+		{name: "manager of group", username: "carol", visible: true},
+		{name: "admin", username: "root", isAdmin: true, visible: true},
+		{name: "admin as owner", username: "root", isAdmin: true, query: "?as=alice", visible: true},
+		{name: "admin as stranger", username: "root", isAdmin: true, query: "?as=mallory", visible: false},
+		{name: "non-admin as owner", username: "mallory", query: "?as=alice", visible: false},
+		{name: "stranger", username: "mallory", visible: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(router, tt.username, tt.isAdmin, "/jobs"+tt.query)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("list status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var list struct {
+				Jobs []jobs.Job `json:"jobs"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+				t.Fatalf("failed to decode job list: %v", err)
+			}
+			listed := slices.ContainsFunc(list.Jobs, func(j jobs.Job) bool { return j.ID == job.ID })
+			if listed != tt.visible {
+				t.Errorf("job listed = %t, want %t", listed, tt.visible)
+			}
+
+			wantStatus := http.StatusNotFound
+			if tt.visible {
+				wantStatus = http.StatusOK
+			}
+
+			rec = serveAs(router, tt.username, tt.isAdmin, "/jobs/"+job.ID+tt.query)
+			if rec.Code != wantStatus {
+				t.Errorf("get status = %d, want %d", rec.Code, wantStatus)
+			}
+
+			// The stream must agree with the listing
+			rec = serveAs(router, tt.username, tt.isAdmin, "/jobs/"+job.ID+"/stream"+tt.query)
+			if rec.Code != wantStatus {
+				t.Errorf("stream status = %d, want %d", rec.Code, wantStatus)
+			}
+			if streamed := strings.Contains(rec.Body.String(), `"id":"`+job.ID+`"`); streamed != tt.visible {
+				t.Errorf("job streamed = %t, want %t", streamed, tt.visible)
+			}
+		})
+	}
+}
+
+func TestStreamJobEndsWhenJobFinishes(t *testing.T) {
+	registry := jobs.NewRegistry()
+	job := registry.Create(jobs.TypeClone, "alice", "Clone for alice", nil, nil)
+	router := newJobsRouter(registry)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- serveAs(router, "alice", false, "/jobs/"+job.ID+"/stream")
+	}()
+
+	// Finishing may land before or after the handler subscribes, the stream must end either way
+	registry.Finish(job.ID, nil)
+
+	select {
+	case rec := <-done:
+		if !strings.Contains(rec.Body.String(), `"status":"completed"`) {
+			t.Errorf("stream did not send the finished job, body: %s", rec.Body.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after the job finished")
+	}
+}
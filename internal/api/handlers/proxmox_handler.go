@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/cpp-cyber/proclone/internal/audit"
 	"github.com/cpp-cyber/proclone/internal/proxmox"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -39,6 +44,49 @@ func (ph *ProxmoxHandler) GetClusterResourceUsageHandler(c *gin.Context) {
 	})
 }
 
+// ADMIN: GetResourceCacheStatsHandler returns the cluster resource cache metrics
+func (ph *ProxmoxHandler) GetResourceCacheStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ph.service.GetResourceCacheStats())
+}
+
+// ADMIN: InvalidateResourceCacheHandler drops cached cluster resources, for changes made
+// directly in Proxmox
+func (ph *ProxmoxHandler) InvalidateResourceCacheHandler(c *gin.Context) {
+	ph.service.InvalidateClusterResources()
+	c.JSON(http.StatusOK, gin.H{"message": "Cluster resource cache invalidated"})
+}
+
+// ADMIN: GetTokenStatusHandler returns the IDs of the Proxmox API tokens in use
+func (ph *ProxmoxHandler) GetTokenStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ph.service.GetTokenStatus())
+}
+
+// ADMIN: RotateTokenHandler switches the service to a new Proxmox API token without a
+// restart, keeping the replaced token as a fallback
+func (ph *ProxmoxHandler) RotateTokenHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req RotateProxmoxTokenRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	log.Printf("Admin %s requested rotation to Proxmox API token %s", username, req.TokenID)
+
+	if err := ph.service.RotateAPIToken(c.Request.Context(), req.TokenID, req.TokenSecret); err != nil {
+		log.Printf("Failed to rotate to Proxmox API token %s: %v", req.TokenID, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to rotate Proxmox API token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	audit.Record(username, "rotate_proxmox_token", req.TokenID, "")
+	c.JSON(http.StatusOK, gin.H{"message": "Proxmox API token rotated", "status": ph.service.GetTokenStatus()})
+}
+
 // ADMIN: GetVMsHandler handles GET requests for retrieving all VMs on Proxmox
 func (ph *ProxmoxHandler) GetVMsHandler(c *gin.Context) {
 	vms, err := ph.service.GetVMs()
@@ -51,52 +99,92 @@ func (ph *ProxmoxHandler) GetVMsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"vms": vms})
 }
 
-// ADMIN: StartVMHandler handles POST requests for starting a VM on Proxmox
-func (ph *ProxmoxHandler) StartVMHandler(c *gin.Context) {
-	var req VMActionRequest
+// ADMIN: BulkVMActionHandler handles POST requests for starting, shutting down, stopping,
+// or rebooting many VMs at once, reporting the outcome for each VM
+func (ph *ProxmoxHandler) BulkVMActionHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req BulkVMActionRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	if err := ph.service.StartVM(req.Node, req.VMID); err != nil {
-		log.Printf("Error starting VM %d on node %s: %v", req.VMID, req.Node, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start VM", "details": err.Error()})
-		return
+	targets := make([]proxmox.VMActionTarget, 0, len(req.VMs))
+	for _, vm := range req.VMs {
+		targets = append(targets, proxmox.VMActionTarget{Node: vm.Node, VMID: vm.VMID})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "VM started"})
+	results := ph.service.BulkVMAction(req.Action, targets)
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+			log.Printf("Error running %s on VM %d on node %s: %s", req.Action, result.VMID, result.Node, result.Error)
+		}
+	}
+
+	audit.Record(username, "bulk_vm_"+req.Action, fmt.Sprintf("%d VMs", len(targets)), fmt.Sprintf("%d failed", failed))
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
 }
 
-// ADMIN: ShutdownVMHandler handles POST requests for shutting down a VM on Proxmox
-func (ph *ProxmoxHandler) ShutdownVMHandler(c *gin.Context) {
-	var req VMActionRequest
+// ADMIN: MigrateVMHandler handles POST requests for moving a VM to another node, live
+// when online is set, and returns the UPID of the migration task
+func (ph *ProxmoxHandler) MigrateVMHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req MigrateVMRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	if err := ph.service.ShutdownVM(req.Node, req.VMID); err != nil {
-		log.Printf("Error shutting down VM %d on node %s: %v", req.VMID, req.Node, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to shutdown VM", "details": err.Error()})
+	if err := ph.service.ValidateMigration(req.Node, req.VMID, req.Target, req.Online); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid migration", "details": err.Error()})
+		return
+	}
+
+	upid, err := ph.service.MigrateVM(req.Node, req.VMID, req.Target, req.Online)
+	if err != nil {
+		log.Printf("Error migrating VM %d from %s to %s: %v", req.VMID, req.Node, req.Target, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to migrate VM", "details": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "VM shutdown"})
+	audit.Record(username, "migrate_vm", fmt.Sprintf("%s/%d", req.Node, req.VMID), fmt.Sprintf("to %s, online %t", req.Target, req.Online))
+	c.JSON(http.StatusAccepted, gin.H{"status": "VM migration started", "upid": upid})
 }
 
-// ADMIN: RebootVMHandler handles POST requests for rebooting a VM on Proxmox
-func (ph *ProxmoxHandler) RebootVMHandler(c *gin.Context) {
-	var req VMActionRequest
+// ADMIN: ResizeVMHandler handles POST requests for changing the cores and memory of a VM
+// and growing its disks
+func (ph *ProxmoxHandler) ResizeVMHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req ResizeVMRequest
 	if !validateAndBind(c, &req) {
 		return
 	}
 
-	if err := ph.service.RebootVM(req.Node, req.VMID); err != nil {
-		log.Printf("Error rebooting VM %d on node %s: %v", req.VMID, req.Node, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reboot VM", "details": err.Error()})
+	if req.Cores == 0 && req.MemoryMB == 0 && len(req.Disks) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to resize", "details": "set cores, memory_mb, or disks"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "VM rebooted"})
+	resize := proxmox.VMResize{Cores: req.Cores, MemoryMB: req.MemoryMB, Disks: req.Disks}
+	if err := ph.service.ResizeVM(req.Node, req.VMID, resize); err != nil {
+		log.Printf("Error resizing VM %d on node %s: %v", req.VMID, req.Node, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to resize VM", "details": err.Error()})
+		return
+	}
+
+	audit.Record(username, "resize_vm", fmt.Sprintf("%s/%d", req.Node, req.VMID), fmt.Sprintf("cores %d, memory %d MB, disks %v", req.Cores, req.MemoryMB, req.Disks))
+	c.JSON(http.StatusOK, gin.H{"status": "VM resized"})
 }
 
 func (ph *ProxmoxHandler) GetVMTemplatesHandler(c *gin.Context) {
@@ -137,6 +225,171 @@ func (ph *ProxmoxHandler) GetUsedVNetsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "VNets retrieved", "vnets": vnets})
 }
 
+// ADMIN: PushVMFileHandler writes a small file into a VM through the qemu guest agent
+func (ph *ProxmoxHandler) PushVMFileHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req AgentFilePushRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	content, err := base64.StdEncoding.DecodeString(req.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file content", "details": err.Error()})
+		return
+	}
+
+	if len(content) > proxmox.AgentFileWriteMaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "File too large",
+			"details": fmt.Sprintf("file size %d bytes exceeds the limit of %d bytes", len(content), proxmox.AgentFileWriteMaxSize),
+		})
+		return
+	}
+
+	audit.Record(username, "vm_file_push", fmt.Sprintf("%s/%d:%s", req.Node, req.VMID, req.Path), fmt.Sprintf("%d bytes", len(content)))
+
+	if err := ph.service.AgentFileWrite(req.Node, req.VMID, req.Path, content); err != nil {
+		log.Printf("Error writing file %s to VM %d on node %s: %v", req.Path, req.VMID, req.Node, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write file", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "File written", "size": len(content)})
+}
+
+// ADMIN: PullVMFileHandler reads a file from a VM through the qemu guest agent
+func (ph *ProxmoxHandler) PullVMFileHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req AgentFilePullRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	audit.Record(username, "vm_file_pull", fmt.Sprintf("%s/%d:%s", req.Node, req.VMID, req.Path), "")
+
+	content, truncated, err := ph.service.AgentFileRead(req.Node, req.VMID, req.Path)
+	if err != nil {
+		log.Printf("Error reading file %s from VM %d on node %s: %v", req.Path, req.VMID, req.Node, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "File read",
+		"content":   base64.StdEncoding.EncodeToString(content),
+		"size":      len(content),
+		"truncated": truncated,
+	})
+}
+
+// ADMIN: AgentExecHandler runs a command in each of the given VMs through the qemu guest
+// agent and returns the exit code and output of each, every run is recorded in the audit log
+func (ph *ProxmoxHandler) AgentExecHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	username := session.Get("id").(string)
+
+	var req AgentExecRequest
+	if !validateAndBind(c, &req) {
+		return
+	}
+
+	timeout := 30 * time.Second
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	command := strings.Join(req.Command, " ")
+
+	type execResult struct {
+		Node   string                   `json:"node"`
+		VMID   int                      `json:"vmid"`
+		Result *proxmox.AgentExecResult `json:"result,omitempty"`
+		Error  string                   `json:"error,omitempty"`
+	}
+	results := make([]execResult, len(req.VMs))
+
+	var wg sync.WaitGroup
+	for i, vm := range req.VMs {
+		wg.Add(1)
+		go func(i int, vm VMActionRequest) {
+			defer wg.Done()
+
+			target := fmt.Sprintf("%s/%d", vm.Node, vm.VMID)
+			audit.Record(username, "vm_agent_exec", target, command)
+
+			results[i] = execResult{Node: vm.Node, VMID: vm.VMID}
+			result, err := ph.service.AgentRunCommand(vm.Node, vm.VMID, req.Command, timeout)
+			if err != nil {
+				log.Printf("Error running command in VM %d on node %s: %v", vm.VMID, vm.Node, err)
+				results[i].Error = err.Error()
+				audit.Record(username, "vm_agent_exec_failed", target, err.Error())
+				return
+			}
+
+			results[i].Result = result
+			audit.Record(username, "vm_agent_exec_exited", target, fmt.Sprintf("pid %d, exit code %d", result.PID, result.ExitCode))
+		}(i, vm)
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{"status": "Commands run", "results": results})
+}
+
+// ADMIN: GetTasksHandler lists recent cluster tasks started by Kamino, newest first.
+// Query parameters type, node, errors=true, all=true, and limit narrow the list.
+func (ph *ProxmoxHandler) GetTasksHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit", "details": "limit must be between 1 and 500"})
+		return
+	}
+
+	tasks, err := ph.service.GetRecentTasks(proxmox.TaskFilter{
+		Type:       c.Query("type"),
+		Node:       c.Query("node"),
+		ErrorsOnly: c.Query("errors") == "true",
+		AllUsers:   c.Query("all") == "true",
+		Limit:      limit,
+	})
+	if err != nil {
+		log.Printf("Error getting cluster tasks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// ADMIN: GetTaskLogHandler returns the log of a task, paged with the start and limit
+// query parameters
+func (ph *ProxmoxHandler) GetTaskLogHandler(c *gin.Context) {
+	upid := c.Param("upid")
+
+	start, err := strconv.Atoi(c.DefaultQuery("start", "0"))
+	if err != nil || start < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start", "details": "start must be zero or more"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "500"))
+	if err != nil || limit < 1 || limit > 5000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit", "details": "limit must be between 1 and 5000"})
+		return
+	}
+
+	lines, err := ph.service.GetTaskLog(upid, start, limit)
+	if err != nil {
+		log.Printf("Error getting log of task %s: %v", upid, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task log", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"upid": upid, "lines": lines})
+}
+
 func (ph *ProxmoxHandler) CreateTemplateHandler(c *gin.Context) {
 	session := sessions.Default(c)
 	username := session.Get("id").(string)
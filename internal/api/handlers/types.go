@@ -4,11 +4,13 @@ import (
 	"net/http"
 
 	"github.com/cpp-cyber/proclone/internal/api/auth"
+	"github.com/cpp-cyber/proclone/internal/audit"
 	"github.com/cpp-cyber/proclone/internal/cloning"
 	"github.com/cpp-cyber/proclone/internal/ldap"
 	"github.com/cpp-cyber/proclone/internal/proxmox"
 	"github.com/cpp-cyber/proclone/internal/tools"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 // =================================================
@@ -17,9 +19,10 @@ import (
 
 // AuthHandler handles HTTP authentication requests
 type AuthHandler struct {
-	authService    auth.Service
-	ldapService    ldap.Service
-	proxmoxService proxmox.Service
+	authService         auth.Service
+	ldapService         ldap.Service
+	proxmoxService      proxmox.Service
+	accessReviewService *audit.AccessReviewService
 }
 
 // CloningHandler holds the cloning service
@@ -35,6 +38,18 @@ type DashboardHandler struct {
 	cloningHandler *CloningHandler
 }
 
+// ConsoleHandler proxies the VM consoles of pods over WebSockets
+type ConsoleHandler struct {
+	cloningHandler *CloningHandler
+	upgrader       websocket.Upgrader
+}
+
+// EventsHandler streams pod lifecycle events over WebSockets
+type EventsHandler struct {
+	cloningHandler *CloningHandler
+	upgrader       websocket.Upgrader
+}
+
 // ProxmoxHandler handles HTTP requests for Proxmox operations
 type ProxmoxHandler struct {
 	service proxmox.Service
@@ -49,6 +64,45 @@ type VMActionRequest struct {
 	VMID int    `json:"vmid" binding:"required,min=100,max=999999"`
 }
 
+type BulkVMActionRequest struct {
+	Action string            `json:"action" binding:"required,oneof=start shutdown stop reboot"`
+	VMs    []VMActionRequest `json:"vms" binding:"required,min=1,max=500,dive"`
+}
+
+type MigrateVMRequest struct {
+	Node   string `json:"node" binding:"required,min=1,max=100" validate:"alphanum"`
+	VMID   int    `json:"vmid" binding:"required,min=100,max=999999"`
+	Target string `json:"target" binding:"required,min=1,max=100" validate:"alphanum"`
+	Online bool   `json:"online"` // Live migration, required for running VMs
+}
+
+type ResizeVMRequest struct {
+	Node     string         `json:"node" binding:"required,min=1,max=100" validate:"alphanum"`
+	VMID     int            `json:"vmid" binding:"required,min=100,max=999999"`
+	Cores    int            `json:"cores" binding:"omitempty,min=1,max=128"`
+	MemoryMB int            `json:"memory_mb" binding:"omitempty,min=64,max=1048576"`
+	Disks    map[string]int `json:"disks" binding:"omitempty,max=16,dive,keys,alphanum,max=20,endkeys,min=1,max=65536"` // New size in GiB by disk, disks can only grow
+}
+
+type AgentExecRequest struct {
+	VMs            []VMActionRequest `json:"vms" binding:"required,min=1,max=100,dive"`
+	Command        []string          `json:"command" binding:"required,min=1,max=100,dive,max=4096"` // Program and arguments, no shell is involved
+	TimeoutSeconds int               `json:"timeout_seconds" binding:"omitempty,min=1,max=600"`      // Defaults to 30
+}
+
+type AgentFilePushRequest struct {
+	Node    string `json:"node" binding:"required,min=1,max=100" validate:"alphanum"`
+	VMID    int    `json:"vmid" binding:"required,min=100,max=999999"`
+	Path    string `json:"path" binding:"required,min=1,max=4096"`
+	Content string `json:"content" binding:"required,base64"`
+}
+
+type AgentFilePullRequest struct {
+	Node string `json:"node" binding:"required,min=1,max=100" validate:"alphanum"`
+	VMID int    `json:"vmid" binding:"required,min=100,max=999999"`
+	Path string `json:"path" binding:"required,min=1,max=4096"`
+}
+
 type TemplateRequest struct {
 	Template string `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
 }
@@ -58,7 +112,9 @@ type PublishTemplateRequest struct {
 }
 
 type CloneRequest struct {
-	Template string `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Template string   `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	VMNames  []string `json:"vm_names" binding:"omitempty,dive,min=1,max=255"`
+	DryRun   bool     `json:"dry_run"`
 }
 
 type GroupsRequest struct {
@@ -70,16 +126,116 @@ type AdminCloneRequest struct {
 	Usernames    []string `json:"usernames" binding:"omitempty,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
 	Groups       []string `json:"groups" binding:"omitempty,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
 	StartingVMID int      `json:"starting_vmid" binding:"omitempty,min=100,max=999900"`
+	CloneMode    string   `json:"clone_mode" binding:"omitempty,oneof=linked full"`
+	Storage      string   `json:"storage" binding:"omitempty,min=1,max=100"`
+	HAGroup      string   `json:"ha_group" binding:"omitempty,min=1,max=100"`
+	Nodes        []string `json:"nodes" binding:"omitempty,dive,min=1,max=100"`
+	TargetNode   string   `json:"target_node" binding:"omitempty,min=1,max=100"`
+	VMNames      []string `json:"vm_names" binding:"omitempty,dive,min=1,max=255"`
+	DryRun       bool     `json:"dry_run"`
+}
+
+type ScheduleCloneRequest struct {
+	Template     string   `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Usernames    []string `json:"usernames" binding:"omitempty,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
+	Groups       []string `json:"groups" binding:"omitempty,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
+	StartingVMID int      `json:"starting_vmid" binding:"omitempty,min=100,max=999900"`
+	RunAt        string   `json:"run_at" binding:"required,max=64"`     // ISO-8601, local to time_zone when it has no offset
+	TimeZone     string   `json:"time_zone" binding:"omitempty,max=64"` // IANA time zone such as America/Los_Angeles
+}
+
+type ScheduleIDRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+type CreateDeploymentBlackoutRequest struct {
+	Reason   string `json:"reason" binding:"required,min=1,max=255"`
+	StartsAt string `json:"starts_at" binding:"required,max=64"`  // ISO-8601, local to time_zone when it has no offset
+	EndsAt   string `json:"ends_at" binding:"required,max=64"`    // ISO-8601, local to time_zone when it has no offset
+	TimeZone string `json:"time_zone" binding:"omitempty,max=64"` // IANA time zone such as America/Los_Angeles
+}
+
+type DeploymentBlackoutIDRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
 }
 
 type DeletePodRequest struct {
 	Pod string `json:"pod" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
 }
 
+type ExportPodVMRequest struct {
+	Pod  string `json:"pod" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	VMID int    `json:"vmid" binding:"required,min=100,max=999999"`
+}
+
 type AdminDeletePodRequest struct {
 	Pods []string `json:"pods" binding:"required,min=1,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
 }
 
+type AdminDeletePodsByFilterRequest struct {
+	Template    string `json:"template" binding:"omitempty,max=100" validate:"omitempty,alphanum,ascii"`
+	Owner       string `json:"owner" binding:"omitempty,max=100" validate:"omitempty,alphanum,ascii"`
+	MinAgeHours int    `json:"min_age_hours" binding:"min=0"`
+	MinPodID    int    `json:"min_pod_id" binding:"omitempty,min=1000,max=9999"`
+	MaxPodID    int    `json:"max_pod_id" binding:"omitempty,min=1000,max=9999"`
+	DryRun      bool   `json:"dry_run"`
+}
+
+type CancelJobRequest struct {
+	KeepPartial bool `json:"keep_partial"`
+}
+
+type RebalanceNodesRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+type TemplateFlagRequest struct {
+	Name   string `json:"name" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	VMName string `json:"vm_name" binding:"required,min=1,max=255"`
+	Path   string `json:"path" binding:"required,min=1,max=1024"`
+}
+
+type SetTemplateFlagsRequest struct {
+	Template string                `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Flags    []TemplateFlagRequest `json:"flags" binding:"omitempty,dive"`
+}
+
+type PlacementRuleRequest struct {
+	Type    string   `json:"type" binding:"required,oneof=spread together"`
+	VMNames []string `json:"vm_names" binding:"required,min=2,dive,min=1,max=255"`
+}
+
+type SetPlacementRulesRequest struct {
+	Template string                 `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Rules    []PlacementRuleRequest `json:"rules" binding:"omitempty,dive"`
+}
+
+type SubmitFlagRequest struct {
+	Pod  string `json:"pod" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Flag string `json:"flag" binding:"required,min=1,max=255"`
+}
+
+type SharePodRequest struct {
+	Username string `json:"username" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Access   string `json:"access" binding:"required,oneof=read full"`
+}
+
+type UnsharePodRequest struct {
+	Username string `json:"username" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+}
+
+type TransferPodRequest struct {
+	Pod      string `json:"pod" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	NewOwner string `json:"new_owner" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	IsGroup  bool   `json:"is_group"`
+}
+
+type ComparePodsRequest struct {
+	PodA  string                 `json:"pod_a" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	PodB  string                 `json:"pod_b" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Files []cloning.PodFileCheck `json:"files" binding:"omitempty,max=50,dive"`
+}
+
 type UsernamePasswordRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=20" validate:"alphanum,ascii"`
 	Password string `json:"password" binding:"required,min=8,max=128"`
@@ -113,6 +269,47 @@ type RenameGroupRequest struct {
 	NewName string `json:"new_name" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
 }
 
+type SetPodQuotaRequest struct {
+	Target  string `json:"target" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	IsGroup bool   `json:"is_group"`
+	MaxPods int    `json:"max_pods" binding:"min=0,max=1000"`
+}
+
+type PodQuotaTargetRequest struct {
+	Target  string `json:"target" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	IsGroup bool   `json:"is_group"`
+}
+
+type SetOrganizationRequest struct {
+	Name            string `json:"name" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Description     string `json:"description" binding:"omitempty,max=1000"`
+	MemberGroup     string `json:"member_group" binding:"omitempty,max=255"`
+	AdminGroup      string `json:"admin_group" binding:"omitempty,max=255"`
+	MinPodID        int    `json:"min_pod_id" binding:"omitempty,min=1000,max=9999"`
+	MaxPodID        int    `json:"max_pod_id" binding:"omitempty,min=1000,max=9999"`
+	DefaultPodQuota int    `json:"default_pod_quota" binding:"min=0,max=1000"`
+}
+
+type OrganizationNameRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+}
+
+type SetTemplateOrganizationRequest struct {
+	Template     string `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Organization string `json:"organization" binding:"omitempty,max=100" validate:"omitempty,alphanum,ascii"` // Empty returns the template to the shared catalog
+}
+
+type OrganizationCloneRequest struct {
+	Template  string   `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Usernames []string `json:"usernames" binding:"omitempty,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
+	Groups    []string `json:"groups" binding:"omitempty,dive,min=1,max=100" validate:"dive,alphanum,ascii"`
+}
+
+type SetWarmPoolSizeRequest struct {
+	Template string `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Size     int    `json:"size" binding:"min=0,max=50"`
+}
+
 type DashboardStats struct {
 	UserCount              int `json:"users"`
 	GroupCount             int `json:"groups"`
@@ -122,6 +319,100 @@ type DashboardStats struct {
 	ClusterResourceUsage   any `json:"cluster"`
 }
 
+type SetTemplateAllowedGroupsRequest struct {
+	Template string   `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Groups   []string `json:"groups" binding:"omitempty,max=50,dive,min=1,max=255"` // Empty makes the template available to every user
+}
+
+type SetTemplateDeprecatedRequest struct {
+	Template   string `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Deprecated bool   `json:"deprecated"` // False reverses an earlier deprecation
+}
+
+type ReorderTemplateImagesRequest struct {
+	Images []string `json:"images" binding:"required,max=12,dive,min=1,max=255"`
+}
+
+type TemplateImageRequest struct {
+	Image string `json:"image" binding:"required,min=1,max=255"`
+}
+
+type PodFirewallRuleRequest struct {
+	Direction string `json:"direction" binding:"required,oneof=in out"`
+	Action    string `json:"action" binding:"required,oneof=ACCEPT DROP REJECT"`
+	Protocol  string `json:"protocol" binding:"omitempty,oneof=tcp udp icmp"`
+	Port      string `json:"port" binding:"omitempty,max=50"` // Such as 3389, 8000:8080, or 80,443
+	Source    string `json:"source" binding:"omitempty,cidr|ip"`
+	Comment   string `json:"comment" binding:"omitempty,max=200"`
+}
+
+type DeletePodFirewallRuleRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+type PodPortForwardRequest struct {
+	Protocol     string `json:"protocol" binding:"required,oneof=tcp udp"`
+	ExternalPort int    `json:"external_port" binding:"required,min=1,max=65535"`
+	InternalIP   string `json:"internal_ip" binding:"required,ipv4"`
+	InternalPort int    `json:"internal_port" binding:"required,min=1,max=65535"`
+	Description  string `json:"description" binding:"omitempty,max=200"`
+}
+
+type DeletePodPortForwardRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+type VNetSettingsRequest struct {
+	VNetPrefix string `json:"vnet_prefix" binding:"required,min=1,max=20,alphanum"`
+	Zone       string `json:"zone" binding:"omitempty,max=50,alphanum"`
+	VLANBase   int    `json:"vlan_base" binding:"min=0,max=4094"` // Zero skips the VLAN tag check
+}
+
+type VLANReservationRequest struct {
+	FirstTag int    `json:"first_tag" binding:"required,min=1,max=4094"`
+	LastTag  int    `json:"last_tag" binding:"required,min=1,max=4094"`
+	Reason   string `json:"reason" binding:"required,min=1,max=200"`
+}
+
+type DeleteVLANReservationRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+type RevokePodVPNConfigRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+type ConsoleTicketRequest struct {
+	Type string `json:"type" binding:"required,oneof=vnc xterm"`
+}
+
+type SetPodRateLimitRequest struct {
+	Rate float64 `json:"rate" binding:"min=0,max=12500"` // MB/s, zero is unlimited
+}
+
+type CreateVMSnapshotRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=40"`
+	Description string `json:"description" binding:"omitempty,max=255"`
+}
+
+type DuplicateTemplateRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+}
+
+type CreateTemplateFromPodRequest struct {
+	Template string                  `json:"template" binding:"required,min=1,max=100" validate:"alphanum,ascii"`
+	Publish  *cloning.KaminoTemplate `json:"publish" binding:"omitempty"` // Publishes the template with these details, named after the template
+}
+
+type RestoreBackupRequest struct {
+	VolID string `json:"volid" binding:"required,max=255"`
+}
+
+type RotateProxmoxTokenRequest struct {
+	TokenID     string `json:"token_id" binding:"required,max=128"` // <user>@<realm>!<name>
+	TokenSecret string `json:"token_secret" binding:"required,max=128"`
+}
+
 type CreateTemplateRequest struct {
 	Name   string       `json:"name"`
 	Router bool         `json:"add_router"`
@@ -11,9 +11,23 @@ func registerAdminRoutes(g *gin.RouterGroup, authHandler *handlers.AuthHandler,
 	// Admin dashboard and cluster management
 	g.GET("/dashboard", dashboardHandler.GetAdminDashboardStatsHandler)
 	g.GET("/cluster", proxmoxHandler.GetClusterResourceUsageHandler)
+	g.GET("/cluster/cache", proxmoxHandler.GetResourceCacheStatsHandler)
+	g.POST("/cluster/cache/invalidate", proxmoxHandler.InvalidateResourceCacheHandler)
+	g.GET("/proxmox/token", proxmoxHandler.GetTokenStatusHandler)
+	g.POST("/proxmox/token/rotate", proxmoxHandler.RotateTokenHandler)
 	g.GET("/vnets", proxmoxHandler.GetUsedVNetsHandler)
+	g.GET("/capacity", cloningHandler.GetCapacityHandler)
+	g.GET("/ipam", cloningHandler.GetIPAMHandler)
+	g.GET("/vlans", cloningHandler.GetVLANReportHandler)
+	g.POST("/vlans/settings", cloningHandler.SetVNetSettingsHandler)
+	g.POST("/vlans/reservations", cloningHandler.AddVLANReservationHandler)
+	g.POST("/vlans/reservations/delete", cloningHandler.DeleteVLANReservationHandler)
 	g.GET("/vms", proxmoxHandler.GetVMsHandler)
+	g.GET("/tasks", proxmoxHandler.GetTasksHandler)
+	g.GET("/tasks/:upid/log", proxmoxHandler.GetTaskLogHandler)
 	g.GET("/pods", cloningHandler.AdminGetPodsHandler)
+	g.POST("/nodes/rebalance", cloningHandler.RebalanceNodesHandler)
+	g.POST("/smoke-test", cloningHandler.SmokeTestHandler)
 
 	// User management (admin only)
 	g.GET("/users", authHandler.GetUsersHandler)
@@ -30,15 +44,82 @@ func registerAdminRoutes(g *gin.RouterGroup, authHandler *handlers.AuthHandler,
 	g.POST("/group/members/remove", authHandler.RemoveUsersHandler)
 	g.POST("/group/rename", authHandler.RenameGroupHandler)
 	g.POST("/groups/delete", authHandler.DeleteGroupsHandler)
+	g.GET("/groups/cache", authHandler.GetGroupCacheStatsHandler)
+	g.POST("/groups/cache/invalidate", authHandler.InvalidateGroupCacheHandler)
+
+	// Access review reporting (admin only)
+	g.GET("/access-review", authHandler.AccessReviewHandler)
 
 	// VM management (admin only)
-	g.POST("/vm/start", proxmoxHandler.StartVMHandler)
-	g.POST("/vm/shutdown", proxmoxHandler.ShutdownVMHandler)
-	g.POST("/vm/reboot", proxmoxHandler.RebootVMHandler)
+	g.POST("/vms/bulk", proxmoxHandler.BulkVMActionHandler)
+	g.POST("/vms/migrate", proxmoxHandler.MigrateVMHandler)
+	g.POST("/vms/resize", proxmoxHandler.ResizeVMHandler)
+	g.POST("/vm/file/push", proxmoxHandler.PushVMFileHandler)
+	g.POST("/vm/file/pull", proxmoxHandler.PullVMFileHandler)
+	g.POST("/vm/exec", proxmoxHandler.AgentExecHandler)
 
 	// Pod management (admin only)
 	g.POST("/pods/delete", cloningHandler.AdminDeletePodHandler)
+	g.POST("/pods/delete/filter", cloningHandler.AdminDeletePodsByFilterHandler)
+	g.POST("/pods/:pod/repair", cloningHandler.AdminRepairPodHandler)
+	g.POST("/pods/:pod/network/repair", cloningHandler.RepairPodNetworkHandler)
+	g.POST("/pods/:pod/ratelimit", cloningHandler.SetPodRateLimitHandler)
+	g.POST("/pods/:pod/retain", cloningHandler.AdminRetainPodHandler)
+	g.POST("/pods/:pod/restore", cloningHandler.AdminRestorePodHandler)
+	g.POST("/pods/transfer", cloningHandler.AdminTransferPodHandler)
+	g.POST("/pods/compare", cloningHandler.AdminComparePodsHandler)
+	g.GET("/pods/:pod/flags", cloningHandler.AdminGetPodFlagsHandler)
+	g.GET("/pods/:pod/firewall", cloningHandler.GetPodFirewallRulesHandler)
+	g.POST("/pods/:pod/firewall/rules", cloningHandler.AddPodFirewallRuleHandler)
+	g.POST("/pods/:pod/firewall/rules/delete", cloningHandler.DeletePodFirewallRuleHandler)
+	g.POST("/pods/:pod/template", cloningHandler.CreateTemplateFromPodHandler)
+	g.POST("/pods/:pod/backup", cloningHandler.AdminBackupPodHandler)
+	g.GET("/pods/:pod/backups", cloningHandler.AdminGetPodBackupsHandler)
+	g.POST("/pods/:pod/backups/restore", cloningHandler.AdminRestorePodBackupHandler)
+
+	// Pod quota management (admin only)
+	g.GET("/quotas", cloningHandler.GetPodQuotasHandler)
+	g.POST("/quotas/set", cloningHandler.SetPodQuotaHandler)
+	g.POST("/quotas/delete", cloningHandler.DeletePodQuotaHandler)
+	g.GET("/warm-pools", cloningHandler.GetWarmPoolsHandler)
+	g.POST("/warm-pools/set", cloningHandler.SetWarmPoolSizeHandler)
+
+	// Organization management (admin only)
+	g.GET("/organizations", cloningHandler.GetOrganizationsHandler)
+	g.POST("/organizations/set", cloningHandler.SetOrganizationHandler)
+	g.POST("/organizations/delete", cloningHandler.DeleteOrganizationHandler)
+	g.POST("/templates/organization", cloningHandler.SetTemplateOrganizationHandler)
+
+	// Template access by group (admin only)
+	g.POST("/templates/groups", cloningHandler.SetTemplateAllowedGroupsHandler)
+
+	// Template deprecation (admin only)
+	g.POST("/templates/deprecate", cloningHandler.SetTemplateDeprecatedHandler)
+
+	// Template preflight checks and reconciliation (admin only)
+	g.POST("/templates/validate", cloningHandler.ValidateTemplateHandler)
+	g.GET("/templates/reconcile", cloningHandler.ReconcileTemplatesHandler)
+	g.POST("/templates/:name/duplicate", cloningHandler.DuplicateTemplateHandler)
+	g.POST("/templates/:name/resources", cloningHandler.RefreshTemplateResourcesHandler)
+
+	// Template usage analytics (admin only)
+	g.GET("/templates/stats", cloningHandler.GetTemplateStatsSummaryHandler)
+	g.GET("/templates/:name/stats", cloningHandler.GetTemplateStatsHandler)
+
+	// Template manifests for moving templates between instances (admin only)
+	g.GET("/templates/:name/manifest", cloningHandler.ExportTemplateManifestHandler)
+	g.POST("/templates/:name/manifest", cloningHandler.ImportTemplateManifestHandler)
 
 	// Bulk template deployment (admin only)
 	g.POST("/templates/clone", cloningHandler.AdminCloneTemplateHandler)
+
+	// Scheduled deployments (admin only)
+	g.GET("/schedules", cloningHandler.GetScheduledDeploymentsHandler)
+	g.POST("/schedules/create", cloningHandler.ScheduleCloneTemplateHandler)
+	g.POST("/schedules/cancel", cloningHandler.CancelScheduledDeploymentHandler)
+
+	// Deployment blackouts (admin only)
+	g.GET("/blackouts", cloningHandler.GetDeploymentBlackoutsHandler)
+	g.POST("/blackouts/create", cloningHandler.CreateDeploymentBlackoutHandler)
+	g.POST("/blackouts/delete", cloningHandler.DeleteDeploymentBlackoutHandler)
 }
@@ -14,10 +14,23 @@ func registerCreatorRoutes(g *gin.RouterGroup, proxmoxHandler *handlers.ProxmoxH
 	g.POST("/template/delete", cloningHandler.DeleteTemplateHandler)
 	g.POST("/template/visibility", cloningHandler.ToggleTemplateVisibilityHandler)
 	g.POST("/template/image/upload", cloningHandler.UploadTemplateImageHandler)
+	g.POST("/template/flags", cloningHandler.SetTemplateFlagsHandler)
+	g.POST("/template/placement", cloningHandler.SetTemplatePlacementRulesHandler)
+	g.POST("/template/:template/images/upload", cloningHandler.AddTemplateImageHandler)
+	g.POST("/template/:template/images/reorder", cloningHandler.ReorderTemplateImagesHandler)
+	g.POST("/template/:template/images/delete", cloningHandler.DeleteTemplateImageHandler)
+
+	// Template backups (trigger, list, restore)
+	g.POST("/template/:template/backup", cloningHandler.BackupTemplateHandler)
+	g.GET("/template/:template/backups", cloningHandler.GetTemplateBackupsHandler)
+	g.POST("/template/:template/backups/restore", cloningHandler.RestoreTemplateBackupHandler)
 
 	// Template viewing operations
 	g.GET("/templates", cloningHandler.AdminGetTemplatesHandler)
 	g.GET("/templates/unpublished", cloningHandler.GetUnpublishedTemplatesHandler)
 	g.GET("/templates/vms", proxmoxHandler.GetVMTemplatesHandler)
 	g.GET("/templates/proxmox", proxmoxHandler.GetProxmoxTemplatePoolsHandler)
+	g.GET("/template/:template", cloningHandler.GetTemplateDetailHandler)
+	g.GET("/template/:template/flags", cloningHandler.GetTemplateFlagsHandler)
+	g.GET("/template/:template/placement", cloningHandler.GetTemplatePlacementRulesHandler)
 }
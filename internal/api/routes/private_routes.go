@@ -6,16 +6,55 @@ import (
 )
 
 // registerPrivateRoutes defines all routes accessible to authenticated users
-func registerPrivateRoutes(g *gin.RouterGroup, authHandler *handlers.AuthHandler, cloningHandler *handlers.CloningHandler, dashboardHandler *handlers.DashboardHandler) {
+func registerPrivateRoutes(g *gin.RouterGroup, authHandler *handlers.AuthHandler, cloningHandler *handlers.CloningHandler, dashboardHandler *handlers.DashboardHandler, eventsHandler *handlers.EventsHandler, consoleHandler *handlers.ConsoleHandler) {
 	// GET Requests
 	g.GET("/dashboard", dashboardHandler.GetUserDashboardStatsHandler)
 	g.GET("/session", authHandler.SessionHandler)
 	g.GET("/pods", cloningHandler.GetPodsHandler)
 	g.GET("/templates", cloningHandler.GetTemplatesHandler)
+	g.GET("/templates/search", cloningHandler.SearchTemplatesHandler)
 	g.GET("/template/image/:filename", cloningHandler.GetTemplateImageHandler)
+	g.GET("/events", eventsHandler.EventsHandler)
+	g.GET("/exports", cloningHandler.GetPodExportsHandler)
+	g.GET("/export/:token/download", cloningHandler.DownloadPodExportHandler)
+	g.GET("/pods/:pod/shares", cloningHandler.GetPodSharesHandler)
+	g.GET("/pods/:pod/health", cloningHandler.GetPodHealthHandler)
+	g.GET("/pods/:pod/router", cloningHandler.GetPodRouterHandler)
+	g.GET("/pods/:pod/guide", cloningHandler.GetPodGuideHandler)
+	g.GET("/pods/:pod/portforwards", cloningHandler.GetPodPortForwardsHandler)
+	g.GET("/pods/:pod/vpn", cloningHandler.GetPodVPNConfigsHandler)
+	g.GET("/pods/:pod/vms/:vmid/console/ws", consoleHandler.ConsoleWebSocketHandler)
+	g.GET("/pods/:pod/vms/:vmid/spice", consoleHandler.DownloadSPICEConfigHandler)
+	g.GET("/pods/:pod/vms/:vmid/snapshots", cloningHandler.GetPodVMSnapshotsHandler)
+	g.GET("/pods/:pod/vms/:vmid/metrics", cloningHandler.GetPodVMMetricsHandler)
+	g.GET("/organizations", cloningHandler.GetUserOrganizationsHandler)
+	g.GET("/organizations/:org/pods", cloningHandler.GetOrganizationPodsHandler)
+	g.GET("/jobs", cloningHandler.GetJobsHandler)
+	g.GET("/jobs/:id", cloningHandler.GetJobHandler)
+	g.GET("/jobs/:id/stream", cloningHandler.StreamJobHandler)
+	g.POST("/jobs/:id/cancel", cloningHandler.CancelJobHandler)
 
 	// POST Requests
 	g.POST("/logout", authHandler.LogoutHandler)
 	g.POST("/pod/delete", cloningHandler.DeletePodHandler)
+	g.POST("/pod/export", cloningHandler.ExportPodVMHandler)
+	g.POST("/pods/:pod/share", cloningHandler.SharePodHandler)
+	g.POST("/pod/flag/submit", cloningHandler.SubmitPodFlagHandler)
+	g.POST("/pods/:pod/unshare", cloningHandler.UnsharePodHandler)
+	g.POST("/pods/:pod/suspend", cloningHandler.SuspendPodHandler)
+	g.POST("/pods/:pod/resume", cloningHandler.ResumePodHandler)
+	g.POST("/pods/:pod/archive", cloningHandler.ArchivePodHandler)
+	g.POST("/pods/:pod/rehydrate", cloningHandler.RehydratePodHandler)
+	g.POST("/pods/:pod/portforwards", cloningHandler.AddPodPortForwardHandler)
+	g.POST("/pods/:pod/portforwards/delete", cloningHandler.DeletePodPortForwardHandler)
+	g.POST("/pods/:pod/vpn", cloningHandler.CreatePodVPNConfigHandler)
+	g.POST("/pods/:pod/vpn/revoke", cloningHandler.RevokePodVPNConfigHandler)
+	g.POST("/pods/:pod/vms/:vmid/reset", cloningHandler.ResetPodVMHandler)
+	g.POST("/pods/:pod/vms/:vmid/console", consoleHandler.CreateConsoleTicketHandler)
+	g.POST("/pods/:pod/vms/:vmid/snapshots", cloningHandler.CreatePodVMSnapshotHandler)
+	g.POST("/pods/:pod/vms/:vmid/snapshots/:snapshot/rollback", cloningHandler.RollbackPodVMSnapshotHandler)
+	g.POST("/pods/:pod/router", cloningHandler.ReconfigurePodRouterHandler)
 	g.POST("/template/clone", cloningHandler.CloneTemplateHandler)
+	g.POST("/organizations/:org/clone", cloningHandler.OrganizationCloneHandler)
+	g.POST("/organizations/:org/pods/delete", cloningHandler.OrganizationDeletePodsHandler)
 }
@@ -9,6 +9,7 @@ import (
 func registerPublicRoutes(g *gin.RouterGroup, authHandler *handlers.AuthHandler, cloningHandler *handlers.CloningHandler) {
 	// GET Requests
 	g.GET("/health", handlers.HealthCheckHandler(authHandler, cloningHandler))
+	g.GET("/health/proxmox", handlers.ProxmoxHealthHandler(cloningHandler))
 	g.POST("/login", authHandler.LoginHandler)
 	// g.POST("/register", authHandler.RegisterHandler)
 }
@@ -7,7 +7,7 @@ import (
 )
 
 // RegisterRoutes sets up all API routes with their respective middleware and handlers
-func RegisterRoutes(r *gin.Engine, authHandler *handlers.AuthHandler, proxmoxHandler *handlers.ProxmoxHandler, cloningHandler *handlers.CloningHandler) {
+func RegisterRoutes(r *gin.Engine, authHandler *handlers.AuthHandler, proxmoxHandler *handlers.ProxmoxHandler, cloningHandler *handlers.CloningHandler, eventsHandler *handlers.EventsHandler, consoleHandler *handlers.ConsoleHandler) {
 	// Create centralized dashboard handler
 	dashboardHandler := handlers.NewDashboardHandler(authHandler, proxmoxHandler, cloningHandler)
 
@@ -21,7 +21,7 @@ func RegisterRoutes(r *gin.Engine, authHandler *handlers.AuthHandler, proxmoxHan
 	// Private routes (authentication required)
 	private := r.Group("/api/v1")
 	private.Use(middleware.AuthRequired)
-	registerPrivateRoutes(private, authHandler, cloningHandler, dashboardHandler)
+	registerPrivateRoutes(private, authHandler, cloningHandler, dashboardHandler, eventsHandler, consoleHandler)
 
 	// Creator routes (authentication + creator OR admin privileges required)
 	// Template management operations accessible to both creators and admins
@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/ldap"
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/kelseyhightower/envconfig"
+)
+
+// NewAccessReviewService creates a new access review service
+func NewAccessReviewService(ldapService ldap.Service, proxmoxService proxmox.Service) (*AccessReviewService, error) {
+	var config Config
+	if err := envconfig.Process("", &config); err != nil {
+		return nil, fmt.Errorf("failed to process access review configuration: %w", err)
+	}
+
+	return &AccessReviewService{
+		LDAPService:    ldapService,
+		ProxmoxService: proxmoxService,
+		Config:         &config,
+	}, nil
+}
+
+// GenerateAccessReview collects admin and creator accounts, delegated group managers,
+// pool ACLs, and API tokens into a single report
+func (s *AccessReviewService) GenerateAccessReview() (*AccessReview, error) {
+	review := &AccessReview{
+		GeneratedAt:   time.Now().UTC(),
+		Admins:        []string{},
+		Creators:      []string{},
+		GroupManagers: []GroupManager{},
+		PoolACLs:      []proxmox.ACLEntry{},
+		APITokens:     []TokenReview{},
+	}
+
+	users, err := s.LDAPService.GetUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, user := range users {
+		if user.IsAdmin {
+			review.Admins = append(review.Admins, user.Name)
+		}
+		if user.IsCreator {
+			review.Creators = append(review.Creators, user.Name)
+		}
+		if (user.IsAdmin || user.IsCreator) && len(user.Attributes) > 0 {
+			if review.UserAttributes == nil {
+				review.UserAttributes = make(map[string]map[string]string)
+			}
+			review.UserAttributes[user.Name] = user.Attributes
+		}
+	}
+
+	groups, err := s.LDAPService.GetGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	for _, group := range groups {
+		if group.Manager != "" {
+			review.GroupManagers = append(review.GroupManagers, GroupManager{Group: group.Name, Manager: group.Manager})
+		}
+	}
+
+	acls, err := s.ProxmoxService.GetACLs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACLs: %w", err)
+	}
+	for _, acl := range acls {
+		if strings.HasPrefix(acl.Path, "/pool/") {
+			review.PoolACLs = append(review.PoolACLs, acl)
+		}
+	}
+
+	tokens, err := s.ProxmoxService.GetAPITokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	for _, token := range tokens {
+		scope := "inherits user permissions"
+		if token.PrivSep == 1 {
+			scope = "privilege separated"
+		}
+
+		expires := "never"
+		if token.Expire > 0 {
+			expires = time.Unix(token.Expire, 0).UTC().Format(time.RFC3339)
+		}
+
+		review.APITokens = append(review.APITokens, TokenReview{
+			TokenID:  fmt.Sprintf("%s!%s", token.UserID, token.TokenID),
+			Scope:    scope,
+			Expires:  expires,
+			LastUsed: "not tracked by Proxmox",
+			Comment:  token.Comment,
+		})
+	}
+
+	sort.Strings(review.Admins)
+	sort.Strings(review.Creators)
+
+	return review, nil
+}
+
+// WriteCSV writes the review as CSV with one row per reviewed access grant
+func (r *AccessReview) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{{"category", "subject", "scope", "role", "details"}}
+	for _, admin := range r.Admins {
+		rows = append(rows, []string{"admin", admin, "kamino", "admin", r.formatUserAttributes(admin)})
+	}
+	for _, creator := range r.Creators {
+		rows = append(rows, []string{"creator", creator, "kamino", "creator", r.formatUserAttributes(creator)})
+	}
+	for _, manager := range r.GroupManagers {
+		rows = append(rows, []string{"group_manager", manager.Manager, manager.Group, "manager", ""})
+	}
+	for _, acl := range r.PoolACLs {
+		rows = append(rows, []string{"pool_acl", acl.UGID, acl.Path, acl.RoleID, fmt.Sprintf("type=%s propagate=%d", acl.Type, acl.Propagate)})
+	}
+	for _, token := range r.APITokens {
+		rows = append(rows, []string{"api_token", token.TokenID, token.Scope, "", fmt.Sprintf("expires=%s last_used=%s comment=%s", token.Expires, token.LastUsed, token.Comment)})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write access review CSV: %w", err)
+	}
+	return nil
+}
+
+// formatUserAttributes formats the mapped attributes of a user as sorted key=value pairs
+func (r *AccessReview) formatUserAttributes(username string) string {
+	attributes := r.UserAttributes[username]
+
+	fields := make([]string, 0, len(attributes))
+	for field := range attributes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", field, attributes[field]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// RunPeriodicReviews writes an access review CSV to the configured directory on
+// every review interval. It returns immediately if no directory is configured.
+func (s *AccessReviewService) RunPeriodicReviews() {
+	if s.Config.ReviewDir == "" {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.ReviewInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.writeReviewFile(); err != nil {
+			log.Printf("Failed to write periodic access review: %v", err)
+		}
+	}
+}
+
+func (s *AccessReviewService) writeReviewFile() error {
+	review, err := s.GenerateAccessReview()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.Config.ReviewDir, fmt.Sprintf("access-review-%s.csv", review.GeneratedAt.Format("20060102-150405")))
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create access review file: %w", err)
+	}
+	defer file.Close()
+
+	if err := review.WriteCSV(file); err != nil {
+		return err
+	}
+
+	log.Printf("Wrote access review to %s", path)
+	return nil
+}
@@ -0,0 +1,10 @@
+package audit
+
+import (
+	"log"
+)
+
+// Record writes an audit trail entry for a privileged action
+func Record(actor string, action string, target string, details string) {
+	log.Printf("AUDIT actor=%q action=%q target=%q details=%q", actor, action, target, details)
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/ldap"
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// Config holds the configuration for periodic access reviews
+type Config struct {
+	ReviewDir      string        `envconfig:"ACCESS_REVIEW_DIR"` // Reports are only written periodically when set
+	ReviewInterval time.Duration `envconfig:"ACCESS_REVIEW_INTERVAL" default:"720h"`
+}
+
+// AccessReviewService builds access review reports from LDAP and Proxmox
+type AccessReviewService struct {
+	LDAPService    ldap.Service
+	ProxmoxService proxmox.Service
+	Config         *Config
+}
+
+// AccessReview is a point-in-time report of privileged access to the system
+type AccessReview struct {
+	GeneratedAt   time.Time          `json:"generated_at"`
+	Admins        []string           `json:"admins"`
+	Creators      []string           `json:"creators"`
+	GroupManagers []GroupManager     `json:"group_managers"`
+	PoolACLs      []proxmox.ACLEntry `json:"pool_acls"`
+	APITokens     []TokenReview      `json:"api_tokens"`
+	// UserAttributes holds the mapped directory attributes of the reviewed admins and creators
+	UserAttributes map[string]map[string]string `json:"user_attributes,omitempty"`
+}
+
+// GroupManager is a group with a delegated manager set in the directory
+type GroupManager struct {
+	Group   string `json:"group"`
+	Manager string `json:"manager"`
+}
+
+// TokenReview describes a Proxmox API token for review
+type TokenReview struct {
+	TokenID  string `json:"token_id"`
+	Scope    string `json:"scope"`
+	Expires  string `json:"expires"`
+	LastUsed string `json:"last_used"`
+	Comment  string `json:"comment,omitempty"`
+}
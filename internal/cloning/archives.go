@@ -0,0 +1,376 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+)
+
+// =================================================
+// Pod Archive Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodArchiveVM(vm PodArchiveVM) error {
+	query := "INSERT INTO pod_archives (pod, vmid, node, name, volid) VALUES (?, ?, ?, ?, ?)"
+	_, err := c.DB.Exec(query, vm.Pod, vm.VMID, vm.Node, vm.Name, vm.VolID)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodArchive(pod string) ([]PodArchiveVM, error) {
+	query := "SELECT pod, vmid, node, name, volid, created_at FROM pod_archives WHERE pod = ? ORDER BY vmid"
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodArchiveVMs(rows)
+}
+
+// GetArchivedPods returns the names of every archived pod
+func (c *TemplateClient) GetArchivedPods() ([]string, error) {
+	rows, err := c.DB.Query("SELECT DISTINCT pod FROM pod_archives")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	pods := []string{}
+	for rows.Next() {
+		var pod string
+		if err := rows.Scan(&pod); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+func (c *TemplateClient) DeletePodArchive(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_archives WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodArchiveVM(pod string, vmid int) error {
+	_, err := c.DB.Exec("DELETE FROM pod_archives WHERE pod = ? AND vmid = ?", pod, vmid)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Archive Operations
+// =================================================
+
+// ArchivePod shuts the pod down, backs every VM up to the archive storage, and removes
+// the VMs while keeping the pool, so the pod stops using cluster resources until it is
+// rehydrated. The work runs in the background as a job whose ID is returned.
+func (cs *CloningService) ArchivePod(pod string, requestedBy string) (string, error) {
+	if cs.Config.ArchiveStorage == "" {
+		return "", fmt.Errorf("pod archiving is not configured, ARCHIVE_STORAGE is not set")
+	}
+
+	archived, err := cs.DatabaseService.GetPodArchive(pod)
+	if err != nil {
+		return "", err
+	}
+	if len(archived) > 0 {
+		return "", fmt.Errorf("pod %s is already archived", pod)
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+	if slices.ContainsFunc(poolVMs, func(vm proxmox.VirtualResource) bool { return vm.Type == proxmox.GuestTypeLXC }) {
+		return "", fmt.Errorf("pod %s contains containers, which cannot be archived", pod)
+	}
+	poolVMs = slices.DeleteFunc(poolVMs, func(vm proxmox.VirtualResource) bool { return vm.Type != proxmox.GuestTypeQEMU })
+	if len(poolVMs) == 0 {
+		return "", fmt.Errorf("pod %s has no VMs to archive", pod)
+	}
+
+	job := cs.Jobs.Create(jobs.TypeArchive, requestedBy, fmt.Sprintf("Archive pod %s", pod), nil, nil)
+	ctx := cs.Jobs.WithCancel(context.Background(), job.ID)
+	go func() {
+		err := cs.archivePod(ctx, job.ID, pod, poolVMs)
+		if err != nil {
+			log.Printf("Archive of pod %s failed: %v", pod, err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return job.ID, nil
+}
+
+// RehydratePod restores the VMs of an archived pod from their backups into its pool. The
+// VMs keep their original VMIDs unless those have since been taken. The work runs in the
+// background as a job whose ID is returned.
+func (cs *CloningService) RehydratePod(pod string, requestedBy string) (string, error) {
+	archived, err := cs.DatabaseService.GetPodArchive(pod)
+	if err != nil {
+		return "", err
+	}
+	if len(archived) == 0 {
+		return "", fmt.Errorf("pod %s is not archived", pod)
+	}
+
+	job := cs.Jobs.Create(jobs.TypeRehydrate, requestedBy, fmt.Sprintf("Rehydrate pod %s", pod), nil, nil)
+	ctx := cs.Jobs.WithCancel(context.Background(), job.ID)
+	go func() {
+		err := cs.rehydratePod(ctx, job.ID, pod, archived)
+		if err != nil {
+			log.Printf("Rehydration of pod %s failed: %v", pod, err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return job.ID, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (cs *CloningService) archivePod(ctx context.Context, jobID string, pod string, poolVMs []proxmox.VirtualResource) error {
+	// 1. Shut down the VMs so the backups are consistent
+	cs.Jobs.Update(jobID, 5, "Shutting down VMs")
+	for _, vm := range poolVMs {
+		if vm.RunningStatus != "running" {
+			continue
+		}
+		if err := cs.ProxmoxService.ShutdownVM(vm.NodeName, vm.VmId); err != nil {
+			return fmt.Errorf("failed to shut down VM %s: %w", vm.Name, err)
+		}
+	}
+	for _, vm := range poolVMs {
+		if vm.RunningStatus != "running" {
+			continue
+		}
+		if err := cs.ProxmoxService.WaitForStopped(ctx, vm.NodeName, vm.VmId); err != nil {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			// Guests without ACPI support ignore the shutdown request
+			if err := cs.ProxmoxService.StopVM(vm.NodeName, vm.VmId); err != nil {
+				return fmt.Errorf("failed to stop VM %s: %w", vm.Name, err)
+			}
+		}
+	}
+
+	// 2. Back up every VM, removing the backups already taken if one fails
+	var backups []PodArchiveVM
+	for i, vm := range poolVMs {
+		cs.Jobs.Update(jobID, 10+80*i/len(poolVMs), fmt.Sprintf("Backing up VM %s", vm.Name))
+
+		volID, err := cs.backupVM(ctx, vm)
+		if err != nil {
+			cs.deleteArchiveBackups(backups)
+			return fmt.Errorf("failed to back up VM %s: %w", vm.Name, err)
+		}
+		backups = append(backups, PodArchiveVM{Pod: pod, VMID: vm.VmId, Node: vm.NodeName, Name: vm.Name, VolID: volID})
+	}
+
+	// 3. Record the backups before any VM is removed
+	for _, backup := range backups {
+		if err := cs.DatabaseService.SavePodArchiveVM(backup); err != nil {
+			if delErr := cs.DatabaseService.DeletePodArchive(pod); delErr != nil {
+				log.Printf("Failed to delete archive records of pod %s: %v", pod, delErr)
+			}
+			cs.deleteArchiveBackups(backups)
+			return fmt.Errorf("failed to record archive of VM %s: %w", backup.Name, err)
+		}
+	}
+
+	// 4. Remove the VMs, the pool and its permissions stay for rehydration
+	cs.Jobs.Update(jobID, 95, "Removing VMs")
+	if _, err := cs.removeHAResources(poolVMs); err != nil {
+		log.Printf("Failed to remove HA resources of pod %s: %v", pod, err)
+	}
+	var errors []string
+	for _, vm := range poolVMs {
+		if err := cs.ProxmoxService.DeleteVM(vm.NodeName, vm.VmId); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to delete VM %s: %v", vm.Name, err))
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("pod %s archived but VMs remain: %v", pod, errors)
+	}
+
+	log.Printf("Archived %d VMs of pod %s to %s", len(backups), pod, cs.Config.ArchiveStorage)
+	return nil
+}
+
+func (cs *CloningService) rehydratePod(ctx context.Context, jobID string, pod string, archived []PodArchiveVM) error {
+	cs.Jobs.Update(jobID, 5, "Allocating VMIDs")
+
+	// Hold the allocation mutex until every restore has claimed its VMID
+	cs.vmidMutex.Lock()
+	vmIDs, err := cs.rehydrateVMIDs(archived)
+	if err != nil {
+		cs.vmidMutex.Unlock()
+		return err
+	}
+
+	var upids []string
+	var errors []string
+	for i, vm := range archived {
+		upid, err := cs.ProxmoxService.RestoreVM(vm.Node, vmIDs[i], vm.VolID, pod, false)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to restore VM %s: %v", vm.Name, err))
+		}
+		upids = append(upids, upid)
+	}
+	cs.vmidMutex.Unlock()
+
+	for i, vm := range archived {
+		if upids[i] == "" {
+			continue
+		}
+
+		cs.Jobs.Update(jobID, 10+85*i/len(archived), fmt.Sprintf("Restoring VM %s", vm.Name))
+		if err := cs.ProxmoxService.WaitForTask(ctx, vm.Node, upids[i], cs.Config.ArchiveTimeout); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to restore VM %s: %v", vm.Name, err))
+			continue
+		}
+
+		// Drop the record of each VM that is back so a retry only restores the missing ones
+		if err := cs.DatabaseService.DeletePodArchiveVM(pod, vm.VMID); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to delete archive record of VM %s: %v", vm.Name, err))
+			continue
+		}
+		cs.deleteArchiveBackups([]PodArchiveVM{vm})
+	}
+
+	// Records of VMs that failed to restore are kept so the rehydration can be retried
+	if len(errors) > 0 {
+		return fmt.Errorf("rehydration of pod %s completed with errors: %v", pod, errors)
+	}
+
+	log.Printf("Rehydrated %d VMs of pod %s", len(archived), pod)
+	return nil
+}
+
+// backupVM backs a stopped VM up to the archive storage and returns the backup volume ID
+func (cs *CloningService) backupVM(ctx context.Context, vm proxmox.VirtualResource) (string, error) {
+	upid, err := cs.ProxmoxService.BackupVMToStorage(vm.NodeName, vm.VmId, cs.Config.ArchiveStorage)
+	if err != nil {
+		return "", err
+	}
+
+	if err := cs.ProxmoxService.WaitForTask(ctx, vm.NodeName, upid, cs.Config.ArchiveTimeout); err != nil {
+		return "", err
+	}
+
+	return cs.ProxmoxService.GetLatestBackup(vm.NodeName, cs.Config.ArchiveStorage, vm.VmId)
+}
+
+// rehydrateVMIDs returns the VMID to restore each archived VM to, its original VMID when
+// that is still free and a newly allocated one otherwise
+func (cs *CloningService) rehydrateVMIDs(archived []PodArchiveVM) ([]int, error) {
+	vms, err := cs.ProxmoxService.GetVMs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VMs: %w", err)
+	}
+
+	vmIDs := make([]int, len(archived))
+	var taken []int
+	for i, vm := range archived {
+		if slices.ContainsFunc(vms, func(existing proxmox.VirtualResource) bool { return existing.VmId == vm.VMID }) {
+			taken = append(taken, i)
+			continue
+		}
+		vmIDs[i] = vm.VMID
+	}
+
+	if len(taken) > 0 {
+		newIDs, err := cs.ProxmoxService.GetNextVMIDs(len(taken))
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate VMIDs: %w", err)
+		}
+		for j, i := range taken {
+			vmIDs[i] = newIDs[j]
+		}
+	}
+
+	return vmIDs, nil
+}
+
+// discardPodArchive removes the backups and records of an archived pod that is being deleted
+func (cs *CloningService) discardPodArchive(pod string) {
+	archived, err := cs.DatabaseService.GetPodArchive(pod)
+	if err != nil {
+		log.Printf("Failed to get archive of pod %s: %v", pod, err)
+		return
+	}
+	if len(archived) == 0 {
+		return
+	}
+
+	cs.deleteArchiveBackups(archived)
+	if err := cs.DatabaseService.DeletePodArchive(pod); err != nil {
+		log.Printf("Failed to delete archive records of pod %s: %v", pod, err)
+	}
+}
+
+func (cs *CloningService) deleteArchiveBackups(backups []PodArchiveVM) {
+	for _, backup := range backups {
+		if err := cs.ProxmoxService.DeleteBackup(backup.Node, cs.Config.ArchiveStorage, backup.VolID); err != nil {
+			log.Printf("Failed to delete archive backup %s: %v", backup.VolID, err)
+		}
+	}
+}
+
+// archivedPods returns the set of archived pods, used to flag them in pod listings
+func (cs *CloningService) archivedPods() map[string]bool {
+	pods, err := cs.DatabaseService.GetArchivedPods()
+	if err != nil {
+		log.Printf("Failed to get archived pods: %v", err)
+		return nil
+	}
+
+	archived := make(map[string]bool)
+	for _, pod := range pods {
+		archived[pod] = true
+	}
+	return archived
+}
+
+func buildPodArchiveVMs(rows *sql.Rows) ([]PodArchiveVM, error) {
+	vms := []PodArchiveVM{}
+
+	for rows.Next() {
+		var vm PodArchiveVM
+		err := rows.Scan(
+			&vm.Pod,
+			&vm.VMID,
+			&vm.Node,
+			&vm.Name,
+			&vm.VolID,
+			&vm.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		vms = append(vms, vm)
+	}
+
+	return vms, nil
+}
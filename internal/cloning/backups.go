@@ -0,0 +1,346 @@
+package cloning
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+)
+
+// =================================================
+// Pool Backup Operations
+// =================================================
+
+// BackupPools backs up every VM of the given template pools and pods to the backup
+// storage while they keep running. The work runs in the background as a job whose ID is
+// returned.
+func (cs *CloningService) BackupPools(pools []string, requestedBy string) (string, error) {
+	if cs.Config.BackupStorage == "" {
+		return "", fmt.Errorf("backups are not configured, BACKUP_STORAGE is not set")
+	}
+	if len(pools) == 0 {
+		return "", fmt.Errorf("no pools to back up")
+	}
+
+	description := fmt.Sprintf("Back up %s", pools[0])
+	if len(pools) > 1 {
+		description = fmt.Sprintf("Back up %d pools", len(pools))
+	}
+
+	job := cs.Jobs.Create(jobs.TypeBackup, requestedBy, description, nil, nil)
+	ctx := cs.Jobs.WithCancel(context.Background(), job.ID)
+	go func() {
+		err := cs.backupPools(ctx, job.ID, pools)
+		if err != nil {
+			log.Printf("Backup of %s failed: %v", strings.Join(pools, ", "), err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return job.ID, nil
+}
+
+// GetPoolBackups lists the backups taken of a template pool or pod, newest first. Backups
+// of VMs that were since removed from the pool are included.
+func (cs *CloningService) GetPoolBackups(pool string) ([]PoolBackup, error) {
+	if cs.Config.BackupStorage == "" {
+		return nil, fmt.Errorf("backups are not configured, BACKUP_STORAGE is not set")
+	}
+
+	nodes, err := cs.backupNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []PoolBackup{}
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		volumes, err := cs.ProxmoxService.GetBackups(node, cs.Config.BackupStorage, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range volumes {
+			backupPool, name, ok := parseBackupNotes(volume.Notes)
+			// Shared storage lists the same backups on every node
+			if !ok || backupPool != pool || seen[volume.VolID] {
+				continue
+			}
+			seen[volume.VolID] = true
+			backups = append(backups, PoolBackup{
+				Pool:      pool,
+				VMID:      volume.VMID,
+				Name:      name,
+				Node:      node,
+				VolID:     volume.VolID,
+				Type:      volume.Subtype,
+				Size:      volume.Size,
+				CreatedAt: time.Unix(volume.CTime, 0),
+			})
+		}
+	}
+
+	slices.SortFunc(backups, func(a, b PoolBackup) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.VMID, b.VMID))
+	})
+	return backups, nil
+}
+
+// RestorePoolBackup restores a VM of a template pool or pod from one of the pool's
+// backups. A VM still holding the backup's VMID is stopped and overwritten, a removed VM
+// is recreated in the pool under its old VMID. The work runs in the background as a job
+// whose ID is returned.
+func (cs *CloningService) RestorePoolBackup(pool string, volID string, requestedBy string) (string, error) {
+	backups, err := cs.GetPoolBackups(pool)
+	if err != nil {
+		return "", err
+	}
+
+	i := slices.IndexFunc(backups, func(b PoolBackup) bool { return b.VolID == volID })
+	if i < 0 {
+		return "", fmt.Errorf("%s is not a backup of %s", volID, pool)
+	}
+	backup := backups[i]
+	if backup.Type == proxmox.GuestTypeLXC {
+		return "", fmt.Errorf("backup %s is of a container, which cannot be restored", volID)
+	}
+
+	vms, err := cs.ProxmoxService.GetVMs()
+	if err != nil {
+		return "", fmt.Errorf("failed to get VMs: %w", err)
+	}
+	var existing *proxmox.VirtualResource
+	if j := slices.IndexFunc(vms, func(vm proxmox.VirtualResource) bool { return vm.VmId == backup.VMID }); j >= 0 {
+		if vms[j].ResourcePool != pool {
+			return "", fmt.Errorf("VMID %d now belongs to %s, it cannot be overwritten", backup.VMID, cmp.Or(vms[j].ResourcePool, "no pool"))
+		}
+		existing = &vms[j]
+	}
+
+	job := cs.Jobs.Create(jobs.TypeRestore, requestedBy, fmt.Sprintf("Restore VM %s of %s", backup.Name, pool), nil, nil)
+	ctx := cs.Jobs.WithCancel(context.Background(), job.ID)
+	go func() {
+		err := cs.restorePoolVM(ctx, job.ID, pool, backup, existing)
+		if err != nil {
+			log.Printf("Restore of VM %s of %s failed: %v", backup.Name, pool, err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return job.ID, nil
+}
+
+// backupOnSchedule backs up every template pool, and every pod when enabled, each
+// BACKUP_INTERVAL
+func (cs *CloningService) backupOnSchedule() {
+	if cs.Config.BackupInterval <= 0 || cs.Config.BackupStorage == "" {
+		return
+	}
+
+	ticker := time.NewTicker(cs.Config.BackupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		pools, err := cs.ProxmoxService.GetTemplatePools()
+		if err != nil {
+			log.Printf("Scheduled backup failed: %v", err)
+			continue
+		}
+
+		if cs.Config.BackupPods {
+			pods, err := cs.AdminGetPods()
+			if err != nil {
+				log.Printf("Scheduled backup failed: %v", err)
+				continue
+			}
+			for _, pod := range pods {
+				// Archived pods have no VMs left to back up
+				if len(pod.VMs) > 0 {
+					pools = append(pools, pod.Name)
+				}
+			}
+		}
+
+		jobID, err := cs.BackupPools(pools, "scheduler")
+		if err != nil {
+			log.Printf("Scheduled backup failed: %v", err)
+			continue
+		}
+		log.Printf("Scheduled backup started job %s covering %d pools", jobID, len(pools))
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// backupPools backs the VMs of the pools up one at a time, carrying on past failures so
+// one broken VM does not leave the rest without backups
+func (cs *CloningService) backupPools(ctx context.Context, jobID string, pools []string) error {
+	cs.Jobs.Update(jobID, 2, "Listing VMs")
+
+	type poolVM struct {
+		pool string
+		vm   proxmox.VirtualResource
+	}
+	var targets []poolVM
+	for _, pool := range pools {
+		vms, err := cs.ProxmoxService.GetPoolVMs(pool)
+		if err != nil {
+			return fmt.Errorf("failed to get pool VMs for %s: %w", pool, err)
+		}
+		for _, vm := range vms {
+			targets = append(targets, poolVM{pool: pool, vm: vm})
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no VMs to back up")
+	}
+
+	var errors []string
+	for i, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return context.Cause(ctx)
+		}
+
+		cs.Jobs.Update(jobID, 5+90*i/len(targets), fmt.Sprintf("Backing up VM %s of %s", target.vm.Name, target.pool))
+		if err := cs.backupPoolVM(ctx, target.pool, target.vm); err != nil {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			errors = append(errors, fmt.Sprintf("failed to back up VM %s of %s: %v", target.vm.Name, target.pool, err))
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("backup completed with errors: %v", errors)
+	}
+
+	log.Printf("Backed up %d VMs of %d pools to %s", len(targets), len(pools), cs.Config.BackupStorage)
+	return nil
+}
+
+// backupPoolVM backs a VM up with notes naming its pool, then prunes the pool's older
+// backups of the VM beyond BACKUP_KEEP
+func (cs *CloningService) backupPoolVM(ctx context.Context, pool string, vm proxmox.VirtualResource) error {
+	upid, err := cs.ProxmoxService.BackupVMWithNotes(vm.NodeName, vm.VmId, cs.Config.BackupStorage, pool+"/{{guestname}}")
+	if err != nil {
+		return err
+	}
+	if err := cs.ProxmoxService.WaitForTask(ctx, vm.NodeName, upid, cs.Config.BackupTimeout); err != nil {
+		return err
+	}
+
+	if cs.Config.BackupKeep <= 0 {
+		return nil
+	}
+
+	// Only backups labelled with the pool are pruned, archives on the same storage are kept
+	volumes, err := cs.ProxmoxService.GetBackups(vm.NodeName, cs.Config.BackupStorage, vm.VmId)
+	if err != nil {
+		log.Printf("Failed to prune backups of VM %s: %v", vm.Name, err)
+		return nil
+	}
+	volumes = slices.DeleteFunc(volumes, func(volume proxmox.Backup) bool {
+		backupPool, _, ok := parseBackupNotes(volume.Notes)
+		return !ok || backupPool != pool
+	})
+	slices.SortFunc(volumes, func(a, b proxmox.Backup) int { return cmp.Compare(b.CTime, a.CTime) })
+	for _, volume := range volumes[min(cs.Config.BackupKeep, len(volumes)):] {
+		if err := cs.ProxmoxService.DeleteBackup(vm.NodeName, cs.Config.BackupStorage, volume.VolID); err != nil {
+			log.Printf("Failed to prune backup %s: %v", volume.VolID, err)
+		}
+	}
+
+	return nil
+}
+
+func (cs *CloningService) restorePoolVM(ctx context.Context, jobID string, pool string, backup PoolBackup, existing *proxmox.VirtualResource) error {
+	node := backup.Node
+	var upid string
+	if existing != nil {
+		node = existing.NodeName
+		if existing.RunningStatus == "running" {
+			cs.Jobs.Update(jobID, 5, "Stopping VM")
+			if err := cs.ProxmoxService.StopVM(node, existing.VmId); err != nil {
+				return fmt.Errorf("failed to stop VM %s: %w", existing.Name, err)
+			}
+			if err := cs.ProxmoxService.WaitForStopped(ctx, node, existing.VmId); err != nil {
+				return fmt.Errorf("failed to stop VM %s: %w", existing.Name, err)
+			}
+		}
+
+		cs.Jobs.Update(jobID, 10, fmt.Sprintf("Restoring VM %s", backup.Name))
+		var err error
+		upid, err = cs.ProxmoxService.RestoreVM(node, backup.VMID, backup.VolID, pool, true)
+		if err != nil {
+			return err
+		}
+	} else {
+		cs.Jobs.Update(jobID, 10, fmt.Sprintf("Restoring VM %s", backup.Name))
+		var err error
+		upid, err = cs.restoreRemovedVM(node, pool, backup)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := cs.ProxmoxService.WaitForTask(ctx, node, upid, cs.Config.BackupTimeout); err != nil {
+		return fmt.Errorf("failed to restore VM %s: %w", backup.Name, err)
+	}
+
+	log.Printf("Restored VM %s of %s from %s", backup.Name, pool, backup.VolID)
+	return nil
+}
+
+// restoreRemovedVM recreates a removed VM under its old VMID, holding the allocation mutex
+// so a clone cannot claim the VMID between the check and the restore
+func (cs *CloningService) restoreRemovedVM(node string, pool string, backup PoolBackup) (string, error) {
+	cs.vmidMutex.Lock()
+	defer cs.vmidMutex.Unlock()
+
+	vms, err := cs.ProxmoxService.GetVMs()
+	if err != nil {
+		return "", fmt.Errorf("failed to get VMs: %w", err)
+	}
+	if slices.ContainsFunc(vms, func(vm proxmox.VirtualResource) bool { return vm.VmId == backup.VMID }) {
+		return "", fmt.Errorf("VMID %d was taken before the restore started", backup.VMID)
+	}
+
+	return cs.ProxmoxService.RestoreVM(node, backup.VMID, backup.VolID, pool, false)
+}
+
+// backupNodes returns the nodes the backup storage is available on
+func (cs *CloningService) backupNodes() ([]string, error) {
+	storages, err := cs.ProxmoxService.GetClusterResources("type=storage")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster storage: %w", err)
+	}
+
+	var nodes []string
+	for _, storage := range storages {
+		if storage.Storage == cs.Config.BackupStorage && storage.RunningStatus == "available" {
+			nodes = append(nodes, storage.NodeName)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("backup storage %s is not available on any node", cs.Config.BackupStorage)
+	}
+
+	return nodes, nil
+}
+
+// parseBackupNotes returns the pool and VM name recorded in the notes of a backup taken
+// by backupPoolVM
+func parseBackupNotes(notes string) (string, string, bool) {
+	line, _, _ := strings.Cut(notes, "\n")
+	pool, name, ok := strings.Cut(line, "/")
+	if !ok || pool == "" {
+		return "", "", false
+	}
+	return pool, name, true
+}
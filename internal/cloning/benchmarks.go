@@ -0,0 +1,192 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clone benchmark statuses
+const (
+	BenchmarkStatusCompleted = "completed"
+	BenchmarkStatusFailed    = "failed"
+)
+
+// benchmarkBaselineSamples is how many previous benchmarks form the regression baseline
+const benchmarkBaselineSamples = 10
+
+// =================================================
+// Clone Benchmark Database Operations
+// =================================================
+
+func (c *TemplateClient) SaveCloneBenchmark(benchmark CloneBenchmark) (int64, error) {
+	stages, err := json.Marshal(benchmark.Stages)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal benchmark stages: %w", err)
+	}
+
+	query := "INSERT INTO clone_benchmarks (label, template, num_targets, duration_ms, stages, status, error) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	result, err := c.DB.Exec(query, benchmark.Label, benchmark.Template, benchmark.NumTargets, benchmark.DurationMS, string(stages), benchmark.Status, benchmark.Error)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// GetCloneBenchmarks returns the most recent benchmarks of a template at the given target count, newest first
+func (c *TemplateClient) GetCloneBenchmarks(templateName string, numTargets int, limit int) ([]CloneBenchmark, error) {
+	query := `SELECT id, label, template, num_targets, duration_ms, stages, status, error, created_at FROM clone_benchmarks
+		WHERE template = ? AND num_targets = ? ORDER BY created_at DESC, id DESC LIMIT ?`
+	rows, err := c.DB.Query(query, templateName, numTargets, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildCloneBenchmarks(rows)
+}
+
+// =================================================
+// Clone Benchmark Operations
+// =================================================
+
+// BenchmarkCloneTemplate clones the template for the targets, timing the whole clone and
+// each of its progress stages, removes the pods it created, and records the result
+func (cs *CloningService) BenchmarkCloneTemplate(ctx context.Context, label string, req CloneRequest) (*CloneBenchmark, error) {
+	benchmark := CloneBenchmark{
+		Label:      label,
+		Template:   req.Template,
+		NumTargets: len(req.Targets),
+		Stages:     make(map[string]int64),
+	}
+
+	// Per-target clones report progress concurrently
+	var stageMutex sync.Mutex
+	start := time.Now()
+	req.SSE = req.SSE.WithListener(func(message any) {
+		progress, ok := message.(ProgressMessage)
+		if !ok {
+			return
+		}
+
+		stageMutex.Lock()
+		defer stageMutex.Unlock()
+		if _, seen := benchmark.Stages[progress.Message]; !seen {
+			benchmark.Stages[progress.Message] = time.Since(start).Milliseconds()
+		}
+	})
+
+	err := cs.CloneTemplate(ctx, req)
+	benchmark.DurationMS = time.Since(start).Milliseconds()
+	benchmark.Status = BenchmarkStatusCompleted
+	if err != nil {
+		benchmark.Status = BenchmarkStatusFailed
+		benchmark.Error = err.Error()
+	}
+
+	// CloneTemplate assigns pool names to the shared targets
+	for _, target := range req.Targets {
+		if target.PoolName == "" {
+			continue
+		}
+		if delErr := cs.DeletePod(target.PoolName); delErr != nil {
+			log.Printf("Failed to remove benchmark pod %s: %v", target.PoolName, delErr)
+		}
+	}
+
+	id, saveErr := cs.DatabaseService.SaveCloneBenchmark(benchmark)
+	if saveErr != nil {
+		return &benchmark, fmt.Errorf("failed to record benchmark: %w", saveErr)
+	}
+	benchmark.ID = id
+
+	return &benchmark, err
+}
+
+// CompareCloneBenchmarks compares the mean duration of a session of benchmarks of one
+// template and target count against the median of the completed benchmarks recorded
+// before them. It is a regression when the mean is slower than the baseline by more
+// than the tolerance, a fraction such as 0.2 for 20%.
+func (cs *CloningService) CompareCloneBenchmarks(results []CloneBenchmark, tolerance float64) (*BenchmarkComparison, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no benchmarks to compare")
+	}
+
+	session := make(map[int64]bool)
+	var totalMS int64
+	for _, result := range results {
+		session[result.ID] = true
+		totalMS += result.DurationMS
+	}
+	meanMS := totalMS / int64(len(results))
+
+	// The session's own benchmarks are the newest records, so fetch enough to skip them
+	history, err := cs.DatabaseService.GetCloneBenchmarks(results[0].Template, results[0].NumTargets, benchmarkBaselineSamples+len(results))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark history: %w", err)
+	}
+
+	var durations []int64
+	for _, previous := range history {
+		if session[previous.ID] || previous.Status != BenchmarkStatusCompleted || len(durations) == benchmarkBaselineSamples {
+			continue
+		}
+		durations = append(durations, previous.DurationMS)
+	}
+
+	comparison := &BenchmarkComparison{MeanMS: meanMS, Samples: len(durations)}
+	if len(durations) == 0 {
+		return comparison, nil
+	}
+
+	slices.Sort(durations)
+	comparison.BaselineMS = durations[len(durations)/2]
+	if comparison.BaselineMS > 0 {
+		comparison.Change = float64(meanMS-comparison.BaselineMS) / float64(comparison.BaselineMS)
+	}
+	comparison.Regression = comparison.Change > tolerance
+
+	return comparison, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func buildCloneBenchmarks(rows *sql.Rows) ([]CloneBenchmark, error) {
+	benchmarks := []CloneBenchmark{}
+
+	for rows.Next() {
+		var benchmark CloneBenchmark
+		var stages string
+		var errMsg sql.NullString
+		err := rows.Scan(
+			&benchmark.ID,
+			&benchmark.Label,
+			&benchmark.Template,
+			&benchmark.NumTargets,
+			&benchmark.DurationMS,
+			&stages,
+			&benchmark.Status,
+			&errMsg,
+			&benchmark.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(stages), &benchmark.Stages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal benchmark stages: %w", err)
+		}
+		benchmark.Error = errMsg.String
+
+		benchmarks = append(benchmarks, benchmark)
+	}
+
+	return benchmarks, nil
+}
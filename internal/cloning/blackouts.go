@@ -0,0 +1,110 @@
+package cloning
+
+import (
+	"fmt"
+	"time"
+)
+
+// =================================================
+// Deployment Blackout Database Operations
+// =================================================
+
+func (c *TemplateClient) CreateDeploymentBlackout(blackout DeploymentBlackout) (int64, error) {
+	query := "INSERT INTO deployment_blackouts (reason, starts_at, ends_at, created_by) VALUES (?, ?, ?, ?)"
+	result, err := c.DB.Exec(query, blackout.Reason, blackout.StartsAt.UTC(), blackout.EndsAt.UTC(), blackout.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted id: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetDeploymentBlackouts returns every blackout that has not ended yet, earliest first
+func (c *TemplateClient) GetDeploymentBlackouts() ([]DeploymentBlackout, error) {
+	query := "SELECT id, reason, starts_at, ends_at, created_by, created_at FROM deployment_blackouts WHERE ends_at > ? ORDER BY starts_at"
+	rows, err := c.DB.Query(query, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	blackouts := []DeploymentBlackout{}
+	for rows.Next() {
+		var blackout DeploymentBlackout
+		err := rows.Scan(
+			&blackout.ID,
+			&blackout.Reason,
+			&blackout.StartsAt,
+			&blackout.EndsAt,
+			&blackout.CreatedBy,
+			&blackout.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		blackouts = append(blackouts, blackout)
+	}
+
+	return blackouts, nil
+}
+
+func (c *TemplateClient) DeleteDeploymentBlackout(id int64) error {
+	result, err := c.DB.Exec("DELETE FROM deployment_blackouts WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deployment blackout %d not found", id)
+	}
+
+	return nil
+}
+
+// =================================================
+// Deployment Blackout Operations
+// =================================================
+
+// CheckDeploymentWindow returns a *DeploymentLimitError when user deployments are blocked
+// at the given time. The error carries the end of the blackout, following any blackouts
+// that overlap or directly continue it, as the next time deployments are allowed.
+func (cs *CloningService) CheckDeploymentWindow(target string, at time.Time) error {
+	blackouts, err := cs.DatabaseService.GetDeploymentBlackouts()
+	if err != nil {
+		return fmt.Errorf("failed to get deployment blackouts: %w", err)
+	}
+
+	var active *DeploymentBlackout
+	for i, blackout := range blackouts {
+		if !at.Before(blackout.StartsAt) && at.Before(blackout.EndsAt) {
+			active = &blackouts[i]
+			break
+		}
+	}
+	if active == nil {
+		return nil
+	}
+
+	// Blackouts are ordered by start, so one pass extends the end through every chained blackout
+	nextAllowed := active.EndsAt
+	for _, blackout := range blackouts {
+		if !blackout.StartsAt.After(nextAllowed) && blackout.EndsAt.After(nextAllowed) {
+			nextAllowed = blackout.EndsAt
+		}
+	}
+
+	return &DeploymentLimitError{
+		Target:      target,
+		Reason:      fmt.Sprintf("deployments are blocked (%s), next allowed at %s", active.Reason, nextAllowed.UTC().Format(time.RFC3339)),
+		NextAllowed: nextAllowed,
+	}
+}
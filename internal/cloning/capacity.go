@@ -0,0 +1,207 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// maxWANSubnets is the number of pod numbers that fit in the third octet of a WAN address
+const maxWANSubnets = 255
+
+// =================================================
+// Capacity Database Operations
+// =================================================
+
+// RecordPodIDUsage records the pod IDs in use today, keeping the day's peak
+func (c *TemplateClient) RecordPodIDUsage(used int) error {
+	query := "INSERT INTO pod_id_usage (recorded_on, used) VALUES (CURDATE(), ?) ON DUPLICATE KEY UPDATE used = GREATEST(used, VALUES(used))"
+	if _, err := c.DB.Exec(query, used); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// GetPodIDUsage returns the daily peak pod ID usage since the given time, oldest first
+func (c *TemplateClient) GetPodIDUsage(since time.Time) ([]PodIDUsage, error) {
+	rows, err := c.DB.Query("SELECT recorded_on, used FROM pod_id_usage WHERE recorded_on >= ? ORDER BY recorded_on", since.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []PodIDUsage{}
+	for rows.Next() {
+		var day PodIDUsage
+		var recordedOn time.Time
+		if err := rows.Scan(&recordedOn, &day.Used); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		day.Date = recordedOn.Format("2006-01-02")
+		usage = append(usage, day)
+	}
+
+	return usage, nil
+}
+
+// =================================================
+// Capacity Operations
+// =================================================
+
+// GetCapacityReport reports the free pod IDs, VNets, and WAN subnets of the configured pod
+// range, the daily pod ID usage history, and when the range is projected to run out at
+// the recent deployment rate
+func (cs *CloningService) GetCapacityReport() (*CapacityReport, error) {
+	minPodID, maxPodID := cs.Config.MinPodID, cs.Config.MaxPodID
+
+	usedIDs, err := cs.ProxmoxService.GetUsedPodIDs(minPodID, maxPodID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get used pod IDs: %w", err)
+	}
+
+	vnets, err := cs.ProxmoxService.GetUsedVNets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VNets: %w", err)
+	}
+
+	settings, err := cs.GetVNetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	// Pod numbers with a pod VNet defined in the SDN
+	var vnetNumbers []int
+	for _, vnet := range vnets {
+		if number, ok := settings.podNumber(vnet); ok {
+			vnetNumbers = append(vnetNumbers, number)
+		}
+	}
+
+	report := &CapacityReport{
+		Ranges:  []CapacityRange{},
+		History: []PodIDUsage{},
+	}
+
+	podIDs := CapacityRange{Resource: "pod_ids", Range: fmt.Sprintf("%d-%d", minPodID, maxPodID)}
+	vnetRange := CapacityRange{Resource: "vnets", Range: fmt.Sprintf("%s-%s", settings.vnetName(minPodID-1000), settings.vnetName(maxPodID-1000))}
+	wanRange := CapacityRange{
+		Resource: "wan_subnets",
+		Range:    fmt.Sprintf("%s-%s", cs.ProxmoxService.PodRouterWANIP(minPodID-1000), cs.ProxmoxService.PodRouterWANIP(min(maxPodID-1000, maxWANSubnets))),
+	}
+	for id := minPodID; id <= maxPodID; id++ {
+		number := id - 1000
+		used := slices.Contains(usedIDs, id)
+
+		podIDs.Total++
+		if used {
+			podIDs.Used++
+		}
+
+		// A pod number can only be deployed if its VNet exists
+		if slices.Contains(vnetNumbers, number) {
+			vnetRange.Total++
+			if used {
+				vnetRange.Used++
+			}
+		}
+
+		if number <= maxWANSubnets {
+			wanRange.Total++
+			if used {
+				wanRange.Used++
+			}
+		}
+	}
+	for _, capacity := range []*CapacityRange{&podIDs, &vnetRange, &wanRange} {
+		capacity.Free = max(capacity.Total-capacity.Used, 0)
+		report.Ranges = append(report.Ranges, *capacity)
+	}
+
+	// The smallest of the ranges limits how many more pods can be deployed
+	report.Free = podIDs.Free
+	for _, capacity := range report.Ranges {
+		report.Free = min(report.Free, capacity.Free)
+	}
+
+	report.History, err = cs.DatabaseService.GetPodIDUsage(time.Now().AddDate(0, 0, -cs.Config.CapacityHistoryDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod ID usage history: %w", err)
+	}
+
+	report.DailyRate = podIDUsageRate(report.History, cs.Config.CapacityRateDays)
+	if report.DailyRate > 0 {
+		days := float64(report.Free) / report.DailyRate
+		exhaustion := time.Now().Add(time.Duration(days * float64(24*time.Hour))).Format("2006-01-02")
+		report.ProjectedExhaustion = &exhaustion
+	}
+
+	return report, nil
+}
+
+// =================================================
+// Capacity Worker
+// =================================================
+
+// recordCapacityUsage periodically records the pod IDs in use for the capacity history
+func (cs *CloningService) recordCapacityUsage() {
+	ticker := time.NewTicker(cs.Config.CapacitySampleInterval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		usedIDs, err := cs.ProxmoxService.GetUsedPodIDs(cs.Config.MinPodID, cs.Config.MaxPodID)
+		if err != nil {
+			log.Printf("Capacity worker failed to get used pod IDs: %v", err)
+			continue
+		}
+		if err := cs.DatabaseService.RecordPodIDUsage(len(usedIDs)); err != nil {
+			log.Printf("Capacity worker failed to record pod ID usage: %v", err)
+		}
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// podIDUsageRate returns the least-squares growth in pod IDs used per day over the most
+// recent days of the history
+func podIDUsageRate(history []PodIDUsage, days int) float64 {
+	cutoff := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+
+	var xs, ys []float64
+	for _, day := range history {
+		if day.Date < cutoff {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		xs = append(xs, date.Sub(time.Unix(0, 0)).Hours()/24)
+		ys = append(ys, float64(day.Used))
+	}
+	if len(xs) < 2 {
+		return 0
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var covariance, variance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+
+	return covariance / variance
+}
@@ -0,0 +1,140 @@
+package cloning
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Clone priority classes, an unset priority is treated as a user clone
+const (
+	PriorityAdmin      ClonePriority = "admin"
+	PriorityInstructor ClonePriority = "instructor"
+	PriorityUser       ClonePriority = "user"
+)
+
+// clonePriorities orders the classes from highest to lowest, ties go to the higher class
+var clonePriorities = []ClonePriority{PriorityAdmin, PriorityInstructor, PriorityUser}
+
+// cloneQueue admits clone jobs to resource allocation one at a time. Waiting jobs are
+// scheduled by stride scheduling: each class is served in proportion to its weight, so a
+// higher class goes first without starving the classes below it.
+type cloneQueue struct {
+	mutex   sync.Mutex
+	busy    bool
+	weights map[ClonePriority]int
+	pass    map[ClonePriority]float64 // Virtual time each class has been served up to
+	now     float64                   // Pass of the most recently admitted job
+	waiting map[ClonePriority][]chan struct{}
+}
+
+func newCloneQueue(weights map[string]int) *cloneQueue {
+	q := &cloneQueue{
+		weights: make(map[ClonePriority]int),
+		pass:    make(map[ClonePriority]float64),
+		waiting: make(map[ClonePriority][]chan struct{}),
+	}
+	for _, priority := range clonePriorities {
+		q.weights[priority] = max(weights[string(priority)], 1)
+	}
+
+	return q
+}
+
+// Acquire blocks until the job is admitted or ctx is cancelled. notify is called once if
+// the job has to wait behind others. Every successful Acquire must be paired with Release.
+func (q *cloneQueue) Acquire(ctx context.Context, priority ClonePriority, notify func(ahead int)) error {
+	if _, ok := q.weights[priority]; !ok {
+		priority = PriorityUser
+	}
+
+	q.mutex.Lock()
+	// A class returning from idle must not claim the service it missed while away
+	if len(q.waiting[priority]) == 0 {
+		q.pass[priority] = max(q.pass[priority], q.now)
+	}
+
+	if !q.busy {
+		q.busy = true
+		q.admit(priority)
+		q.mutex.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	q.waiting[priority] = append(q.waiting[priority], ready)
+	ahead := q.pendingLocked()
+	q.mutex.Unlock()
+
+	if notify != nil {
+		notify(ahead)
+	}
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		q.mutex.Lock()
+		defer q.mutex.Unlock()
+		for i, waiter := range q.waiting[priority] {
+			if waiter == ready {
+				q.waiting[priority] = append(q.waiting[priority][:i], q.waiting[priority][i+1:]...)
+				return ctx.Err()
+			}
+		}
+
+		// Admitted while being cancelled, pass the turn on
+		q.releaseLocked()
+		return ctx.Err()
+	}
+}
+
+// Release hands the queue to the next waiting job
+func (q *cloneQueue) Release() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.releaseLocked()
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (q *cloneQueue) releaseLocked() {
+	var next ClonePriority
+	for _, priority := range clonePriorities {
+		if len(q.waiting[priority]) == 0 {
+			continue
+		}
+		if next == "" || q.pass[priority] < q.pass[next] {
+			next = priority
+		}
+	}
+
+	if next == "" {
+		q.busy = false
+		return
+	}
+
+	ready := q.waiting[next][0]
+	q.waiting[next] = q.waiting[next][1:]
+	q.admit(next)
+	log.Printf("Admitting %s clone job from the clone queue (%d still waiting)", next, q.pendingLocked())
+	close(ready)
+}
+
+// admit charges a class for one job, the lower a class's weight the further it advances
+func (q *cloneQueue) admit(priority ClonePriority) {
+	q.now = q.pass[priority]
+	q.pass[priority] += 1 / float64(q.weights[priority])
+}
+
+func (q *cloneQueue) pendingLocked() int {
+	pending := 0
+	for _, waiters := range q.waiting {
+		pending += len(waiters)
+	}
+
+	return pending
+}
@@ -1,18 +1,27 @@
 package cloning
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cpp-cyber/proclone/internal/ldap"
 	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/events"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// routerPattern identifies the router VM in a template pool by name
+var routerPattern = regexp.MustCompile(`(?i)(router|pfsense|vyos)`)
+
 // LoadCloningConfig loads and validates cloning configuration from environment variables
 func LoadCloningConfig() (*Config, error) {
 	var config Config
@@ -49,18 +58,89 @@ func NewCloningService(proxmoxService proxmox.Service, db *sql.DB, ldapService l
 		return nil, fmt.Errorf("incomplete cloning configuration")
 	}
 
+	templateClient := NewTemplateClient(db)
+	if err := templateClient.EnsureSchema(); err != nil {
+		return nil, fmt.Errorf("failed to initialize database schema: %w", err)
+	}
+
 	return &CloningService{
 		ProxmoxService:  proxmoxService,
-		DatabaseService: NewDatabaseService(db),
+		DatabaseService: templateClient,
 		LDAPService:     ldapService,
 		Config:          config,
+		Events:          events.NewBus(),
+		Jobs:            jobs.NewRegistry(),
+		cloneQueue:      newCloneQueue(config.ClonePriorityWeights),
+		idempotencyKeys: make(map[string]*IdempotentClone),
 	}, nil
 }
 
-func (cs *CloningService) CloneTemplate(req CloneRequest) error {
+// CloneTemplate deploys the template for every target, tracking the deployment as a job.
+// Cancelling ctx, or the job, stops the deployment and removes the pods created so far.
+func (cs *CloningService) CloneTemplate(ctx context.Context, req CloneRequest) error {
+	started := time.Now()
+	ctx, req = cs.startCloneJob(ctx, req)
+	err := cs.cloneTemplate(ctx, req)
+	cs.recordTemplateDeployment(req, started, err)
+	cs.Jobs.Finish(req.JobID, err)
+	return err
+}
+
+// StartCloneTemplate runs CloneTemplate in the background and returns the ID of the job
+// tracking the deployment
+func (cs *CloningService) StartCloneTemplate(req CloneRequest) string {
+	ctx, req := cs.startCloneJob(context.Background(), req)
+	go func() {
+		started := time.Now()
+		err := cs.cloneTemplate(ctx, req)
+		if err != nil {
+			log.Printf("Clone of template %s failed: %v", req.Template, err)
+		}
+		cs.recordTemplateDeployment(req, started, err)
+		cs.Jobs.Finish(req.JobID, err)
+	}()
+
+	return req.JobID
+}
+
+// startCloneJob registers the job of a clone request and mirrors its progress onto the job
+func (cs *CloningService) startCloneJob(ctx context.Context, req CloneRequest) (context.Context, CloneRequest) {
+	var users, groups []string
+	for _, target := range req.Targets {
+		if target.IsGroup {
+			groups = append(groups, target.Name)
+		} else {
+			users = append(users, target.Name)
+		}
+	}
+
+	description := fmt.Sprintf("Clone template %s for %d targets", req.Template, len(req.Targets))
+	job := cs.Jobs.Create(jobs.TypeClone, req.RequestedBy, description, users, groups)
+	req.JobID = job.ID
+	cs.attachIdempotentJob(req.RequestedBy, req.IdempotencyKey, job.ID)
+
+	// Mirror progress sent to the client onto the job
+	req.SSE = req.SSE.WithListener(func(message any) {
+		if progress, ok := message.(ProgressMessage); ok {
+			cs.Jobs.Update(job.ID, progress.Progress, progress.Message)
+		}
+	})
+	req.SSE.Send(
+		ProgressMessage{
+			Message: "Deployment started",
+			JobID:   job.ID,
+		},
+	)
+
+	return cs.Jobs.WithCancel(ctx, job.ID), req
+}
+
+func (cs *CloningService) cloneTemplate(ctx context.Context, req CloneRequest) error {
 	var errors []string
 	var createdPools []string
 	var clonedRouters []RouterInfo
+	// Pools of the targets that hit an error, the others are still registered with HA
+	failedTargets := make(map[string]bool)
 
 	// 1. Get the template pool and its VMs
 	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + req.Template)
@@ -68,24 +148,60 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 		return fmt.Errorf("failed to get template pool: %w", err)
 	}
 
+	templatePool, err = selectTemplateVMs(templatePool, req.VMNames)
+	if err != nil {
+		return err
+	}
+
 	// 2. Check if any template is already deployed (if requested)
 	if req.CheckExistingDeployments {
 		for _, target := range req.Targets {
-			targetPoolName := fmt.Sprintf("%s_%s", req.Template, target.Name)
-			isValid, err := cs.ValidateCloneRequest(targetPoolName, target.Name)
-			if err != nil {
+			if err := cs.ValidateCloneRequest(req.Template, target); err != nil {
 				return fmt.Errorf("failed to validate the deployment of template for %s: %w", target.Name, err)
 			}
-			if !isValid {
-				return fmt.Errorf("template %s is already deployed for %s or they have exceeded the maximum of 5 deployed pods", req.Template, target.Name)
-			}
 		}
 	}
 
+	// Hand pre-cloned warm pods to as many targets as possible, only the rest are cloned
+	if req.UseWarmPool {
+		req.Targets = cs.assignWarmPods(req)
+		if len(req.Targets) == 0 {
+			req.SSE.Send(
+				ProgressMessage{
+					Message:  "Template cloning completed!",
+					Progress: 100,
+				},
+			)
+			return nil
+		}
+	}
+
+	// Resolve clone mode and storage, request overrides take precedence over the template settings
+	templateInfo, err := cs.DatabaseService.GetTemplateInfo(req.Template)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	cloneMode := req.CloneMode
+	if cloneMode == "" {
+		cloneMode = templateInfo.CloneMode
+	}
+	fullClone := 0
+	if cloneMode == CloneModeFull {
+		fullClone = 1
+	}
+	storage, err := cs.resolveCloneStorage(req, templateInfo, cloneMode)
+	if err != nil {
+		return err
+	}
+	haGroup, err := cs.resolveHAGroup(req, templateInfo)
+	if err != nil {
+		return err
+	}
+	log.Printf("Cloning template %s using clone mode %q and storage %q", req.Template, cloneMode, storage)
+
 	// 3. Identify router and other VMs
 	var router *proxmox.VM
 	var templateVMs []proxmox.VM
-	routerPattern := regexp.MustCompile(`(?i)(router|pfsense|vyos)`)
 
 	for _, vm := range templatePool {
 		// Check to see if this VM is the router
@@ -94,12 +210,14 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 				Name: vm.Name,
 				Node: vm.NodeName,
 				VMID: vm.VmId,
+				Type: vm.Type,
 			}
 		} else {
 			templateVMs = append(templateVMs, proxmox.VM{
 				Name: vm.Name,
 				Node: vm.NodeName,
 				VMID: vm.VmId,
+				Type: vm.Type,
 			})
 		}
 	}
@@ -113,24 +231,81 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 		}
 	}
 
+	placement, err := cs.resolveVMPlacement(req)
+	if err != nil {
+		return err
+	}
+
 	// 4. Verify that the pool is not empty
 	if len(templateVMs) == 0 {
 		return fmt.Errorf("template pool %s contains no VMs", req.Template)
 	}
 
+	// Validate pinned nodes have the capacity for the deployment
+	if nodes := req.placementNodes(); len(nodes) > 0 {
+		warnings, err := cs.validatePinnedNodes(nodes, templatePool, len(req.Targets))
+		if err != nil {
+			return fmt.Errorf("failed to validate pinned nodes: %w", err)
+		}
+		for _, warning := range warnings {
+			req.SSE.Send(
+				ProgressMessage{
+					Message:  fmt.Sprintf("Warning: %s", warning),
+					Progress: 5,
+				},
+			)
+		}
+	}
+
 	// 5. Get pod IDs, Numbers, and VMIDs and assign them to targets
 	numVMsPerTarget := len(templateVMs) + 1 // +1 for router
 	log.Printf("Number of VMs per target (including router): %d", numVMsPerTarget)
 
-	podIDs, podNumbers, err := cs.ProxmoxService.GetNextPodIDs(cs.Config.MinPodID, cs.Config.MaxPodID, len(req.Targets))
+	// Wait for this job's turn to allocate resources, competing jobs are admitted by priority
+	err = cs.cloneQueue.Acquire(ctx, req.Priority, func(ahead int) {
+		req.SSE.Send(
+			ProgressMessage{
+				Message:  fmt.Sprintf("Waiting in the clone queue behind %d deployments", ahead),
+				Progress: 5,
+			},
+		)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get next pod IDs: %w", err)
+		return cs.cancelClone(ctx, req, nil)
 	}
-
 	// Lock the vmid allocation mutex to prevent race conditions during vmid allocation
 	cs.vmidMutex.Lock()
+	releaseAllocation := sync.OnceFunc(func() {
+		cs.vmidMutex.Unlock()
+		cs.cloneQueue.Release()
+	})
+	defer releaseAllocation()
+
+	// Enforce the template's concurrent deployment limit while no other clone can add pods,
+	// warm pods are pre-provisioned rather than deployed and are exempt
+	if cs.Config.WarmPoolOwner == "" || req.RequestedBy != cs.Config.WarmPoolOwner {
+		pods, err := cs.AdminGetPods()
+		if err != nil {
+			return fmt.Errorf("failed to get deployed pods: %w", err)
+		}
+		if err := cs.checkTemplateDeploymentLimit(req.Template, pods, len(req.Targets)); err != nil {
+			return err
+		}
+	}
+
+	// Templates scoped to an organization deploy into the organization's pod ID range
+	minPodID, maxPodID, err := cs.podIDRange(req.Template)
+	if err != nil {
+		return err
+	}
+
+	podIDs, podNumbers, err := cs.ProxmoxService.GetNextPodIDs(minPodID, maxPodID, len(req.Targets))
+	if err != nil {
+		return fmt.Errorf("failed to get next pod IDs: %w", err)
+	}
 
-	// Use StartingVMID from request if provided, otherwise get next available VMIDs
+	// Use StartingVMID from request if provided, otherwise get next available VMIDs from
+	// the template's reserved block
 	var vmIDs []int
 	numVMs := len(req.Targets) * numVMsPerTarget
 	if req.StartingVMID != 0 {
@@ -139,7 +314,7 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 			vmIDs = append(vmIDs, req.StartingVMID+i)
 		}
 	} else {
-		vmIDs, err = cs.ProxmoxService.GetNextVMIDs(numVMs)
+		vmIDs, err = cs.nextVMIDs(req.Template, numVMs)
 		if err != nil {
 			return fmt.Errorf("failed to get next VM IDs: %w", err)
 		}
@@ -155,17 +330,40 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 			req.Targets[i].Name, req.Targets[i].PodID, req.Targets[i].PodNumber, req.Targets[i].VMIDs)
 	}
 
+	// Record the WAN subnet of each pod, refusing subnets that conflict
+	if err := cs.allocatePodSubnets(req.Targets); err != nil {
+		cs.releaseUncreatedPools(req.Targets, createdPools)
+		return fmt.Errorf("failed to allocate pod subnets: %w", err)
+	}
+	if err := cs.allocatePodVNets(req.Targets); err != nil {
+		cs.releaseUncreatedPools(req.Targets, createdPools)
+		return fmt.Errorf("failed to allocate pod VNets: %w", err)
+	}
+
 	// 6. Create new pool for each target
 	for _, target := range req.Targets {
+		if ctx.Err() != nil {
+			releaseAllocation()
+			cs.releaseUncreatedPools(req.Targets, createdPools)
+			return cs.cancelClone(ctx, req, createdPools)
+		}
+
 		err = cs.ProxmoxService.CreateNewPool(target.PoolName)
 		if err != nil {
+			cs.releaseUncreatedPools(req.Targets, createdPools)
 			cs.cleanupFailedClones(createdPools)
 			return fmt.Errorf("failed to create new pool for %s: %w", target.Name, err)
 		}
 		createdPools = append(createdPools, target.PoolName)
 	}
 
-	// 7. Clone targets to proxmox
+	// Create the VNets of the template's extra network segments for each pod
+	if err := cs.createPodSegments(ctx, req.Targets, req.Template); err != nil {
+		cs.cleanupFailedClones(createdPools)
+		return fmt.Errorf("failed to create pod network segments: %w", err)
+	}
+
+	// 7. Clone targets to proxmox, up to the configured number of targets at a time
 	req.SSE.Send(
 		ProgressMessage{
 			Message:  "Cloning VMs",
@@ -173,102 +371,72 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 		},
 	)
 
-	for _, target := range req.Targets {
-		// Find best node per target
-		bestNode, err := cs.ProxmoxService.FindBestNode()
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("failed to find best node for %s: %v", target.Name, err))
-			continue
-		}
+	concurrency := max(cs.Config.CloneConcurrency, 1)
+	log.Printf("Cloning %d targets with concurrency %d", len(req.Targets), concurrency)
 
-		// Clone router
-		routerCloneReq := proxmox.VMCloneRequest{
-			SourceVM:   *router,
-			PoolName:   target.PoolName,
-			PodID:      target.PodID,
-			NewVMID:    target.VMIDs[0],
-			TargetNode: bestNode,
-		}
-		err = cs.ProxmoxService.CloneVM(routerCloneReq)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("failed to clone router VM for %s: %v", target.Name, err))
-		} else {
-			// Determine router type
-			routerType, err := cs.ProxmoxService.GetRouterType(*router)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("failed to get router type for %s: %v", target.Name, err))
-			}
+	var resultMutex sync.Mutex
+	var cloneTasks []cloneTask
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
 
-			// Store router info for later operations
-			clonedRouters = append(clonedRouters, RouterInfo{
-				TargetName: target.Name,
-				RouterType: routerType,
-				PodNumber:  target.PodNumber,
-				Node:       bestNode,
-				VMID:       target.VMIDs[0],
-			})
+	for _, target := range req.Targets {
+		semaphore <- struct{}{}
+		if ctx.Err() != nil {
+			<-semaphore
+			break
 		}
+		wg.Add(1)
 
-		// Clone each VM to new pool
-		for i, vm := range templateVMs {
-			vmCloneReq := proxmox.VMCloneRequest{
-				SourceVM:   vm,
-				PoolName:   target.PoolName,
-				PodID:      target.PodID,
-				NewVMID:    target.VMIDs[i+1],
-				TargetNode: bestNode,
-			}
-			err := cs.ProxmoxService.CloneVM(vmCloneReq)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("failed to clone VM %s for %s: %v", vm.Name, target.Name, err))
-			}
-		}
-	}
+		go func(target CloneTarget) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
 
-	// 8. Wait for all VM clone operations to complete before configuring VNets
-	log.Printf("Waiting for clone operations to complete for %d targets", len(req.Targets))
-	for _, target := range req.Targets {
-		// Wait for all VMs in the pool to be properly cloned
-		log.Printf("Waiting for VMs in pool %s to be available", target.PoolName)
-		time.Sleep(2 * time.Second)
+			routerInfo, tasks, targetErrors := cs.cloneTarget(ctx, req, target, router, templateVMs, placement, fullClone, storage)
 
-		// First wait for all VMs to appear in the pool
-		for retries := range 30 {
-			poolVMs, err := cs.ProxmoxService.GetPoolVMs(target.PoolName)
-			if err != nil {
-				time.Sleep(2 * time.Second)
-				continue
+			resultMutex.Lock()
+			defer resultMutex.Unlock()
+			if routerInfo != nil {
+				clonedRouters = append(clonedRouters, *routerInfo)
 			}
-
-			if len(poolVMs) >= numVMsPerTarget {
-				log.Printf("Pool %s has %d VMs (expected %d) - all VMs present", target.PoolName, len(poolVMs), numVMsPerTarget)
-				break
+			cloneTasks = append(cloneTasks, tasks...)
+			errors = append(errors, targetErrors...)
+			if len(targetErrors) > 0 {
+				failedTargets[target.PoolName] = true
 			}
-
-			log.Printf("Pool %s has %d VMs, waiting for %d (retry %d/30)", target.PoolName, len(poolVMs), numVMsPerTarget, retries+1)
-			time.Sleep(2 * time.Second)
+		}(target)
+	}
+	wg.Wait()
+
+	// 8. Follow every clone task to completion before configuring VNets, the task status
+	// is authoritative where the VM's own state can look complete after a failed clone
+	log.Printf("Waiting for %d clone operations to complete for %d targets", len(cloneTasks), len(req.Targets))
+	failedRouters := make(map[int]bool)
+	for _, task := range cloneTasks {
+		if ctx.Err() != nil {
+			break
 		}
 
-		// Wait for all VM locks to be released
-		log.Printf("Waiting for all VM clone operations to complete for pool %s (checking locks)", target.PoolName)
-		poolVMs, err := cs.ProxmoxService.GetPoolVMs(target.PoolName)
+		vmID := task.target.VMIDs[task.index]
+		err := cs.waitForCloneTask(ctx, task.upid, vmID)
+		cs.recordCloneResult(req.Template, task.target, task.index, task.source, task.isRouter, err)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("failed to get pool VMs after waiting for %s: %v", target.Name, err))
-			continue
-		}
-
-		for _, vm := range poolVMs {
-			log.Printf("Waiting for VM %d (%s) lock to be released", vm.VmId, vm.Name)
-			if err := cs.ProxmoxService.WaitForLock(vm.NodeName, vm.VmId); err != nil {
-				log.Printf("Warning: timeout waiting for VM %d lock, continuing anyway: %v", vm.VmId, err)
+			errors = append(errors, fmt.Sprintf("failed to clone VM %s for %s: %v", task.source.Name, task.target.Name, err))
+			failedTargets[task.target.PoolName] = true
+			if task.isRouter {
+				failedRouters[vmID] = true
 			}
 		}
-
-		log.Printf("All clone operations complete for pool %s", target.PoolName)
 	}
+	clonedRouters = slices.DeleteFunc(clonedRouters, func(routerInfo RouterInfo) bool {
+		return failedRouters[routerInfo.VMID]
+	})
 
 	// Release the vmid allocation mutex now that all of the VMs are cloned on proxmox
-	cs.vmidMutex.Unlock()
+	releaseAllocation()
+
+	if ctx.Err() != nil {
+		return cs.cancelClone(ctx, req, createdPools)
+	}
 
 	// 9. Wait for all router disks to be fully available before configuring VNets.
 	// Proxmox clone is two-phase: the clone lock (Phase 1) releases before the storage
@@ -279,21 +447,52 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 	routerDiskReady := make(map[int]bool)
 	for _, routerInfo := range clonedRouters {
 		log.Printf("Waiting for router disk to be available for %s (VMID: %d)", routerInfo.TargetName, routerInfo.VMID)
-		if err := cs.ProxmoxService.WaitForDisk(routerInfo.Node, routerInfo.VMID, cs.Config.RouterWaitTimeout); err != nil {
+		if err := cs.ProxmoxService.WaitForDisk(ctx, routerInfo.Node, routerInfo.VMID, cs.Config.RouterWaitTimeout); err != nil {
 			errors = append(errors, fmt.Sprintf("router disk unavailable for %s: %v", routerInfo.TargetName, err))
+			failedTargets[routerInfo.PoolName] = true
 		} else {
 			routerDiskReady[routerInfo.VMID] = true
 		}
 	}
 
+	if ctx.Err() != nil {
+		return cs.cancelClone(ctx, req, createdPools)
+	}
+
 	// 10. Configure VNet of all VMs
 	log.Printf("Configuring VNets for %d targets", len(req.Targets))
 	for _, target := range req.Targets {
-		vnetName := fmt.Sprintf("kamino%d", target.PodNumber)
-		log.Printf("Setting VNet %s for pool %s (target: %s)", vnetName, target.PoolName, target.Name)
-		err = cs.ProxmoxService.SetPodVnet(target.PoolName, vnetName, target.VMIDs[0])
+		log.Printf("Setting VNets for pool %s (target: %s)", target.PoolName, target.Name)
+		err := cs.attachPodNetworks(target.PoolName, target.PodNumber, target.VMIDs[0])
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("failed to update pod vnet for %s: %v", target.Name, err))
+			failedTargets[target.PoolName] = true
+			continue
+		}
+
+		if err := cs.applyPodRateLimit(target.PoolName); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to apply rate limit for %s: %v", target.Name, err))
+			failedTargets[target.PoolName] = true
+		}
+
+		if err := cs.applyPodCloudInit(target.PoolName, target.PodNumber); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to write cloud-init config for %s: %v", target.Name, err))
+			failedTargets[target.PoolName] = true
+		}
+
+		if cs.Config.PodFirewall {
+			if err := cs.applyDefaultFirewall(target.PoolName); err != nil {
+				errors = append(errors, fmt.Sprintf("failed to apply default firewall for %s: %v", target.Name, err))
+				failedTargets[target.PoolName] = true
+			}
+		}
+
+		// A missing DNS record doesn't stop the pod from working, so it doesn't fail the clone
+		if err := cs.createPodDNSRecord(target.PoolName); err != nil {
+			log.Printf("Failed to create DNS record for pod %s: %v", target.PoolName, err)
+		}
+		if err := cs.createPodGuacamoleConnections(target.PoolName, target.Name, target.IsGroup); err != nil {
+			log.Printf("Failed to create Guacamole connections for pod %s: %v", target.PoolName, err)
 		}
 	}
 
@@ -315,17 +514,23 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 		err = cs.ProxmoxService.StartVM(routerInfo.Node, routerInfo.VMID)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("failed to start router VM for %s: %v", routerInfo.TargetName, err))
+			failedTargets[routerInfo.PoolName] = true
 			continue
 		}
 
 		// Wait for router to be running
 		log.Printf("Waiting for router VM to be running for %s (VMID: %d)", routerInfo.TargetName, routerInfo.VMID)
-		err = cs.ProxmoxService.WaitForRunning(routerInfo.Node, routerInfo.VMID)
+		err = cs.ProxmoxService.WaitForRunning(ctx, routerInfo.Node, routerInfo.VMID)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("failed to start router VM for %s: %v", routerInfo.TargetName, err))
+			failedTargets[routerInfo.PoolName] = true
 		}
 	}
 
+	if ctx.Err() != nil {
+		return cs.cancelClone(ctx, req, createdPools)
+	}
+
 	// 12. Configure all pod routers (separate step after all routers are running)
 	req.SSE.Send(
 		ProgressMessage{
@@ -336,17 +541,30 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 
 	log.Printf("Configuring %d pod routers", len(clonedRouters))
 	for _, routerInfo := range clonedRouters {
+		if ctx.Err() != nil {
+			return cs.cancelClone(ctx, req, createdPools)
+		}
+
 		// Double-check that router is still running before configuration
-		err = cs.ProxmoxService.WaitForRunning(routerInfo.Node, routerInfo.VMID)
+		// A router whose agent comes up late is retried in the background rather than failing the pod
+		err = cs.ProxmoxService.WaitForRunning(ctx, routerInfo.Node, routerInfo.VMID)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("router not running before configuration for %s: %v", routerInfo.TargetName, err))
+			cs.deferRouterConfiguration(routerInfo, fmt.Errorf("router not running before configuration: %w", err))
 			continue
 		}
 
 		log.Printf("Configuring pod router for %s (Pod: %d, VMID: %d)", routerInfo.TargetName, routerInfo.PodNumber, routerInfo.VMID)
-		err = cs.ProxmoxService.ConfigurePodRouter(routerInfo.PodNumber, routerInfo.Node, routerInfo.VMID, routerInfo.RouterType)
+		lan, err := cs.podRouterLAN(routerInfo.PoolName, routerInfo.Node, routerInfo.VMID)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("failed to configure pod router for %s: %v", routerInfo.TargetName, err))
+			cs.deferRouterConfiguration(routerInfo, err)
+			continue
+		}
+		err = cs.ProxmoxService.ConfigurePodRouter(ctx, routerInfo.PodNumber, routerInfo.Node, routerInfo.VMID, routerInfo.RouterType, lan)
+		if ctx.Err() != nil {
+			return cs.cancelClone(ctx, req, createdPools)
+		}
+		if err != nil {
+			cs.deferRouterConfiguration(routerInfo, err)
 		}
 	}
 
@@ -358,15 +576,66 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 		},
 	)
 
-	// 12. Set permissions on the pool to the user/group
+	// 12. Set permissions on the pool to the user/group, retrying in the background rather
+	// than failing the deployment if the ACL cannot be applied
 	for _, target := range req.Targets {
-		err = cs.ProxmoxService.SetPoolPermission(target.PoolName, target.Name, target.IsGroup)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("failed to update pool permissions for %s: %v", target.Name, err))
+		if err := cs.applyPoolPermission(target); err != nil {
+			cs.deferPoolPermission(target, err)
+			req.SSE.Send(
+				ProgressMessage{
+					Message:  fmt.Sprintf("Access to the pod of %s is pending, permissions will be retried", target.Name),
+					Progress: 95,
+				},
+			)
+		}
+	}
+
+	// 13. Inject per-pod flags into the VMs declared by the template
+	flags, err := cs.DatabaseService.GetTemplateFlags(req.Template)
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("failed to get flags for %s: %v", req.Template, err))
+		for _, target := range req.Targets {
+			failedTargets[target.PoolName] = true
+		}
+	} else {
+		// Skip flags declared on VMs left out of a selective clone
+		flags = slices.DeleteFunc(flags, func(flag TemplateFlag) bool {
+			return !slices.ContainsFunc(templatePool, func(vm proxmox.VirtualResource) bool { return vm.Name == flag.VMName })
+		})
+		if len(flags) > 0 {
+			req.SSE.Send(
+				ProgressMessage{
+					Message:  "Injecting flags",
+					Progress: 95,
+				},
+			)
+			for _, target := range req.Targets {
+				if flagErrors := cs.injectPodFlags(ctx, flags, target); len(flagErrors) > 0 {
+					errors = append(errors, flagErrors...)
+					failedTargets[target.PoolName] = true
+				}
+			}
 		}
 	}
 
-	// 13. Add deployments to the templates database
+	if ctx.Err() != nil {
+		return cs.cancelClone(ctx, req, createdPools)
+	}
+
+	// 14. Register the VMs with the HA manager so they are recovered when their node fails.
+	// Targets that failed are skipped so DELETE_FAILED_CLONES cleanup can delete the VMs.
+	if haGroup != "" {
+		for _, target := range req.Targets {
+			if failedTargets[target.PoolName] {
+				continue
+			}
+			if err := cs.registerPodHA(target.PoolName, haGroup); err != nil {
+				errors = append(errors, fmt.Sprintf("failed to register %s in HA group %s: %v", target.Name, haGroup, err))
+			}
+		}
+	}
+
+	// 15. Add deployments to the templates database
 	err = cs.DatabaseService.AddDeployment(req.Template, len(req.Targets))
 	if err != nil {
 		errors = append(errors, fmt.Sprintf("failed to increment template deployments for %s: %v", req.Template, err))
@@ -386,9 +655,98 @@ func (cs *CloningService) CloneTemplate(req CloneRequest) error {
 		return fmt.Errorf("bulk clone operation completed with errors: %v", errors)
 	}
 
+	for _, target := range req.Targets {
+		cs.Events.Publish(events.Event{Type: events.PodCreated, Pod: target.PoolName})
+	}
+
 	return nil
 }
 
+// cloneTask is a submitted clone whose Proxmox task is followed to completion
+type cloneTask struct {
+	target   CloneTarget
+	index    int
+	source   proxmox.VM
+	isRouter bool
+	upid     string
+}
+
+// cloneTarget submits the router and template VM clones for a single target. It returns
+// the cloned router, if any, the submitted clone tasks, and the errors encountered.
+func (cs *CloningService) cloneTarget(ctx context.Context, req CloneRequest, target CloneTarget, router *proxmox.VM, templateVMs []proxmox.VM, placement *vmPlacement, fullClone int, storage string) (*RouterInfo, []cloneTask, []string) {
+	var errors []string
+	var routerInfo *RouterInfo
+	var tasks []cloneTask
+
+	// Find best node per target
+	bestNode, err := cs.selectTargetNode(req)
+	if err != nil {
+		return nil, nil, []string{fmt.Sprintf("failed to find best node for %s: %v", target.Name, err)}
+	}
+
+	// Clone router
+	routerCloneReq := proxmox.VMCloneRequest{
+		SourceVM:   *router,
+		PoolName:   target.PoolName,
+		PodID:      target.PodID,
+		NewVMID:    target.VMIDs[0],
+		Full:       fullClone,
+		TargetNode: bestNode,
+		Storage:    storage,
+	}
+	upid, err := cs.submitClone(ctx, req, routerCloneReq)
+	if err != nil {
+		cs.recordCloneResult(req.Template, target, 0, *router, true, err)
+		errors = append(errors, fmt.Sprintf("failed to clone router VM for %s: %v", target.Name, err))
+	} else {
+		tasks = append(tasks, cloneTask{target: target, index: 0, source: *router, isRouter: true, upid: upid})
+
+		// Determine router type
+		routerType, err := cs.ProxmoxService.GetRouterType(*router)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to get router type for %s: %v", target.Name, err))
+		}
+
+		// Store router info for later operations
+		routerInfo = &RouterInfo{
+			TargetName: target.Name,
+			PoolName:   target.PoolName,
+			RouterType: routerType,
+			PodNumber:  target.PodNumber,
+			Node:       bestNode,
+			VMID:       target.VMIDs[0],
+		}
+	}
+
+	// Clone each VM to new pool, on the nodes chosen by the template's placement rules
+	vmNodes := cs.assignVMNodes(placement, templateVMs, bestNode)
+	for i, vm := range templateVMs {
+		if ctx.Err() != nil {
+			errors = append(errors, fmt.Sprintf("clone for %s cancelled: %v", target.Name, ctx.Err()))
+			break
+		}
+
+		vmCloneReq := proxmox.VMCloneRequest{
+			SourceVM:   vm,
+			PoolName:   target.PoolName,
+			PodID:      target.PodID,
+			NewVMID:    target.VMIDs[i+1],
+			Full:       fullClone,
+			TargetNode: vmNodes[i],
+			Storage:    storage,
+		}
+		upid, err := cs.submitClone(ctx, req, vmCloneReq)
+		if err != nil {
+			cs.recordCloneResult(req.Template, target, i+1, vm, false, err)
+			errors = append(errors, fmt.Sprintf("failed to clone VM %s for %s: %v", vm.Name, target.Name, err))
+			continue
+		}
+		tasks = append(tasks, cloneTask{target: target, index: i + 1, source: vm, isRouter: false, upid: upid})
+	}
+
+	return routerInfo, tasks, errors
+}
+
 func (cs *CloningService) DeletePod(pod string) error {
 
 	// 1. Check if pool is already empty
@@ -401,6 +759,9 @@ func (cs *CloningService) DeletePod(pod string) error {
 		if err := cs.ProxmoxService.DeletePool(pod); err != nil {
 			return fmt.Errorf("failed to delete empty pool %s: %w", pod, err)
 		}
+		cs.discardPodArchive(pod)
+		cs.removePodRecords(pod)
+		cs.Events.Publish(events.Event{Type: events.PodDeleted, Pod: pod})
 		return nil
 	}
 
@@ -410,12 +771,18 @@ func (cs *CloningService) DeletePod(pod string) error {
 		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
 	}
 
-	// 3. Stop all VMs and wait for them to be stopped
+	// 3. Take the VMs out of the HA manager, which would otherwise restart them and blocks
+	// their deletion
+	if _, err := cs.removeHAResources(poolVMs); err != nil {
+		log.Printf("Failed to remove HA resources of pod %s: %v", pod, err)
+	}
+
+	// 4. Stop all VMs and wait for them to be stopped
 	var runningVMs []proxmox.VM
 	stoppedCount := 0
 
 	for _, vm := range poolVMs {
-		if vm.Type == "qemu" {
+		if vm.IsGuest() {
 			// Only stop if VM is running
 			if vm.RunningStatus == "running" {
 				err := cs.ProxmoxService.StopVM(vm.NodeName, vm.VmId)
@@ -436,17 +803,17 @@ func (cs *CloningService) DeletePod(pod string) error {
 	// Wait for all previously running VMs to be stopped
 	if len(runningVMs) > 0 {
 		for _, vm := range runningVMs {
-			if err := cs.ProxmoxService.WaitForStopped(vm.Node, vm.VMID); err != nil {
+			if err := cs.ProxmoxService.WaitForStopped(context.Background(), vm.Node, vm.VMID); err != nil {
 				// Continue with deletion even if we can't confirm the VM is stopped
 			}
 		}
 	}
 
-	// 4. Delete all VMs
+	// 5. Delete all VMs
 	deletedCount := 0
 
 	for _, vm := range poolVMs {
-		if vm.Type == "qemu" {
+		if vm.IsGuest() {
 			err := cs.ProxmoxService.DeleteVM(vm.NodeName, vm.VmId)
 			if err != nil {
 				return fmt.Errorf("failed to delete VM %s: %w", vm.Name, err)
@@ -455,22 +822,230 @@ func (cs *CloningService) DeletePod(pod string) error {
 		}
 	}
 
-	// 5. Wait for all VMs to be deleted and pool to become empty
-	err = cs.ProxmoxService.WaitForPoolEmpty(pod, 5*time.Minute)
+	// 6. Wait for all VMs to be deleted and pool to become empty
+	err = cs.ProxmoxService.WaitForPoolEmpty(context.Background(), pod, 5*time.Minute)
 	if err != nil {
 		// Continue with pool deletion even if we can't confirm all VMs are gone
 	}
 
-	// 6. Delete the pool
+	// 7. Delete the pool
 	err = cs.ProxmoxService.DeletePool(pod)
 	if err != nil {
 		return fmt.Errorf("failed to delete pool %s: %w", pod, err)
 	}
 
+	cs.removePodRecords(pod)
+
+	cs.Events.Publish(events.Event{Type: events.PodDeleted, Pod: pod})
 	return nil
 }
 
+// removePodRecords deletes the database records kept for a deleted pod
+func (cs *CloningService) removePodRecords(pod string) {
+	if err := cs.DatabaseService.DeletePodCloneRecords(pod); err != nil {
+		log.Printf("Failed to delete clone records for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodShares(pod); err != nil {
+		log.Printf("Failed to delete shares for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodFlags(pod); err != nil {
+		log.Printf("Failed to delete flags for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodRouterStatus(pod); err != nil {
+		log.Printf("Failed to delete router status for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodAccessStatus(pod); err != nil {
+		log.Printf("Failed to delete access status for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodRetention(pod); err != nil {
+		log.Printf("Failed to delete retention record for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodActivity(pod); err != nil {
+		log.Printf("Failed to delete activity for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeleteWarmPod(pod); err != nil {
+		log.Printf("Failed to delete warm pod record for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeleteSubnetAllocation(pod); err != nil {
+		log.Printf("Failed to release subnet of pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeleteVNetAllocation(pod); err != nil {
+		log.Printf("Failed to release VNet of pod %s: %v", pod, err)
+	}
+	cs.removePodSegments(pod)
+	if err := cs.DatabaseService.DeletePodRateLimit(pod); err != nil {
+		log.Printf("Failed to delete rate limit of pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodFirewallRules(pod); err != nil {
+		log.Printf("Failed to delete firewall rules of pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.DeletePodPortForwards(pod); err != nil {
+		log.Printf("Failed to delete port forwards of pod %s: %v", pod, err)
+	}
+	cs.revokePodVPNConfigs(pod)
+	if err := cs.removePodDNSRecord(pod); err != nil {
+		log.Printf("Failed to remove DNS record of pod %s: %v", pod, err)
+	}
+	if err := cs.removePodGuacamoleConnections(pod); err != nil {
+		log.Printf("Failed to remove Guacamole connections of pod %s: %v", pod, err)
+	}
+}
+
+// cancelClone removes every pod created by a cancelled deployment, including VMs that
+// were already cloned, and returns the cancellation error. When the cancellation keeps
+// partial work only the pools left empty are removed.
+func (cs *CloningService) cancelClone(ctx context.Context, req CloneRequest, createdPools []string) error {
+	if cancellation := jobs.CancellationFrom(ctx); cancellation != nil && cancellation.KeepPartial {
+		log.Printf("Clone of template %s cancelled by %s, keeping %d partial pods", req.Template, cancellation.By, len(createdPools))
+		cs.removeEmptyPools(createdPools)
+		return fmt.Errorf("clone of template %s cancelled: %w", req.Template, ctx.Err())
+	}
+
+	log.Printf("Clone of template %s cancelled, removing %d pods", req.Template, len(createdPools))
+
+	for _, poolName := range createdPools {
+		// Clones still in progress hold a lock that prevents the VM being deleted
+		poolVMs, err := cs.ProxmoxService.GetPoolVMs(poolName)
+		if err == nil {
+			for _, vm := range poolVMs {
+				if err := cs.ProxmoxService.WaitForLock(context.Background(), vm.NodeName, vm.VmId); err != nil {
+					log.Printf("Warning: timeout waiting for VM %d lock during cancellation: %v", vm.VmId, err)
+				}
+			}
+		}
+
+		if err := cs.DeletePod(poolName); err != nil {
+			log.Printf("Failed to remove pod %s after cancellation: %v", poolName, err)
+		}
+	}
+
+	return fmt.Errorf("clone of template %s cancelled: %w", req.Template, ctx.Err())
+}
+
+// selectTemplateVMs narrows the template pool to the named VMs, keeping the router so
+// the pod still has networking. No names selects every VM.
+func selectTemplateVMs(templatePool []proxmox.VirtualResource, vmNames []string) ([]proxmox.VirtualResource, error) {
+	if len(vmNames) == 0 {
+		return templatePool, nil
+	}
+
+	var selected []proxmox.VirtualResource
+	found := make(map[string]bool)
+	for _, vm := range templatePool {
+		if routerPattern.MatchString(vm.Name) {
+			selected = append(selected, vm)
+			found[vm.Name] = true
+			continue
+		}
+		if slices.Contains(vmNames, vm.Name) {
+			selected = append(selected, vm)
+			found[vm.Name] = true
+		}
+	}
+
+	var unknown []string
+	for _, name := range vmNames {
+		if !found[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("VMs not found in template: %s", strings.Join(unknown, ", "))
+	}
+
+	return selected, nil
+}
+
+// cleanupFailedClones removes what a failed deployment left behind. Pods with a failed
+// VM clone are kept so RepairPod can re-clone the missing VMs, unless DELETE_FAILED_CLONES
+// deletes their cloned VMs instead, and then every pool left empty is removed.
 func (cs *CloningService) cleanupFailedClones(createdPools []string) {
+	if cs.Config.DeleteFailedClones {
+		for _, poolName := range createdPools {
+			if err := cs.removeFailedCloneVMs(poolName); err != nil {
+				log.Printf("Failed to remove VMs of failed clone in pod %s: %v", poolName, err)
+			}
+		}
+	}
+
+	cs.removeEmptyPools(createdPools)
+}
+
+// releaseUncreatedPools releases the subnet and VNet allocations of targets whose pool was
+// never created, which the cleanup of created pools does not reach
+func (cs *CloningService) releaseUncreatedPools(targets []CloneTarget, createdPools []string) {
+	for _, target := range targets {
+		if slices.Contains(createdPools, target.PoolName) {
+			continue
+		}
+
+		if err := cs.DatabaseService.DeleteSubnetAllocation(target.PoolName); err != nil {
+			log.Printf("Failed to release subnet of pod %s: %v", target.PoolName, err)
+		}
+		if err := cs.DatabaseService.DeleteVNetAllocation(target.PoolName); err != nil {
+			log.Printf("Failed to release VNet of pod %s: %v", target.PoolName, err)
+		}
+	}
+}
+
+// removeFailedCloneVMs deletes the VMs cloned into a pod during the run when any of the
+// pod's VM clones failed. Only VMs recorded by VMID in the pod's clone records are
+// deleted, anything else placed in the pool is left alone.
+func (cs *CloningService) removeFailedCloneVMs(pod string) error {
+	records, err := cs.DatabaseService.GetPodCloneRecords(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get clone records: %w", err)
+	}
+	if !slices.ContainsFunc(records, func(record PodCloneRecord) bool { return record.Status == CloneRecordFailed }) {
+		return nil
+	}
+
+	cloned := make(map[int]bool)
+	for _, record := range records {
+		cloned[record.VMID] = true
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs: %w", err)
+	}
+
+	deleted := 0
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() || !cloned[vm.VmId] {
+			continue
+		}
+
+		// A clone still finishing holds a lock that prevents the VM being deleted
+		if err := cs.ProxmoxService.WaitForLock(context.Background(), vm.NodeName, vm.VmId); err != nil {
+			log.Printf("Warning: timeout waiting for VM %d lock during cleanup: %v", vm.VmId, err)
+		}
+
+		if vm.RunningStatus == "running" {
+			if err := cs.ProxmoxService.StopVM(vm.NodeName, vm.VmId); err != nil {
+				return fmt.Errorf("failed to stop VM %s: %w", vm.Name, err)
+			}
+			if err := cs.ProxmoxService.WaitForStopped(context.Background(), vm.NodeName, vm.VmId); err != nil {
+				log.Printf("Warning: unable to confirm VM %d stopped during cleanup: %v", vm.VmId, err)
+			}
+		}
+
+		if err := cs.ProxmoxService.DeleteVM(vm.NodeName, vm.VmId); err != nil {
+			return fmt.Errorf("failed to delete VM %s: %w", vm.Name, err)
+		}
+		deleted++
+	}
+
+	log.Printf("Removed %d VMs of failed clone in pod %s", deleted, pod)
+
+	if deleted == len(poolVMs) {
+		return cs.ProxmoxService.WaitForPoolEmpty(context.Background(), pod, 5*time.Minute)
+	}
+	return nil
+}
+
+// removeEmptyPools deletes the pools that have no VMs, along with their records
+func (cs *CloningService) removeEmptyPools(createdPools []string) {
 	for _, poolName := range createdPools {
 		// Check if pool has any VMs
 		poolVMs, err := cs.ProxmoxService.GetPoolVMs(poolName)
@@ -481,6 +1056,7 @@ func (cs *CloningService) cleanupFailedClones(createdPools []string) {
 		// If pool is empty, delete it
 		if len(poolVMs) == 0 {
 			_ = cs.ProxmoxService.DeletePool(poolName)
+			cs.removePodRecords(poolName)
 		}
 	}
 }
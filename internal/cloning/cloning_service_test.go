@@ -0,0 +1,97 @@
+package cloning
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// cleanupProxmox records the deletions made by failed clone cleanup. Methods the cleanup
+// does not use are left to the embedded interface and panic if called.
+type cleanupProxmox struct {
+	proxmox.Service
+	poolVMs      []proxmox.VirtualResource
+	deletedVMs   []int
+	deletedPools []string
+}
+
+func (p *cleanupProxmox) GetPoolVMs(poolName string) ([]proxmox.VirtualResource, error) {
+	return slices.DeleteFunc(slices.Clone(p.poolVMs), func(vm proxmox.VirtualResource) bool {
+		return slices.Contains(p.deletedVMs, vm.VmId)
+	}), nil
+}
+
+func (p *cleanupProxmox) WaitForLock(ctx context.Context, node string, vmID int) error {
+	return nil
+}
+
+func (p *cleanupProxmox) DeleteVM(node string, vmID int) error {
+	p.deletedVMs = append(p.deletedVMs, vmID)
+	return nil
+}
+
+func (p *cleanupProxmox) WaitForPoolEmpty(ctx context.Context, poolName string, timeout time.Duration) error {
+	return nil
+}
+
+func (p *cleanupProxmox) DeletePool(poolName string) error {
+	p.deletedPools = append(p.deletedPools, poolName)
+	return nil
+}
+
+type cleanupDatabase struct {
+	DatabaseService
+	records []PodCloneRecord
+}
+
+func (d *cleanupDatabase) GetPodCloneRecords(pod string) ([]PodCloneRecord, error) {
+	return d.records, nil
+}
+
+func TestCleanupFailedClones(t *testing.T) {
+	const pod = "1001_lab_alice"
+
+	tests := []struct {
+		name        string
+		deleteVMs   bool
+		wantDeleted []int
+	}{
+		{name: "keeps partial pod for repair", deleteVMs: false, wantDeleted: nil},
+		{name: "deletes cloned VMs when opted in", deleteVMs: true, wantDeleted: []int{101, 102}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			px := &cleanupProxmox{
+				poolVMs: []proxmox.VirtualResource{
+					{Type: proxmox.GuestTypeQEMU, VmId: 101, NodeName: "pve1", ResourcePool: pod},
+					{Type: proxmox.GuestTypeQEMU, VmId: 102, NodeName: "pve1", ResourcePool: pod},
+					{Type: proxmox.GuestTypeQEMU, VmId: 300, NodeName: "pve1", ResourcePool: pod}, // Not cloned by the deployment
+				},
+			}
+			db := &cleanupDatabase{
+				records: []PodCloneRecord{
+					{Pod: pod, VMIndex: 0, VMID: 101, Status: CloneRecordCloned},
+					{Pod: pod, VMIndex: 1, VMID: 102, Status: CloneRecordFailed},
+				},
+			}
+			cs := &CloningService{
+				ProxmoxService:  px,
+				DatabaseService: db,
+				Config:          &Config{DeleteFailedClones: tt.deleteVMs},
+			}
+
+			cs.cleanupFailedClones([]string{pod})
+
+			if !slices.Equal(px.deletedVMs, tt.wantDeleted) {
+				t.Errorf("deleted VMs = %v, want %v", px.deletedVMs, tt.wantDeleted)
+			}
+			if len(px.deletedPools) > 0 {
+				t.Errorf("deleted pools = %v, want none", px.deletedPools)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package cloning
+
+import (
+	"fmt"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Private Functions
+// =================================================
+
+// applyPodCloudInit writes the configured credentials to every VM of a pod with a cloud-init
+// drive, and the WAN, LAN, and segment addresses to the router when it has one. It runs
+// after the VMs are attached to the pod networks and before they start, so routers without
+// a guest agent come up configured.
+func (cs *CloningService) applyPodCloudInit(pod string, podNumber int) error {
+	poolVMs, err := cs.podGuests(pod)
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range poolVMs {
+		if vm.Type != proxmox.GuestTypeQEMU {
+			continue
+		}
+
+		hasCloudInit, err := cs.ProxmoxService.HasCloudInit(vm.NodeName, vm.VmId)
+		if err != nil {
+			return err
+		}
+		if !hasCloudInit {
+			continue
+		}
+
+		config := proxmox.CloudInitConfig{
+			User:     cs.Config.CloudInitUser,
+			Password: cs.Config.CloudInitPassword,
+			SSHKeys:  cs.Config.CloudInitSSHKeys,
+		}
+		if routerPattern.MatchString(vm.Name) {
+			lan, err := cs.podRouterLAN(pod, vm.NodeName, vm.VmId)
+			if err != nil {
+				return err
+			}
+			config.IPConfigs, err = cs.ProxmoxService.PodRouterIPConfigs(podNumber, lan)
+			if err != nil {
+				return fmt.Errorf("failed to build cloud-init addresses of router %s: %w", vm.Name, err)
+			}
+		}
+
+		if err := cs.ProxmoxService.SetCloudInit(vm.NodeName, vm.VmId, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
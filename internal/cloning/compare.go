@@ -0,0 +1,225 @@
+package cloning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// cloneSpecificConfigKeys differ between every clone and are left out of config comparisons
+var cloneSpecificConfigKeys = []string{"digest", "vmgenid", "smbios1", "meta", "lock"}
+
+var (
+	// macAddressPattern matches the MAC in a netX value such as virtio=BC:24:11:00:00:01
+	macAddressPattern = regexp.MustCompile(`=([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}`)
+	// bridgePattern matches the pod VNet a NIC is attached to
+	bridgePattern = regexp.MustCompile(`bridge=[^,]+`)
+	// volumeVMIDPattern matches the VMID embedded in disk volume names
+	volumeVMIDPattern = regexp.MustCompile(`\b(vm|base)-\d+-`)
+)
+
+// ComparePods compares two pods deployed from the same template VM by VM: presence,
+// power state, configuration, snapshots, and the hashes of the requested files read
+// through the guest agent
+func (cs *CloningService) ComparePods(podA string, podB string, files []PodFileCheck) (*PodComparison, error) {
+	if PodTemplateName(podA) != PodTemplateName(podB) {
+		return nil, fmt.Errorf("pods %s and %s were not deployed from the same template", podA, podB)
+	}
+
+	vmsA, err := cs.podVMsByName(podA)
+	if err != nil {
+		return nil, err
+	}
+	vmsB, err := cs.podVMsByName(podB)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range vmsA {
+		names = append(names, name)
+	}
+	for name := range vmsB {
+		if _, ok := vmsA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	comparison := &PodComparison{PodA: podA, PodB: podB, Identical: true, VMs: []VMComparison{}}
+	for _, name := range names {
+		vmA, okA := vmsA[name]
+		vmB, okB := vmsB[name]
+
+		vmComparison := VMComparison{Name: name, Differences: []PodDifference{}}
+		if !okA || !okB {
+			vmComparison.VMIDA, vmComparison.VMIDB = vmA.VmId, vmB.VmId
+			vmComparison.Differences = append(vmComparison.Differences, PodDifference{
+				Category: "presence",
+				A:        fmt.Sprintf("%t", okA),
+				B:        fmt.Sprintf("%t", okB),
+			})
+		} else {
+			vmComparison = cs.compareVMs(name, vmA, vmB, files)
+		}
+
+		if len(vmComparison.Differences) > 0 {
+			comparison.Identical = false
+		}
+		comparison.VMs = append(comparison.VMs, vmComparison)
+	}
+
+	return comparison, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (cs *CloningService) podVMsByName(pod string) (map[string]proxmox.VirtualResource, error) {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	vms := make(map[string]proxmox.VirtualResource)
+	for _, vm := range poolVMs {
+		if vm.IsGuest() {
+			vms[vm.Name] = vm
+		}
+	}
+	return vms, nil
+}
+
+func (cs *CloningService) compareVMs(name string, vmA proxmox.VirtualResource, vmB proxmox.VirtualResource, files []PodFileCheck) VMComparison {
+	comparison := VMComparison{Name: name, VMIDA: vmA.VmId, VMIDB: vmB.VmId, Differences: []PodDifference{}}
+	differ := func(category string, field string, a string, b string) {
+		if a != b {
+			comparison.Differences = append(comparison.Differences, PodDifference{Category: category, Field: field, A: a, B: b})
+		}
+	}
+
+	// Power state
+	differ("power", "status", vmA.RunningStatus, vmB.RunningStatus)
+
+	// Configuration, normalized so values that differ between every clone are ignored
+	configA, errA := cs.normalizedVMConfig(vmA)
+	configB, errB := cs.normalizedVMConfig(vmB)
+	if errA != nil || errB != nil {
+		differ("config", "", errorOr(errA, "available"), errorOr(errB, "available"))
+	} else {
+		comparison.ConfigDigest = [2]string{configDigest(configA), configDigest(configB)}
+		for _, key := range unionKeys(configA, configB) {
+			differ("config", key, configA[key], configB[key])
+		}
+	}
+
+	// Snapshots
+	snapshotsA, errA := cs.snapshotNames(vmA)
+	snapshotsB, errB := cs.snapshotNames(vmB)
+	differ("snapshots", "", errorOr(errA, snapshotsA), errorOr(errB, snapshotsB))
+
+	// Key files read through the guest agent
+	for _, file := range files {
+		if file.VMName != name {
+			continue
+		}
+		differ("file", file.Path, cs.agentFileHash(vmA, file.Path), cs.agentFileHash(vmB, file.Path))
+	}
+
+	return comparison
+}
+
+func (cs *CloningService) normalizedVMConfig(vm proxmox.VirtualResource) (map[string]string, error) {
+	config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make(map[string]string)
+	for key, value := range config {
+		if slices.Contains(cloneSpecificConfigKeys, key) {
+			continue
+		}
+
+		text := fmt.Sprintf("%v", value)
+		if strings.HasPrefix(key, "net") {
+			text = macAddressPattern.ReplaceAllString(text, "")
+			text = bridgePattern.ReplaceAllString(text, "bridge=")
+		}
+		text = volumeVMIDPattern.ReplaceAllString(text, "${1}-N-")
+
+		normalized[key] = text
+	}
+	return normalized, nil
+}
+
+func (cs *CloningService) snapshotNames(vm proxmox.VirtualResource) (string, error) {
+	snapshots, err := cs.ProxmoxService.GetVMSnapshots(vm.NodeName, vm.VmId)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, snapshot := range snapshots {
+		if snapshot.Name != "current" {
+			names = append(names, snapshot.Name)
+		}
+	}
+	slices.Sort(names)
+	return strings.Join(names, ","), nil
+}
+
+// agentFileHash returns the SHA-256 of a file in the VM, or a description of why it
+// could not be read so unreadable files show up as differences
+func (cs *CloningService) agentFileHash(vm proxmox.VirtualResource, path string) string {
+	if vm.RunningStatus != "running" {
+		return "unavailable: VM is not running"
+	}
+
+	content, truncated, err := cs.ProxmoxService.AgentFileRead(vm.NodeName, vm.VmId, path)
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v", err)
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if truncated {
+		hash += " (truncated)"
+	}
+	return hash
+}
+
+func configDigest(config map[string]string) string {
+	hash := sha256.New()
+	for _, key := range unionKeys(config, nil) {
+		fmt.Fprintf(hash, "%s=%s\n", key, config[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func unionKeys(a map[string]string, b map[string]string) []string {
+	var keys []string
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func errorOr(err error, value string) string {
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	return value
+}
@@ -0,0 +1,72 @@
+package cloning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/gorilla/websocket"
+)
+
+// =================================================
+// Pod Console Operations
+// =================================================
+
+// CreatePodConsoleTicket opens a VNC or terminal console proxy for a VM of the pod
+func (cs *CloningService) CreatePodConsoleTicket(pod string, vmID int, consoleType string) (*proxmox.ConsoleTicket, error) {
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if vm.RunningStatus != "running" {
+		return nil, fmt.Errorf("VM %d is %s", vmID, vm.RunningStatus)
+	}
+
+	return cs.ProxmoxService.CreateConsoleTicket(vm, consoleType)
+}
+
+// DialPodConsole connects to the console proxy of a VM of the pod with a ticket from
+// CreatePodConsoleTicket
+func (cs *CloningService) DialPodConsole(ctx context.Context, pod string, vmID int, port string, ticket string) (*websocket.Conn, error) {
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.ProxmoxService.DialConsole(ctx, vm, port, ticket)
+}
+
+// CreatePodSPICEConfig returns the remote-viewer settings for a SPICE connection to a VM
+// of the pod
+func (cs *CloningService) CreatePodSPICEConfig(pod string, vmID int) (map[string]any, error) {
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if vm.Type != proxmox.GuestTypeQEMU {
+		return nil, fmt.Errorf("VM %d is a container, which has no SPICE console", vmID)
+	}
+
+	return cs.ProxmoxService.CreateSPICEConfig(vm)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// podVM returns a VM or container of the pod, failing for VMIDs outside the pod so
+// consoles can't be opened on other guests
+func (cs *CloningService) podVM(pod string, vmID int) (proxmox.VirtualResource, error) {
+	poolVMs, err := cs.podGuests(pod)
+	if err != nil {
+		return proxmox.VirtualResource{}, err
+	}
+
+	for _, vm := range poolVMs {
+		if vm.VmId == vmID {
+			return vm, nil
+		}
+	}
+
+	return proxmox.VirtualResource{}, fmt.Errorf("VM %d is not part of pod %s", vmID, pod)
+}
@@ -0,0 +1,132 @@
+package cloning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dnsLabelInvalid matches the characters an owner name can't use in a DNS label
+var dnsLabelInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+var dnsHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// powerDNSRRSet is a record set change sent to the PowerDNS zone API
+type powerDNSRRSet struct {
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	TTL        int              `json:"ttl,omitempty"`
+	ChangeType string           `json:"changetype"`
+	Records    []powerDNSRecord `json:"records"`
+}
+
+type powerDNSRecord struct {
+	Content  string `json:"content"`
+	Disabled bool   `json:"disabled"`
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// createPodDNSRecord points podID.owner.<zone> at the WAN address of the pod's router.
+// Nothing is done when no DNS API is configured.
+func (cs *CloningService) createPodDNSRecord(pod string) error {
+	if cs.Config.DNSAPIURL == "" {
+		return nil
+	}
+
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return err
+	}
+
+	return cs.patchDNSZone(powerDNSRRSet{
+		Name:       podDNSName(pod, cs.Config.DNSZone),
+		Type:       "A",
+		TTL:        cs.Config.DNSRecordTTL,
+		ChangeType: "REPLACE",
+		Records:    []powerDNSRecord{{Content: cs.ProxmoxService.PodRouterWANIP(podNumber)}},
+	})
+}
+
+// removePodDNSRecord deletes the record created by createPodDNSRecord
+func (cs *CloningService) removePodDNSRecord(pod string) error {
+	if cs.Config.DNSAPIURL == "" {
+		return nil
+	}
+
+	return cs.patchDNSZone(powerDNSRRSet{
+		Name:       podDNSName(pod, cs.Config.DNSZone),
+		Type:       "A",
+		ChangeType: "DELETE",
+		Records:    []powerDNSRecord{},
+	})
+}
+
+func (cs *CloningService) patchDNSZone(rrset powerDNSRRSet) error {
+	body, err := json.Marshal(map[string]any{"rrsets": []powerDNSRRSet{rrset}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal DNS record: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s",
+		strings.TrimSuffix(cs.Config.DNSAPIURL, "/"), url.PathEscape(cs.Config.DNSServerID), url.PathEscape(dnsFQDN(cs.Config.DNSZone)))
+	req, err := http.NewRequest(http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create DNS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", cs.Config.DNSAPIKey)
+
+	resp, err := dnsHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update DNS record %s: %w", rrset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to update DNS record %s: status %d: %s", rrset.Name, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	log.Printf("DNS record %s %s", rrset.Name, strings.ToLower(rrset.ChangeType))
+	return nil
+}
+
+// podDNSName names a pod <podID>_<template>_<owner> as podID.owner.<zone>, with the owner
+// reduced to a valid DNS label
+func podDNSName(pod string, zone string) string {
+	podID, owner := pod, ""
+	if len(pod) >= 4 {
+		podID = pod[:4]
+	}
+	if i := strings.LastIndex(pod, "_"); i >= 0 {
+		owner = pod[i+1:]
+	}
+
+	label := strings.Trim(dnsLabelInvalid.ReplaceAllString(strings.ToLower(owner), "-"), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	if label == "" {
+		label = "pod"
+	}
+
+	return dnsFQDN(fmt.Sprintf("%s.%s.%s", podID, label, strings.TrimSuffix(zone, ".")))
+}
+
+// dnsFQDN adds the trailing dot PowerDNS expects on names
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
@@ -0,0 +1,56 @@
+package cloning
+
+import (
+	"log"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/tools/events"
+)
+
+// watchVMStates polls cluster resources while anyone is subscribed to the event bus
+// and publishes a state change event whenever a pod VM's status changes
+func (cs *CloningService) watchVMStates() {
+	previous := make(map[int]string)
+
+	ticker := time.NewTicker(cs.Config.EventPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if cs.Events.SubscriberCount() == 0 {
+			// Forget stale state so reconnecting clients don't get a burst of old changes
+			clear(previous)
+			continue
+		}
+
+		resources, err := cs.ProxmoxService.GetClusterResources("type=vm")
+		if err != nil {
+			log.Printf("Event watcher failed to get cluster resources: %v", err)
+			continue
+		}
+
+		current := make(map[int]string)
+		for _, r := range resources {
+			if !cs.isPodPool(r.ResourcePool) {
+				continue
+			}
+			current[r.VmId] = r.RunningStatus
+
+			if len(previous) == 0 {
+				continue // First poll only establishes a baseline
+			}
+
+			if prevStatus, ok := previous[r.VmId]; ok && prevStatus != r.RunningStatus {
+				cs.Events.Publish(events.Event{
+					Type:           events.VMStateChanged,
+					Pod:            r.ResourcePool,
+					VMID:           r.VmId,
+					Node:           r.NodeName,
+					Status:         r.RunningStatus,
+					PreviousStatus: prevStatus,
+				})
+			}
+		}
+
+		previous = current
+	}
+}
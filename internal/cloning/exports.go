@@ -0,0 +1,273 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/google/uuid"
+)
+
+// Pod export statuses
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusExpired   = "expired"
+)
+
+// =================================================
+// Pod Export Database Operations
+// =================================================
+
+func (c *TemplateClient) CreatePodExport(export PodExport) error {
+	query := "INSERT INTO pod_exports (token, pod, vmid, owner, status, expires_at) VALUES (?, ?, ?, ?, ?, ?)"
+	_, err := c.DB.Exec(query, export.Token, export.Pod, export.VMID, export.Owner, ExportStatusPending, export.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodExport(token string) (*PodExport, error) {
+	query := "SELECT token, pod, vmid, owner, filename, size, status, error, expires_at, created_at FROM pod_exports WHERE token = ?"
+	rows, err := c.DB.Query(query, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	exports, err := buildPodExports(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(exports) == 0 {
+		return nil, nil
+	}
+
+	return &exports[0], nil
+}
+
+func (c *TemplateClient) GetPodExports(owner string) ([]PodExport, error) {
+	query := "SELECT token, pod, vmid, owner, filename, size, status, error, expires_at, created_at FROM pod_exports WHERE owner = ? ORDER BY created_at DESC"
+	rows, err := c.DB.Query(query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodExports(rows)
+}
+
+func (c *TemplateClient) GetExpiredPodExports() ([]PodExport, error) {
+	query := "SELECT token, pod, vmid, owner, filename, size, status, error, expires_at, created_at FROM pod_exports WHERE status <> ? AND expires_at <= ?"
+	rows, err := c.DB.Query(query, ExportStatusExpired, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodExports(rows)
+}
+
+func (c *TemplateClient) UpdatePodExport(export PodExport) error {
+	query := "UPDATE pod_exports SET filename = ?, size = ?, status = ?, error = ? WHERE token = ?"
+	_, err := c.DB.Exec(query, export.Filename, export.Size, export.Status, export.Error, export.Token)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Export Operations
+// =================================================
+
+// RequestPodExport validates that the VM belongs to the pod and fits within the export
+// size limit, then starts a background vzdump into the export area
+func (cs *CloningService) RequestPodExport(pod string, vmID int, owner string) (*PodExport, error) {
+	if cs.Config.ExportRemoteDir == "" || cs.Config.ExportLocalDir == "" {
+		return nil, fmt.Errorf("pod exports are not configured")
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	index := slices.IndexFunc(poolVMs, func(vm proxmox.VirtualResource) bool { return vm.VmId == vmID && vm.IsGuest() })
+	if index < 0 {
+		return nil, fmt.Errorf("VM %d is not part of pod %s", vmID, pod)
+	}
+	vm := poolVMs[index]
+
+	if vm.MaxDisk > cs.Config.ExportMaxSize {
+		return nil, fmt.Errorf("VM %d disk size %d bytes exceeds the export limit of %d bytes", vmID, vm.MaxDisk, cs.Config.ExportMaxSize)
+	}
+
+	export := PodExport{
+		Token:     uuid.NewString(),
+		Pod:       pod,
+		VMID:      vmID,
+		Owner:     owner,
+		Status:    ExportStatusPending,
+		ExpiresAt: time.Now().Add(cs.Config.ExportTTL),
+	}
+	if err := cs.DatabaseService.CreatePodExport(export); err != nil {
+		return nil, fmt.Errorf("failed to record export: %w", err)
+	}
+
+	go cs.runPodExport(export, vm)
+
+	return &export, nil
+}
+
+// GetPodExportFile returns the local path of a completed export the user owns
+func (cs *CloningService) GetPodExportFile(token string, username string) (string, error) {
+	export, err := cs.DatabaseService.GetPodExport(token)
+	if err != nil {
+		return "", err
+	}
+	if export == nil || export.Owner != username {
+		return "", fmt.Errorf("export not found")
+	}
+	if time.Now().After(export.ExpiresAt) {
+		return "", fmt.Errorf("export link has expired")
+	}
+	if export.Status != ExportStatusCompleted {
+		return "", fmt.Errorf("export is not ready (status: %s)", export.Status)
+	}
+
+	return filepath.Join(cs.Config.ExportLocalDir, export.Filename), nil
+}
+
+func (cs *CloningService) runPodExport(export PodExport, vm proxmox.VirtualResource) {
+	log.Printf("Exporting VM %d from pod %s for %s", export.VMID, export.Pod, export.Owner)
+
+	if err := cs.exportVM(&export, vm); err != nil {
+		log.Printf("Export of VM %d from pod %s failed: %v", export.VMID, export.Pod, err)
+		export.Status = ExportStatusFailed
+		export.Error = err.Error()
+	} else {
+		export.Status = ExportStatusCompleted
+	}
+
+	if err := cs.DatabaseService.UpdatePodExport(export); err != nil {
+		log.Printf("Failed to update export %s: %v", export.Token, err)
+	}
+}
+
+func (cs *CloningService) exportVM(export *PodExport, vm proxmox.VirtualResource) error {
+	upid, err := cs.ProxmoxService.BackupVMToDir(vm.NodeName, export.VMID, cs.Config.ExportRemoteDir)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.ProxmoxService.WaitForTask(context.Background(), vm.NodeName, upid, cs.Config.ExportTimeout); err != nil {
+		return err
+	}
+
+	// vzdump names archives vzdump-<qemu|lxc>-<vmid>-<timestamp>.*, pick the newest one.
+	// The task log and notes written next to the archive share its name.
+	matches, err := filepath.Glob(filepath.Join(cs.Config.ExportLocalDir, fmt.Sprintf("vzdump-%s-%d-*", vm.Type, export.VMID)))
+	if err == nil {
+		matches = slices.DeleteFunc(matches, func(match string) bool {
+			return strings.HasSuffix(match, ".log") || strings.HasSuffix(match, ".notes")
+		})
+	}
+	if err != nil || len(matches) == 0 {
+		return fmt.Errorf("backup archive for VM %d not found in export area", export.VMID)
+	}
+	sort.Strings(matches)
+	archive := matches[len(matches)-1]
+
+	info, err := os.Stat(archive)
+	if err != nil {
+		return fmt.Errorf("failed to stat backup archive: %w", err)
+	}
+
+	if info.Size() > cs.Config.ExportMaxSize {
+		os.Remove(archive)
+		return fmt.Errorf("backup archive size %d bytes exceeds the export limit of %d bytes", info.Size(), cs.Config.ExportMaxSize)
+	}
+
+	export.Filename = filepath.Base(archive)
+	export.Size = info.Size()
+	return nil
+}
+
+// sweepExpiredExports removes export archives whose download links have expired
+func (cs *CloningService) sweepExpiredExports() {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		expired, err := cs.DatabaseService.GetExpiredPodExports()
+		if err != nil {
+			log.Printf("Export sweeper failed to get expired exports: %v", err)
+			continue
+		}
+
+		for _, export := range expired {
+			if export.Filename != "" {
+				path := filepath.Join(cs.Config.ExportLocalDir, export.Filename)
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Printf("Failed to remove expired export %s: %v", path, err)
+					continue
+				}
+			}
+
+			export.Status = ExportStatusExpired
+			if err := cs.DatabaseService.UpdatePodExport(export); err != nil {
+				log.Printf("Failed to mark export %s as expired: %v", export.Token, err)
+			}
+		}
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func buildPodExports(rows *sql.Rows) ([]PodExport, error) {
+	exports := []PodExport{}
+
+	for rows.Next() {
+		var export PodExport
+		var filename, errMsg sql.NullString
+		var size sql.NullInt64
+		err := rows.Scan(
+			&export.Token,
+			&export.Pod,
+			&export.VMID,
+			&export.Owner,
+			&filename,
+			&size,
+			&export.Status,
+			&errMsg,
+			&export.ExpiresAt,
+			&export.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		export.Filename = filename.String
+		export.Size = size.Int64
+		export.Error = errMsg.String
+
+		exports = append(exports, export)
+	}
+
+	return exports, nil
+}
@@ -0,0 +1,294 @@
+package cloning
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// podNetworkRuleComment marks the default rule that keeps traffic inside the pod flowing
+const podNetworkRuleComment = "kamino-pod-network"
+
+// firewallPortPattern matches the ports Proxmox accepts, such as 3389, 8000:8080, or 80,443
+var firewallPortPattern = regexp.MustCompile(`^[0-9]{1,5}(:[0-9]{1,5})?(,[0-9]{1,5}(:[0-9]{1,5})?)*$`)
+
+// =================================================
+// Pod Firewall Database Operations
+// =================================================
+
+func (c *TemplateClient) InsertPodFirewallRule(rule PodFirewallRule) (int64, error) {
+	query := `INSERT INTO pod_firewall_rules (pod, direction, action, protocol, port, source, comment, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := c.DB.Exec(query, rule.Pod, rule.Direction, rule.Action, rule.Protocol, rule.Port, rule.Source, rule.Comment, rule.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (c *TemplateClient) GetPodFirewallRules(pod string) ([]PodFirewallRule, error) {
+	query := `SELECT id, pod, direction, action, protocol, port, source, comment, created_by, created_at
+		FROM pod_firewall_rules WHERE pod = ? ORDER BY id`
+
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodFirewallRules(rows)
+}
+
+func (c *TemplateClient) DeletePodFirewallRule(pod string, id int64) error {
+	_, err := c.DB.Exec("DELETE FROM pod_firewall_rules WHERE pod = ? AND id = ?", pod, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) RenamePodFirewallRules(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_firewall_rules SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodFirewallRules(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_firewall_rules WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Firewall Operations
+// =================================================
+
+// GetPodFirewallRules returns the firewall rules added to a pod
+func (cs *CloningService) GetPodFirewallRules(pod string) ([]PodFirewallRule, error) {
+	rules, err := cs.DatabaseService.GetPodFirewallRules(pod)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []PodFirewallRule{}
+	}
+
+	return rules, nil
+}
+
+// AddPodFirewallRule adds a firewall rule to every VM of the pod. When the rule can't be
+// applied to all of them it is removed again so the VMs stay consistent.
+func (cs *CloningService) AddPodFirewallRule(rule PodFirewallRule) (*PodFirewallRule, error) {
+	if rule.Port != "" {
+		if !firewallPortPattern.MatchString(rule.Port) {
+			return nil, fmt.Errorf("invalid port %q", rule.Port)
+		}
+		if rule.Protocol != "tcp" && rule.Protocol != "udp" {
+			return nil, fmt.Errorf("a port requires the tcp or udp protocol")
+		}
+	}
+
+	poolVMs, err := cs.podGuests(rule.Pod)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID, err = cs.DatabaseService.InsertPodFirewallRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	guestRule := proxmox.FirewallRule{
+		Type:    rule.Direction,
+		Action:  rule.Action,
+		Proto:   rule.Protocol,
+		DPort:   rule.Port,
+		Source:  rule.Source,
+		Comment: strings.TrimSpace(firewallRuleTag(rule.ID) + " " + rule.Comment),
+	}
+	for _, vm := range poolVMs {
+		if err := cs.ProxmoxService.AddGuestFirewallRule(vm, guestRule); err != nil {
+			if err := cs.removeFirewallRule(poolVMs, rule.ID); err != nil {
+				log.Printf("Failed to roll back firewall rule %d of pod %s: %v", rule.ID, rule.Pod, err)
+			}
+			if err := cs.DatabaseService.DeletePodFirewallRule(rule.Pod, rule.ID); err != nil {
+				log.Printf("Failed to delete unapplied firewall rule %d of pod %s: %v", rule.ID, rule.Pod, err)
+			}
+			return nil, err
+		}
+	}
+
+	return &rule, nil
+}
+
+// DeletePodFirewallRule removes a firewall rule from every VM of the pod
+func (cs *CloningService) DeletePodFirewallRule(pod string, id int64) error {
+	rules, err := cs.DatabaseService.GetPodFirewallRules(pod)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(rules, func(rule PodFirewallRule) bool { return rule.ID == id }) {
+		return fmt.Errorf("firewall rule %d not found on pod %s", id, pod)
+	}
+
+	poolVMs, err := cs.podGuests(pod)
+	if err != nil {
+		return err
+	}
+	if err := cs.removeFirewallRule(poolVMs, id); err != nil {
+		return err
+	}
+
+	return cs.DatabaseService.DeletePodFirewallRule(pod, id)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// applyDefaultFirewall drops inbound traffic to the pod's VMs except on the NICs attached
+// to the pod VNet and the pod's segment VNets, so only ports opened with pod rules are
+// reachable from outside the pod, and allows all outbound traffic
+func (cs *CloningService) applyDefaultFirewall(pod string) error {
+	poolVMs, err := cs.podGuests(pod)
+	if err != nil {
+		return err
+	}
+
+	segmentVNets, err := cs.podSegmentVNets(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get segments of pod %s: %w", pod, err)
+	}
+
+	for _, vm := range poolVMs {
+		if err := cs.ProxmoxService.SetGuestFirewallPolicy(vm, "DROP", "ACCEPT"); err != nil {
+			return err
+		}
+
+		// SetPodVnet attaches net1 of the router and net0 of every other VM to the pod VNet
+		iface := "net0"
+		if routerPattern.MatchString(vm.Name) {
+			iface = "net1"
+		}
+		ifaces := []string{iface}
+
+		if len(segmentVNets) > 0 {
+			segmentIfaces, err := cs.segmentInterfaces(vm, segmentVNets)
+			if err != nil {
+				return err
+			}
+			ifaces = append(ifaces, segmentIfaces...)
+		}
+
+		for _, iface := range ifaces {
+			rule := proxmox.FirewallRule{Type: "in", Action: "ACCEPT", Iface: iface, Comment: podNetworkRuleComment}
+			if err := cs.ProxmoxService.AddGuestFirewallRule(vm, rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// segmentInterfaces returns the NICs of a VM that are bridged to one of the VNets
+func (cs *CloningService) segmentInterfaces(vm proxmox.VirtualResource, vnets []string) ([]string, error) {
+	config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+	if err != nil {
+		return nil, err
+	}
+
+	var ifaces []string
+	for key, value := range config {
+		definition, ok := value.(string)
+		if !ok || !strings.HasPrefix(key, "net") {
+			continue
+		}
+		if match := nicBridgePattern.FindStringSubmatch(definition); match != nil && slices.Contains(vnets, match[1]) {
+			ifaces = append(ifaces, key)
+		}
+	}
+	slices.Sort(ifaces)
+
+	return ifaces, nil
+}
+
+// removeFirewallRule deletes the rule tagged with the ID from every VM that has it
+func (cs *CloningService) removeFirewallRule(poolVMs []proxmox.VirtualResource, id int64) error {
+	tag := firewallRuleTag(id)
+
+	var errors []string
+	for _, vm := range poolVMs {
+		rules, err := cs.ProxmoxService.GetGuestFirewallRules(vm)
+		if err != nil {
+			errors = append(errors, err.Error())
+			continue
+		}
+
+		// Deleting a rule moves the ones after it up, so delete from the bottom
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].Comment != tag && !strings.HasPrefix(rules[i].Comment, tag+" ") {
+				continue
+			}
+			if err := cs.ProxmoxService.DeleteGuestFirewallRule(vm, rules[i].Pos); err != nil {
+				errors = append(errors, err.Error())
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to remove firewall rule %d: %s", id, strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (cs *CloningService) podGuests(pod string) ([]proxmox.VirtualResource, error) {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VMs of pod %s: %w", pod, err)
+	}
+
+	var guests []proxmox.VirtualResource
+	for _, vm := range poolVMs {
+		if vm.IsGuest() {
+			guests = append(guests, vm)
+		}
+	}
+	if len(guests) == 0 {
+		return nil, fmt.Errorf("pod %s has no VMs", pod)
+	}
+
+	return guests, nil
+}
+
+// firewallRuleTag starts the comment of a pod rule on the VMs so it can be found again
+func firewallRuleTag(id int64) string {
+	return fmt.Sprintf("kamino-rule-%d", id)
+}
+
+func buildPodFirewallRules(rows *sql.Rows) ([]PodFirewallRule, error) {
+	var rules []PodFirewallRule
+	for rows.Next() {
+		var rule PodFirewallRule
+		if err := rows.Scan(&rule.ID, &rule.Pod, &rule.Direction, &rule.Action, &rule.Protocol, &rule.Port, &rule.Source, &rule.Comment, &rule.CreatedBy, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,244 @@
+package cloning
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// =================================================
+// Flag Database Operations
+// =================================================
+
+func (c *TemplateClient) GetTemplateFlags(templateName string) ([]TemplateFlag, error) {
+	query := "SELECT template, name, vm_name, path FROM template_flags WHERE template = ? ORDER BY name"
+	rows, err := c.DB.Query(query, templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []TemplateFlag{}
+	for rows.Next() {
+		var flag TemplateFlag
+		if err := rows.Scan(&flag.Template, &flag.Name, &flag.VMName, &flag.Path); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
+
+// SetTemplateFlags replaces the flag placeholders declared by a template
+func (c *TemplateClient) SetTemplateFlags(templateName string, flags []TemplateFlag) error {
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM template_flags WHERE template = ?", templateName); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	for _, flag := range flags {
+		query := "INSERT INTO template_flags (template, name, vm_name, path) VALUES (?, ?, ?, ?)"
+		if _, err := tx.Exec(query, templateName, flag.Name, flag.VMName, flag.Path); err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) SavePodFlag(flag PodFlag) error {
+	query := `INSERT INTO pod_flags (pod, name, hash) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE hash = VALUES(hash), solved_at = NULL, solved_by = NULL`
+	_, err := c.DB.Exec(query, flag.Pod, flag.Name, flag.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodFlags(pod string) ([]PodFlag, error) {
+	query := "SELECT pod, name, hash, solved_at, solved_by FROM pod_flags WHERE pod = ? ORDER BY name"
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []PodFlag{}
+	for rows.Next() {
+		var flag PodFlag
+		var solvedAt sql.NullTime
+		var solvedBy sql.NullString
+		if err := rows.Scan(&flag.Pod, &flag.Name, &flag.Hash, &solvedAt, &solvedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if solvedAt.Valid {
+			flag.SolvedAt = &solvedAt.Time
+		}
+		flag.SolvedBy = solvedBy.String
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
+
+func (c *TemplateClient) MarkPodFlagSolved(pod string, name string, username string) error {
+	query := "UPDATE pod_flags SET solved_at = ?, solved_by = ? WHERE pod = ? AND name = ? AND solved_at IS NULL"
+	_, err := c.DB.Exec(query, time.Now().UTC(), username, pod, name)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) RenamePodFlags(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_flags SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodFlags(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_flags WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Flag Operations
+// =================================================
+
+// injectPodFlags generates a unique value for each of the template's flags, writes it
+// into the designated pod VM through the guest agent, and stores its hash for scoring
+func (cs *CloningService) injectPodFlags(ctx context.Context, flags []TemplateFlag, target CloneTarget) []string {
+	var errors []string
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(target.PoolName)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to get pool VMs for flag injection for %s: %v", target.Name, err)}
+	}
+
+	started := make(map[int]bool)
+	for _, flag := range flags {
+		var node string
+		var vmID int
+		for _, vm := range poolVMs {
+			if vm.Name == flag.VMName {
+				node = vm.NodeName
+				vmID = vm.VmId
+				break
+			}
+		}
+		if vmID == 0 {
+			errors = append(errors, fmt.Sprintf("VM %s for flag %s not found in pod for %s", flag.VMName, flag.Name, target.Name))
+			continue
+		}
+
+		// The guest agent is only available while the VM is running
+		if !started[vmID] {
+			if err := cs.startForAgent(ctx, node, vmID); err != nil {
+				errors = append(errors, fmt.Sprintf("failed to start VM %s for flag injection for %s: %v", flag.VMName, target.Name, err))
+				continue
+			}
+			started[vmID] = true
+		}
+
+		value, err := cs.generateFlagValue()
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to generate flag %s for %s: %v", flag.Name, target.Name, err))
+			continue
+		}
+
+		if err := cs.ProxmoxService.AgentFileWrite(node, vmID, flag.Path, []byte(value+"\n")); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to inject flag %s for %s: %v", flag.Name, target.Name, err))
+			continue
+		}
+
+		podFlag := PodFlag{Pod: target.PoolName, Name: flag.Name, Hash: hashFlag(value)}
+		if err := cs.DatabaseService.SavePodFlag(podFlag); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to store flag %s for %s: %v", flag.Name, target.Name, err))
+		}
+	}
+
+	return errors
+}
+
+// ValidatePodFlag checks a submitted value against the pod's flags, marking the
+// matching flag as solved. It returns the matched flag or nil if the value is wrong.
+func (cs *CloningService) ValidatePodFlag(pod string, value string, username string) (*PodFlag, error) {
+	flags, err := cs.DatabaseService.GetPodFlags(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flags for pod %s: %w", pod, err)
+	}
+
+	submitted := hashFlag(value)
+	for _, flag := range flags {
+		if subtle.ConstantTimeCompare([]byte(flag.Hash), []byte(submitted)) == 1 {
+			if flag.SolvedAt == nil {
+				if err := cs.DatabaseService.MarkPodFlagSolved(pod, flag.Name, username); err != nil {
+					return nil, err
+				}
+				now := time.Now().UTC()
+				flag.SolvedAt = &now
+				flag.SolvedBy = username
+			}
+			log.Printf("User %s solved flag %s in pod %s", username, flag.Name, pod)
+			return &flag, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (cs *CloningService) startForAgent(ctx context.Context, node string, vmID int) error {
+	if err := cs.ProxmoxService.StartVM(node, vmID); err != nil {
+		return err
+	}
+
+	if err := cs.ProxmoxService.WaitForRunning(ctx, node, vmID); err != nil {
+		return err
+	}
+
+	return cs.ProxmoxService.WaitForAgent(ctx, node, vmID, cs.Config.FlagAgentTimeout)
+}
+
+func (cs *CloningService) generateFlagValue() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(cs.Config.FlagFormat, hex.EncodeToString(bytes)), nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func hashFlag(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
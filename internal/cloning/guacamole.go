@@ -0,0 +1,319 @@
+package cloning
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var guacamoleHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// errGuacamoleNotFound is returned by requests for objects Guacamole doesn't have
+var errGuacamoleNotFound = fmt.Errorf("not found in Guacamole")
+
+// guacamoleDefaultPorts are used for remote access entries that don't set a port
+var guacamoleDefaultPorts = map[string]int{
+	"rdp": 3389,
+	"ssh": 22,
+	"vnc": 5900,
+}
+
+// guacamoleSession is an authentication token of the Guacamole admin account
+type guacamoleSession struct {
+	baseURL    string
+	token      string
+	dataSource string
+}
+
+type guacamoleConnection struct {
+	Identifier       string            `json:"identifier,omitempty"`
+	ParentIdentifier string            `json:"parentIdentifier"`
+	Name             string            `json:"name"`
+	Protocol         string            `json:"protocol"`
+	Parameters       map[string]string `json:"parameters"`
+	Attributes       map[string]string `json:"attributes"`
+}
+
+type guacamolePatch struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// =================================================
+// Guacamole Database Operations
+// =================================================
+
+func (c *TemplateClient) InsertPodGuacamoleConnection(connection PodGuacamoleConnection) error {
+	query := "INSERT INTO pod_guacamole_connections (pod, connection_id, name) VALUES (?, ?, ?)"
+
+	_, err := c.DB.Exec(query, connection.Pod, connection.ConnectionID, connection.Name)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodGuacamoleConnections(pod string) ([]PodGuacamoleConnection, error) {
+	query := "SELECT pod, connection_id, name, created_at FROM pod_guacamole_connections WHERE pod = ? ORDER BY created_at"
+
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodGuacamoleConnections(rows)
+}
+
+func (c *TemplateClient) DeletePodGuacamoleConnections(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_guacamole_connections WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// createPodGuacamoleConnections creates a Guacamole connection for each remote access entry
+// of the pod's template and grants the owner access to it. Nothing is done when no
+// Guacamole server is configured or the template has no entries.
+func (cs *CloningService) createPodGuacamoleConnections(pod string, owner string, isGroup bool) error {
+	if cs.Config.GuacamoleURL == "" {
+		return nil
+	}
+
+	template, err := cs.podTemplateNetworks(pod)
+	if err != nil {
+		return err
+	}
+	if len(template.RemoteAccess) == 0 {
+		return nil
+	}
+
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return err
+	}
+
+	session, err := cs.guacamoleLogin()
+	if err != nil {
+		return err
+	}
+	defer session.logout()
+
+	for _, access := range template.RemoteAccess {
+		connection := guacamoleConnection{
+			ParentIdentifier: "ROOT",
+			Name:             fmt.Sprintf("%s %s", pod, access.Name),
+			Protocol:         access.Protocol,
+			Parameters:       guacamoleParameters(access, cs.ProxmoxService.PodWANAddress(podNumber, access.Host)),
+			Attributes:       map[string]string{},
+		}
+
+		var created guacamoleConnection
+		if err := session.request(http.MethodPost, "/connections", connection, &created); err != nil {
+			return fmt.Errorf("failed to create Guacamole connection %s: %w", connection.Name, err)
+		}
+
+		// Recorded before the permission so a failed grant still gets cleaned up with the pod
+		if err := cs.DatabaseService.InsertPodGuacamoleConnection(PodGuacamoleConnection{Pod: pod, ConnectionID: created.Identifier, Name: connection.Name}); err != nil {
+			return err
+		}
+
+		subject := "/users/"
+		if isGroup {
+			subject = "/userGroups/"
+		}
+		patch := []guacamolePatch{{Op: "add", Path: "/connectionPermissions/" + created.Identifier, Value: "READ"}}
+		if err := session.request(http.MethodPatch, subject+url.PathEscape(owner)+"/permissions", patch, nil); err != nil {
+			return fmt.Errorf("failed to grant %s access to Guacamole connection %s: %w", owner, connection.Name, err)
+		}
+	}
+
+	log.Printf("Created %d Guacamole connections for pod %s", len(template.RemoteAccess), pod)
+	return nil
+}
+
+// removePodGuacamoleConnections deletes the connections created by
+// createPodGuacamoleConnections, which also drops the owner's permissions on them
+func (cs *CloningService) removePodGuacamoleConnections(pod string) error {
+	if cs.Config.GuacamoleURL == "" {
+		return nil
+	}
+
+	connections, err := cs.DatabaseService.GetPodGuacamoleConnections(pod)
+	if err != nil {
+		return err
+	}
+	if len(connections) == 0 {
+		return nil
+	}
+
+	session, err := cs.guacamoleLogin()
+	if err != nil {
+		return err
+	}
+	defer session.logout()
+
+	var errors []string
+	for _, connection := range connections {
+		err := session.request(http.MethodDelete, "/connections/"+url.PathEscape(connection.ConnectionID), nil, nil)
+		if err != nil && err != errGuacamoleNotFound {
+			errors = append(errors, err.Error())
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to delete Guacamole connections of pod %s: %s", pod, strings.Join(errors, "; "))
+	}
+
+	return cs.DatabaseService.DeletePodGuacamoleConnections(pod)
+}
+
+// guacamoleLogin authenticates the admin account configured with GUACAMOLE_USERNAME
+func (cs *CloningService) guacamoleLogin() (*guacamoleSession, error) {
+	baseURL := strings.TrimSuffix(cs.Config.GuacamoleURL, "/")
+	form := url.Values{
+		"username": {cs.Config.GuacamoleUsername},
+		"password": {cs.Config.GuacamolePassword},
+	}
+
+	resp, err := guacamoleHTTPClient.PostForm(baseURL+"/api/tokens", form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in to Guacamole: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to log in to Guacamole: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var login struct {
+		AuthToken  string `json:"authToken"`
+		DataSource string `json:"dataSource"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&login); err != nil {
+		return nil, fmt.Errorf("failed to decode Guacamole login: %w", err)
+	}
+
+	session := &guacamoleSession{baseURL: baseURL, token: login.AuthToken, dataSource: login.DataSource}
+	if cs.Config.GuacamoleDataSource != "" {
+		session.dataSource = cs.Config.GuacamoleDataSource
+	}
+
+	return session, nil
+}
+
+// request calls an endpoint of the session's data source, decoding the response into out
+// when it isn't nil
+func (s *guacamoleSession) request(method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal Guacamole request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/session/data/%s%s", s.baseURL, url.PathEscape(s.dataSource), path)
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create Guacamole request: %w", err)
+	}
+	req.Header.Set("Guacamole-Token", s.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := guacamoleHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errGuacamoleNotFound
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode Guacamole response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// logout revokes the session's token, failures only leave it to expire
+func (s *guacamoleSession) logout() {
+	req, err := http.NewRequest(http.MethodDelete, s.baseURL+"/api/tokens/"+url.PathEscape(s.token), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Guacamole-Token", s.token)
+
+	resp, err := guacamoleHTTPClient.Do(req)
+	if err != nil {
+		log.Printf("Failed to log out of Guacamole: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// guacamoleParameters builds the connection parameters of a remote access entry
+func guacamoleParameters(access RemoteAccess, hostname string) map[string]string {
+	port := access.Port
+	if port == 0 {
+		port = guacamoleDefaultPorts[access.Protocol]
+	}
+
+	parameters := map[string]string{
+		"hostname": hostname,
+		"port":     strconv.Itoa(port),
+	}
+	if access.Username != "" {
+		parameters["username"] = access.Username
+	}
+	if access.Password != "" {
+		parameters["password"] = access.Password
+	}
+	if access.Protocol == "rdp" {
+		// Pod VMs use self-signed certificates and whatever security mode their image has
+		parameters["security"] = "any"
+		parameters["ignore-cert"] = "true"
+	}
+
+	return parameters
+}
+
+func buildPodGuacamoleConnections(rows *sql.Rows) ([]PodGuacamoleConnection, error) {
+	var connections []PodGuacamoleConnection
+	for rows.Next() {
+		var connection PodGuacamoleConnection
+		if err := rows.Scan(&connection.Pod, &connection.ConnectionID, &connection.Name, &connection.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		connections = append(connections, connection)
+	}
+
+	return connections, nil
+}
@@ -0,0 +1,122 @@
+package cloning
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Private Functions
+// =================================================
+
+// validateHAGroup checks that an HA group exists on the cluster
+func (cs *CloningService) validateHAGroup(group string) error {
+	if group == "" {
+		return nil
+	}
+
+	groups, err := cs.ProxmoxService.GetHAGroups()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(groups, func(g proxmox.HAGroup) bool { return g.Group == group }) {
+		return fmt.Errorf("HA group %s does not exist", group)
+	}
+
+	return nil
+}
+
+// resolveHAGroup returns the HA group a deployment's VMs are registered in, a request
+// override taking precedence over the template setting
+func (cs *CloningService) resolveHAGroup(req CloneRequest, template KaminoTemplate) (string, error) {
+	group := cmp.Or(req.HAGroup, template.HAGroup)
+	if err := cs.validateHAGroup(group); err != nil {
+		return "", err
+	}
+	return group, nil
+}
+
+// registerPodHA puts the VMs of a pod that are not managed yet under the HA manager in a
+// group. Each VM keeps its current power state, the HA manager starts tracking a stopped
+// VM as started once it is started.
+func (cs *CloningService) registerPodHA(pod string, group string) error {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	managed, err := cs.ProxmoxService.GetHAResources()
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range poolVMs {
+		sid := proxmox.HAResourceID(vm.Type, vm.VmId)
+		if slices.ContainsFunc(managed, func(r proxmox.HAResource) bool { return r.SID == sid }) {
+			continue
+		}
+
+		state := "stopped"
+		if vm.RunningStatus == "running" {
+			state = "started"
+		}
+		if err := cs.ProxmoxService.AddHAResource(sid, group, state, "Kamino pod "+pod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extendPodHA registers VMs added to a pod, such as by a repair, in the HA group the
+// pod's other VMs are in. Pods without HA managed VMs are left alone.
+func (cs *CloningService) extendPodHA(pod string) error {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	managed, err := cs.ProxmoxService.GetHAResources()
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range poolVMs {
+		sid := proxmox.HAResourceID(vm.Type, vm.VmId)
+		if i := slices.IndexFunc(managed, func(r proxmox.HAResource) bool { return r.SID == sid }); i >= 0 && managed[i].Group != "" {
+			return cs.registerPodHA(pod, managed[i].Group)
+		}
+	}
+
+	return nil
+}
+
+// removeHAResources takes VMs out of the HA manager so they can be deleted, returning the
+// HA group each removed VM was in by VMID
+func (cs *CloningService) removeHAResources(vms []proxmox.VirtualResource) (map[int]string, error) {
+	managed, err := cs.ProxmoxService.GetHAResources()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int]string)
+	for _, vm := range vms {
+		sid := proxmox.HAResourceID(vm.Type, vm.VmId)
+		i := slices.IndexFunc(managed, func(r proxmox.HAResource) bool { return r.SID == sid })
+		if i < 0 {
+			continue
+		}
+
+		if err := cs.ProxmoxService.RemoveHAResource(sid); err != nil {
+			return groups, err
+		}
+		groups[vm.VmId] = managed[i].Group
+		log.Printf("Removed VM %s (VMID: %d) from HA group %s", vm.Name, vm.VmId, managed[i].Group)
+	}
+
+	return groups, nil
+}
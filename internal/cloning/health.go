@@ -0,0 +1,160 @@
+package cloning
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// nicBridgePattern extracts the bridge a netX value is attached to
+var nicBridgePattern = regexp.MustCompile(`bridge=([^,]+)`)
+
+// GetPodHealth checks each VM of a pod for its power state, guest agent reachability,
+// and VNet binding, and verifies the router holds the WAN address assigned to the pod
+func (cs *CloningService) GetPodHealth(pod string) (*PodHealth, error) {
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	vnet, err := cs.podVNet(pod, podNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentVNets, err := cs.podSegmentVNets(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &PodHealth{
+		Pod:     pod,
+		Healthy: true,
+		VNet:    vnet,
+		VMs:     []VMHealth{},
+	}
+
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() {
+			continue
+		}
+
+		isRouter := routerPattern.MatchString(vm.Name)
+		vmHealth := cs.checkVMHealth(vm, isRouter, health.VNet, segmentVNets)
+
+		if isRouter && health.Router == nil {
+			health.Router = cs.checkRouterWAN(vm, podNumber, vmHealth.AgentReachable)
+			if !health.Router.WANIPVerified {
+				vmHealth.Problems = append(vmHealth.Problems, fmt.Sprintf("router does not have WAN address %s", health.Router.ExpectedWANIP))
+			}
+		}
+
+		if len(vmHealth.Problems) > 0 {
+			health.Healthy = false
+		}
+		health.VMs = append(health.VMs, vmHealth)
+	}
+
+	return health, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// checkVMHealth checks a VM's power state, guest agent, and the bridge of the NIC that
+// SetPodVnet attaches to the pod VNet: net1 on the router and net0 on every other VM.
+// Other VMs may have that NIC on one of the pod's segment VNets instead.
+func (cs *CloningService) checkVMHealth(vm proxmox.VirtualResource, isRouter bool, vnet string, segmentVNets []string) VMHealth {
+	vmHealth := VMHealth{
+		Name:     vm.Name,
+		VMID:     vm.VmId,
+		Status:   vm.RunningStatus,
+		Problems: []string{},
+	}
+
+	if vm.RunningStatus != "running" {
+		vmHealth.Problems = append(vmHealth.Problems, fmt.Sprintf("VM is %s", vm.RunningStatus))
+	} else if vm.Type == proxmox.GuestTypeLXC {
+		// Containers have no guest agent, the host manages them directly
+		vmHealth.AgentReachable = true
+	} else if err := cs.ProxmoxService.AgentPing(vm.NodeName, vm.VmId); err != nil {
+		vmHealth.Problems = append(vmHealth.Problems, "qemu guest agent is not responding")
+	} else {
+		vmHealth.AgentReachable = true
+	}
+
+	nic := "net0"
+	if isRouter {
+		nic = "net1"
+	}
+
+	config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+	if err != nil {
+		vmHealth.Problems = append(vmHealth.Problems, fmt.Sprintf("failed to read VM config: %v", err))
+		return vmHealth
+	}
+
+	if value, ok := config[nic].(string); ok {
+		if match := nicBridgePattern.FindStringSubmatch(value); match != nil {
+			vmHealth.Bridge = match[1]
+		}
+	}
+
+	vmHealth.VNetBound = vmHealth.Bridge == vnet || (!isRouter && slices.Contains(segmentVNets, vmHealth.Bridge))
+	if !vmHealth.VNetBound {
+		vmHealth.Problems = append(vmHealth.Problems, fmt.Sprintf("%s is attached to %q instead of %s", nic, vmHealth.Bridge, vnet))
+	}
+
+	return vmHealth
+}
+
+func (cs *CloningService) checkRouterWAN(router proxmox.VirtualResource, podNumber int, agentReachable bool) *RouterHealth {
+	routerHealth := &RouterHealth{
+		VMID:          router.VmId,
+		ExpectedWANIP: cs.ProxmoxService.PodRouterWANIP(podNumber),
+		Addresses:     []string{},
+	}
+
+	// The router's addresses can only be read through its guest agent
+	if !agentReachable {
+		return routerHealth
+	}
+
+	interfaces, err := cs.ProxmoxService.AgentNetworkInterfaces(router.NodeName, router.VmId)
+	if err != nil {
+		return routerHealth
+	}
+
+	for _, iface := range interfaces {
+		for _, address := range iface.IPAddresses {
+			routerHealth.Addresses = append(routerHealth.Addresses, address.Address)
+		}
+	}
+	routerHealth.WANIPVerified = slices.Contains(routerHealth.Addresses, routerHealth.ExpectedWANIP)
+
+	return routerHealth
+}
+
+// podNumberFromPool recovers the pod number from the pod ID that prefixes the pool name,
+// matching the numbering of GetNextPodIDs
+func podNumberFromPool(pod string) (int, error) {
+	if len(pod) < 4 {
+		return 0, fmt.Errorf("invalid pod name: %s", pod)
+	}
+
+	podID, err := strconv.Atoi(pod[:4])
+	if err != nil {
+		return 0, fmt.Errorf("invalid pod name: %s", pod)
+	}
+
+	return podID - 1000, nil
+}
@@ -0,0 +1,186 @@
+package cloning
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Pod Activity Database Operations
+// =================================================
+
+// SavePodActivity records a heartbeat. The last activity is only moved forward, so a
+// heartbeat without logged in users keeps the previous activity time.
+func (c *TemplateClient) SavePodActivity(activity PodActivity) error {
+	query := `INSERT INTO pod_activity (pod, active_users, uptime, last_activity, checked_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE active_users = VALUES(active_users), uptime = VALUES(uptime),
+		last_activity = COALESCE(VALUES(last_activity), last_activity), checked_at = VALUES(checked_at)`
+
+	var lastActivity any
+	if activity.LastActivity != nil {
+		lastActivity = activity.LastActivity.UTC()
+	}
+	_, err := c.DB.Exec(query, activity.Pod, activity.ActiveUsers, activity.Uptime, lastActivity, activity.CheckedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodActivities() ([]PodActivity, error) {
+	rows, err := c.DB.Query("SELECT pod, active_users, uptime, last_activity, checked_at FROM pod_activity")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	activities := []PodActivity{}
+	for rows.Next() {
+		var activity PodActivity
+		var lastActivity sql.NullTime
+		err := rows.Scan(
+			&activity.Pod,
+			&activity.ActiveUsers,
+			&activity.Uptime,
+			&lastActivity,
+			&activity.CheckedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if lastActivity.Valid {
+			activity.LastActivity = &lastActivity.Time
+		}
+
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
+
+func (c *TemplateClient) DeletePodActivity(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_activity WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Activity Operations
+// =================================================
+
+// GetPodLastActivity returns the last time a user was logged in to one of the pod's
+// designated VMs, or nil when the heartbeat has never seen activity in the pod
+func (cs *CloningService) GetPodLastActivity(pod string) (*time.Time, error) {
+	activities, err := cs.DatabaseService.GetPodActivities()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, activity := range activities {
+		if activity.Pod == pod {
+			return activity.LastActivity, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// =================================================
+// Pod Activity Worker
+// =================================================
+
+// collectHeartbeats periodically asks the guest agents of each pod's designated VMs for
+// logged in users, recording when each pod was last in use
+func (cs *CloningService) collectHeartbeats() {
+	if cs.Config.HeartbeatInterval <= 0 {
+		return
+	}
+
+	var vmPattern *regexp.Regexp
+	if cs.Config.HeartbeatVMPattern != "" {
+		pattern, err := regexp.Compile(cs.Config.HeartbeatVMPattern)
+		if err != nil {
+			log.Printf("Invalid HEARTBEAT_VM_PATTERN, activity heartbeat disabled: %v", err)
+			return
+		}
+		vmPattern = pattern
+	}
+
+	ticker := time.NewTicker(cs.Config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		pods, err := cs.AdminGetPods()
+		if err != nil {
+			log.Printf("Heartbeat failed to get pods: %v", err)
+			continue
+		}
+
+		for _, pod := range pods {
+			if pod.Retained || pod.Archived {
+				continue
+			}
+			if err := cs.DatabaseService.SavePodActivity(cs.podHeartbeat(pod, vmPattern)); err != nil {
+				log.Printf("Failed to record heartbeat of pod %s: %v", pod.Name, err)
+			}
+		}
+	}
+}
+
+// podHeartbeat polls the running designated VMs of a pod. VMs whose guest agent does not
+// answer count as idle.
+func (cs *CloningService) podHeartbeat(pod Pod, vmPattern *regexp.Regexp) PodActivity {
+	activity := PodActivity{
+		Pod:       pod.Name,
+		CheckedAt: time.Now(),
+	}
+
+	for _, vm := range pod.VMs {
+		// Only qemu VMs have a guest agent to ask
+		if vm.Type != proxmox.GuestTypeQEMU || vm.RunningStatus != "running" || routerPattern.MatchString(vm.Name) {
+			continue
+		}
+		if vmPattern != nil && !vmPattern.MatchString(vm.Name) {
+			continue
+		}
+
+		activity.Uptime = max(activity.Uptime, vm.Uptime)
+
+		users, err := cs.ProxmoxService.AgentGetUsers(vm.NodeName, vm.VmId)
+		if err != nil {
+			continue
+		}
+		activity.ActiveUsers += len(users)
+	}
+
+	if activity.ActiveUsers > 0 {
+		activity.LastActivity = &activity.CheckedAt
+	}
+
+	return activity
+}
+
+// podLastActivity returns the last activity time of every pod the heartbeat has seen in use
+func (cs *CloningService) podLastActivity() map[string]*time.Time {
+	activities, err := cs.DatabaseService.GetPodActivities()
+	if err != nil {
+		log.Printf("Failed to get pod activity: %v", err)
+		return nil
+	}
+
+	byPod := make(map[string]*time.Time)
+	for _, activity := range activities {
+		byPod[activity.Pod] = activity.LastActivity
+	}
+	return byPod
+}
@@ -0,0 +1,70 @@
+package cloning
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+)
+
+// ClaimIdempotencyKey reserves a client supplied idempotency key for a clone of the
+// template by the user. It returns the earlier clone and false when the key was already
+// used within the idempotency window. A key whose clone failed or was cancelled is
+// released so the request can be retried with it.
+func (cs *CloningService) ClaimIdempotencyKey(username string, key string, templateName string) (*IdempotentClone, bool) {
+	cs.idempotencyMutex.Lock()
+	defer cs.idempotencyMutex.Unlock()
+
+	now := time.Now()
+	for k, clone := range cs.idempotencyKeys {
+		if now.Sub(clone.CreatedAt) > cs.Config.IdempotencyWindow {
+			delete(cs.idempotencyKeys, k)
+		}
+	}
+
+	mapKey := idempotencyMapKey(username, key)
+	if existing, ok := cs.idempotencyKeys[mapKey]; ok {
+		retryable := false
+		if job, found := cs.Jobs.Get(existing.JobID); found {
+			retryable = job.Status == jobs.StatusFailed || job.Status == jobs.StatusCancelled
+		}
+		if !retryable || existing.Template != templateName {
+			duplicate := *existing
+			return &duplicate, false
+		}
+	}
+
+	clone := &IdempotentClone{Template: templateName, CreatedAt: now}
+	cs.idempotencyKeys[mapKey] = clone
+	return clone, true
+}
+
+// ReleaseIdempotencyKey forgets a claimed key whose request was rejected before cloning started
+func (cs *CloningService) ReleaseIdempotencyKey(username string, key string) {
+	cs.idempotencyMutex.Lock()
+	defer cs.idempotencyMutex.Unlock()
+
+	delete(cs.idempotencyKeys, idempotencyMapKey(username, key))
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// attachIdempotentJob links the job of a clone to the idempotency key it was requested with
+func (cs *CloningService) attachIdempotentJob(username string, key string, jobID string) {
+	if key == "" {
+		return
+	}
+
+	cs.idempotencyMutex.Lock()
+	defer cs.idempotencyMutex.Unlock()
+
+	if clone, ok := cs.idempotencyKeys[idempotencyMapKey(username, key)]; ok {
+		clone.JobID = jobID
+	}
+}
+
+func idempotencyMapKey(username string, key string) string {
+	return strings.ToLower(username) + "\x00" + key
+}
@@ -0,0 +1,229 @@
+package cloning
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// =================================================
+// IPAM Database Operations
+// =================================================
+
+func (c *TemplateClient) SaveSubnetAllocation(allocation SubnetAllocation) error {
+	query := `INSERT INTO pod_subnets (pod, pod_number, subnet, wan_ip) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE pod_number = VALUES(pod_number), subnet = VALUES(subnet), wan_ip = VALUES(wan_ip), allocated_at = UTC_TIMESTAMP()`
+
+	_, err := c.DB.Exec(query, allocation.Pod, allocation.PodNumber, allocation.Subnet, allocation.WANIP)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetSubnetAllocations() ([]SubnetAllocation, error) {
+	rows, err := c.DB.Query("SELECT pod, pod_number, subnet, wan_ip, allocated_at FROM pod_subnets ORDER BY pod_number")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildSubnetAllocations(rows)
+}
+
+func (c *TemplateClient) RenameSubnetAllocation(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_subnets SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeleteSubnetAllocation(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_subnets WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// IPAM Operations
+// =================================================
+
+// GetIPAMReport lists the WAN subnet allocated to each pod and the conflicts found between
+// the allocations, the deployed pods, and the reserved ranges
+func (cs *CloningService) GetIPAMReport() (*IPAMReport, error) {
+	allocations, err := cs.DatabaseService.GetSubnetAllocations()
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := cs.AdminGetPods()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployed pods: %w", err)
+	}
+
+	reserved, err := cs.reservedSubnets()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IPAMReport{
+		Allocations: allocations,
+		Reserved:    cs.Config.IPAMReservedSubnets,
+		Conflicts:   []string{},
+	}
+	if report.Allocations == nil {
+		report.Allocations = []SubnetAllocation{}
+	}
+
+	allocated := make(map[string]bool)
+	for _, allocation := range allocations {
+		allocated[allocation.Pod] = true
+
+		if !slices.ContainsFunc(pods, func(pod Pod) bool { return pod.Name == allocation.Pod }) {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("subnet %s is allocated to pod %s which is no longer deployed", allocation.Subnet, allocation.Pod))
+		}
+		if expected := cs.ProxmoxService.PodWANSubnet(allocation.PodNumber); allocation.Subnet != expected {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("pod %s holds subnet %s but its router is configured for %s", allocation.Pod, allocation.Subnet, expected))
+		}
+		if overlap := overlappingSubnet(allocation.Subnet, reserved); overlap != "" {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("subnet %s of pod %s overlaps reserved range %s", allocation.Subnet, allocation.Pod, overlap))
+		}
+	}
+
+	for _, pod := range pods {
+		if !allocated[pod.Name] {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("pod %s has no subnet allocation", pod.Name))
+		}
+	}
+
+	return report, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// allocatePodSubnets records the WAN subnet of every target, refusing the deployment when
+// a subnet falls in a reserved range or is held by another deployed pod. Allocations left
+// by pods that are gone are taken over. The router scripts derive the subnet from the pod
+// number, so the allocation records that subnet rather than choosing a free one.
+func (cs *CloningService) allocatePodSubnets(targets []CloneTarget) error {
+	allocations, err := cs.DatabaseService.GetSubnetAllocations()
+	if err != nil {
+		return err
+	}
+
+	reserved, err := cs.reservedSubnets()
+	if err != nil {
+		return err
+	}
+
+	// Deployed pods are only looked up when a subnet is already allocated
+	var deployed []Pod
+	isDeployed := func(pod string) (bool, error) {
+		if deployed == nil {
+			if deployed, err = cs.AdminGetPods(); err != nil {
+				return false, fmt.Errorf("failed to get deployed pods: %w", err)
+			}
+		}
+		return slices.ContainsFunc(deployed, func(p Pod) bool { return p.Name == pod }), nil
+	}
+
+	var requested []SubnetAllocation
+	for _, target := range targets {
+		if target.PodNumber < 1 || target.PodNumber > maxWANSubnets {
+			return fmt.Errorf("pod number %d of %s has no WAN subnet", target.PodNumber, target.PoolName)
+		}
+
+		allocation := SubnetAllocation{
+			Pod:       target.PoolName,
+			PodNumber: target.PodNumber,
+			Subnet:    cs.ProxmoxService.PodWANSubnet(target.PodNumber),
+			WANIP:     cs.ProxmoxService.PodRouterWANIP(target.PodNumber),
+		}
+		if overlap := overlappingSubnet(allocation.Subnet, reserved); overlap != "" {
+			return fmt.Errorf("subnet %s of %s overlaps reserved range %s", allocation.Subnet, target.PoolName, overlap)
+		}
+
+		for _, existing := range allocations {
+			if existing.Subnet != allocation.Subnet || existing.Pod == allocation.Pod {
+				continue
+			}
+
+			inUse, err := isDeployed(existing.Pod)
+			if err != nil {
+				return err
+			}
+			if inUse {
+				return fmt.Errorf("subnet %s of %s is already allocated to pod %s", allocation.Subnet, target.PoolName, existing.Pod)
+			}
+
+			log.Printf("Releasing subnet %s of pod %s which is no longer deployed", existing.Subnet, existing.Pod)
+			if err := cs.DatabaseService.DeleteSubnetAllocation(existing.Pod); err != nil {
+				return err
+			}
+		}
+
+		requested = append(requested, allocation)
+	}
+
+	for _, allocation := range requested {
+		if err := cs.DatabaseService.SaveSubnetAllocation(allocation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reservedSubnets parses the ranges IPAM_RESERVED_SUBNETS keeps out of pod allocations
+func (cs *CloningService) reservedSubnets() ([]netip.Prefix, error) {
+	var reserved []netip.Prefix
+	for _, value := range cs.Config.IPAMReservedSubnets {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid reserved subnet %q: %w", value, err)
+		}
+		reserved = append(reserved, prefix.Masked())
+	}
+
+	return reserved, nil
+}
+
+// overlappingSubnet returns the reserved range the subnet overlaps, if any
+func overlappingSubnet(subnet string, reserved []netip.Prefix) string {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return ""
+	}
+
+	for _, r := range reserved {
+		if r.Overlaps(prefix) {
+			return r.String()
+		}
+	}
+
+	return ""
+}
+
+func buildSubnetAllocations(rows *sql.Rows) ([]SubnetAllocation, error) {
+	var allocations []SubnetAllocation
+	for rows.Next() {
+		var allocation SubnetAllocation
+		if err := rows.Scan(&allocation.Pod, &allocation.PodNumber, &allocation.Subnet, &allocation.WANIP, &allocation.AllocatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations, nil
+}
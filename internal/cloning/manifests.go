@@ -0,0 +1,279 @@
+package cloning
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/google/uuid"
+)
+
+// TemplateManifestVersion is the manifest format written by ExportTemplateManifest
+const TemplateManifestVersion = 1
+
+// =================================================
+// Template Image Files
+// =================================================
+
+// ReadTemplateImage returns the contents of an uploaded template image
+func (c *TemplateClient) ReadTemplateImage(imagePath string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(c.TemplateConfig.UploadDir, filepath.Base(imagePath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	return data, nil
+}
+
+// SaveTemplateImage stores image data under a unique name in the upload directory and
+// returns the name to use as the template's image path
+func (c *TemplateClient) SaveTemplateImage(filename string, data []byte) (string, error) {
+	if _, ok := allowedMIMEs[http.DetectContentType(data)]; !ok {
+		return "", fmt.Errorf("unsupported file type: %s", http.DetectContentType(data))
+	}
+
+	filename = strings.ReplaceAll(filepath.Clean(filepath.Base(filename)), " ", "_")
+	newFilename := fmt.Sprintf("%s-%s", uuid.NewString(), filename)
+	outPath := filepath.Join(c.TemplateConfig.UploadDir, newFilename)
+	if err := os.WriteFile(outPath, data, 0644); err != nil {
+		return "", fmt.Errorf("unable to save file: %w", err)
+	}
+	generateThumbnails(outPath)
+
+	return newFilename, nil
+}
+
+// =================================================
+// Template Manifest Operations
+// =================================================
+
+// ExportTemplateManifest describes a published template, its VMs and their networks,
+// flags, placement rules, and image as a portable manifest
+func (cs *CloningService) ExportTemplateManifest(templateName string) (*TemplateManifest, error) {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return nil, fmt.Errorf("template not found: %s", templateName)
+	}
+
+	manifest := &TemplateManifest{
+		Version:         TemplateManifestVersion,
+		Name:            template.Name,
+		Description:     template.Description,
+		Authors:         template.Authors,
+		TemplateVisible: template.TemplateVisible,
+		CloneMode:       template.CloneMode,
+		Storage:         template.Storage,
+		HAGroup:         template.HAGroup,
+		MaxDeployments:  template.MaxDeployments,
+		Category:        template.Category,
+		Tags:            template.Tags,
+		Difficulty:      template.Difficulty,
+		Guide:           template.Guide,
+		LANSubnets:      template.LANSubnets,
+		Segments:        template.Segments,
+		RateLimit:       template.RateLimit,
+		RemoteAccess:    template.RemoteAccess,
+		ExportedAt:      time.Now().UTC(),
+	}
+
+	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template pool: %w", err)
+	}
+	for _, vm := range templatePool {
+		if !vm.IsGuest() {
+			continue
+		}
+
+		manifestVM := ManifestVM{
+			Name:     vm.Name,
+			Type:     vm.Type,
+			IsRouter: routerPattern.MatchString(vm.Name),
+			Networks: map[string]string{},
+		}
+
+		config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get config of VM %s: %w", vm.Name, err)
+		}
+		for key, value := range config {
+			if !strings.HasPrefix(key, "net") {
+				continue
+			}
+			if setting, ok := value.(string); ok {
+				manifestVM.Networks[key] = networkBridge(setting)
+			}
+		}
+
+		manifest.VMs = append(manifest.VMs, manifestVM)
+	}
+	sort.Slice(manifest.VMs, func(i, j int) bool { return manifest.VMs[i].Name < manifest.VMs[j].Name })
+
+	flags, err := cs.DatabaseService.GetTemplateFlags(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template flags: %w", err)
+	}
+	for _, flag := range flags {
+		manifest.Flags = append(manifest.Flags, ManifestFlag{Name: flag.Name, VMName: flag.VMName, Path: flag.Path})
+	}
+
+	rules, err := cs.DatabaseService.GetPlacementRules(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placement rules: %w", err)
+	}
+	manifest.PlacementRules = rules
+
+	if template.ImagePath != "" {
+		data, err := cs.DatabaseService.ReadTemplateImage(template.ImagePath)
+		if err != nil {
+			// The manifest is still useful without its image
+			log.Printf("Exporting template %s without its image: %v", templateName, err)
+		} else {
+			manifest.Image = &ManifestImage{
+				Filename: imageFilename(template.ImagePath),
+				Data:     base64.StdEncoding.EncodeToString(data),
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+// ImportTemplateManifest recreates a template definition from a manifest. The template's
+// VMs must already be in the kamino_template_<name> pool on this instance, the manifest
+// only carries the definition. Differences that don't prevent the import are returned
+// as warnings.
+func (cs *CloningService) ImportTemplateManifest(templateName string, manifest TemplateManifest, importedBy string) ([]string, error) {
+	if manifest.Version != TemplateManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+
+	existing, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template info: %w", err)
+	}
+	if existing.Name != "" {
+		return nil, fmt.Errorf("template %s is already published", templateName)
+	}
+
+	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template pool: %w", err)
+	}
+
+	var missing []string
+	for _, vm := range manifest.VMs {
+		if !slices.ContainsFunc(templatePool, func(poolVM proxmox.VirtualResource) bool { return poolVM.Name == vm.Name }) {
+			missing = append(missing, vm.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("VMs missing from pool kamino_template_%s: %s", templateName, strings.Join(missing, ", "))
+	}
+
+	var warnings []string
+	for _, vm := range templatePool {
+		if vm.IsGuest() && !slices.ContainsFunc(manifest.VMs, func(manifestVM ManifestVM) bool { return manifestVM.Name == vm.Name }) {
+			warnings = append(warnings, fmt.Sprintf("VM %s is in the pool but not in the manifest", vm.Name))
+		}
+	}
+
+	template := KaminoTemplate{
+		Name:            templateName,
+		Description:     manifest.Description,
+		Authors:         manifest.Authors,
+		TemplateVisible: manifest.TemplateVisible,
+		VMCount:         len(manifest.VMs),
+		CloneMode:       manifest.CloneMode,
+		Storage:         manifest.Storage,
+		HAGroup:         manifest.HAGroup,
+		MaxDeployments:  manifest.MaxDeployments,
+		Category:        manifest.Category,
+		Tags:            manifest.Tags,
+		Difficulty:      manifest.Difficulty,
+		Guide:           manifest.Guide,
+		LANSubnets:      manifest.LANSubnets,
+		Segments:        manifest.Segments,
+		RateLimit:       manifest.RateLimit,
+		RemoteAccess:    manifest.RemoteAccess,
+	}
+
+	if manifest.Image != nil {
+		data, err := base64.StdEncoding.DecodeString(manifest.Image.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+		imagePath, err := cs.DatabaseService.SaveTemplateImage(manifest.Image.Filename, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save image: %w", err)
+		}
+		template.ImagePath = imagePath
+	}
+
+	if err := cs.PublishTemplate(template, importedBy); err != nil {
+		if template.ImagePath != "" {
+			if err := cs.DatabaseService.DeleteImage(template.ImagePath); err != nil {
+				log.Printf("Failed to remove image of unimported template %s: %v", templateName, err)
+			}
+		}
+		return nil, err
+	}
+
+	if len(manifest.Flags) > 0 {
+		var flags []TemplateFlag
+		for _, flag := range manifest.Flags {
+			flags = append(flags, TemplateFlag{Template: templateName, Name: flag.Name, VMName: flag.VMName, Path: flag.Path})
+		}
+		if err := cs.DatabaseService.SetTemplateFlags(templateName, flags); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to set flags: %v", err))
+		}
+	}
+
+	if len(manifest.PlacementRules) > 0 {
+		if err := cs.SetTemplatePlacementRules(templateName, manifest.PlacementRules); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to set placement rules: %v", err))
+		}
+	}
+
+	log.Printf("Imported template %s from manifest of %s with %d warnings", templateName, manifest.Name, len(warnings))
+	return warnings, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// networkBridge extracts the bridge from a network device setting such as
+// virtio=BC:24:11:00:00:01,bridge=vmbr0,firewall=1
+func networkBridge(setting string) string {
+	for _, option := range strings.Split(setting, ",") {
+		if bridge, ok := strings.CutPrefix(option, "bridge="); ok {
+			return bridge
+		}
+	}
+
+	return ""
+}
+
+// imageFilename strips the unique prefix SaveTemplateImage and uploads add to a filename
+func imageFilename(imagePath string) string {
+	name := filepath.Base(imagePath)
+	if len(name) > 37 && name[36] == '-' {
+		if _, err := uuid.Parse(name[:36]); err == nil {
+			return name[37:]
+		}
+	}
+
+	return name
+}
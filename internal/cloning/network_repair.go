@@ -0,0 +1,78 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+// RepairPodNetwork compares the bridge of every VM's pod NIC with the pod's VNet and moves
+// the NICs that drifted, such as after manual edits in Proxmox, back to the VNet. With
+// dryRun the drift is only reported.
+func (cs *CloningService) RepairPodNetwork(pod string, dryRun bool) (*NetworkRepairResult, error) {
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	vnet, err := cs.podVNet(pod, podNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentVNets, err := cs.podSegmentVNets(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	poolVMs, err := cs.podGuests(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NetworkRepairResult{
+		Pod:    pod,
+		VNet:   vnet,
+		DryRun: dryRun,
+		Drift:  []NetworkDrift{},
+	}
+
+	for _, vm := range poolVMs {
+		result.Checked++
+
+		// SetPodVnet attaches net1 of the router and net0 of every other VM to the pod VNet
+		nic := "net0"
+		if routerPattern.MatchString(vm.Name) {
+			nic = "net1"
+		}
+
+		config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+		if err != nil {
+			return result, fmt.Errorf("failed to read config of VM %s: %w", vm.Name, err)
+		}
+
+		bridge := ""
+		if value, ok := config[nic].(string); ok {
+			if match := nicBridgePattern.FindStringSubmatch(value); match != nil {
+				bridge = match[1]
+			}
+		}
+		// NICs the template put on a segment were moved to the pod's segment VNet
+		if bridge == vnet || (nic == "net0" && slices.Contains(segmentVNets, bridge)) {
+			continue
+		}
+
+		drift := NetworkDrift{Name: vm.Name, VMID: vm.VmId, NIC: nic, Bridge: bridge}
+		if !dryRun {
+			if err := cs.ProxmoxService.SetGuestBridge(vm, nic, vnet); err != nil {
+				drift.Error = err.Error()
+			} else {
+				drift.Repaired = true
+				log.Printf("Moved %s of VM %s (VMID: %d) in pod %s from %q to %s", nic, vm.Name, vm.VmId, pod, bridge, vnet)
+			}
+		}
+		result.Drift = append(result.Drift, drift)
+	}
+
+	return result, nil
+}
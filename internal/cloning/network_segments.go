@@ -0,0 +1,373 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Pod Segment Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodSegment(segment PodSegment) error {
+	// A VNet reused from an earlier pod takes over the row that pod left behind
+	query := `INSERT INTO pod_segments (pod, alias, vnet, vlan_tag) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE pod = VALUES(pod), alias = VALUES(alias), vnet = VALUES(vnet), vlan_tag = VALUES(vlan_tag)`
+
+	_, err := c.DB.Exec(query, segment.Pod, segment.Alias, segment.VNet, segment.VLANTag)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodSegments(pod string) ([]PodSegment, error) {
+	rows, err := c.DB.Query("SELECT pod, alias, vnet, vlan_tag, created_at FROM pod_segments WHERE pod = ? ORDER BY vnet", pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodSegments(rows)
+}
+
+// GetSegmentPods returns the pods that have segment records
+func (c *TemplateClient) GetSegmentPods() ([]string, error) {
+	rows, err := c.DB.Query("SELECT DISTINCT pod FROM pod_segments")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var pods []string
+	for rows.Next() {
+		var pod string
+		if err := rows.Scan(&pod); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return pods, nil
+}
+
+func (c *TemplateClient) RenamePodSegments(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_segments SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodSegment(pod string, alias string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_segments WHERE pod = ? AND alias = ?", pod, alias)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// createPodSegments creates a VNet for every segment of the template in each target's
+// pod and applies the SDN once for the whole job so the VNets exist before the VMs are
+// attached. The SDN lock keeps another job from applying the VNets half created.
+func (cs *CloningService) createPodSegments(ctx context.Context, targets []CloneTarget, templateName string) error {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	segments := template.Segments
+	if len(segments) == 0 {
+		return nil
+	}
+
+	settings, err := cs.GetVNetSettings()
+	if err != nil {
+		return err
+	}
+	if settings.Zone == "" {
+		return fmt.Errorf("templates with network segments need a VNet zone to create segment VNets in")
+	}
+
+	vnets, err := cs.ProxmoxService.GetUsedVNets()
+	if err != nil {
+		return fmt.Errorf("failed to get VNets: %w", err)
+	}
+
+	reservations, err := cs.DatabaseService.GetVLANReservations()
+	if err != nil {
+		return err
+	}
+
+	cs.sdnMutex.Lock()
+	defer cs.sdnMutex.Unlock()
+
+	created := 0
+	for _, target := range targets {
+		for i, segment := range segments {
+			podSegment := PodSegment{
+				Pod:     target.PoolName,
+				Alias:   segment.Alias,
+				VNet:    fmt.Sprintf("%s%ds%d", cs.Config.SegmentVNetPrefix, target.PodNumber, i+1),
+				VLANTag: cs.Config.SegmentVLANBase + (target.PodNumber-1)*maxSegments + i,
+			}
+			if reservation := reservedVLAN(reservations, podSegment.VLANTag); reservation != nil {
+				return fmt.Errorf("VLAN %d of segment %s for %s is reserved: %s", podSegment.VLANTag, segment.Alias, target.PoolName, reservation.Reason)
+			}
+
+			// A VNet left by an earlier pod with the same number is reused
+			if !slices.ContainsFunc(vnets, func(vnet proxmox.VNet) bool { return vnet.Name == podSegment.VNet }) {
+				if err := cs.ProxmoxService.CreateVNet(podSegment.VNet, settings.Zone, podSegment.VLANTag, target.PoolName+" "+segment.Alias); err != nil {
+					return err
+				}
+				created++
+			}
+
+			if err := cs.DatabaseService.SavePodSegment(podSegment); err != nil {
+				return err
+			}
+		}
+	}
+
+	if created == 0 {
+		return nil
+	}
+	log.Printf("Applying SDN for %d segment VNets of %d pods", created, len(targets))
+	return cs.ProxmoxService.ApplySDN(ctx, cs.Config.SDNApplyTimeout)
+}
+
+// removePodSegments deletes the segment VNets of a deleted pod and their records. The
+// record of a VNet that can't be deleted is kept so sweepOrphanedSegments retries it.
+func (cs *CloningService) removePodSegments(pod string) {
+	segments, err := cs.DatabaseService.GetPodSegments(pod)
+	if err != nil {
+		log.Printf("Failed to get segments of pod %s: %v", pod, err)
+		return
+	}
+	if len(segments) == 0 {
+		return
+	}
+
+	vnets, err := cs.ProxmoxService.GetUsedVNets()
+	if err != nil {
+		log.Printf("Failed to get VNets to delete segments of pod %s: %v", pod, err)
+		return
+	}
+
+	cs.sdnMutex.Lock()
+	deleted := 0
+	var removed []PodSegment
+	for _, segment := range segments {
+		// A VNet already gone only leaves its record to clean up
+		if slices.ContainsFunc(vnets, func(vnet proxmox.VNet) bool { return vnet.Name == segment.VNet }) {
+			if err := cs.ProxmoxService.DeleteVNet(segment.VNet); err != nil {
+				log.Printf("Failed to delete segment VNet %s of pod %s: %v", segment.VNet, pod, err)
+				continue
+			}
+			deleted++
+		}
+		removed = append(removed, segment)
+	}
+	if deleted > 0 {
+		if err := cs.ProxmoxService.ApplySDN(context.Background(), cs.Config.SDNApplyTimeout); err != nil {
+			log.Printf("Failed to apply SDN after deleting segments of pod %s: %v", pod, err)
+		}
+	}
+	cs.sdnMutex.Unlock()
+
+	for _, segment := range removed {
+		if err := cs.DatabaseService.DeletePodSegment(pod, segment.Alias); err != nil {
+			log.Printf("Failed to delete record of segment %s of pod %s: %v", segment.Alias, pod, err)
+		}
+	}
+}
+
+// sweepOrphanedSegments retries removing the segments of pods whose pool is gone, left
+// behind when deleting their VNets failed
+func (cs *CloningService) sweepOrphanedSegments() {
+	segmentPods, err := cs.DatabaseService.GetSegmentPods()
+	if err != nil {
+		log.Printf("Segment sweep failed to get pods with segments: %v", err)
+		return
+	}
+	if len(segmentPods) == 0 {
+		return
+	}
+
+	// Pools are listed directly since a pod being cloned has a pool before it has VMs
+	pools, err := cs.ProxmoxService.GetPools()
+	if err != nil {
+		log.Printf("Segment sweep failed to get pools: %v", err)
+		return
+	}
+
+	for _, pod := range segmentPods {
+		if slices.Contains(pools, pod) {
+			continue
+		}
+		log.Printf("Retrying removal of segments of deleted pod %s", pod)
+		cs.removePodSegments(pod)
+	}
+}
+
+// attachPodNetworks attaches the VMs of a pod to the pod VNet and moves NICs that the
+// template put on a segment bridge to the pod's VNet for that segment. VMs fresh from the
+// template still have the template bridges, which SetPodVnet overwrites, so the segment
+// NICs are found first.
+func (cs *CloningService) attachPodNetworks(pod string, podNumber int, routerVMID int) error {
+	vnet, err := cs.podVNet(pod, podNumber)
+	if err != nil {
+		return err
+	}
+
+	template, err := cs.podTemplateNetworks(pod)
+	if err != nil {
+		return err
+	}
+
+	podSegments, err := cs.DatabaseService.GetPodSegments(pod)
+	if err != nil {
+		return err
+	}
+
+	// Template bridge to the pod VNet of its segment
+	segmentVNets := map[string]string{}
+	for _, segment := range template.Segments {
+		i := slices.IndexFunc(podSegments, func(s PodSegment) bool { return strings.EqualFold(s.Alias, segment.Alias) })
+		if i >= 0 {
+			segmentVNets[segment.Bridge] = podSegments[i].VNet
+		}
+	}
+
+	type segmentNIC struct {
+		vm   proxmox.VirtualResource
+		nic  string
+		vnet string
+	}
+	var moves []segmentNIC
+	if len(segmentVNets) > 0 {
+		poolVMs, err := cs.podGuests(pod)
+		if err != nil {
+			return err
+		}
+
+		for _, vm := range poolVMs {
+			config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+			if err != nil {
+				return fmt.Errorf("failed to read config of VM %s: %w", vm.Name, err)
+			}
+
+			for key, value := range config {
+				definition, ok := value.(string)
+				if !ok || !strings.HasPrefix(key, "net") {
+					continue
+				}
+				if match := nicBridgePattern.FindStringSubmatch(definition); match != nil && segmentVNets[match[1]] != "" {
+					moves = append(moves, segmentNIC{vm: vm, nic: key, vnet: segmentVNets[match[1]]})
+				}
+			}
+		}
+	}
+
+	if err := cs.ProxmoxService.SetPodVnet(pod, vnet, routerVMID); err != nil {
+		return err
+	}
+
+	for _, move := range moves {
+		if err := cs.ProxmoxService.SetGuestBridge(move.vm, move.nic, move.vnet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// podSegmentVNets returns the names of a pod's segment VNets
+func (cs *CloningService) podSegmentVNets(pod string) ([]string, error) {
+	segments, err := cs.DatabaseService.GetPodSegments(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	var vnets []string
+	for _, segment := range segments {
+		vnets = append(vnets, segment.VNet)
+	}
+	return vnets, nil
+}
+
+// podRouterLAN returns the LAN layout the router of a pod is configured for: the LAN
+// subnets of its template, and the subnet of each segment the router has a NIC on
+func (cs *CloningService) podRouterLAN(pod string, node string, vmid int) (proxmox.RouterLAN, error) {
+	template, err := cs.podTemplateNetworks(pod)
+	if err != nil {
+		return proxmox.RouterLAN{}, err
+	}
+
+	lan := proxmox.RouterLAN{Subnets: template.LANSubnets}
+	if len(template.Segments) == 0 {
+		return lan, nil
+	}
+
+	podSegments, err := cs.DatabaseService.GetPodSegments(pod)
+	if err != nil {
+		return lan, err
+	}
+
+	config, err := cs.ProxmoxService.GetVMConfigValues(node, vmid)
+	if err != nil {
+		return lan, fmt.Errorf("failed to read router config: %w", err)
+	}
+
+	for _, segment := range template.Segments {
+		i := slices.IndexFunc(podSegments, func(s PodSegment) bool { return strings.EqualFold(s.Alias, segment.Alias) })
+		if segment.Subnet == "" || i < 0 {
+			continue
+		}
+
+		for key, value := range config {
+			definition, ok := value.(string)
+			if !ok || !strings.HasPrefix(key, "net") {
+				continue
+			}
+			nic, err := strconv.Atoi(strings.TrimPrefix(key, "net"))
+			if err != nil {
+				continue
+			}
+			if match := nicBridgePattern.FindStringSubmatch(definition); match != nil && match[1] == podSegments[i].VNet {
+				lan.Segments = append(lan.Segments, proxmox.RouterSegment{NIC: nic, Subnet: segment.Subnet})
+			}
+		}
+	}
+
+	return lan, nil
+}
+
+func buildPodSegments(rows *sql.Rows) ([]PodSegment, error) {
+	var segments []PodSegment
+	for rows.Next() {
+		var segment PodSegment
+		if err := rows.Scan(&segment.Pod, &segment.Alias, &segment.VNet, &segment.VLANTag, &segment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
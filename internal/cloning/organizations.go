@@ -0,0 +1,405 @@
+package cloning
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrNotOrganizationMember is returned when a clone target of an organization admin is not
+// part of the organization
+var ErrNotOrganizationMember = errors.New("not a member of the organization")
+
+// =================================================
+// Organization Database Operations
+// =================================================
+
+func (c *TemplateClient) GetOrganizations() ([]Organization, error) {
+	query := "SELECT name, description, member_group, admin_group, min_pod_id, max_pod_id, default_pod_quota FROM organizations ORDER BY name"
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	organizations := []Organization{}
+	for rows.Next() {
+		var org Organization
+		err := rows.Scan(&org.Name, &org.Description, &org.MemberGroup, &org.AdminGroup, &org.MinPodID, &org.MaxPodID, &org.DefaultPodQuota)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		organizations = append(organizations, org)
+	}
+
+	return organizations, nil
+}
+
+// GetOrganization returns the named organization, or nil when it does not exist
+func (c *TemplateClient) GetOrganization(name string) (*Organization, error) {
+	query := "SELECT name, description, member_group, admin_group, min_pod_id, max_pod_id, default_pod_quota FROM organizations WHERE name = ?"
+	var org Organization
+	err := c.DB.QueryRow(query, name).Scan(&org.Name, &org.Description, &org.MemberGroup, &org.AdminGroup, &org.MinPodID, &org.MaxPodID, &org.DefaultPodQuota)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return &org, nil
+}
+
+func (c *TemplateClient) SaveOrganization(org Organization) error {
+	query := `INSERT INTO organizations (name, description, member_group, admin_group, min_pod_id, max_pod_id, default_pod_quota)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE description = VALUES(description), member_group = VALUES(member_group),
+		admin_group = VALUES(admin_group), min_pod_id = VALUES(min_pod_id), max_pod_id = VALUES(max_pod_id),
+		default_pod_quota = VALUES(default_pod_quota)`
+	_, err := c.DB.Exec(query, org.Name, org.Description, org.MemberGroup, org.AdminGroup, org.MinPodID, org.MaxPodID, org.DefaultPodQuota)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOrganization removes an organization, returning its templates to the shared catalog
+func (c *TemplateClient) DeleteOrganization(name string) error {
+	if _, err := c.DB.Exec("UPDATE templates SET organization = '' WHERE organization = ?", name); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if _, err := c.DB.Exec("DELETE FROM organizations WHERE name = ?", name); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) SetTemplateOrganization(templateName string, organization string) error {
+	_, err := c.DB.Exec("UPDATE templates SET organization = ? WHERE name = ?", organization, templateName)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Organization Operations
+// =================================================
+
+// SetOrganization creates or updates an organization. A pod ID range, when set, must lie
+// within the instance's range and must not overlap the range of another organization.
+func (cs *CloningService) SetOrganization(org Organization) error {
+	if org.MinPodID != 0 || org.MaxPodID != 0 {
+		if org.MinPodID > org.MaxPodID {
+			return fmt.Errorf("min_pod_id %d is greater than max_pod_id %d", org.MinPodID, org.MaxPodID)
+		}
+		if org.MinPodID < cs.Config.MinPodID || org.MaxPodID > cs.Config.MaxPodID {
+			return fmt.Errorf("pod ID range %d-%d is outside of the instance range %d-%d", org.MinPodID, org.MaxPodID, cs.Config.MinPodID, cs.Config.MaxPodID)
+		}
+
+		organizations, err := cs.DatabaseService.GetOrganizations()
+		if err != nil {
+			return fmt.Errorf("failed to get organizations: %w", err)
+		}
+		for _, other := range organizations {
+			if other.Name == org.Name || (other.MinPodID == 0 && other.MaxPodID == 0) {
+				continue
+			}
+			if org.MinPodID <= other.MaxPodID && other.MinPodID <= org.MaxPodID {
+				return fmt.Errorf("pod ID range %d-%d overlaps organization %s (%d-%d)", org.MinPodID, org.MaxPodID, other.Name, other.MinPodID, other.MaxPodID)
+			}
+		}
+	}
+
+	return cs.DatabaseService.SaveOrganization(org)
+}
+
+// SetTemplateOrganization scopes a template to an organization, an empty organization
+// returns it to the shared catalog
+func (cs *CloningService) SetTemplateOrganization(templateName string, organization string) error {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return fmt.Errorf("template not found: %s", templateName)
+	}
+
+	if organization != "" {
+		org, err := cs.DatabaseService.GetOrganization(organization)
+		if err != nil {
+			return err
+		}
+		if org == nil {
+			return fmt.Errorf("organization not found: %s", organization)
+		}
+	}
+
+	return cs.DatabaseService.SetTemplateOrganization(templateName, organization)
+}
+
+// GetUserOrganizations returns the organizations the user is a member or admin of
+func (cs *CloningService) GetUserOrganizations(username string) ([]Organization, error) {
+	organizations, err := cs.DatabaseService.GetOrganizations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+	if len(organizations) == 0 {
+		return organizations, nil
+	}
+
+	groups, err := cs.userGroups(username)
+	if err != nil {
+		return nil, err
+	}
+
+	member := []Organization{}
+	for _, org := range organizations {
+		if containsFold(groups, org.MemberGroup) || containsFold(groups, org.AdminGroup) {
+			member = append(member, org)
+		}
+	}
+
+	return member, nil
+}
+
+// IsOrganizationAdmin reports whether the user belongs to the organization's admin group
+func (cs *CloningService) IsOrganizationAdmin(username string, organization string) (bool, error) {
+	org, err := cs.DatabaseService.GetOrganization(organization)
+	if err != nil {
+		return false, err
+	}
+	if org == nil || org.AdminGroup == "" {
+		return false, nil
+	}
+
+	groups, err := cs.userGroups(username)
+	if err != nil {
+		return false, err
+	}
+
+	return containsFold(groups, org.AdminGroup), nil
+}
+
+// ValidateOrganizationTargets checks that every target of an organization admin's clone
+// belongs to the organization, a user through its member or admin group and a group by
+// being one of them, and that each target passes ValidateCloneRequest
+func (cs *CloningService) ValidateOrganizationTargets(organization string, templateName string, targets []CloneTarget) error {
+	org, err := cs.DatabaseService.GetOrganization(organization)
+	if err != nil {
+		return err
+	}
+	if org == nil {
+		return fmt.Errorf("organization not found: %s", organization)
+	}
+
+	for _, target := range targets {
+		var member bool
+		if target.IsGroup {
+			member = target.Name != "" && (strings.EqualFold(target.Name, org.MemberGroup) || strings.EqualFold(target.Name, org.AdminGroup))
+		} else {
+			groups, err := cs.userGroups(target.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get groups of %s: %w", target.Name, err)
+			}
+			member = containsFold(groups, org.MemberGroup) || containsFold(groups, org.AdminGroup)
+		}
+		if !member {
+			return fmt.Errorf("%w: %s is not in organization %s", ErrNotOrganizationMember, target.Name, organization)
+		}
+
+		if err := cs.ValidateCloneRequest(templateName, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetTemplatesForUser returns the visible templates of the shared catalog and of the
+// organizations the user belongs to, leaving out deprecated templates and templates
+// restricted to other groups
+func (cs *CloningService) GetTemplatesForUser(username string) ([]KaminoTemplate, error) {
+	templates, err := cs.DatabaseService.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.templatesForUser(username, templates)
+}
+
+// SearchTemplatesForUser returns the templates matching a full-text query that the user
+// can see in GetTemplatesForUser, most relevant first
+func (cs *CloningService) SearchTemplatesForUser(username string, query string) ([]KaminoTemplate, error) {
+	templates, err := cs.DatabaseService.SearchTemplates(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.templatesForUser(username, templates)
+}
+
+// CanUseTemplate reports whether the template is not deprecated, is not a draft of another
+// user, is in the shared catalog or in one of the user's organizations, and the user is in
+// one of its allowed groups when it has any
+func (cs *CloningService) CanUseTemplate(username string, templateName string) (bool, error) {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.DeprecatedAt != "" || !templateAllowsUser(template, username) {
+		return false, nil
+	}
+
+	if template.Organization != "" {
+		organizations, err := cs.GetUserOrganizations(username)
+		if err != nil {
+			return false, err
+		}
+		if !templateInOrganizations(template, organizations) {
+			return false, nil
+		}
+	}
+
+	if len(template.AllowedGroups) > 0 {
+		groups, err := cs.userGroups(username)
+		if err != nil {
+			return false, err
+		}
+		return templateAllowsGroups(template, groups), nil
+	}
+
+	return true, nil
+}
+
+// GetOrganizationPods returns the deployed pods of the organization's templates
+func (cs *CloningService) GetOrganizationPods(organization string) ([]Pod, error) {
+	templates, err := cs.DatabaseService.GetPublishedTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, template := range templates {
+		if template.Organization == organization {
+			names = append(names, strings.ToLower(template.Name))
+		}
+	}
+
+	pods, err := cs.AdminGetPods()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(pods, func(pod Pod) bool {
+		return !slices.Contains(names, PodTemplateName(pod.Name))
+	}), nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// templatesForUser leaves out the templates GetTemplatesForUser hides from the user
+func (cs *CloningService) templatesForUser(username string, templates []KaminoTemplate) ([]KaminoTemplate, error) {
+	organizations, err := cs.GetUserOrganizations(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group membership is only looked up when a template restricts access by group
+	var groups []string
+	if slices.ContainsFunc(templates, func(template KaminoTemplate) bool { return len(template.AllowedGroups) > 0 }) {
+		groups, err = cs.userGroups(username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return slices.DeleteFunc(templates, func(template KaminoTemplate) bool {
+		return template.DeprecatedAt != "" || !templateAllowsUser(template, username) ||
+			!templateInOrganizations(template, organizations) || !templateAllowsGroups(template, groups)
+	}), nil
+}
+
+// podIDRange returns the pod ID range a template deploys into, the range of its
+// organization when it has one and the instance range otherwise
+func (cs *CloningService) podIDRange(templateName string) (int, int, error) {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get template info: %w", err)
+	}
+
+	if template.Organization != "" {
+		org, err := cs.DatabaseService.GetOrganization(template.Organization)
+		if err != nil {
+			return 0, 0, err
+		}
+		if org != nil && (org.MinPodID != 0 || org.MaxPodID != 0) {
+			return org.MinPodID, org.MaxPodID, nil
+		}
+	}
+
+	return cs.Config.MinPodID, cs.Config.MaxPodID, nil
+}
+
+// organizationPodQuota returns the largest default pod quota of the user's organizations,
+// or -1 when none of them sets one. A zero default quota is unset.
+func (cs *CloningService) organizationPodQuota(username string) (int, string, error) {
+	organizations, err := cs.GetUserOrganizations(username)
+	if err != nil {
+		return 0, "", err
+	}
+
+	best := -1
+	source := ""
+	for _, org := range organizations {
+		if org.DefaultPodQuota > 0 && org.DefaultPodQuota > best {
+			best = org.DefaultPodQuota
+			source = fmt.Sprintf("organization %s", org.Name)
+		}
+	}
+
+	return best, source, nil
+}
+
+func (cs *CloningService) userGroups(username string) ([]string, error) {
+	userDN, err := cs.LDAPService.GetUserDN(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user DN: %w", err)
+	}
+
+	groups, err := cs.LDAPService.GetUserGroups(userDN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+func templateInOrganizations(template KaminoTemplate, organizations []Organization) bool {
+	if template.Organization == "" {
+		return true
+	}
+
+	return slices.ContainsFunc(organizations, func(org Organization) bool { return org.Name == template.Organization })
+}
+
+// templateAllowsUser reports whether the template is published or a draft of the user
+func templateAllowsUser(template KaminoTemplate, username string) bool {
+	return !template.Draft || strings.EqualFold(template.PublishedBy, username)
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
@@ -0,0 +1,280 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// validatePinnedNodes checks that every pinned node exists and that the pinned nodes
+// together have enough free memory for the deployment. It returns warnings for
+// nodes that would be pushed past the configured pinning thresholds.
+func (cs *CloningService) validatePinnedNodes(nodes []string, templatePool []proxmox.VirtualResource, numTargets int) ([]string, error) {
+	usage, err := cs.ProxmoxService.GetClusterResourceUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster resource usage: %w", err)
+	}
+
+	var pinned []proxmox.NodeResourceUsage
+	for _, name := range nodes {
+		index := slices.IndexFunc(usage.Nodes, func(node proxmox.NodeResourceUsage) bool {
+			return node.Name == name
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("pinned node %s is not an available cluster node", name)
+		}
+		pinned = append(pinned, usage.Nodes[index])
+	}
+
+	// Estimate the memory the deployment will need from the template VMs
+	var memoryPerTarget int64
+	for _, vm := range templatePool {
+		memoryPerTarget += int64(vm.MaxMem)
+	}
+	memoryNeeded := memoryPerTarget * int64(numTargets)
+
+	var memoryFree, memoryTotal, memoryUsed int64
+	var warnings []string
+	for _, node := range pinned {
+		memoryTotal += node.Resources.MemoryTotal
+		memoryUsed += node.Resources.MemoryUsed
+		memoryFree += node.Resources.MemoryTotal - node.Resources.MemoryUsed
+
+		if node.Resources.CPUUsage > cs.Config.PinnedCPUThreshold {
+			warnings = append(warnings, fmt.Sprintf("node %s CPU usage is %.0f%%, above the %.0f%% threshold",
+				node.Name, node.Resources.CPUUsage*100, cs.Config.PinnedCPUThreshold*100))
+		}
+	}
+
+	if memoryNeeded > memoryFree {
+		return nil, fmt.Errorf("pinned nodes %v have %d bytes of free memory but the deployment needs %d bytes", nodes, memoryFree, memoryNeeded)
+	}
+
+	if memoryTotal > 0 {
+		projected := float64(memoryUsed+memoryNeeded) / float64(memoryTotal)
+		if projected > cs.Config.PinnedMemoryThreshold {
+			warnings = append(warnings, fmt.Sprintf("deployment would raise memory usage on pinned nodes to %.0f%%, above the %.0f%% threshold",
+				projected*100, cs.Config.PinnedMemoryThreshold*100))
+		}
+	}
+
+	for _, warning := range warnings {
+		log.Printf("Warning: %s", warning)
+	}
+
+	return warnings, nil
+}
+
+// selectTargetNode picks the node to clone a target to, restricted to the pinned
+// nodes of the request when any are set
+func (cs *CloningService) selectTargetNode(req CloneRequest) (string, error) {
+	if req.TargetNode != "" {
+		return req.TargetNode, nil
+	}
+	if len(req.Nodes) > 0 {
+		return cs.ProxmoxService.FindBestNodeIn(req.Nodes)
+	}
+	return cs.ProxmoxService.FindBestNode()
+}
+
+// placementNodes returns the nodes a request restricts placement to, the target node
+// override taking precedence over pinned nodes
+func (req CloneRequest) placementNodes() []string {
+	if req.TargetNode != "" {
+		return []string{req.TargetNode}
+	}
+	return req.Nodes
+}
+
+// =================================================
+// Placement Rule Database Operations
+// =================================================
+
+func (c *TemplateClient) GetPlacementRules(templateName string) ([]PlacementRule, error) {
+	rows, err := c.DB.Query("SELECT rule_type, vm_names FROM template_placement_rules WHERE template = ? ORDER BY id", templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []PlacementRule{}
+	for rows.Next() {
+		var rule PlacementRule
+		var vmNames string
+		if err := rows.Scan(&rule.Type, &vmNames); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		rule.VMNames = strings.Split(vmNames, ",")
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// SetPlacementRules replaces the placement rules of a template
+func (c *TemplateClient) SetPlacementRules(templateName string, rules []PlacementRule) error {
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM template_placement_rules WHERE template = ?", templateName); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	for _, rule := range rules {
+		query := "INSERT INTO template_placement_rules (template, rule_type, vm_names) VALUES (?, ?, ?)"
+		if _, err := tx.Exec(query, templateName, rule.Type, strings.Join(rule.VMNames, ",")); err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Placement Rule Operations
+// =================================================
+
+// SetTemplatePlacementRules validates the rules against the VMs of the template pool and
+// replaces the template's placement rules
+func (cs *CloningService) SetTemplatePlacementRules(templateName string, rules []PlacementRule) error {
+	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template pool: %w", err)
+	}
+
+	for _, rule := range rules {
+		if rule.Type != PlacementSpread && rule.Type != PlacementTogether {
+			return fmt.Errorf("unknown placement rule type: %s", rule.Type)
+		}
+		if len(rule.VMNames) < 2 {
+			return fmt.Errorf("a %s rule needs at least two VMs", rule.Type)
+		}
+		for _, name := range rule.VMNames {
+			if !slices.ContainsFunc(templatePool, func(vm proxmox.VirtualResource) bool { return vm.Name == name }) {
+				return fmt.Errorf("VM %s not found in template %s", name, templateName)
+			}
+			if routerPattern.MatchString(name) {
+				return fmt.Errorf("the router cannot be part of a placement rule")
+			}
+		}
+	}
+
+	return cs.DatabaseService.SetPlacementRules(templateName, rules)
+}
+
+// vmPlacement holds what cloneTarget needs to apply a template's placement rules
+type vmPlacement struct {
+	rules []PlacementRule
+	nodes []string // Nodes spread rules may use
+}
+
+// resolveVMPlacement loads the template's placement rules, returning nil when VMs should
+// simply follow their target's node
+func (cs *CloningService) resolveVMPlacement(req CloneRequest) (*vmPlacement, error) {
+	if req.TargetNode != "" {
+		return nil, nil
+	}
+
+	rules, err := cs.DatabaseService.GetPlacementRules(req.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placement rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	placement := &vmPlacement{rules: rules, nodes: req.Nodes}
+	if len(placement.nodes) == 0 {
+		usage, err := cs.ProxmoxService.GetClusterResourceUsage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster resource usage: %w", err)
+		}
+		for _, node := range usage.Nodes {
+			placement.nodes = append(placement.nodes, node.Name)
+		}
+	}
+
+	return placement, nil
+}
+
+// assignVMNodes returns the node each template VM of a target is cloned to. VMs default
+// to the target's node, spread rules move their VMs to distinct nodes, and together
+// rules then gather their VMs on the node of the first one.
+func (cs *CloningService) assignVMNodes(placement *vmPlacement, templateVMs []proxmox.VM, targetNode string) []string {
+	nodes := make([]string, len(templateVMs))
+	for i := range nodes {
+		nodes[i] = targetNode
+	}
+	if placement == nil {
+		return nodes
+	}
+
+	index := make(map[string]int)
+	for i, vm := range templateVMs {
+		index[vm.Name] = i
+	}
+
+	for _, rule := range placement.rules {
+		if rule.Type != PlacementSpread {
+			continue
+		}
+
+		used := []string{targetNode}
+		first := true
+		for _, name := range rule.VMNames {
+			i, ok := index[name]
+			if !ok {
+				continue
+			}
+			if first {
+				first = false
+				continue
+			}
+
+			candidates := slices.DeleteFunc(slices.Clone(placement.nodes), func(node string) bool {
+				return slices.Contains(used, node)
+			})
+			// More VMs than nodes, start another round across every node
+			if len(candidates) == 0 {
+				used = nil
+				candidates = placement.nodes
+			}
+
+			node, err := cs.ProxmoxService.FindBestNodeIn(candidates)
+			if err != nil {
+				log.Printf("Failed to find a node to spread VM %s to, keeping it on %s: %v", name, targetNode, err)
+				continue
+			}
+			nodes[i] = node
+			used = append(used, node)
+		}
+	}
+
+	for _, rule := range placement.rules {
+		if rule.Type != PlacementTogether {
+			continue
+		}
+
+		anchor := ""
+		for _, name := range rule.VMNames {
+			if i, ok := index[name]; ok {
+				if anchor == "" {
+					anchor = nodes[i]
+				}
+				nodes[i] = anchor
+			}
+		}
+	}
+
+	return nodes
+}
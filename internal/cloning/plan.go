@@ -0,0 +1,197 @@
+package cloning
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// PlanCloneTemplate computes the resources a clone request needs and the pod IDs and
+// VMIDs it would use, and checks them against current cluster capacity. It only
+// reads from Proxmox and never creates pools or VMs.
+func (cs *CloningService) PlanCloneTemplate(req CloneRequest) (*ClonePlan, error) {
+	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + req.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template pool: %w", err)
+	}
+
+	templatePool, err = selectTemplateVMs(templatePool, req.VMNames)
+	if err != nil {
+		return nil, err
+	}
+
+	templateInfo, err := cs.DatabaseService.GetTemplateInfo(req.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template info: %w", err)
+	}
+	cloneMode := req.CloneMode
+	if cloneMode == "" {
+		cloneMode = templateInfo.CloneMode
+	}
+
+	plan := &ClonePlan{
+		Template:  req.Template,
+		CloneMode: cloneMode,
+		Problems:  []string{},
+	}
+
+	plan.Storage, err = cs.resolveCloneStorage(req, templateInfo, cloneMode)
+	if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+	plan.HAGroup, err = cs.resolveHAGroup(req, templateInfo)
+	if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+
+	for _, vm := range templatePool {
+		if vm.IsGuest() && !routerPattern.MatchString(vm.Name) {
+			plan.VMsPerTarget++
+		}
+	}
+
+	if plan.VMsPerTarget == 0 {
+		return nil, fmt.Errorf("template pool %s contains no VMs", req.Template)
+	}
+	plan.VMsPerTarget++ // +1 for router
+
+	// Sum the resources of one pod, a missing default router leaves it out of the sum
+	perTarget, err := cs.templateFootprint(templatePool, cloneMode)
+	if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+	plan.PerTarget = perTarget
+
+	plan.Required = ResourceRequirement{
+		VCPUs:       perTarget.VCPUs * len(req.Targets),
+		MemoryBytes: perTarget.MemoryBytes * int64(len(req.Targets)),
+		DiskBytes:   perTarget.DiskBytes * int64(len(req.Targets)),
+	}
+
+	// Check existing deployments and quotas for each target
+	if req.CheckExistingDeployments {
+		for _, target := range req.Targets {
+			if err := cs.ValidateCloneRequest(req.Template, target); err != nil {
+				var limitErr *DeploymentLimitError
+				if !errors.As(err, &limitErr) {
+					return nil, err
+				}
+				plan.Problems = append(plan.Problems, limitErr.Error())
+			}
+		}
+	}
+
+	// Compare against current cluster capacity
+	usage, err := cs.ProxmoxService.GetClusterResourceUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster resource usage: %w", err)
+	}
+	plan.Available = ResourceAvailability{
+		MemoryBytes:  usage.Total.MemoryTotal - usage.Total.MemoryUsed,
+		StorageBytes: usage.Total.StorageTotal - usage.Total.StorageUsed,
+		CPUUsage:     usage.Total.CPUUsage,
+	}
+
+	if plan.Required.MemoryBytes > plan.Available.MemoryBytes {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("deployment needs %d bytes of memory but only %d bytes are free", plan.Required.MemoryBytes, plan.Available.MemoryBytes))
+	}
+	if plan.Required.DiskBytes > plan.Available.StorageBytes {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("deployment needs %d bytes of storage but only %d bytes are free", plan.Required.DiskBytes, plan.Available.StorageBytes))
+	}
+
+	if nodes := req.placementNodes(); len(nodes) > 0 {
+		warnings, err := cs.validatePinnedNodes(nodes, templatePool, len(req.Targets))
+		if err != nil {
+			plan.Problems = append(plan.Problems, err.Error())
+		}
+		plan.Warnings = warnings
+	}
+
+	// Preview the pod IDs and VMIDs the deployment would be assigned
+	minPodID, maxPodID, err := cs.podIDRange(req.Template)
+	if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+		minPodID, maxPodID = cs.Config.MinPodID, cs.Config.MaxPodID
+	}
+	podIDs, podNumbers, err := cs.ProxmoxService.GetNextPodIDs(minPodID, maxPodID, len(req.Targets))
+	if err != nil {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("failed to get next pod IDs: %v", err))
+	} else {
+		var vmIDs []int
+		numVMs := len(req.Targets) * plan.VMsPerTarget
+		if req.StartingVMID != 0 {
+			for i := range numVMs {
+				vmIDs = append(vmIDs, req.StartingVMID+i)
+			}
+		} else {
+			vmIDs, err = cs.nextVMIDs(req.Template, numVMs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get next VM IDs: %w", err)
+			}
+		}
+
+		for i, target := range req.Targets {
+			target.PoolName = fmt.Sprintf("%s_%s_%s", podIDs[i], req.Template, target.Name)
+			target.PodID = podIDs[i]
+			target.PodNumber = podNumbers[i]
+			target.VMIDs = vmIDs[i*plan.VMsPerTarget : (i+1)*plan.VMsPerTarget]
+			plan.Targets = append(plan.Targets, target)
+		}
+	}
+
+	plan.Feasible = len(plan.Problems) == 0
+	return plan, nil
+}
+
+// templateFootprint sums the resources one deployment of the template pool uses, adding
+// the default router when the pool has none. When the default router can't be found the
+// sum without it is returned along with the error.
+func (cs *CloningService) templateFootprint(templatePool []proxmox.VirtualResource, cloneMode string) (ResourceRequirement, error) {
+	var footprint ResourceRequirement
+	hasRouter := false
+	for _, vm := range templatePool {
+		if !vm.IsGuest() {
+			continue
+		}
+		if routerPattern.MatchString(vm.Name) {
+			hasRouter = true
+		}
+		footprint.add(vm, cloneMode)
+	}
+
+	if !hasRouter {
+		router, err := cs.findDefaultRouter()
+		if err != nil {
+			return footprint, err
+		}
+		footprint.add(*router, cloneMode)
+	}
+
+	return footprint, nil
+}
+
+func (cs *CloningService) findDefaultRouter() (*proxmox.VirtualResource, error) {
+	resources, err := cs.ProxmoxService.GetClusterResources("type=vm")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster resources: %w", err)
+	}
+
+	for _, resource := range resources {
+		if resource.VmId == cs.Config.RouterVMID {
+			return &resource, nil
+		}
+	}
+
+	return nil, fmt.Errorf("default router VM %d not found", cs.Config.RouterVMID)
+}
+
+// add includes a VM in the requirement. Linked clones share the template's base
+// disk, so only full clones count the VM's disk.
+func (r *ResourceRequirement) add(vm proxmox.VirtualResource, cloneMode string) {
+	r.VCPUs += vm.MaxCPU
+	r.MemoryBytes += int64(vm.MaxMem)
+	if cloneMode == CloneModeFull {
+		r.DiskBytes += vm.MaxDisk
+	}
+}
@@ -0,0 +1,283 @@
+package cloning
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Pod access statuses
+const (
+	AccessStatusPending = "pending"
+	AccessStatusGranted = "granted"
+	AccessStatusFailed  = "failed"
+)
+
+// =================================================
+// Pod Access Status Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodAccessStatus(status PodAccessStatus) error {
+	query := `INSERT INTO pod_access_status (pod, target, is_group, status, attempts, error, retry_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE target = VALUES(target), is_group = VALUES(is_group), status = VALUES(status),
+		attempts = VALUES(attempts), error = VALUES(error), retry_until = VALUES(retry_until)`
+	_, err := c.DB.Exec(query, status.Pod, status.Target, status.IsGroup, status.Status, status.Attempts,
+		status.Error, status.RetryUntil.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodAccessStatuses() ([]PodAccessStatus, error) {
+	query := "SELECT pod, target, is_group, status, attempts, error, retry_until, updated_at FROM pod_access_status"
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []PodAccessStatus{}
+	for rows.Next() {
+		var status PodAccessStatus
+		var errMsg sql.NullString
+		err := rows.Scan(
+			&status.Pod,
+			&status.Target,
+			&status.IsGroup,
+			&status.Status,
+			&status.Attempts,
+			&errMsg,
+			&status.RetryUntil,
+			&status.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		status.Error = errMsg.String
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// GetPodAccessStatus returns the access status of a pod, or nil when it has none
+func (c *TemplateClient) GetPodAccessStatus(pod string) (*PodAccessStatus, error) {
+	query := "SELECT pod, target, is_group, status, attempts, error, retry_until, updated_at FROM pod_access_status WHERE pod = ?"
+	var status PodAccessStatus
+	var errMsg sql.NullString
+	err := c.DB.QueryRow(query, pod).Scan(
+		&status.Pod,
+		&status.Target,
+		&status.IsGroup,
+		&status.Status,
+		&status.Attempts,
+		&errMsg,
+		&status.RetryUntil,
+		&status.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	status.Error = errMsg.String
+
+	return &status, nil
+}
+
+// UpdatePodAccessStatus updates the access status of a pod without recreating it, and
+// reports whether the pod still had one
+func (c *TemplateClient) UpdatePodAccessStatus(status PodAccessStatus) (bool, error) {
+	query := `UPDATE pod_access_status SET target = ?, is_group = ?, status = ?, attempts = ?, error = ?,
+		retry_until = ? WHERE pod = ?`
+	result, err := c.DB.Exec(query, status.Target, status.IsGroup, status.Status, status.Attempts,
+		status.Error, status.RetryUntil.UTC(), status.Pod)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// RenamePodAccessStatus moves a pod's access status to its new pool, resolving it since
+// the new owner is granted access as part of the transfer
+func (c *TemplateClient) RenamePodAccessStatus(pod string, newPod string, target string, isGroup bool) error {
+	query := "UPDATE pod_access_status SET pod = ?, target = ?, is_group = ?, status = ?, error = NULL WHERE pod = ?"
+	_, err := c.DB.Exec(query, newPod, target, isGroup, AccessStatusGranted, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodAccessStatus(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_access_status WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Deferred Pool Permissions
+// =================================================
+
+// applyPoolPermission grants the target access to its pod, retrying with exponential
+// backoff for up to the configured number of attempts
+func (cs *CloningService) applyPoolPermission(target CloneTarget) error {
+	backoff := 2 * time.Second
+	attempts := max(cs.Config.PermissionRetryAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = cs.ProxmoxService.SetPoolPermission(target.PoolName, target.Name, target.IsGroup)
+		if err == nil {
+			return nil
+		}
+
+		if attempt < attempts {
+			log.Printf("Failed to set pool permission on %s for %s (attempt %d/%d), retrying in %v: %v",
+				target.PoolName, target.Name, attempt, attempts, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return fmt.Errorf("failed to set pool permission after %d attempts: %w", attempts, err)
+}
+
+// deferPoolPermission marks the pod's access as pending and keeps retrying the pool
+// permission in the background instead of failing the deployment
+func (cs *CloningService) deferPoolPermission(target CloneTarget, cause error) {
+	status := PodAccessStatus{
+		Pod:        target.PoolName,
+		Target:     target.Name,
+		IsGroup:    target.IsGroup,
+		Status:     AccessStatusPending,
+		Attempts:   max(cs.Config.PermissionRetryAttempts, 1),
+		Error:      cause.Error(),
+		RetryUntil: time.Now().Add(cs.Config.PermissionRetryWindow),
+	}
+
+	if err := cs.DatabaseService.SavePodAccessStatus(status); err != nil {
+		log.Printf("Failed to record pending access for pod %s: %v", status.Pod, err)
+	}
+
+	log.Printf("Pool permission for pod %s deferred until %s: %v", status.Pod, status.RetryUntil.Format(time.RFC3339), cause)
+	go cs.retryPoolPermission(status)
+}
+
+// resumePendingAccess restarts retries for pod permissions left pending by a previous run of the server
+func (cs *CloningService) resumePendingAccess() {
+	statuses, err := cs.DatabaseService.GetPodAccessStatuses()
+	if err != nil {
+		log.Printf("Failed to get pending pod access: %v", err)
+		return
+	}
+
+	for _, status := range statuses {
+		if status.Status == AccessStatusPending {
+			go cs.retryPoolPermission(status)
+		}
+	}
+}
+
+// retryPoolPermission grants the pod's owner access until it succeeds or the retry window
+// ends. It stops early once the pod's status row is gone or the pod has a different owner,
+// since the pod was deleted or transferred.
+func (cs *CloningService) retryPoolPermission(status PodAccessStatus) {
+	for time.Now().Before(status.RetryUntil) {
+		time.Sleep(cs.Config.PermissionRetryInterval)
+
+		current, err := cs.accessRetryCurrent(&status)
+		if err != nil {
+			log.Printf("Skipping pool permission retry for pod %s: %v", status.Pod, err)
+			continue
+		}
+		if !current {
+			return
+		}
+
+		status.Attempts++
+		err = cs.ProxmoxService.SetPoolPermission(status.Pod, status.Target, status.IsGroup)
+		if err == nil {
+			status.Status = AccessStatusGranted
+			status.Error = ""
+			log.Printf("Pool permission for pod %s applied after %d attempts", status.Pod, status.Attempts)
+			cs.updateAccessRetry(status)
+			return
+		}
+
+		status.Error = err.Error()
+		if !cs.updateAccessRetry(status) {
+			return
+		}
+	}
+
+	status.Status = AccessStatusFailed
+	log.Printf("Giving up on pool permission for pod %s after %d attempts: %s", status.Pod, status.Attempts, status.Error)
+	cs.updateAccessRetry(status)
+}
+
+// accessRetryCurrent reloads the pod's access status and reports whether the retry should
+// continue, which is while the row is still pending and its target still owns the pod
+func (cs *CloningService) accessRetryCurrent(status *PodAccessStatus) (bool, error) {
+	current, err := cs.DatabaseService.GetPodAccessStatus(status.Pod)
+	if err != nil {
+		return false, fmt.Errorf("failed to get access status: %w", err)
+	}
+	if current == nil || current.Status != AccessStatusPending {
+		log.Printf("Stopping pool permission retries for pod %s, its access status was removed or resolved", status.Pod)
+		return false, nil
+	}
+	if current.Target != status.Target || current.IsGroup != status.IsGroup || !PodOwnedBy(current.Pod, current.Target) {
+		log.Printf("Stopping pool permission retries for pod %s, it is no longer owned by %s", status.Pod, status.Target)
+		return false, nil
+	}
+
+	current.Attempts = max(current.Attempts, status.Attempts)
+	*status = *current
+	return true, nil
+}
+
+// updateAccessRetry records a retry's progress and reports whether the pod still has a status row
+func (cs *CloningService) updateAccessRetry(status PodAccessStatus) bool {
+	updated, err := cs.DatabaseService.UpdatePodAccessStatus(status)
+	if err != nil {
+		log.Printf("Failed to update access status for pod %s: %v", status.Pod, err)
+		return true
+	}
+	if !updated {
+		log.Printf("Stopping pool permission retries for pod %s, its access status was removed", status.Pod)
+	}
+	return updated
+}
+
+// podAccessStatuses returns the access status of every pod whose pool permission needed deferred application
+func (cs *CloningService) podAccessStatuses() map[string]string {
+	statuses, err := cs.DatabaseService.GetPodAccessStatuses()
+	if err != nil {
+		log.Printf("Failed to get pod access statuses: %v", err)
+		return nil
+	}
+
+	byPod := make(map[string]string)
+	for _, status := range statuses {
+		byPod[status.Pod] = status.Status
+	}
+	return byPod
+}
@@ -2,10 +2,15 @@ package cloning
 
 import (
 	"fmt"
+	"log"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/events"
 )
 
 func (cs *CloningService) GetPods(username string) ([]Pod, error) {
@@ -21,18 +26,63 @@ func (cs *CloningService) GetPods(username string) ([]Pod, error) {
 		return nil, fmt.Errorf("failed to get user groups: %w", err)
 	}
 
+	// Get pods shared with the user by other owners
+	shares, err := cs.DatabaseService.GetSharesForUser(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared pods: %w", err)
+	}
+
+	sharedAccess := make(map[string]string)
+	var sharedPods []string
+	for _, share := range shares {
+		sharedAccess[share.Pod] = share.Access
+		sharedPods = append(sharedPods, regexp.QuoteMeta(share.Pod))
+	}
+
 	// Build regex pattern to match username or any of their group names
 	groupsWithUser := append(groups, username)
 	regexPattern := fmt.Sprintf(`(?i)1[0-9]{3}_.*_(%s)$`, strings.Join(groupsWithUser, "|"))
+	if len(sharedPods) > 0 {
+		regexPattern = fmt.Sprintf(`(?i)(1[0-9]{3}_.*_(%s)|^(%s))$`, strings.Join(groupsWithUser, "|"), strings.Join(sharedPods, "|"))
+	}
 
 	// Get pods based on regex pattern
 	pods, err := cs.MapVirtualResourcesToPods(regexPattern)
 	if err != nil {
 		return nil, err
 	}
+
+	for i := range pods {
+		pods[i].SharedAccess = sharedAccess[pods[i].Name]
+	}
 	return pods, nil
 }
 
+// CanAccessPod reports whether the pod belongs to the user or one of their groups
+func (cs *CloningService) CanAccessPod(username string, pod string) (bool, error) {
+	if PodOwnedBy(pod, username) {
+		return true, nil
+	}
+
+	userDN, err := cs.LDAPService.GetUserDN(username)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user DN: %w", err)
+	}
+
+	groups, err := cs.LDAPService.GetUserGroups(userDN)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user groups: %w", err)
+	}
+
+	for _, group := range groups {
+		if PodOwnedBy(pod, group) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (cs *CloningService) AdminGetPods() ([]Pod, error) {
 	pods, err := cs.MapVirtualResourcesToPods(`1[0-9]{3}_.*`)
 	if err != nil {
@@ -41,6 +91,48 @@ func (cs *CloningService) AdminGetPods() ([]Pod, error) {
 	return pods, nil
 }
 
+// FilterPods returns the deployed pods that match every set field of the filter
+func (cs *CloningService) FilterPods(filter PodFilter) ([]Pod, error) {
+	pods, err := cs.AdminGetPods()
+	if err != nil {
+		return nil, err
+	}
+
+	var created map[string]time.Time
+	if filter.MinAge > 0 {
+		created, err = cs.DatabaseService.GetPodCreationTimes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod creation times: %w", err)
+		}
+	}
+
+	matched := []Pod{}
+	for _, pod := range pods {
+		if filter.Template != "" && !strings.EqualFold(PodTemplateName(pod.Name), filter.Template) {
+			continue
+		}
+		if filter.Owner != "" && !PodOwnedBy(pod.Name, filter.Owner) {
+			continue
+		}
+		if filter.MinAge > 0 {
+			createdAt, ok := created[pod.Name]
+			if !ok || time.Since(createdAt) < filter.MinAge {
+				continue
+			}
+		}
+		if filter.MinPodID > 0 || filter.MaxPodID > 0 {
+			podID, err := strconv.Atoi(pod.Name[:4])
+			if err != nil || (filter.MinPodID > 0 && podID < filter.MinPodID) || (filter.MaxPodID > 0 && podID > filter.MaxPodID) {
+				continue
+			}
+		}
+		matched = append(matched, pod)
+	}
+
+	slices.SortFunc(matched, func(a, b Pod) int { return strings.Compare(a.Name, b.Name) })
+	return matched, nil
+}
+
 func (cs *CloningService) MapVirtualResourcesToPods(regex string) ([]Pod, error) {
 	// Get cluster resources
 	resources, err := cs.ProxmoxService.GetClusterResources("")
@@ -60,7 +152,7 @@ func (cs *CloningService) MapVirtualResourcesToPods(regex string) ([]Pod, error)
 				VMs:  []proxmox.VirtualResource{},
 			}
 		}
-		if r.Type == "qemu" && reg.MatchString(r.ResourcePool) {
+		if r.IsGuest() && reg.MatchString(r.ResourcePool) {
 			if pod, ok := podMap[r.ResourcePool]; ok {
 				pod.VMs = append(pod.VMs, r)
 			}
@@ -68,36 +160,345 @@ func (cs *CloningService) MapVirtualResourcesToPods(regex string) ([]Pod, error)
 	}
 
 	// Convert map to slice
+	routerStatuses := cs.podRouterStatuses()
+	accessStatuses := cs.podAccessStatuses()
+	archived := cs.archivedPods()
+	retained := cs.retainedPods()
+	repairable := cs.repairablePods()
+	activity := cs.podLastActivity()
 	var pods []Pod
 	for _, pod := range podMap {
+		pod.RouterStatus = routerStatuses[pod.Name]
+		pod.AccessStatus = accessStatuses[pod.Name]
+		pod.Archived = archived[pod.Name]
+		pod.Retained = retained[pod.Name]
+		pod.Repairable = repairable[pod.Name]
+		pod.LastActivity = activity[pod.Name]
 		pods = append(pods, *pod)
 	}
 
 	return pods, nil
 }
 
-func (cs *CloningService) ValidateCloneRequest(templateName string, username string) (bool, error) {
+// ValidateCloneRequest checks that deployments are not blacked out and that the target
+// does not already have the template deployed and is within its pod quota. Rule violations are returned as a
+// *DeploymentLimitError describing which limit was hit.
+func (cs *CloningService) ValidateCloneRequest(templateName string, target CloneTarget) error {
+	if err := cs.CheckDeploymentWindow(target.Name, time.Now()); err != nil {
+		return err
+	}
+
 	podPools, err := cs.AdminGetPods()
 	if err != nil {
-		return false, fmt.Errorf("failed to get deployed pods: %w", err)
+		return fmt.Errorf("failed to get deployed pods: %w", err)
 	}
 
-	var alreadyDeployed = false
-	var numDeployments = 0
+	targetPoolName := fmt.Sprintf("%s_%s", templateName, target.Name)
+	numDeployments := 0
 
 	for _, pod := range podPools {
+		// Retained pods are kept for the record and do not count as deployments
+		if pod.Retained {
+			continue
+		}
+
 		// Remove the Pod ID number and _ to compare
-		if !alreadyDeployed && strings.EqualFold(pod.Name[5:], templateName) {
-			alreadyDeployed = true
+		if strings.EqualFold(pod.Name[5:], targetPoolName) {
+			return &DeploymentLimitError{
+				Target: target.Name,
+				Reason: fmt.Sprintf("template %s is already deployed (pod %s)", templateName, pod.Name),
+			}
 		}
 
-		if strings.Contains(strings.ToLower(pod.Name), strings.ToLower(username)) {
+		if PodOwnedBy(pod.Name, target.Name) {
 			numDeployments++
 		}
 	}
 
-	// Valid if not already deployed and user has less than 5 deployments
-	var isValidCloneRequest = !alreadyDeployed && numDeployments < 5
+	maxPods, source, err := cs.GetEffectivePodQuota(target.Name, target.IsGroup)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.checkTemplateDeploymentLimit(templateName, podPools, 1); err != nil {
+		return err
+	}
+
+	if numDeployments >= maxPods {
+		return &DeploymentLimitError{
+			Target: target.Name,
+			Reason: fmt.Sprintf("%d of %d pods already deployed (%s)", numDeployments, maxPods, source),
+		}
+	}
+
+	return nil
+}
+
+// checkTemplateDeploymentLimit verifies the template's maximum concurrent deployments
+// leaves room for the requested number of new pods. Retained pods and warm pods do not
+// count as deployments.
+func (cs *CloningService) checkTemplateDeploymentLimit(templateName string, pods []Pod, requested int) error {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.MaxDeployments <= 0 {
+		return nil
+	}
+
+	deployed := 0
+	for _, pod := range pods {
+		if pod.Retained || !strings.EqualFold(PodTemplateName(pod.Name), templateName) {
+			continue
+		}
+		if cs.Config.WarmPoolOwner != "" && PodOwnedBy(pod.Name, cs.Config.WarmPoolOwner) {
+			continue
+		}
+		deployed++
+	}
+
+	if deployed+requested > template.MaxDeployments {
+		return &DeploymentLimitError{
+			Target: templateName,
+			Reason: fmt.Sprintf("%d of %d concurrent deployments of the template are in use", deployed, template.MaxDeployments),
+		}
+	}
+
+	return nil
+}
+
+// PodOwnedBy reports whether a pool named <podID>_<template>_<owner> belongs to owner
+func PodOwnedBy(podName string, owner string) bool {
+	return strings.HasSuffix(strings.ToLower(podName), "_"+strings.ToLower(owner))
+}
+
+// PodTemplateName returns the template segment of a pool named <podID>_<template>_<owner>
+func PodTemplateName(pod string) string {
+	parts := strings.SplitN(pod, "_", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// isPodPool reports whether a pool is named <podID>_<template>_<owner> with a pod ID in the
+// instance range, which holds the range of every organization
+func (cs *CloningService) isPodPool(pool string) bool {
+	parts := strings.SplitN(pool, "_", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return false
+	}
+
+	podID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+
+	return podID >= cs.Config.MinPodID && podID <= cs.Config.MaxPodID
+}
+
+// TransferPod reassigns a pod to a new owner. Proxmox pools cannot be renamed, so the
+// VMs are moved into a new pool named for the new owner, permissions are granted on
+// the new pool, and the old pool and its permission are removed.
+func (cs *CloningService) TransferPod(pod string, newOwner string, isGroup bool) (string, error) {
+	parts := strings.SplitN(pod, "_", 3)
+	if len(parts) != 3 {
+		return "", fmt.Errorf("pod %s does not follow the <id>_<template>_<owner> naming scheme", pod)
+	}
+	oldOwner := parts[2]
+	newPod := fmt.Sprintf("%s_%s_%s", parts[0], parts[1], newOwner)
+
+	if newPod == pod {
+		return "", fmt.Errorf("pod %s is already owned by %s", pod, newOwner)
+	}
+
+	// Transferring would grant the new owner access a retained pod is meant to be kept from
+	retention, err := cs.DatabaseService.GetPodRetention(pod)
+	if err != nil {
+		return "", err
+	}
+	if retention != nil {
+		return "", fmt.Errorf("pod %s is retained, restore it before transferring", pod)
+	}
+
+	oldIsGroup, err := cs.poolOwnerIsGroup(pod, oldOwner)
+	if err != nil {
+		return "", err
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	var vmIDs []int
+	for _, vm := range poolVMs {
+		vmIDs = append(vmIDs, vm.VmId)
+	}
+
+	// 1. Create the new pool and move the VMs into it
+	if err := cs.ProxmoxService.CreateNewPool(newPod); err != nil {
+		return "", err
+	}
+
+	if len(vmIDs) > 0 {
+		if err := cs.ProxmoxService.MoveVMsToPool(newPod, vmIDs); err != nil {
+			_ = cs.ProxmoxService.DeletePool(newPod)
+			return "", err
+		}
+	}
+
+	// 2. Grant the new owner access and remove the old owner's access
+	if err := cs.ProxmoxService.SetPoolPermission(newPod, newOwner, isGroup); err != nil {
+		return newPod, fmt.Errorf("VMs moved to %s but failed to set permissions: %w", newPod, err)
+	}
+
+	if err := cs.ProxmoxService.RemovePoolPermission(pod, oldOwner, oldIsGroup); err != nil {
+		log.Printf("Failed to remove permission of %s on pool %s: %v", oldOwner, pod, err)
+	}
+
+	// 3. Delete the old pool, which is now empty
+	if err := cs.ProxmoxService.DeletePool(pod); err != nil {
+		return newPod, fmt.Errorf("VMs moved to %s but failed to delete old pool: %w", newPod, err)
+	}
+
+	if err := cs.DatabaseService.RenamePodCloneRecords(pod, newPod, newOwner, isGroup); err != nil {
+		log.Printf("Failed to update clone records for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenamePodFlags(pod, newPod); err != nil {
+		log.Printf("Failed to update flags for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenameSubnetAllocation(pod, newPod); err != nil {
+		log.Printf("Failed to update subnet allocation for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenameVNetAllocation(pod, newPod); err != nil {
+		log.Printf("Failed to update VNet allocation for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenamePodSegments(pod, newPod); err != nil {
+		log.Printf("Failed to update network segments for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenamePodRateLimit(pod, newPod); err != nil {
+		log.Printf("Failed to update rate limit for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenamePodFirewallRules(pod, newPod); err != nil {
+		log.Printf("Failed to update firewall rules for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenamePodPortForwards(pod, newPod); err != nil {
+		log.Printf("Failed to update port forwards for pod %s: %v", pod, err)
+	}
+	if err := cs.DatabaseService.RenamePodAccessStatus(pod, newPod, newOwner, isGroup); err != nil {
+		log.Printf("Failed to update access status for pod %s: %v", pod, err)
+	}
+	cs.renamePodRouterStatus(pod, newPod)
+	cs.revokePodVPNConfigs(pod)
+	if err := cs.removePodDNSRecord(pod); err != nil {
+		log.Printf("Failed to remove DNS record of pod %s: %v", pod, err)
+	}
+	if err := cs.createPodDNSRecord(newPod); err != nil {
+		log.Printf("Failed to create DNS record for pod %s: %v", newPod, err)
+	}
+	// Connections are named after the pod and granted to its owner, so they are replaced
+	if err := cs.removePodGuacamoleConnections(pod); err != nil {
+		log.Printf("Failed to remove Guacamole connections of pod %s: %v", pod, err)
+	}
+	if err := cs.createPodGuacamoleConnections(newPod, newOwner, isGroup); err != nil {
+		log.Printf("Failed to create Guacamole connections for pod %s: %v", newPod, err)
+	}
+	cs.movePodShares(pod, newPod)
+
+	cs.Events.Publish(events.Event{Type: events.PodDeleted, Pod: pod})
+	cs.Events.Publish(events.Event{Type: events.PodCreated, Pod: newPod})
+
+	log.Printf("Transferred pod %s from %s to %s as %s", pod, oldOwner, newOwner, newPod)
+	return newPod, nil
+}
+
+// SuspendPod hibernates every running VM in the pod to disk so the pod stops using
+// node memory without losing in-guest state
+func (cs *CloningService) SuspendPod(pod string) error {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	var errors []string
+	suspended := 0
+	for _, vm := range poolVMs {
+		if vm.Type != "qemu" || vm.RunningStatus != "running" {
+			continue
+		}
+
+		if err := cs.ProxmoxService.HibernateVM(vm.NodeName, vm.VmId); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to suspend VM %s: %v", vm.Name, err))
+			continue
+		}
+		suspended++
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("suspended %d VMs in pod %s with errors: %v", suspended, pod, errors)
+	}
+
+	log.Printf("Suspended %d VMs in pod %s", suspended, pod)
+	return nil
+}
+
+// ResumePod resumes every suspended VM in the pod from its saved state. The router is
+// resumed first so the other VMs have networking when they wake.
+func (cs *CloningService) ResumePod(pod string) error {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	slices.SortStableFunc(poolVMs, func(a, b proxmox.VirtualResource) int {
+		if routerPattern.MatchString(a.Name) == routerPattern.MatchString(b.Name) {
+			return 0
+		}
+		if routerPattern.MatchString(a.Name) {
+			return -1
+		}
+		return 1
+	})
+
+	var errors []string
+	resumed := 0
+	for _, vm := range poolVMs {
+		if vm.Type != "qemu" || vm.RunningStatus == "running" {
+			continue
+		}
+
+		wasSuspended, err := cs.ProxmoxService.ResumeVM(vm.NodeName, vm.VmId)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to resume VM %s: %v", vm.Name, err))
+			continue
+		}
+		if wasSuspended {
+			resumed++
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("resumed %d VMs in pod %s with errors: %v", resumed, pod, errors)
+	}
+
+	log.Printf("Resumed %d VMs in pod %s", resumed, pod)
+	return nil
+}
+
+// poolOwnerIsGroup reports whether the owner's permission on the pool was granted to a group
+func (cs *CloningService) poolOwnerIsGroup(pod string, owner string) (bool, error) {
+	acls, err := cs.ProxmoxService.GetACLs()
+	if err != nil {
+		return false, fmt.Errorf("failed to get ACLs: %w", err)
+	}
+
+	path := fmt.Sprintf("/pool/%s", pod)
+	for _, acl := range acls {
+		if acl.Path == path && acl.Type == "group" && strings.HasPrefix(acl.UGID, owner+"-") {
+			return true, nil
+		}
+	}
 
-	return isValidCloneRequest, nil
+	return false, nil
 }
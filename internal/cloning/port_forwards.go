@@ -0,0 +1,278 @@
+package cloning
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Port Forward Database Operations
+// =================================================
+
+func (c *TemplateClient) InsertPodPortForward(forward PodPortForward) (int64, error) {
+	query := `INSERT INTO pod_port_forwards (pod, protocol, external_port, internal_ip, internal_port, description, slot, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := c.DB.Exec(query, forward.Pod, forward.Protocol, forward.ExternalPort, forward.InternalIP, forward.InternalPort, forward.Description, forward.Slot, forward.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (c *TemplateClient) GetPodPortForwards(pod string) ([]PodPortForward, error) {
+	query := `SELECT id, pod, protocol, external_port, internal_ip, internal_port, description, slot, created_by, created_at
+		FROM pod_port_forwards WHERE pod = ? ORDER BY external_port, protocol`
+
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodPortForwards(rows)
+}
+
+func (c *TemplateClient) DeletePodPortForward(pod string, id int64) error {
+	_, err := c.DB.Exec("DELETE FROM pod_port_forwards WHERE pod = ? AND id = ?", pod, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) RenamePodPortForwards(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_port_forwards SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodPortForwards(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_port_forwards WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Port Forward Operations
+// =================================================
+
+// GetPodPortForwards returns the ports forwarded from a pod's WAN address
+func (cs *CloningService) GetPodPortForwards(pod string) ([]PodPortForward, error) {
+	forwards, err := cs.DatabaseService.GetPodPortForwards(pod)
+	if err != nil {
+		return nil, err
+	}
+	if forwards == nil {
+		forwards = []PodPortForward{}
+	}
+
+	return forwards, nil
+}
+
+// AddPodPortForward forwards a port of the pod's WAN address to a VM inside the pod
+// through the pod router. The internal address must be on one of the pod's LAN or segment
+// subnets. The record is removed again when the router can't be configured.
+func (cs *CloningService) AddPodPortForward(forward PodPortForward) (*PodPortForward, error) {
+	existing, err := cs.DatabaseService.GetPodPortForwards(forward.Pod)
+	if err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(existing, func(f PodPortForward) bool {
+		return f.Protocol == forward.Protocol && f.ExternalPort == forward.ExternalPort
+	}) {
+		return nil, fmt.Errorf("%s port %d of pod %s is already forwarded", forward.Protocol, forward.ExternalPort, forward.Pod)
+	}
+
+	if err := cs.validatePortForwardTarget(forward.Pod, forward.InternalIP); err != nil {
+		return nil, err
+	}
+
+	router, err := cs.podRouter(forward.Pod)
+	if err != nil {
+		return nil, err
+	}
+
+	forward.Slot = nextPortForwardSlot(existing)
+	forward.ID, err = cs.DatabaseService.InsertPodPortForward(forward)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := router.apply(cs.ProxmoxService.AddRouterPortForward, forward); err != nil {
+		if err := cs.DatabaseService.DeletePodPortForward(forward.Pod, forward.ID); err != nil {
+			log.Printf("Failed to delete unapplied port forward %d of pod %s: %v", forward.ID, forward.Pod, err)
+		}
+		return nil, err
+	}
+
+	return &forward, nil
+}
+
+// DeletePodPortForward removes a port forward from the pod router
+func (cs *CloningService) DeletePodPortForward(pod string, id int64) error {
+	forwards, err := cs.DatabaseService.GetPodPortForwards(pod)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(forwards, func(forward PodPortForward) bool { return forward.ID == id })
+	if index < 0 {
+		return fmt.Errorf("port forward %d not found on pod %s", id, pod)
+	}
+
+	router, err := cs.podRouter(pod)
+	if err != nil {
+		return err
+	}
+	if err := router.apply(cs.ProxmoxService.RemoveRouterPortForward, forwards[index]); err != nil {
+		return err
+	}
+
+	return cs.DatabaseService.DeletePodPortForward(pod, id)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// podRouterTarget is the router of a pod along with what is needed to configure it
+type podRouterTarget struct {
+	podNumber  int
+	node       string
+	vmid       int
+	routerType string
+}
+
+func (r podRouterTarget) apply(configure func(int, string, int, string, proxmox.PortForward) error, forward PodPortForward) error {
+	return configure(r.podNumber, r.node, r.vmid, r.routerType, proxmox.PortForward{
+		ID:           forward.ID,
+		Slot:         forward.Slot,
+		Protocol:     forward.Protocol,
+		ExternalPort: forward.ExternalPort,
+		InternalIP:   forward.InternalIP,
+		InternalPort: forward.InternalPort,
+	})
+}
+
+// validatePortForwardTarget checks that a port forward points inside the pod: on one of the
+// LAN subnets of its template or the subnet of one of its segments. Templates without LAN
+// subnets keep the LAN of the router image, there the address only has to be private and
+// outside the pod WAN range.
+func (cs *CloningService) validatePortForwardTarget(pod string, internalIP string) error {
+	addr, err := netip.ParseAddr(internalIP)
+	if err != nil || !addr.Is4() {
+		return fmt.Errorf("invalid internal IP %q", internalIP)
+	}
+
+	template, err := cs.podTemplateNetworks(pod)
+	if err != nil {
+		return err
+	}
+
+	subnets := append([]string{}, template.LANSubnets...)
+	if len(template.Segments) > 0 {
+		podSegments, err := cs.DatabaseService.GetPodSegments(pod)
+		if err != nil {
+			return err
+		}
+		for _, segment := range template.Segments {
+			if segment.Subnet != "" && slices.ContainsFunc(podSegments, func(s PodSegment) bool { return strings.EqualFold(s.Alias, segment.Alias) }) {
+				subnets = append(subnets, segment.Subnet)
+			}
+		}
+	}
+
+	if len(template.LANSubnets) == 0 {
+		wanRange, err := cs.podWANRange()
+		if err != nil {
+			return err
+		}
+		if !addr.IsPrivate() || wanRange.Contains(addr) {
+			return fmt.Errorf("internal IP %s is not inside pod %s", internalIP, pod)
+		}
+		return nil
+	}
+
+	for _, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("internal IP %s is not inside the LAN subnets of pod %s (%s)", internalIP, pod, strings.Join(subnets, ", "))
+}
+
+func (cs *CloningService) podRouter(pod string) (*podRouterTarget, error) {
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := cs.podRouterVM(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	routerType, err := cs.ProxmoxService.GetRouterType(proxmox.VM{Name: vm.Name, Node: vm.NodeName, VMID: vm.VmId})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get router type of pod %s: %w", pod, err)
+	}
+
+	return &podRouterTarget{podNumber: podNumber, node: vm.NodeName, vmid: vm.VmId, routerType: routerType}, nil
+}
+
+// podRouterVM returns the router VM in a pod's pool
+func (cs *CloningService) podRouterVM(pod string) (proxmox.VirtualResource, error) {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return proxmox.VirtualResource{}, fmt.Errorf("failed to get VMs of pod %s: %w", pod, err)
+	}
+
+	for _, vm := range poolVMs {
+		if vm.IsGuest() && routerPattern.MatchString(vm.Name) {
+			return vm, nil
+		}
+	}
+
+	return proxmox.VirtualResource{}, fmt.Errorf("pod %s has no router", pod)
+}
+
+// nextPortForwardSlot returns the lowest slot not taken by one of the pod's forwards
+func nextPortForwardSlot(forwards []PodPortForward) int {
+	slot := 1
+	for slices.ContainsFunc(forwards, func(forward PodPortForward) bool { return forward.Slot == slot }) {
+		slot++
+	}
+	return slot
+}
+
+func buildPodPortForwards(rows *sql.Rows) ([]PodPortForward, error) {
+	var forwards []PodPortForward
+	for rows.Next() {
+		var forward PodPortForward
+		if err := rows.Scan(&forward.ID, &forward.Pod, &forward.Protocol, &forward.ExternalPort, &forward.InternalIP, &forward.InternalPort, &forward.Description, &forward.Slot, &forward.CreatedBy, &forward.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		forwards = append(forwards, forward)
+	}
+
+	return forwards, nil
+}
@@ -0,0 +1,151 @@
+package cloning
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// =================================================
+// Pod Quota Database Operations
+// =================================================
+
+func (c *TemplateClient) GetPodQuotas() ([]PodQuota, error) {
+	query := "SELECT target, is_group, max_pods FROM pod_quotas ORDER BY is_group, target"
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	quotas := []PodQuota{}
+	for rows.Next() {
+		var quota PodQuota
+		if err := rows.Scan(&quota.Target, &quota.IsGroup, &quota.MaxPods); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		quotas = append(quotas, quota)
+	}
+
+	return quotas, nil
+}
+
+func (c *TemplateClient) GetPodQuota(target string, isGroup bool) (*PodQuota, error) {
+	query := "SELECT target, is_group, max_pods FROM pod_quotas WHERE target = ? AND is_group = ?"
+	row := c.DB.QueryRow(query, target, isGroup)
+
+	var quota PodQuota
+	if err := row.Scan(&quota.Target, &quota.IsGroup, &quota.MaxPods); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No quota configured for this target
+		}
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return &quota, nil
+}
+
+func (c *TemplateClient) SetPodQuota(quota PodQuota) error {
+	query := "INSERT INTO pod_quotas (target, is_group, max_pods) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE max_pods = VALUES(max_pods)"
+	_, err := c.DB.Exec(query, quota.Target, quota.IsGroup, quota.MaxPods)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodQuota(target string, isGroup bool) error {
+	query := "DELETE FROM pod_quotas WHERE target = ? AND is_group = ?"
+	result, err := c.DB.Exec(query, target, isGroup)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("quota not found for %s", target)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Quota Enforcement
+// =================================================
+
+// GetEffectivePodQuota resolves the pod limit for a target. An explicit quota for the
+// target wins; users without one inherit the largest quota of their groups, and
+// anything left falls back to the configured default.
+func (cs *CloningService) GetEffectivePodQuota(target string, isGroup bool) (int, string, error) {
+	quota, err := cs.DatabaseService.GetPodQuota(target, isGroup)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get pod quota for %s: %w", target, err)
+	}
+	if quota != nil {
+		return quota.MaxPods, quotaSource(target, isGroup), nil
+	}
+
+	if !isGroup {
+		userDN, err := cs.LDAPService.GetUserDN(target)
+		if err == nil {
+			groups, err := cs.LDAPService.GetUserGroups(userDN)
+			if err != nil {
+				return 0, "", fmt.Errorf("failed to get user groups: %w", err)
+			}
+
+			best := -1
+			source := ""
+			for _, group := range groups {
+				groupQuota, err := cs.DatabaseService.GetPodQuota(group, true)
+				if err != nil {
+					return 0, "", fmt.Errorf("failed to get pod quota for group %s: %w", group, err)
+				}
+				if groupQuota != nil && groupQuota.MaxPods > best {
+					best = groupQuota.MaxPods
+					source = quotaSource(group, true)
+				}
+			}
+			if best >= 0 {
+				return best, source, nil
+			}
+		}
+
+		orgQuota, source, err := cs.organizationPodQuota(target)
+		if err != nil {
+			return 0, "", err
+		}
+		if orgQuota >= 0 {
+			return orgQuota, source, nil
+		}
+	}
+
+	return cs.Config.DefaultPodQuota, "default quota", nil
+}
+
+// CountDeployedPods returns the number of deployed pods owned by the target, excluding retained pods
+func (cs *CloningService) CountDeployedPods(target string) (int, error) {
+	pods, err := cs.AdminGetPods()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deployed pods: %w", err)
+	}
+
+	count := 0
+	for _, pod := range pods {
+		if PodOwnedBy(pod.Name, target) && !pod.Retained {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func quotaSource(target string, isGroup bool) string {
+	if isGroup {
+		return fmt.Sprintf("group quota for %s", target)
+	}
+	return fmt.Sprintf("user quota for %s", target)
+}
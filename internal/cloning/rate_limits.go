@@ -0,0 +1,110 @@
+package cloning
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// =================================================
+// Pod Rate Limit Database Operations
+// =================================================
+
+func (c *TemplateClient) GetPodRateLimit(pod string) (*PodRateLimit, error) {
+	query := "SELECT pod, rate, updated_by, updated_at FROM pod_rate_limits WHERE pod = ?"
+	var limit PodRateLimit
+	err := c.DB.QueryRow(query, pod).Scan(&limit.Pod, &limit.Rate, &limit.UpdatedBy, &limit.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return &limit, nil
+}
+
+func (c *TemplateClient) SavePodRateLimit(limit PodRateLimit) error {
+	query := `INSERT INTO pod_rate_limits (pod, rate, updated_by) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE rate = VALUES(rate), updated_by = VALUES(updated_by), updated_at = UTC_TIMESTAMP()`
+
+	_, err := c.DB.Exec(query, limit.Pod, limit.Rate, limit.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) RenamePodRateLimit(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_rate_limits SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodRateLimit(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_rate_limits WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Rate Limit Operations
+// =================================================
+
+// SetPodRateLimit overrides the rate limit a pod got from its template and applies it to
+// the pod's router right away
+func (cs *CloningService) SetPodRateLimit(pod string, rate float64, username string) error {
+	if _, err := cs.podRouterVM(pod); err != nil {
+		return err
+	}
+
+	if err := cs.DatabaseService.SavePodRateLimit(PodRateLimit{Pod: pod, Rate: rate, UpdatedBy: username}); err != nil {
+		return err
+	}
+
+	return cs.applyPodRateLimit(pod)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// podRateLimit returns the rate limit of a pod, an admin override or else its template's
+func (cs *CloningService) podRateLimit(pod string) (float64, error) {
+	limit, err := cs.DatabaseService.GetPodRateLimit(pod)
+	if err != nil {
+		return 0, err
+	}
+	if limit != nil {
+		return limit.Rate, nil
+	}
+
+	template, err := cs.podTemplateNetworks(pod)
+	if err != nil {
+		return 0, err
+	}
+	return template.RateLimit, nil
+}
+
+// applyPodRateLimit sets the pod's rate limit on the WAN NIC of its router. Attaching the
+// pod networks rewrites the router's NICs, so it runs again after every attach.
+func (cs *CloningService) applyPodRateLimit(pod string) error {
+	rate, err := cs.podRateLimit(pod)
+	if err != nil {
+		return err
+	}
+
+	router, err := cs.podRouterVM(pod)
+	if err != nil {
+		return err
+	}
+
+	return cs.ProxmoxService.SetGuestNICRate(router, "net0", rate)
+}
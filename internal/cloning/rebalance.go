@@ -0,0 +1,262 @@
+package cloning
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+)
+
+// PlanRebalance plans the pod migrations that bring every node back under the rebalance
+// memory threshold. Whole pods are moved so their VMs stay on one node, largest first.
+// Pods whose template spreads VMs across nodes are never moved.
+func (cs *CloningService) PlanRebalance() (*RebalancePlan, error) {
+	usage, err := cs.ProxmoxService.GetClusterResourceUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster resource usage: %w", err)
+	}
+
+	pods, err := cs.AdminGetPods()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployed pods: %w", err)
+	}
+
+	threshold := cs.Config.RebalanceMemoryThreshold
+	plan := &RebalancePlan{
+		Threshold: threshold,
+		Nodes:     []NodeLoad{},
+		Moves:     []PodMove{},
+	}
+
+	used := make(map[string]int64)
+	total := make(map[string]int64)
+	for _, node := range usage.Nodes {
+		used[node.Name] = node.Resources.MemoryUsed
+		total[node.Name] = node.Resources.MemoryTotal
+	}
+	load := func(node string) float64 {
+		return loadRatio(used[node], total[node])
+	}
+
+	spread := cs.spreadTemplates(pods)
+
+	// Largest pods first so each node needs as few migrations as possible
+	slices.SortFunc(pods, func(a, b Pod) int {
+		return cmp.Compare(podRunningMemory(b.VMs, ""), podRunningMemory(a.VMs, ""))
+	})
+
+	for _, node := range usage.Nodes {
+		for _, pod := range pods {
+			if load(node.Name) <= threshold {
+				break
+			}
+			if podHomeNode(pod.VMs) != node.Name || podRunningMemory(pod.VMs, "") == 0 || spread[PodTemplateName(pod.Name)] {
+				continue
+			}
+
+			// Move the pod to the least loaded node that stays under the threshold with it
+			target := ""
+			for _, candidate := range usage.Nodes {
+				if candidate.Name == node.Name || total[candidate.Name] == 0 {
+					continue
+				}
+				incoming := podRunningMemory(pod.VMs, "") - podRunningMemory(pod.VMs, candidate.Name)
+				projected := float64(used[candidate.Name]+incoming) / float64(total[candidate.Name])
+				if projected > threshold {
+					continue
+				}
+				if target == "" || load(candidate.Name) < load(target) {
+					target = candidate.Name
+				}
+			}
+			if target == "" {
+				continue
+			}
+
+			move := PodMove{Pod: pod.Name, From: node.Name, To: target, VMs: []PodMoveVM{}}
+			for _, vm := range pod.VMs {
+				if vm.NodeName == target {
+					continue
+				}
+				move.VMs = append(move.VMs, PodMoveVM{
+					VMID:    vm.VmId,
+					Name:    vm.Name,
+					From:    vm.NodeName,
+					Running: vm.RunningStatus == "running",
+				})
+				if vm.RunningStatus == "running" {
+					used[vm.NodeName] -= int64(vm.MaxMem)
+					used[target] += int64(vm.MaxMem)
+					move.Memory += int64(vm.MaxMem)
+				}
+			}
+			plan.Moves = append(plan.Moves, move)
+		}
+	}
+
+	for _, node := range usage.Nodes {
+		plan.Nodes = append(plan.Nodes, NodeLoad{
+			Name:          node.Name,
+			MemoryUsage:   loadRatio(node.Resources.MemoryUsed, node.Resources.MemoryTotal),
+			ProjectedLoad: load(node.Name),
+			Overloaded:    load(node.Name) > threshold,
+		})
+	}
+
+	return plan, nil
+}
+
+// RebalanceNodes plans a rebalance and carries out its migrations in the background as a
+// job. The job ID is empty when no pod needs to move. Only one rebalance runs at a time.
+func (cs *CloningService) RebalanceNodes(requestedBy string) (*RebalancePlan, string, error) {
+	if !cs.rebalanceMutex.TryLock() {
+		return nil, "", fmt.Errorf("a rebalance is already running")
+	}
+
+	plan, err := cs.PlanRebalance()
+	if err != nil {
+		cs.rebalanceMutex.Unlock()
+		return nil, "", err
+	}
+	if len(plan.Moves) == 0 {
+		cs.rebalanceMutex.Unlock()
+		return plan, "", nil
+	}
+
+	description := fmt.Sprintf("Rebalance %d pods across nodes", len(plan.Moves))
+	job := cs.Jobs.Create(jobs.TypeRebalance, requestedBy, description, nil, nil)
+	ctx := cs.Jobs.WithCancel(context.Background(), job.ID)
+	go func() {
+		defer cs.rebalanceMutex.Unlock()
+
+		err := cs.migratePods(ctx, job.ID, plan.Moves)
+		if err != nil {
+			log.Printf("Rebalance failed: %v", err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return plan, job.ID, nil
+}
+
+// =================================================
+// Rebalance Worker
+// =================================================
+
+// rebalanceOnSchedule periodically rebalances overloaded nodes
+func (cs *CloningService) rebalanceOnSchedule() {
+	if cs.Config.RebalanceInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cs.Config.RebalanceInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		plan, jobID, err := cs.RebalanceNodes("scheduler")
+		if err != nil {
+			log.Printf("Scheduled rebalance failed: %v", err)
+			continue
+		}
+		if jobID != "" {
+			log.Printf("Scheduled rebalance started job %s moving %d pods", jobID, len(plan.Moves))
+		}
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// migratePods migrates the VMs of every planned move one at a time so only one
+// migration loads the cluster network at once. Cancelling the job stops before the next
+// VM, a migration already running is left to finish.
+func (cs *CloningService) migratePods(ctx context.Context, jobID string, moves []PodMove) error {
+	var errors []string
+	for i, move := range moves {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("rebalance cancelled after %d of %d pods: %w", i, len(moves), context.Cause(ctx))
+		}
+		cs.Jobs.Update(jobID, 100*i/len(moves), fmt.Sprintf("Migrating pod %s from %s to %s", move.Pod, move.From, move.To))
+
+		for _, vm := range move.VMs {
+			upid, err := cs.ProxmoxService.MigrateVM(vm.From, vm.VMID, move.To, vm.Running)
+			if err == nil {
+				err = cs.ProxmoxService.WaitForTask(ctx, vm.From, upid, cs.Config.MigrationTimeout)
+			}
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("failed to migrate VM %s of pod %s: %v", vm.Name, move.Pod, err))
+			}
+		}
+
+		log.Printf("Migrated pod %s from %s to %s", move.Pod, move.From, move.To)
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("rebalance completed with errors: %v", errors)
+	}
+
+	return nil
+}
+
+// spreadTemplates returns the templates of the pods that have a spread placement rule
+func (cs *CloningService) spreadTemplates(pods []Pod) map[string]bool {
+	spread := make(map[string]bool)
+	checked := make(map[string]bool)
+	for _, pod := range pods {
+		templateName := PodTemplateName(pod.Name)
+		if checked[templateName] {
+			continue
+		}
+		checked[templateName] = true
+
+		rules, err := cs.DatabaseService.GetPlacementRules(templateName)
+		if err != nil {
+			// Leave the pods alone rather than risk collapsing a spread pod onto one node
+			log.Printf("Failed to get placement rules of template %s: %v", templateName, err)
+			spread[templateName] = true
+			continue
+		}
+		spread[templateName] = slices.ContainsFunc(rules, func(rule PlacementRule) bool {
+			return rule.Type == PlacementSpread
+		})
+	}
+	return spread
+}
+
+// podHomeNode returns the node holding most of the pod's running memory, or the node of
+// its first VM when none are running
+func podHomeNode(vms []proxmox.VirtualResource) string {
+	home := ""
+	var homeMemory int64 = -1
+	for _, vm := range vms {
+		if memory := podRunningMemory(vms, vm.NodeName); memory > homeMemory {
+			home = vm.NodeName
+			homeMemory = memory
+		}
+	}
+	return home
+}
+
+// podRunningMemory sums the memory of the running VMs, limited to one node when set
+func podRunningMemory(vms []proxmox.VirtualResource, node string) int64 {
+	var memory int64
+	for _, vm := range vms {
+		if vm.RunningStatus == "running" && (node == "" || vm.NodeName == node) {
+			memory += int64(vm.MaxMem)
+		}
+	}
+	return memory
+}
+
+func loadRatio(used int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total)
+}
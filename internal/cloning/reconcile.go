@@ -0,0 +1,135 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =================================================
+// Template Reconciliation Database Operations
+// =================================================
+
+// ListTemplateImageFiles returns the names of every file in the upload directory
+func (c *TemplateClient) ListTemplateImageFiles() ([]string, error) {
+	entries, err := os.ReadDir(c.TemplateConfig.UploadDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			files = append(files, entry.Name())
+		}
+	}
+
+	return files, nil
+}
+
+// =================================================
+// Template Reconciliation Operations
+// =================================================
+
+// ReconcileTemplates cross-checks the templates table against the kamino_template_ pools
+// in Proxmox and the upload directory. It only reports, nothing is changed.
+func (cs *CloningService) ReconcileTemplates() (*TemplateReconciliation, error) {
+	templates, err := cs.DatabaseService.GetPublishedTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get published templates: %w", err)
+	}
+
+	pools, err := cs.ProxmoxService.GetTemplatePools()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template pools: %w", err)
+	}
+
+	files, err := cs.DatabaseService.ListTemplateImageFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TemplateReconciliation{
+		OrphanedTemplates: []string{},
+		UnpublishedPools:  []string{},
+		EmptyPools:        []string{},
+		OrphanedImages:    []string{},
+		CheckedAt:         time.Now(),
+	}
+
+	// Templates whose pool is gone can no longer be cloned
+	referenced := make(map[string]bool)
+	for _, template := range templates {
+		if !slices.Contains(pools, "kamino_template_"+template.Name) {
+			report.OrphanedTemplates = append(report.OrphanedTemplates, template.Name)
+		}
+
+		for _, image := range append([]string{template.ImagePath}, template.Images...) {
+			if image == "" {
+				continue
+			}
+			referenced[image] = true
+			for _, width := range ThumbnailSizes {
+				referenced[thumbnailPath(image, width)] = true
+			}
+		}
+	}
+
+	for _, pool := range pools {
+		name := strings.TrimPrefix(pool, "kamino_template_")
+		if !slices.ContainsFunc(templates, func(template KaminoTemplate) bool { return template.Name == name }) {
+			report.UnpublishedPools = append(report.UnpublishedPools, name)
+		}
+
+		poolVMs, err := cs.ProxmoxService.GetPoolVMs(pool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get VMs of pool %s: %w", pool, err)
+		}
+		if len(poolVMs) == 0 {
+			report.EmptyPools = append(report.EmptyPools, name)
+		}
+	}
+
+	for _, file := range files {
+		if !referenced[file] {
+			report.OrphanedImages = append(report.OrphanedImages, file)
+		}
+	}
+
+	report.Clean = len(report.OrphanedTemplates) == 0 && len(report.UnpublishedPools) == 0 &&
+		len(report.EmptyPools) == 0 && len(report.OrphanedImages) == 0
+	return report, nil
+}
+
+// reconcileTemplatesOnSchedule periodically logs the findings of ReconcileTemplates and
+// retries removing segment VNets of deleted pods
+func (cs *CloningService) reconcileTemplatesOnSchedule() {
+	if cs.Config.ReconcileInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cs.Config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cs.sweepOrphanedSegments()
+
+		report, err := cs.ReconcileTemplates()
+		if err != nil {
+			log.Printf("Template reconciliation failed: %v", err)
+			continue
+		}
+		if report.Clean {
+			continue
+		}
+
+		log.Printf("Template reconciliation found %d orphaned templates %v, %d unpublished pools %v, %d empty pools %v, and %d orphaned images",
+			len(report.OrphanedTemplates), report.OrphanedTemplates,
+			len(report.UnpublishedPools), report.UnpublishedPools,
+			len(report.EmptyPools), report.EmptyPools,
+			len(report.OrphanedImages))
+	}
+}
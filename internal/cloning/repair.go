@@ -0,0 +1,520 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// Pod clone record statuses
+const (
+	CloneRecordCloned = "cloned"
+	CloneRecordFailed = "failed"
+)
+
+// =================================================
+// Pod Clone Record Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodCloneRecord(record PodCloneRecord) error {
+	query := `INSERT INTO pod_clone_records
+		(pod, vm_index, template, target, is_group, pod_number, source_vmid, source_node, source_name, vmid, is_router, status, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE vmid = VALUES(vmid), status = VALUES(status), error = VALUES(error)`
+	_, err := c.DB.Exec(query,
+		record.Pod, record.VMIndex, record.Template, record.Target, record.IsGroup, record.PodNumber,
+		record.SourceVMID, record.SourceNode, record.SourceName, record.VMID, record.IsRouter, record.Status, record.Error)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodCloneRecords(pod string) ([]PodCloneRecord, error) {
+	query := `SELECT pod, vm_index, template, target, is_group, pod_number, source_vmid, source_node, source_name, vmid, is_router, status, error
+		FROM pod_clone_records WHERE pod = ? ORDER BY vm_index`
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodCloneRecords(rows)
+}
+
+// GetPodCreationTimes returns when each pod with clone records was first cloned
+func (c *TemplateClient) GetPodCreationTimes() (map[string]time.Time, error) {
+	rows, err := c.DB.Query("SELECT pod, MIN(created_at) FROM pod_clone_records GROUP BY pod")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	created := make(map[string]time.Time)
+	for rows.Next() {
+		var pod string
+		var createdAt time.Time
+		if err := rows.Scan(&pod, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		created[pod] = createdAt
+	}
+
+	return created, nil
+}
+
+// GetRepairablePods returns the names of every pod with a failed VM clone
+func (c *TemplateClient) GetRepairablePods() ([]string, error) {
+	rows, err := c.DB.Query("SELECT DISTINCT pod FROM pod_clone_records WHERE status = ?", CloneRecordFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	pods := []string{}
+	for rows.Next() {
+		var pod string
+		if err := rows.Scan(&pod); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+func (c *TemplateClient) DeletePodCloneRecords(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_clone_records WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) RenamePodCloneRecords(pod string, newPod string, target string, isGroup bool) error {
+	query := "UPDATE pod_clone_records SET pod = ?, target = ?, is_group = ? WHERE pod = ?"
+	_, err := c.DB.Exec(query, newPod, target, isGroup, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Repair Operations
+// =================================================
+
+// recordCloneResult persists the outcome of a single VM clone so a partially
+// failed pod can later be repaired. Failures to record are only logged.
+func (cs *CloningService) recordCloneResult(template string, target CloneTarget, index int, source proxmox.VM, isRouter bool, cloneErr error) {
+	record := PodCloneRecord{
+		Pod:        target.PoolName,
+		VMIndex:    index,
+		Template:   template,
+		Target:     target.Name,
+		IsGroup:    target.IsGroup,
+		PodNumber:  target.PodNumber,
+		SourceVMID: source.VMID,
+		SourceNode: source.Node,
+		SourceName: source.Name,
+		VMID:       target.VMIDs[index],
+		IsRouter:   isRouter,
+		Status:     CloneRecordCloned,
+	}
+	if cloneErr != nil {
+		record.Status = CloneRecordFailed
+		record.Error = cloneErr.Error()
+	}
+
+	if err := cs.DatabaseService.SavePodCloneRecord(record); err != nil {
+		log.Printf("Failed to record clone result for VM %d in pod %s: %v", record.VMID, record.Pod, err)
+	}
+}
+
+// RepairPod re-clones any recorded pod VMs that are missing from the pool, then
+// reapplies the pod's networking, router configuration, and permissions
+func (cs *CloningService) RepairPod(pod string) (*PodRepairResult, error) {
+	records, err := cs.DatabaseService.GetPodCloneRecords(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clone records for %s: %w", pod, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no clone records found for pod %s", pod)
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	var presentVMIDs []int
+	node := ""
+	for _, vm := range poolVMs {
+		presentVMIDs = append(presentVMIDs, vm.VmId)
+		if node == "" {
+			node = vm.NodeName
+		}
+	}
+
+	var missing []PodCloneRecord
+	for _, record := range records {
+		if !slices.Contains(presentVMIDs, record.VMID) {
+			missing = append(missing, record)
+		}
+	}
+
+	result := &PodRepairResult{Pod: pod, Recloned: []int{}, Errors: []string{}}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	log.Printf("Repairing pod %s: %d of %d VMs missing", pod, len(missing), len(records))
+
+	// Keep the repaired VMs on the same node as the rest of the pod when possible
+	if node == "" {
+		node, err = cs.ProxmoxService.FindBestNode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find best node: %w", err)
+		}
+	}
+
+	templateInfo, err := cs.DatabaseService.GetTemplateInfo(records[0].Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template info: %w", err)
+	}
+	fullClone := 0
+	if templateInfo.CloneMode == CloneModeFull {
+		fullClone = 1
+	}
+
+	// The original VMIDs may have been reused since the failed clone, so allocate new ones
+	cs.vmidMutex.Lock()
+	vmIDs, err := cs.nextVMIDs(templateInfo.Name, len(missing))
+	if err != nil {
+		cs.vmidMutex.Unlock()
+		return nil, fmt.Errorf("failed to get next VM IDs: %w", err)
+	}
+
+	routerVMID := 0
+	var repairedRouter *PodCloneRecord
+	for i := range missing {
+		record := &missing[i]
+		record.VMID = vmIDs[i]
+
+		source := proxmox.VM{Name: record.SourceName, Node: record.SourceNode, VMID: record.SourceVMID}
+		cloneReq := proxmox.VMCloneRequest{
+			SourceVM:   source,
+			PoolName:   pod,
+			PodID:      pod[:4],
+			NewVMID:    record.VMID,
+			Full:       fullClone,
+			TargetNode: node,
+			Storage:    templateInfo.Storage,
+		}
+
+		upid, err := cs.submitClone(context.Background(), CloneRequest{}, cloneReq)
+		if err == nil {
+			err = cs.waitForCloneTask(context.Background(), upid, record.VMID)
+		}
+
+		record.Status = CloneRecordCloned
+		record.Error = ""
+		if err != nil {
+			record.Status = CloneRecordFailed
+			record.Error = err.Error()
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to clone VM %s: %v", record.SourceName, err))
+		} else {
+			result.Recloned = append(result.Recloned, record.VMID)
+			if record.IsRouter {
+				repairedRouter = record
+			}
+		}
+
+		if err := cs.DatabaseService.SavePodCloneRecord(*record); err != nil {
+			log.Printf("Failed to record clone result for VM %d in pod %s: %v", record.VMID, pod, err)
+		}
+	}
+	cs.vmidMutex.Unlock()
+
+	for _, record := range records {
+		if record.IsRouter {
+			routerVMID = record.VMID
+		}
+	}
+	if repairedRouter != nil {
+		routerVMID = repairedRouter.VMID
+	}
+
+	if len(result.Recloned) == 0 {
+		return result, fmt.Errorf("pod repair failed: %v", result.Errors)
+	}
+
+	// Reapply networking so the new VMs join the pod's VNet
+	podNumber := records[0].PodNumber
+	if err := cs.attachPodNetworks(pod, podNumber, routerVMID); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to update pod vnet: %v", err))
+	}
+	if err := cs.applyPodRateLimit(pod); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to apply rate limit: %v", err))
+	}
+	if err := cs.applyPodCloudInit(pod, podNumber); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to write cloud-init config: %v", err))
+	}
+
+	// A replaced router needs to be started and configured for the pod
+	if repairedRouter != nil {
+		if err := cs.configureRepairedRouter(*repairedRouter, node); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	if err := cs.ProxmoxService.SetPoolPermission(pod, records[0].Target, records[0].IsGroup); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to update pool permissions: %v", err))
+	}
+
+	// Recloned VMs join the HA group the rest of the pod is in
+	if err := cs.extendPodHA(pod); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to register VMs with HA: %v", err))
+	}
+
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("pod repair completed with errors: %v", result.Errors)
+	}
+
+	return result, nil
+}
+
+// ResetPodVM deletes one VM of a pod and re-clones it from the template VM it was cloned
+// from, keeping its VMID so the rest of the pod and the VNet binding are unaffected
+func (cs *CloningService) ResetPodVM(pod string, vmID int) error {
+	records, err := cs.DatabaseService.GetPodCloneRecords(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get clone records for %s: %w", pod, err)
+	}
+
+	index := slices.IndexFunc(records, func(record PodCloneRecord) bool { return record.VMID == vmID })
+	if index == -1 {
+		return fmt.Errorf("VM %d has no clone record in pod %s", vmID, pod)
+	}
+	record := records[index]
+
+	templateInfo, err := cs.DatabaseService.GetTemplateInfo(record.Template)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	fullClone := 0
+	if templateInfo.CloneMode == CloneModeFull {
+		fullClone = 1
+	}
+
+	log.Printf("Resetting VM %d of pod %s from template VM %s", vmID, pod, record.SourceName)
+
+	node, err := cs.replacePodVM(record, fullClone, templateInfo.Storage)
+	record.Status = CloneRecordCloned
+	record.Error = ""
+	if err != nil {
+		record.Status = CloneRecordFailed
+		record.Error = err.Error()
+	}
+	if err := cs.DatabaseService.SavePodCloneRecord(record); err != nil {
+		log.Printf("Failed to record clone result for VM %d in pod %s: %v", vmID, pod, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	routerVMID := 0
+	for _, r := range records {
+		if r.IsRouter {
+			routerVMID = r.VMID
+		}
+	}
+	if err := cs.attachPodNetworks(pod, record.PodNumber, routerVMID); err != nil {
+		return fmt.Errorf("failed to update pod vnet: %w", err)
+	}
+	if err := cs.applyPodRateLimit(pod); err != nil {
+		return fmt.Errorf("failed to apply rate limit: %w", err)
+	}
+	if err := cs.applyPodCloudInit(pod, record.PodNumber); err != nil {
+		return fmt.Errorf("failed to write cloud-init config: %w", err)
+	}
+
+	if record.IsRouter {
+		if err := cs.configureRepairedRouter(record, node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replacePodVM deletes the VM of a clone record, if it still exists, and clones it again
+// under the same VMID on the pod's node, which is returned. The VMID lock is held
+// throughout so no other clone can claim the VMID while it is free.
+func (cs *CloningService) replacePodVM(record PodCloneRecord, fullClone int, storage string) (string, error) {
+	cs.vmidMutex.Lock()
+	defer cs.vmidMutex.Unlock()
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(record.Pod)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pool VMs for %s: %w", record.Pod, err)
+	}
+
+	node := ""
+	haGroup := ""
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() {
+			continue
+		}
+		if node == "" || vm.VmId == record.VMID {
+			node = vm.NodeName
+		}
+		if vm.VmId != record.VMID {
+			continue
+		}
+
+		if vm.RunningStatus == "running" {
+			if err := cs.ProxmoxService.StopVM(vm.NodeName, vm.VmId); err != nil {
+				return "", fmt.Errorf("failed to stop VM %s: %w", vm.Name, err)
+			}
+			if err := cs.ProxmoxService.WaitForStopped(context.Background(), vm.NodeName, vm.VmId); err != nil {
+				return "", fmt.Errorf("failed to stop VM %s: %w", vm.Name, err)
+			}
+		}
+		groups, err := cs.removeHAResources([]proxmox.VirtualResource{vm})
+		if err != nil {
+			log.Printf("Failed to remove HA resource of VM %s: %v", vm.Name, err)
+		}
+		haGroup = groups[vm.VmId]
+		if err := cs.ProxmoxService.DeleteVM(vm.NodeName, vm.VmId); err != nil {
+			return "", fmt.Errorf("failed to delete VM %s: %w", vm.Name, err)
+		}
+		if err := cs.ProxmoxService.WaitForVMRemoved(context.Background(), record.Pod, vm.VmId, 5*time.Minute); err != nil {
+			return "", err
+		}
+	}
+
+	if node == "" {
+		node, err = cs.ProxmoxService.FindBestNode()
+		if err != nil {
+			return "", fmt.Errorf("failed to find best node: %w", err)
+		}
+	}
+
+	source := proxmox.VM{Name: record.SourceName, Node: record.SourceNode, VMID: record.SourceVMID}
+	cloneReq := proxmox.VMCloneRequest{
+		SourceVM:   source,
+		PoolName:   record.Pod,
+		PodID:      record.Pod[:4],
+		NewVMID:    record.VMID,
+		Full:       fullClone,
+		TargetNode: node,
+		Storage:    storage,
+	}
+	upid, err := cs.submitClone(context.Background(), CloneRequest{}, cloneReq)
+	if err == nil {
+		err = cs.waitForCloneTask(context.Background(), upid, record.VMID)
+	}
+	if err != nil {
+		return node, fmt.Errorf("failed to clone VM %s: %w", record.SourceName, err)
+	}
+
+	// The replacement takes the old VM's place in the HA manager
+	if haGroup != "" {
+		if err := cs.registerPodHA(record.Pod, haGroup); err != nil {
+			return node, err
+		}
+	}
+
+	return node, nil
+}
+
+func (cs *CloningService) configureRepairedRouter(record PodCloneRecord, node string) error {
+	source := proxmox.VM{Name: record.SourceName, Node: record.SourceNode, VMID: record.SourceVMID}
+	routerType, err := cs.ProxmoxService.GetRouterType(source)
+	if err != nil {
+		return fmt.Errorf("failed to get router type: %w", err)
+	}
+
+	if err := cs.ProxmoxService.WaitForDisk(context.Background(), node, record.VMID, cs.Config.RouterWaitTimeout); err != nil {
+		return fmt.Errorf("router disk unavailable: %w", err)
+	}
+
+	if err := cs.ProxmoxService.StartVM(node, record.VMID); err != nil {
+		return fmt.Errorf("failed to start router VM: %w", err)
+	}
+
+	if err := cs.ProxmoxService.WaitForRunning(context.Background(), node, record.VMID); err != nil {
+		return fmt.Errorf("failed to start router VM: %w", err)
+	}
+
+	lan, err := cs.podRouterLAN(record.Pod, node, record.VMID)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.ProxmoxService.ConfigurePodRouter(context.Background(), record.PodNumber, node, record.VMID, routerType, lan); err != nil {
+		return fmt.Errorf("failed to configure pod router: %w", err)
+	}
+
+	return nil
+}
+
+// repairablePods returns the pods that have a failed VM clone RepairPod can re-clone
+func (cs *CloningService) repairablePods() map[string]bool {
+	pods, err := cs.DatabaseService.GetRepairablePods()
+	if err != nil {
+		log.Printf("Failed to get repairable pods: %v", err)
+		return nil
+	}
+
+	repairable := make(map[string]bool)
+	for _, pod := range pods {
+		repairable[pod] = true
+	}
+	return repairable
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func buildPodCloneRecords(rows *sql.Rows) ([]PodCloneRecord, error) {
+	records := []PodCloneRecord{}
+
+	for rows.Next() {
+		var record PodCloneRecord
+		var errMsg sql.NullString
+		err := rows.Scan(
+			&record.Pod,
+			&record.VMIndex,
+			&record.Template,
+			&record.Target,
+			&record.IsGroup,
+			&record.PodNumber,
+			&record.SourceVMID,
+			&record.SourceNode,
+			&record.SourceName,
+			&record.VMID,
+			&record.IsRouter,
+			&record.Status,
+			&errMsg,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		record.Error = errMsg.String
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,241 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Pod Retention Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodRetention(retention PodRetention) error {
+	query := "INSERT INTO pod_retention (pod, owner, is_group, retained_by) VALUES (?, ?, ?, ?)"
+	_, err := c.DB.Exec(query, retention.Pod, retention.Owner, retention.IsGroup, retention.RetainedBy)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// GetPodRetention returns the retention record of a pod, or nil when it is not retained
+func (c *TemplateClient) GetPodRetention(pod string) (*PodRetention, error) {
+	query := "SELECT pod, owner, is_group, retained_by, retained_at FROM pod_retention WHERE pod = ?"
+	var retention PodRetention
+	err := c.DB.QueryRow(query, pod).Scan(
+		&retention.Pod,
+		&retention.Owner,
+		&retention.IsGroup,
+		&retention.RetainedBy,
+		&retention.RetainedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return &retention, nil
+}
+
+// GetRetainedPods returns the names of every retained pod
+func (c *TemplateClient) GetRetainedPods() ([]string, error) {
+	rows, err := c.DB.Query("SELECT pod FROM pod_retention")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	pods := []string{}
+	for rows.Next() {
+		var pod string
+		if err := rows.Scan(&pod); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+func (c *TemplateClient) DeletePodRetention(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_retention WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Retention Operations
+// =================================================
+
+// RetainPod shuts every VM of the pod down and removes the owner's and shared users'
+// access, keeping the pod intact for end-of-term retention. Retained pods do not count
+// towards quotas and cannot be managed by their owner until restored.
+func (cs *CloningService) RetainPod(pod string, retainedBy string) error {
+	existing, err := cs.DatabaseService.GetPodRetention(pod)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("pod %s is already retained", pod)
+	}
+
+	owner, isGroup, err := cs.podOwner(pod)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.shutdownPod(pod); err != nil {
+		return err
+	}
+
+	if err := cs.ProxmoxService.RemovePoolPermission(pod, owner, isGroup); err != nil {
+		return fmt.Errorf("failed to remove pool permission of %s: %w", owner, err)
+	}
+
+	shares, err := cs.DatabaseService.GetPodShares(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get shares of pod %s: %w", pod, err)
+	}
+	for _, share := range shares {
+		if err := cs.ProxmoxService.RemovePoolUserRoles(pod, share.SharedWith, shareRoles[share.Access]); err != nil {
+			return fmt.Errorf("failed to remove access of %s: %w", share.SharedWith, err)
+		}
+	}
+
+	retention := PodRetention{
+		Pod:        pod,
+		Owner:      owner,
+		IsGroup:    isGroup,
+		RetainedBy: retainedBy,
+	}
+	if err := cs.DatabaseService.SavePodRetention(retention); err != nil {
+		return err
+	}
+
+	log.Printf("Retained pod %s of %s", pod, owner)
+	return nil
+}
+
+// RestorePod returns a retained pod to its owner, granting back the owner's and shared
+// users' access. The VMs are left stopped for the owner to start.
+func (cs *CloningService) RestorePod(pod string) error {
+	retention, err := cs.DatabaseService.GetPodRetention(pod)
+	if err != nil {
+		return err
+	}
+	if retention == nil {
+		return fmt.Errorf("pod %s is not retained", pod)
+	}
+
+	if err := cs.ProxmoxService.SetPoolPermission(pod, retention.Owner, retention.IsGroup); err != nil {
+		return fmt.Errorf("failed to restore pool permission of %s: %w", retention.Owner, err)
+	}
+
+	shares, err := cs.DatabaseService.GetPodShares(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get shares of pod %s: %w", pod, err)
+	}
+	for _, share := range shares {
+		if err := cs.ProxmoxService.SetPoolUserRoles(pod, share.SharedWith, shareRoles[share.Access]); err != nil {
+			return fmt.Errorf("failed to restore access of %s: %w", share.SharedWith, err)
+		}
+	}
+
+	if err := cs.DatabaseService.DeletePodRetention(pod); err != nil {
+		return err
+	}
+
+	log.Printf("Restored pod %s to %s", pod, retention.Owner)
+	return nil
+}
+
+// IsPodRetained reports whether the pod is retained
+func (cs *CloningService) IsPodRetained(pod string) (bool, error) {
+	retention, err := cs.DatabaseService.GetPodRetention(pod)
+	if err != nil {
+		return false, err
+	}
+
+	return retention != nil, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// podOwner returns the user or group a pod was cloned for, falling back to the owner
+// segment of the pool name for pods without clone records
+func (cs *CloningService) podOwner(pod string) (string, bool, error) {
+	records, err := cs.DatabaseService.GetPodCloneRecords(pod)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get clone records for %s: %w", pod, err)
+	}
+	if len(records) > 0 {
+		return records[0].Target, records[0].IsGroup, nil
+	}
+
+	parts := strings.SplitN(pod, "_", 3)
+	if len(parts) != 3 {
+		return "", false, fmt.Errorf("pod %s does not follow the <id>_<template>_<owner> naming scheme", pod)
+	}
+
+	return parts[2], false, nil
+}
+
+// shutdownPod gracefully shuts down every running VM of the pod, stopping any VM that
+// does not shut down in time
+func (cs *CloningService) shutdownPod(pod string) error {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	var running []proxmox.VirtualResource
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() || vm.RunningStatus != "running" {
+			continue
+		}
+
+		if err := cs.ProxmoxService.ShutdownVM(vm.NodeName, vm.VmId); err != nil {
+			return fmt.Errorf("failed to shut down VM %s: %w", vm.Name, err)
+		}
+		running = append(running, vm)
+	}
+
+	for _, vm := range running {
+		if err := cs.ProxmoxService.WaitForStopped(context.Background(), vm.NodeName, vm.VmId); err != nil {
+			log.Printf("VM %s of pod %s did not shut down, stopping it: %v", vm.Name, pod, err)
+			if err := cs.ProxmoxService.StopVM(vm.NodeName, vm.VmId); err != nil {
+				return fmt.Errorf("failed to stop VM %s: %w", vm.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cs *CloningService) retainedPods() map[string]bool {
+	pods, err := cs.DatabaseService.GetRetainedPods()
+	if err != nil {
+		log.Printf("Failed to get retained pods: %v", err)
+		return nil
+	}
+
+	retained := make(map[string]bool)
+	for _, pod := range pods {
+		retained[pod] = true
+	}
+	return retained
+}
@@ -0,0 +1,138 @@
+package cloning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// Router NAT statuses
+const (
+	NATStatusOK      = "ok"
+	NATStatusMissing = "missing"
+	NATStatusUnknown = "unknown"
+)
+
+// =================================================
+// Pod Router Operations
+// =================================================
+
+// GetPodRouterReport reports whether a pod's router is running, reachable through its
+// guest agent, holds the pod's WAN address, and translates the pod's WAN subnet
+func (cs *CloningService) GetPodRouterReport(pod string) (*PodRouterReport, error) {
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := cs.podRouterVM(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PodRouterReport{
+		Pod:       pod,
+		Name:      vm.Name,
+		Node:      vm.NodeName,
+		VMID:      vm.VmId,
+		Status:    vm.RunningStatus,
+		NATStatus: NATStatusUnknown,
+	}
+
+	report.RouterType, err = cs.ProxmoxService.GetRouterType(proxmox.VM{Name: vm.Name, Node: vm.NodeName, VMID: vm.VmId})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get router type of pod %s: %w", pod, err)
+	}
+
+	report.AgentReachable = vm.RunningStatus == "running" && cs.ProxmoxService.AgentPing(vm.NodeName, vm.VmId) == nil
+
+	wan := cs.checkRouterWAN(vm, podNumber, report.AgentReachable)
+	report.ExpectedWANIP = wan.ExpectedWANIP
+	report.Addresses = wan.Addresses
+	report.WANIPVerified = wan.WANIPVerified
+
+	if report.AgentReachable {
+		err := cs.ProxmoxService.CheckPodRouterNAT(podNumber, vm.NodeName, vm.VmId, report.RouterType)
+		switch {
+		case err == nil:
+			report.NATStatus = NATStatusOK
+		case errors.Is(err, proxmox.ErrNATCheckUnsupported):
+		default:
+			report.NATStatus = NATStatusMissing
+			report.NATError = err.Error()
+		}
+	}
+
+	statuses, err := cs.DatabaseService.GetPodRouterStatuses()
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.Pod == pod {
+			report.Configuration = &status
+			break
+		}
+	}
+
+	return report, nil
+}
+
+// ReconfigurePodRouter runs the router configuration of a pod again, such as after the
+// initial configuration failed or the router was replaced. Cloud-init routers get their
+// cloud-init drive rewritten, which takes effect when the router restarts.
+func (cs *CloningService) ReconfigurePodRouter(pod string) error {
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return err
+	}
+
+	vm, err := cs.podRouterVM(pod)
+	if err != nil {
+		return err
+	}
+
+	routerType, err := cs.ProxmoxService.GetRouterType(proxmox.VM{Name: vm.Name, Node: vm.NodeName, VMID: vm.VmId})
+	if err != nil {
+		return fmt.Errorf("failed to get router type of pod %s: %w", pod, err)
+	}
+
+	if routerType == proxmox.CloudInitRouterType {
+		return cs.applyPodCloudInit(pod, podNumber)
+	}
+	if vm.RunningStatus != "running" {
+		return fmt.Errorf("router of pod %s is %s", pod, vm.RunningStatus)
+	}
+
+	lan, err := cs.podRouterLAN(pod, vm.NodeName, vm.VmId)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.ProxmoxService.ConfigurePodRouter(context.Background(), podNumber, vm.NodeName, vm.VmId, routerType, lan); err != nil {
+		return fmt.Errorf("failed to configure pod router: %w", err)
+	}
+
+	// Record the outcome over a pending or failed background configuration
+	statuses, err := cs.DatabaseService.GetPodRouterStatuses()
+	if err != nil {
+		log.Printf("Failed to get router status of pod %s: %v", pod, err)
+		return nil
+	}
+	for _, status := range statuses {
+		if status.Pod != pod {
+			continue
+		}
+
+		status.Node, status.VMID, status.RouterType = vm.NodeName, vm.VmId, routerType
+		status.Status = RouterStatusConfigured
+		status.Error = ""
+		if err := cs.DatabaseService.SavePodRouterStatus(status); err != nil {
+			log.Printf("Failed to update router status for pod %s: %v", pod, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Pod router statuses
+const (
+	RouterStatusPending    = "pending"
+	RouterStatusConfigured = "configured"
+	RouterStatusFailed     = "failed"
+)
+
+// =================================================
+// Pod Router Status Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodRouterStatus(status PodRouterStatus) error {
+	query := `INSERT INTO pod_router_status (pod, node, vmid, router_type, pod_number, status, attempts, error, retry_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE node = VALUES(node), vmid = VALUES(vmid), router_type = VALUES(router_type),
+		pod_number = VALUES(pod_number), status = VALUES(status), attempts = VALUES(attempts),
+		error = VALUES(error), retry_until = VALUES(retry_until)`
+	_, err := c.DB.Exec(query, status.Pod, status.Node, status.VMID, status.RouterType, status.PodNumber,
+		status.Status, status.Attempts, status.Error, status.RetryUntil.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodRouterStatuses() ([]PodRouterStatus, error) {
+	query := "SELECT pod, node, vmid, router_type, pod_number, status, attempts, error, retry_until, updated_at FROM pod_router_status"
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []PodRouterStatus{}
+	for rows.Next() {
+		var status PodRouterStatus
+		var errMsg sql.NullString
+		err := rows.Scan(
+			&status.Pod,
+			&status.Node,
+			&status.VMID,
+			&status.RouterType,
+			&status.PodNumber,
+			&status.Status,
+			&status.Attempts,
+			&errMsg,
+			&status.RetryUntil,
+			&status.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		status.Error = errMsg.String
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// GetPodRouterStatus returns the router status of a pod, or nil when it has none
+func (c *TemplateClient) GetPodRouterStatus(pod string) (*PodRouterStatus, error) {
+	query := "SELECT pod, node, vmid, router_type, pod_number, status, attempts, error, retry_until, updated_at FROM pod_router_status WHERE pod = ?"
+	var status PodRouterStatus
+	var errMsg sql.NullString
+	err := c.DB.QueryRow(query, pod).Scan(
+		&status.Pod,
+		&status.Node,
+		&status.VMID,
+		&status.RouterType,
+		&status.PodNumber,
+		&status.Status,
+		&status.Attempts,
+		&errMsg,
+		&status.RetryUntil,
+		&status.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	status.Error = errMsg.String
+
+	return &status, nil
+}
+
+// UpdatePodRouterStatus updates the router status of a pod without recreating it, and
+// reports whether the pod still had one
+func (c *TemplateClient) UpdatePodRouterStatus(status PodRouterStatus) (bool, error) {
+	query := `UPDATE pod_router_status SET node = ?, vmid = ?, router_type = ?, pod_number = ?, status = ?,
+		attempts = ?, error = ?, retry_until = ? WHERE pod = ?`
+	result, err := c.DB.Exec(query, status.Node, status.VMID, status.RouterType, status.PodNumber,
+		status.Status, status.Attempts, status.Error, status.RetryUntil.UTC(), status.Pod)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (c *TemplateClient) RenamePodRouterStatus(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_router_status SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodRouterStatus(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_router_status WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Deferred Router Configuration
+// =================================================
+
+// deferRouterConfiguration marks the pod's router as pending and keeps retrying its
+// configuration in the background instead of failing the deployment
+func (cs *CloningService) deferRouterConfiguration(routerInfo RouterInfo, cause error) {
+	status := PodRouterStatus{
+		Pod:        routerInfo.PoolName,
+		Node:       routerInfo.Node,
+		VMID:       routerInfo.VMID,
+		RouterType: routerInfo.RouterType,
+		PodNumber:  routerInfo.PodNumber,
+		Status:     RouterStatusPending,
+		Attempts:   1,
+		Error:      cause.Error(),
+		RetryUntil: time.Now().Add(cs.Config.RouterRetryWindow),
+	}
+
+	if err := cs.DatabaseService.SavePodRouterStatus(status); err != nil {
+		log.Printf("Failed to record pending router for pod %s: %v", status.Pod, err)
+	}
+
+	log.Printf("Router configuration for pod %s deferred until %s: %v", status.Pod, status.RetryUntil.Format(time.RFC3339), cause)
+	go cs.retryRouterConfiguration(status)
+}
+
+// resumePendingRouters restarts retries for routers left pending by a previous run of the server
+func (cs *CloningService) resumePendingRouters() {
+	statuses, err := cs.DatabaseService.GetPodRouterStatuses()
+	if err != nil {
+		log.Printf("Failed to get pending routers: %v", err)
+		return
+	}
+
+	for _, status := range statuses {
+		if status.Status == RouterStatusPending {
+			go cs.retryRouterConfiguration(status)
+		}
+	}
+}
+
+// retryRouterConfiguration configures the pod's router until it succeeds or the retry
+// window ends. It stops early once the pod's status row is gone or the router is no
+// longer in the pod's pool, since the pod was deleted or transferred and its VMID may
+// already belong to another pod.
+func (cs *CloningService) retryRouterConfiguration(status PodRouterStatus) {
+	for time.Now().Before(status.RetryUntil) {
+		time.Sleep(cs.Config.RouterRetryInterval)
+
+		current, err := cs.routerRetryCurrent(&status)
+		if err != nil {
+			log.Printf("Skipping router retry for pod %s: %v", status.Pod, err)
+			continue
+		}
+		if !current {
+			return
+		}
+
+		status.Attempts++
+		err = cs.configureRouter(status)
+		if err == nil {
+			status.Status = RouterStatusConfigured
+			status.Error = ""
+			log.Printf("Router for pod %s configured after %d attempts", status.Pod, status.Attempts)
+			cs.updateRouterRetry(status)
+			return
+		}
+
+		status.Error = err.Error()
+		if !cs.updateRouterRetry(status) {
+			return
+		}
+	}
+
+	status.Status = RouterStatusFailed
+	log.Printf("Giving up on router configuration for pod %s after %d attempts: %s", status.Pod, status.Attempts, status.Error)
+	cs.updateRouterRetry(status)
+}
+
+// routerRetryCurrent reloads the pod's router status and reports whether the retry should
+// continue, which is while the row is still pending and the router is still in the pod's pool
+func (cs *CloningService) routerRetryCurrent(status *PodRouterStatus) (bool, error) {
+	current, err := cs.DatabaseService.GetPodRouterStatus(status.Pod)
+	if err != nil {
+		return false, fmt.Errorf("failed to get router status: %w", err)
+	}
+	if current == nil || current.Status != RouterStatusPending {
+		log.Printf("Stopping router retries for pod %s, its router status was removed or resolved", status.Pod)
+		return false, nil
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(status.Pod)
+	if err != nil {
+		return false, fmt.Errorf("failed to get pool VMs: %w", err)
+	}
+	for _, vm := range poolVMs {
+		if vm.VmId == current.VMID && vm.NodeName == current.Node {
+			current.Attempts = max(current.Attempts, status.Attempts)
+			*status = *current
+			return true, nil
+		}
+	}
+
+	log.Printf("Stopping router retries for pod %s, router %d is no longer in its pool", status.Pod, current.VMID)
+	return false, nil
+}
+
+// updateRouterRetry records a retry's progress and reports whether the pod still has a status row
+func (cs *CloningService) updateRouterRetry(status PodRouterStatus) bool {
+	updated, err := cs.DatabaseService.UpdatePodRouterStatus(status)
+	if err != nil {
+		log.Printf("Failed to update router status for pod %s: %v", status.Pod, err)
+		return true
+	}
+	if !updated {
+		log.Printf("Stopping router retries for pod %s, its router status was removed", status.Pod)
+	}
+	return updated
+}
+
+func (cs *CloningService) configureRouter(status PodRouterStatus) error {
+	if err := cs.ProxmoxService.WaitForRunning(context.Background(), status.Node, status.VMID); err != nil {
+		return fmt.Errorf("router not running: %w", err)
+	}
+
+	lan, err := cs.podRouterLAN(status.Pod, status.Node, status.VMID)
+	if err != nil {
+		return err
+	}
+
+	// An earlier attempt may have gone through without being confirmed. The check only
+	// covers the WAN address, so routers with LAN subnets or segments are always configured again.
+	if len(lan.Subnets) == 0 && len(lan.Segments) == 0 {
+		if err := cs.ProxmoxService.CheckPodRouter(status.PodNumber, status.Node, status.VMID, status.RouterType); err == nil {
+			return nil
+		}
+	}
+
+	return cs.ProxmoxService.ConfigurePodRouter(context.Background(), status.PodNumber, status.Node, status.VMID, status.RouterType, lan)
+}
+
+// renamePodRouterStatus moves a transferred pod's router status to its new pool. Retries
+// under the old name stop once the row is gone, so a pending router is retried again
+// under the new name.
+func (cs *CloningService) renamePodRouterStatus(pod string, newPod string) {
+	if err := cs.DatabaseService.RenamePodRouterStatus(pod, newPod); err != nil {
+		log.Printf("Failed to update router status for pod %s: %v", pod, err)
+		return
+	}
+
+	status, err := cs.DatabaseService.GetPodRouterStatus(newPod)
+	if err != nil {
+		log.Printf("Failed to get router status of pod %s: %v", newPod, err)
+		return
+	}
+	if status != nil && status.Status == RouterStatusPending {
+		go cs.retryRouterConfiguration(*status)
+	}
+}
+
+// podRouterStatuses returns the router status of every pod whose router needed deferred configuration
+func (cs *CloningService) podRouterStatuses() map[string]string {
+	statuses, err := cs.DatabaseService.GetPodRouterStatuses()
+	if err != nil {
+		log.Printf("Failed to get pod router statuses: %v", err)
+		return nil
+	}
+
+	byPod := make(map[string]string)
+	for _, status := range statuses {
+		byPod[status.Pod] = status.Status
+	}
+	return byPod
+}
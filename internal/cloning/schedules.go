@@ -0,0 +1,232 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// Scheduled deployment statuses
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusRunning   = "running"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// =================================================
+// Scheduled Deployment Database Operations
+// =================================================
+
+func (c *TemplateClient) CreateScheduledDeployment(deployment ScheduledDeployment) (int64, error) {
+	targets, err := json.Marshal(deployment.Targets)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal targets: %w", err)
+	}
+
+	query := "INSERT INTO scheduled_deployments (template, targets, starting_vmid, run_at, time_zone, status, created_by) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	result, err := c.DB.Exec(query, deployment.Template, string(targets), deployment.StartingVMID, deployment.RunAt.UTC(), deployment.TimeZone, ScheduleStatusPending, deployment.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted id: %w", err)
+	}
+
+	return id, nil
+}
+
+func (c *TemplateClient) GetScheduledDeployments() ([]ScheduledDeployment, error) {
+	query := "SELECT id, template, targets, starting_vmid, run_at, time_zone, status, created_by, error, created_at FROM scheduled_deployments ORDER BY run_at DESC"
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildScheduledDeployments(rows)
+}
+
+// ClaimDueScheduledDeployments atomically marks pending deployments whose run time has
+// passed as running and returns them, so a deployment is only ever executed once
+func (c *TemplateClient) ClaimDueScheduledDeployments() ([]ScheduledDeployment, error) {
+	query := "SELECT id, template, targets, starting_vmid, run_at, time_zone, status, created_by, error, created_at FROM scheduled_deployments WHERE status = ? AND run_at <= ?"
+	rows, err := c.DB.Query(query, ScheduleStatusPending, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	due, err := buildScheduledDeployments(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []ScheduledDeployment
+	for _, deployment := range due {
+		result, err := c.DB.Exec("UPDATE scheduled_deployments SET status = ? WHERE id = ? AND status = ?", ScheduleStatusRunning, deployment.ID, ScheduleStatusPending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 1 {
+			deployment.Status = ScheduleStatusRunning
+			claimed = append(claimed, deployment)
+		}
+	}
+
+	return claimed, nil
+}
+
+func (c *TemplateClient) UpdateScheduledDeploymentStatus(id int64, status string, errMsg string) error {
+	query := "UPDATE scheduled_deployments SET status = ?, error = ? WHERE id = ?"
+	_, err := c.DB.Exec(query, status, errMsg, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// FailRunningScheduledDeployments marks every deployment still running as failed and
+// returns how many were marked
+func (c *TemplateClient) FailRunningScheduledDeployments(errMsg string) (int64, error) {
+	query := "UPDATE scheduled_deployments SET status = ?, error = ? WHERE status = ?"
+	result, err := c.DB.Exec(query, ScheduleStatusFailed, errMsg, ScheduleStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func (c *TemplateClient) CancelScheduledDeployment(id int64) error {
+	query := "UPDATE scheduled_deployments SET status = ? WHERE id = ? AND status = ?"
+	result, err := c.DB.Exec(query, ScheduleStatusCancelled, id, ScheduleStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no pending scheduled deployment with id %d", id)
+	}
+
+	return nil
+}
+
+// =================================================
+// Scheduled Deployment Worker
+// =================================================
+
+// runScheduler periodically executes scheduled deployments whose run time has passed. Each
+// deployment runs on its own so a long clone doesn't hold back the ones due after it.
+func (cs *CloningService) runScheduler() {
+	// Deployments left running by a previous process were interrupted, they are failed rather
+	// than run again since they may have created some of their pods
+	failed, err := cs.DatabaseService.FailRunningScheduledDeployments("interrupted by a server restart")
+	if err != nil {
+		log.Printf("Scheduler failed to mark interrupted deployments as failed: %v", err)
+	} else if failed > 0 {
+		log.Printf("Marked %d scheduled deployments interrupted by a restart as failed", failed)
+	}
+
+	ticker := time.NewTicker(cs.Config.SchedulerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		due, err := cs.DatabaseService.ClaimDueScheduledDeployments()
+		if err != nil {
+			log.Printf("Scheduler failed to get due deployments: %v", err)
+			continue
+		}
+
+		for _, deployment := range due {
+			go cs.runScheduledDeployment(deployment)
+		}
+	}
+}
+
+func (cs *CloningService) runScheduledDeployment(deployment ScheduledDeployment) {
+	log.Printf("Running scheduled deployment %d of template %s for %d targets (scheduled by %s)",
+		deployment.ID, deployment.Template, len(deployment.Targets), deployment.CreatedBy)
+
+	cloneReq := CloneRequest{
+		Template:                 deployment.Template,
+		Targets:                  deployment.Targets,
+		CheckExistingDeployments: false,
+		StartingVMID:             deployment.StartingVMID,
+		RequestedBy:              deployment.CreatedBy,
+		Priority:                 PriorityAdmin,
+	}
+
+	status := ScheduleStatusCompleted
+	errMsg := ""
+	if err := cs.CloneTemplate(context.Background(), cloneReq); err != nil {
+		log.Printf("Scheduled deployment %d failed: %v", deployment.ID, err)
+		status = ScheduleStatusFailed
+		errMsg = err.Error()
+	}
+
+	if err := cs.DatabaseService.UpdateScheduledDeploymentStatus(deployment.ID, status, errMsg); err != nil {
+		log.Printf("Failed to update status of scheduled deployment %d: %v", deployment.ID, err)
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func buildScheduledDeployments(rows *sql.Rows) ([]ScheduledDeployment, error) {
+	deployments := []ScheduledDeployment{}
+
+	for rows.Next() {
+		var deployment ScheduledDeployment
+		var targets string
+		var errMsg sql.NullString
+		err := rows.Scan(
+			&deployment.ID,
+			&deployment.Template,
+			&targets,
+			&deployment.StartingVMID,
+			&deployment.RunAt,
+			&deployment.TimeZone,
+			&deployment.Status,
+			&deployment.CreatedBy,
+			&errMsg,
+			&deployment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(targets), &deployment.Targets); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal targets for scheduled deployment %d: %w", deployment.ID, err)
+		}
+		deployment.Error = errMsg.String
+
+		// Present times in the zone the deployment was scheduled in
+		if loc, err := tools.LoadTimeZone(deployment.TimeZone); err == nil {
+			deployment.RunAt = deployment.RunAt.In(loc)
+			deployment.CreatedAt = deployment.CreatedAt.In(loc)
+		}
+
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
@@ -0,0 +1,395 @@
+package cloning
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// schemaStatements are applied in order at startup to add columns to the base
+// templates table and create any other tables the cloning service depends on. The IF
+// [NOT] EXISTS clauses of ALTER TABLE and CREATE INDEX are MariaDB only, EnsureSchema
+// checks information_schema instead so the statements also run on MySQL.
+var schemaStatements = []string{
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS clone_mode VARCHAR(10) NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS pod_quotas (
+		target VARCHAR(100) NOT NULL,
+		is_group BOOLEAN NOT NULL DEFAULT FALSE,
+		max_pods INT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		PRIMARY KEY (target, is_group)
+	)`,
+	`CREATE TABLE IF NOT EXISTS scheduled_deployments (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		template VARCHAR(100) NOT NULL,
+		targets TEXT NOT NULL,
+		starting_vmid INT NOT NULL DEFAULT 0,
+		run_at DATETIME NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		created_by VARCHAR(100) NOT NULL,
+		error TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_scheduled_deployments_status_run_at (status, run_at)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_exports (
+		token VARCHAR(36) PRIMARY KEY,
+		pod VARCHAR(255) NOT NULL,
+		vmid INT NOT NULL,
+		owner VARCHAR(100) NOT NULL,
+		filename VARCHAR(255),
+		size BIGINT,
+		status VARCHAR(20) NOT NULL,
+		error TEXT,
+		expires_at DATETIME NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_pod_exports_owner (owner)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_clone_records (
+		pod VARCHAR(255) NOT NULL,
+		vm_index INT NOT NULL,
+		template VARCHAR(100) NOT NULL,
+		target VARCHAR(100) NOT NULL,
+		is_group BOOLEAN NOT NULL DEFAULT FALSE,
+		pod_number INT NOT NULL,
+		source_vmid INT NOT NULL,
+		source_node VARCHAR(100) NOT NULL,
+		source_name VARCHAR(255) NOT NULL,
+		vmid INT NOT NULL,
+		is_router BOOLEAN NOT NULL DEFAULT FALSE,
+		status VARCHAR(20) NOT NULL,
+		error TEXT,
+		PRIMARY KEY (pod, vm_index)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_shares (
+		pod VARCHAR(255) NOT NULL,
+		shared_with VARCHAR(100) NOT NULL,
+		access VARCHAR(10) NOT NULL,
+		shared_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (pod, shared_with),
+		INDEX idx_pod_shares_shared_with (shared_with)
+	)`,
+	`CREATE TABLE IF NOT EXISTS template_flags (
+		template VARCHAR(100) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		vm_name VARCHAR(255) NOT NULL,
+		path VARCHAR(1024) NOT NULL,
+		PRIMARY KEY (template, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_flags (
+		pod VARCHAR(255) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		hash CHAR(64) NOT NULL,
+		solved_at DATETIME,
+		solved_by VARCHAR(100),
+		PRIMARY KEY (pod, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_router_status (
+		pod VARCHAR(255) PRIMARY KEY,
+		node VARCHAR(100) NOT NULL,
+		vmid INT NOT NULL,
+		router_type VARCHAR(20) NOT NULL,
+		pod_number INT NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		error TEXT,
+		retry_until DATETIME NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS clone_benchmarks (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		label VARCHAR(100) NOT NULL,
+		template VARCHAR(100) NOT NULL,
+		num_targets INT NOT NULL,
+		duration_ms BIGINT NOT NULL,
+		stages TEXT NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		error TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_clone_benchmarks_template (template, num_targets, created_at)
+	)`,
+	`ALTER TABLE scheduled_deployments ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS published_by VARCHAR(100) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS updated_by VARCHAR(100) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS updated_at DATETIME NULL`,
+	`CREATE TABLE IF NOT EXISTS template_changes (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		template VARCHAR(100) NOT NULL,
+		action VARCHAR(20) NOT NULL,
+		changed_by VARCHAR(100) NOT NULL,
+		changes TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_template_changes_template (template, created_at)
+	)`,
+	`CREATE TABLE IF NOT EXISTS warm_pool_sizes (
+		template VARCHAR(100) NOT NULL PRIMARY KEY,
+		size INT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS warm_pods (
+		pod VARCHAR(255) NOT NULL PRIMARY KEY,
+		template VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_warm_pods_template (template, created_at)
+	)`,
+	`ALTER TABLE pod_clone_records ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`,
+	`CREATE TABLE IF NOT EXISTS pod_archives (
+		pod VARCHAR(255) NOT NULL,
+		vmid INT NOT NULL,
+		node VARCHAR(100) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		volid VARCHAR(512) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (pod, vmid)
+	)`,
+	`CREATE TABLE IF NOT EXISTS template_placement_rules (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		template VARCHAR(100) NOT NULL,
+		rule_type VARCHAR(20) NOT NULL,
+		vm_names TEXT NOT NULL,
+		INDEX idx_template_placement_rules_template (template)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_id_usage (
+		recorded_on DATE PRIMARY KEY,
+		used INT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_retention (
+		pod VARCHAR(255) PRIMARY KEY,
+		owner VARCHAR(255) NOT NULL,
+		is_group BOOLEAN NOT NULL DEFAULT FALSE,
+		retained_by VARCHAR(255) NOT NULL,
+		retained_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_activity (
+		pod VARCHAR(255) PRIMARY KEY,
+		active_users INT NOT NULL DEFAULT 0,
+		uptime INT NOT NULL DEFAULT 0,
+		last_activity DATETIME NULL,
+		checked_at DATETIME NOT NULL
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS max_deployments INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS pod_access_status (
+		pod VARCHAR(255) PRIMARY KEY,
+		target VARCHAR(255) NOT NULL,
+		is_group BOOLEAN NOT NULL DEFAULT FALSE,
+		status VARCHAR(20) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		error TEXT,
+		retry_until DATETIME NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS organizations (
+		name VARCHAR(100) PRIMARY KEY,
+		description TEXT,
+		member_group VARCHAR(255) NOT NULL DEFAULT '',
+		admin_group VARCHAR(255) NOT NULL DEFAULT '',
+		min_pod_id INT NOT NULL DEFAULT 0,
+		max_pod_id INT NOT NULL DEFAULT 0,
+		default_pod_quota INT NOT NULL DEFAULT 0
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS organization VARCHAR(100) NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS deployment_blackouts (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		reason VARCHAR(255) NOT NULL,
+		starts_at DATETIME NOT NULL,
+		ends_at DATETIME NOT NULL,
+		created_by VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_deployment_blackouts_ends_at (ends_at)
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS category VARCHAR(100) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS tags VARCHAR(1500) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS difficulty VARCHAR(20) NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS template_deployments (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		template VARCHAR(100) NOT NULL,
+		targets INT NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		duration_ms BIGINT NOT NULL,
+		error TEXT,
+		requested_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_template_deployments_template (template, created_at)
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS allowed_groups VARCHAR(2000) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS deprecated_at DATETIME NULL`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS images TEXT NULL`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS vcpus INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS memory_bytes BIGINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS disk_bytes BIGINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS guide TEXT NULL`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS draft BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS min_vmid INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS max_vmid INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS lan_subnets TEXT NULL`,
+	`CREATE FULLTEXT INDEX IF NOT EXISTS idx_templates_search ON templates (name, description, authors, tags)`,
+	`CREATE TABLE IF NOT EXISTS pod_subnets (
+		pod VARCHAR(255) NOT NULL PRIMARY KEY,
+		pod_number INT NOT NULL,
+		subnet VARCHAR(43) NOT NULL,
+		wan_ip VARCHAR(39) NOT NULL,
+		allocated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX idx_pod_subnets_subnet (subnet)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_firewall_rules (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		pod VARCHAR(255) NOT NULL,
+		direction VARCHAR(3) NOT NULL,
+		action VARCHAR(10) NOT NULL,
+		protocol VARCHAR(10) NOT NULL DEFAULT '',
+		port VARCHAR(50) NOT NULL DEFAULT '',
+		source VARCHAR(100) NOT NULL DEFAULT '',
+		comment VARCHAR(255) NOT NULL DEFAULT '',
+		created_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_pod_firewall_rules_pod (pod)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_port_forwards (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		pod VARCHAR(255) NOT NULL,
+		protocol VARCHAR(3) NOT NULL,
+		external_port INT NOT NULL,
+		internal_ip VARCHAR(45) NOT NULL,
+		internal_port INT NOT NULL,
+		description VARCHAR(255) NOT NULL DEFAULT '',
+		created_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX idx_pod_port_forwards_port (pod, protocol, external_port)
+	)`,
+	`ALTER TABLE pod_port_forwards ADD COLUMN IF NOT EXISTS slot INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS pod_vpn_configs (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		pod VARCHAR(255) NOT NULL,
+		username VARCHAR(100) NOT NULL,
+		address VARCHAR(45) NOT NULL,
+		public_key VARCHAR(64) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX idx_pod_vpn_configs_address (address),
+		INDEX idx_pod_vpn_configs_pod (pod)
+	)`,
+	// Private keys are only handed out when a config is created, never stored
+	`ALTER TABLE pod_vpn_configs DROP COLUMN IF EXISTS private_key`,
+	`CREATE TABLE IF NOT EXISTS vnet_settings (
+		id TINYINT PRIMARY KEY,
+		vnet_prefix VARCHAR(20) NOT NULL,
+		zone VARCHAR(50) NOT NULL DEFAULT '',
+		vlan_base INT NOT NULL,
+		updated_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS vlan_reservations (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		first_tag INT NOT NULL,
+		last_tag INT NOT NULL,
+		reason VARCHAR(255) NOT NULL,
+		created_by VARCHAR(100) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS pod_vnets (
+		pod VARCHAR(255) PRIMARY KEY,
+		vnet VARCHAR(50) NOT NULL,
+		vlan_tag INT NOT NULL,
+		allocated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX idx_pod_vnets_vnet (vnet)
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS segments TEXT NULL`,
+	`CREATE TABLE IF NOT EXISTS pod_segments (
+		pod VARCHAR(255) NOT NULL,
+		alias VARCHAR(20) NOT NULL,
+		vnet VARCHAR(50) NOT NULL,
+		vlan_tag INT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (pod, alias),
+		UNIQUE INDEX idx_pod_segments_vnet (vnet)
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS rate_limit DOUBLE NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS pod_rate_limits (
+		pod VARCHAR(255) PRIMARY KEY,
+		rate DOUBLE NOT NULL,
+		updated_by VARCHAR(100) NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS remote_access TEXT NULL`,
+	`CREATE TABLE IF NOT EXISTS pod_guacamole_connections (
+		pod VARCHAR(255) NOT NULL,
+		connection_id VARCHAR(50) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (pod, connection_id)
+	)`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS storage VARCHAR(100) NOT NULL DEFAULT ''`,
+	`ALTER TABLE templates ADD COLUMN IF NOT EXISTS ha_group VARCHAR(100) NOT NULL DEFAULT ''`,
+}
+
+var (
+	schemaAddColumnPattern   = regexp.MustCompile(`(?is)^ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (\w+) (.+)$`)
+	schemaDropColumnPattern  = regexp.MustCompile(`(?is)^ALTER TABLE (\w+) DROP COLUMN IF EXISTS (\w+)$`)
+	schemaCreateIndexPattern = regexp.MustCompile(`(?is)^CREATE ((?:FULLTEXT |UNIQUE )?INDEX) IF NOT EXISTS (\w+) ON (\w+) (.+)$`)
+)
+
+// schemaCheck is a schema statement that only runs depending on whether the column or
+// index it adds or drops exists
+type schemaCheck struct {
+	query     string // Counts the column or index in the current database
+	args      []any
+	runIfSeen bool   // Whether the statement runs when the column or index exists
+	statement string // The statement without its IF [NOT] EXISTS clause
+}
+
+// EnsureSchema creates any missing tables and columns used by the cloning service
+func (c *TemplateClient) EnsureSchema() error {
+	for _, statement := range schemaStatements {
+		if check, ok := conditionalSchemaStatement(statement); ok {
+			var count int
+			if err := c.DB.QueryRow(check.query, check.args...).Scan(&count); err != nil {
+				return fmt.Errorf("failed to check schema: %w", err)
+			}
+			if (count > 0) != check.runIfSeen {
+				continue
+			}
+			statement = check.statement
+		}
+
+		if _, err := c.DB.Exec(statement); err != nil {
+			return fmt.Errorf("failed to apply schema statement: %w", err)
+		}
+	}
+	return nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// conditionalSchemaStatement splits a statement using a MariaDB only IF [NOT] EXISTS
+// clause into an information_schema check and the statement without the clause
+func conditionalSchemaStatement(statement string) (schemaCheck, bool) {
+	const columnQuery = "SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?"
+	const indexQuery = "SELECT COUNT(*) FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?"
+
+	if match := schemaAddColumnPattern.FindStringSubmatch(statement); match != nil {
+		return schemaCheck{
+			query:     columnQuery,
+			args:      []any{match[1], match[2]},
+			runIfSeen: false,
+			statement: fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", match[1], match[2], match[3]),
+		}, true
+	}
+	if match := schemaDropColumnPattern.FindStringSubmatch(statement); match != nil {
+		return schemaCheck{
+			query:     columnQuery,
+			args:      []any{match[1], match[2]},
+			runIfSeen: true,
+			statement: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", match[1], match[2]),
+		}, true
+	}
+	if match := schemaCreateIndexPattern.FindStringSubmatch(statement); match != nil {
+		return schemaCheck{
+			query:     indexQuery,
+			args:      []any{match[3], match[2]},
+			runIfSeen: false,
+			statement: fmt.Sprintf("CREATE %s %s ON %s %s", match[1], match[2], match[3], match[4]),
+		}, true
+	}
+
+	return schemaCheck{}, false
+}
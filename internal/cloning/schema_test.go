@@ -0,0 +1,72 @@
+package cloning
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestConditionalSchemaStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		runIfSeen bool
+		args      []any
+		want      string
+	}{
+		{
+			name:      "add column",
+			statement: `ALTER TABLE templates ADD COLUMN IF NOT EXISTS clone_mode VARCHAR(10) NOT NULL DEFAULT ''`,
+			args:      []any{"templates", "clone_mode"},
+			want:      `ALTER TABLE templates ADD COLUMN clone_mode VARCHAR(10) NOT NULL DEFAULT ''`,
+		},
+		{
+			name:      "drop column",
+			statement: `ALTER TABLE pod_vpn_configs DROP COLUMN IF EXISTS private_key`,
+			runIfSeen: true,
+			args:      []any{"pod_vpn_configs", "private_key"},
+			want:      `ALTER TABLE pod_vpn_configs DROP COLUMN private_key`,
+		},
+		{
+			name:      "create fulltext index",
+			statement: `CREATE FULLTEXT INDEX IF NOT EXISTS idx_templates_search ON templates (name, description, authors, tags)`,
+			args:      []any{"templates", "idx_templates_search"},
+			want:      `CREATE FULLTEXT INDEX idx_templates_search ON templates (name, description, authors, tags)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, ok := conditionalSchemaStatement(tt.statement)
+			if !ok {
+				t.Fatalf("statement was not recognized as conditional")
+			}
+			if check.statement != tt.want {
+				t.Errorf("statement = %q, want %q", check.statement, tt.want)
+			}
+			if check.runIfSeen != tt.runIfSeen {
+				t.Errorf("runIfSeen = %t, want %t", check.runIfSeen, tt.runIfSeen)
+			}
+			if len(check.args) != 2 || check.args[0] != tt.args[0] || check.args[1] != tt.args[1] {
+				t.Errorf("args = %v, want %v", check.args, tt.args)
+			}
+		})
+	}
+}
+
+// Every statement must be portable once its MariaDB only clause is checked instead
+func TestSchemaStatementsArePortable(t *testing.T) {
+	mariaDBOnly := regexp.MustCompile(`(?i)\b(ALTER TABLE|INDEX)\b.*\bIF (NOT )?EXISTS\b`)
+
+	for _, statement := range schemaStatements {
+		if check, ok := conditionalSchemaStatement(statement); ok {
+			statement = check.statement
+		}
+		if strings.HasPrefix(strings.TrimSpace(statement), "CREATE TABLE") {
+			continue
+		}
+		if mariaDBOnly.MatchString(statement) {
+			t.Errorf("statement uses a MariaDB only clause: %s", statement)
+		}
+	}
+}
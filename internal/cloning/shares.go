@@ -0,0 +1,207 @@
+package cloning
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Pod share access levels
+const (
+	ShareAccessRead = "read"
+	ShareAccessFull = "full"
+)
+
+// shareRoles maps share access levels to the Proxmox roles granted on the pool
+var shareRoles = map[string][]string{
+	ShareAccessRead: {"PVEAuditor"},
+	ShareAccessFull: {"PVEVMUser", "PVEPoolUser"},
+}
+
+// =================================================
+// Pod Share Database Operations
+// =================================================
+
+func (c *TemplateClient) SavePodShare(share PodShare) error {
+	query := `INSERT INTO pod_shares (pod, shared_with, access, shared_by) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE access = VALUES(access), shared_by = VALUES(shared_by)`
+	_, err := c.DB.Exec(query, share.Pod, share.SharedWith, share.Access, share.SharedBy)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetPodShares(pod string) ([]PodShare, error) {
+	query := "SELECT pod, shared_with, access, shared_by, created_at FROM pod_shares WHERE pod = ? ORDER BY shared_with"
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodShares(rows)
+}
+
+func (c *TemplateClient) GetSharesForUser(username string) ([]PodShare, error) {
+	query := "SELECT pod, shared_with, access, shared_by, created_at FROM pod_shares WHERE shared_with = ? ORDER BY pod"
+	rows, err := c.DB.Query(query, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildPodShares(rows)
+}
+
+func (c *TemplateClient) DeletePodShare(pod string, sharedWith string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_shares WHERE pod = ? AND shared_with = ?", pod, sharedWith)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) RenamePodShares(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_shares SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeletePodShares(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_shares WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Pod Share Operations
+// =================================================
+
+// SharePod grants another Kamino user read-only or full access to the pool and records the share
+func (cs *CloningService) SharePod(pod string, sharedBy string, sharedWith string, access string) error {
+	roles, ok := shareRoles[access]
+	if !ok {
+		return fmt.Errorf("invalid share access level %q", access)
+	}
+
+	if strings.EqualFold(sharedBy, sharedWith) || PodOwnedBy(pod, sharedWith) {
+		return fmt.Errorf("pod %s already belongs to %s", pod, sharedWith)
+	}
+
+	if _, err := cs.LDAPService.GetUserDN(sharedWith); err != nil {
+		return fmt.Errorf("user %s not found: %w", sharedWith, err)
+	}
+
+	// Replace any previous grant so changing the access level doesn't leave extra roles behind
+	existing, err := cs.findPodShare(pod, sharedWith)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Access != access {
+		if err := cs.ProxmoxService.RemovePoolUserRoles(pod, sharedWith, shareRoles[existing.Access]); err != nil {
+			return err
+		}
+	}
+
+	if err := cs.ProxmoxService.SetPoolUserRoles(pod, sharedWith, roles); err != nil {
+		return err
+	}
+
+	share := PodShare{Pod: pod, SharedWith: sharedWith, Access: access, SharedBy: sharedBy}
+	if err := cs.DatabaseService.SavePodShare(share); err != nil {
+		return fmt.Errorf("failed to record share: %w", err)
+	}
+
+	return nil
+}
+
+// UnsharePod revokes a user's shared access to the pool
+func (cs *CloningService) UnsharePod(pod string, sharedWith string) error {
+	share, err := cs.findPodShare(pod, sharedWith)
+	if err != nil {
+		return err
+	}
+	if share == nil {
+		return fmt.Errorf("pod %s is not shared with %s", pod, sharedWith)
+	}
+
+	if err := cs.ProxmoxService.RemovePoolUserRoles(pod, sharedWith, shareRoles[share.Access]); err != nil {
+		return err
+	}
+
+	if err := cs.DatabaseService.DeletePodShare(pod, sharedWith); err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+
+	return nil
+}
+
+// movePodShares re-grants shared access on a pod's new pool after an ownership transfer
+func (cs *CloningService) movePodShares(pod string, newPod string) {
+	shares, err := cs.DatabaseService.GetPodShares(pod)
+	if err != nil {
+		log.Printf("Failed to get shares for pod %s: %v", pod, err)
+		return
+	}
+
+	for _, share := range shares {
+		if err := cs.ProxmoxService.SetPoolUserRoles(newPod, share.SharedWith, shareRoles[share.Access]); err != nil {
+			log.Printf("Failed to re-share pod %s with %s: %v", newPod, share.SharedWith, err)
+		}
+	}
+
+	if err := cs.DatabaseService.RenamePodShares(pod, newPod); err != nil {
+		log.Printf("Failed to update shares for pod %s: %v", pod, err)
+	}
+}
+
+func (cs *CloningService) findPodShare(pod string, sharedWith string) (*PodShare, error) {
+	shares, err := cs.DatabaseService.GetPodShares(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shares for pod %s: %w", pod, err)
+	}
+
+	for _, share := range shares {
+		if strings.EqualFold(share.SharedWith, sharedWith) {
+			return &share, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func buildPodShares(rows *sql.Rows) ([]PodShare, error) {
+	shares := []PodShare{}
+
+	for rows.Next() {
+		var share PodShare
+		err := rows.Scan(
+			&share.Pod,
+			&share.SharedWith,
+			&share.Access,
+			&share.SharedBy,
+			&share.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
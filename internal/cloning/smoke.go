@@ -0,0 +1,141 @@
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Smoke test steps
+const (
+	SmokeStepClone  = "clone"
+	SmokeStepRouter = "router"
+	SmokeStepStart  = "start"
+	SmokeStepDelete = "delete"
+)
+
+// RunSmokeTest clones the designated smoke test template, checks that its router was
+// configured and its VMs start, then deletes the pod, timing every step. The pod is
+// deleted even when an earlier step fails. Only one smoke test runs at a time.
+func (cs *CloningService) RunSmokeTest(requestedBy string) (*SmokeTestResult, error) {
+	if cs.Config.SmokeTestTemplate == "" || cs.Config.SmokeTestUser == "" {
+		return nil, fmt.Errorf("smoke tests are disabled, SMOKE_TEST_TEMPLATE and SMOKE_TEST_USER must be set")
+	}
+
+	if !cs.smokeTestMutex.TryLock() {
+		return nil, fmt.Errorf("a smoke test is already running")
+	}
+	defer cs.smokeTestMutex.Unlock()
+
+	result := &SmokeTestResult{
+		Template:  cs.Config.SmokeTestTemplate,
+		Success:   true,
+		Steps:     []SmokeTestStep{},
+		StartedAt: time.Now(),
+	}
+
+	// 1. Clone the template, CloneTemplate assigns the pool name to the target
+	targets := []CloneTarget{{Name: cs.Config.SmokeTestUser}}
+	cloned := result.runStep(SmokeStepClone, func() error {
+		return cs.CloneTemplate(context.Background(), CloneRequest{
+			Template:    cs.Config.SmokeTestTemplate,
+			Targets:     targets,
+			RequestedBy: requestedBy,
+			Priority:    PriorityAdmin,
+		})
+	})
+	result.Pod = targets[0].PoolName
+
+	// 2. Check the router was configured rather than deferred and holds its WAN address
+	if cloned {
+		result.runStep(SmokeStepRouter, func() error {
+			return cs.verifySmokeRouter(result.Pod)
+		})
+	}
+
+	// 3. Start every VM and wait for it to run
+	if cloned {
+		result.runStep(SmokeStepStart, func() error {
+			return cs.startSmokeVMs(result.Pod)
+		})
+	}
+
+	// 4. Remove the pod, a failed clone may have left nothing behind to delete
+	if result.Pod != "" {
+		result.runStep(SmokeStepDelete, func() error {
+			return cs.DeletePod(result.Pod)
+		})
+	}
+
+	result.Duration = time.Since(result.StartedAt).Milliseconds()
+	log.Printf("Smoke test of template %s finished in %dms, success: %t", result.Template, result.Duration, result.Success)
+
+	return result, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// runStep times a step and records its outcome, returning whether it succeeded
+func (r *SmokeTestResult) runStep(name string, step func() error) bool {
+	start := time.Now()
+	err := step()
+
+	result := SmokeTestStep{
+		Name:     name,
+		Success:  err == nil,
+		Duration: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		r.Success = false
+	}
+	r.Steps = append(r.Steps, result)
+
+	return err == nil
+}
+
+func (cs *CloningService) verifySmokeRouter(pod string) error {
+	if status := cs.podRouterStatuses()[pod]; status != "" {
+		return fmt.Errorf("router configuration was deferred, status: %s", status)
+	}
+
+	health, err := cs.GetPodHealth(pod)
+	if err != nil {
+		return err
+	}
+	if health.Router == nil {
+		return fmt.Errorf("pod has no router")
+	}
+	if !health.Router.WANIPVerified {
+		return fmt.Errorf("router does not have WAN address %s", health.Router.ExpectedWANIP)
+	}
+
+	return nil
+}
+
+func (cs *CloningService) startSmokeVMs(pod string) error {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cs.Config.RouterWaitTimeout)
+	defer cancel()
+
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() || vm.RunningStatus == "running" {
+			continue
+		}
+		if err := cs.ProxmoxService.StartVM(vm.NodeName, vm.VmId); err != nil {
+			return fmt.Errorf("failed to start VM %s: %w", vm.Name, err)
+		}
+		if err := cs.ProxmoxService.WaitForRunning(ctx, vm.NodeName, vm.VmId); err != nil {
+			return fmt.Errorf("VM %s did not start: %w", vm.Name, err)
+		}
+	}
+
+	return nil
+}
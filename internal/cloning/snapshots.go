@@ -0,0 +1,107 @@
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// snapshotNamePattern matches the snapshot names Proxmox accepts
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{1,39}$`)
+
+// =================================================
+// Pod VM Snapshot Operations
+// =================================================
+
+// GetPodVMSnapshots lists the snapshots of a VM of the pod, oldest first
+func (cs *CloningService) GetPodVMSnapshots(pod string, vmID int) ([]proxmox.VMSnapshot, error) {
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.vmSnapshots(vm)
+}
+
+// CreatePodVMSnapshot snapshots a VM of the pod, refusing once the VM holds the number
+// of snapshots MAX_VM_SNAPSHOTS allows. Cancelling ctx stops waiting for the snapshot task.
+func (cs *CloningService) CreatePodVMSnapshot(ctx context.Context, pod string, vmID int, name string, description string) error {
+	if cs.Config.MaxVMSnapshots <= 0 {
+		return fmt.Errorf("VM snapshots are disabled")
+	}
+	if !snapshotNamePattern.MatchString(name) || name == "current" {
+		return fmt.Errorf("invalid snapshot name %q, use 2 to 40 letters, digits, - or _ starting with a letter", name)
+	}
+
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := cs.vmSnapshots(vm)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) >= cs.Config.MaxVMSnapshots {
+		return fmt.Errorf("VM %d already has %d snapshots, the most allowed", vmID, len(snapshots))
+	}
+	if slices.ContainsFunc(snapshots, func(snapshot proxmox.VMSnapshot) bool { return snapshot.Name == name }) {
+		return fmt.Errorf("VM %d already has a snapshot named %s", vmID, name)
+	}
+
+	upid, err := cs.ProxmoxService.CreateVMSnapshot(vm.NodeName, vmID, name, description)
+	if err != nil {
+		return err
+	}
+
+	return cs.ProxmoxService.WaitForTask(ctx, vm.NodeName, upid, cs.Config.SnapshotTimeout)
+}
+
+// RollbackPodVMSnapshot returns a VM of the pod to one of its snapshots
+func (cs *CloningService) RollbackPodVMSnapshot(ctx context.Context, pod string, vmID int, name string) error {
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := cs.vmSnapshots(vm)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(snapshots, func(snapshot proxmox.VMSnapshot) bool { return snapshot.Name == name }) {
+		return fmt.Errorf("snapshot %s not found on VM %d", name, vmID)
+	}
+
+	upid, err := cs.ProxmoxService.RollbackVMSnapshot(vm.NodeName, vmID, name)
+	if err != nil {
+		return err
+	}
+
+	return cs.ProxmoxService.WaitForTask(ctx, vm.NodeName, upid, cs.Config.SnapshotTimeout)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// vmSnapshots returns the snapshots of a VM without the "current" entry Proxmox lists for
+// the running state
+func (cs *CloningService) vmSnapshots(vm proxmox.VirtualResource) ([]proxmox.VMSnapshot, error) {
+	snapshots, err := cs.ProxmoxService.GetVMSnapshots(vm.NodeName, vm.VmId)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := []proxmox.VMSnapshot{}
+	for _, snapshot := range snapshots {
+		if snapshot.Name != "current" {
+			taken = append(taken, snapshot)
+		}
+	}
+	slices.SortFunc(taken, func(a, b proxmox.VMSnapshot) int { return int(a.SnapTime - b.SnapTime) })
+
+	return taken, nil
+}
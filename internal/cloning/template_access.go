@@ -0,0 +1,55 @@
+package cloning
+
+import (
+	"fmt"
+	"slices"
+)
+
+// =================================================
+// Template Access Database Operations
+// =================================================
+
+func (c *TemplateClient) SetTemplateAllowedGroups(templateName string, groups []string) error {
+	allowedGroups, err := marshalStrings(groups)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DB.Exec("UPDATE templates SET allowed_groups = ? WHERE name = ?", allowedGroups, templateName)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Template Access Operations
+// =================================================
+
+// SetTemplateAllowedGroups limits a template to members of the given groups, an empty
+// list makes it available to every user again
+func (cs *CloningService) SetTemplateAllowedGroups(templateName string, groups []string) error {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return fmt.Errorf("template not found: %s", templateName)
+	}
+
+	return cs.DatabaseService.SetTemplateAllowedGroups(templateName, groups)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// templateAllowsGroups reports whether a member of the groups may see and clone the template
+func templateAllowsGroups(template KaminoTemplate, groups []string) bool {
+	if len(template.AllowedGroups) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(template.AllowedGroups, func(group string) bool { return containsFold(groups, group) })
+}
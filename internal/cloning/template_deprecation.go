@@ -0,0 +1,161 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// deprecationActor is recorded as the author of changes made by the deprecation worker
+const deprecationActor = "system"
+
+// =================================================
+// Template Deprecation Database Operations
+// =================================================
+
+// SetTemplateDeprecated marks a template deprecated from now on, or clears the mark
+func (c *TemplateClient) SetTemplateDeprecated(templateName string, deprecated bool) error {
+	query := "UPDATE templates SET deprecated_at = NULL WHERE name = ?"
+	if deprecated {
+		query = "UPDATE templates SET deprecated_at = COALESCE(deprecated_at, UTC_TIMESTAMP()) WHERE name = ?"
+	}
+
+	if _, err := c.DB.Exec(query, templateName); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateDeprecations returns when each deprecated template was deprecated
+func (c *TemplateClient) GetTemplateDeprecations() (map[string]time.Time, error) {
+	rows, err := c.DB.Query("SELECT name, deprecated_at FROM templates WHERE deprecated_at IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	deprecated := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var deprecatedAt time.Time
+		if err := rows.Scan(&name, &deprecatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		deprecated[name] = deprecatedAt
+	}
+
+	return deprecated, nil
+}
+
+// =================================================
+// Template Deprecation Operations
+// =================================================
+
+// SetTemplateDeprecated deprecates a template, removing it from the user catalog while
+// existing pods keep working, or reverses the deprecation. Deprecated templates are
+// hidden and later deleted by retireDeprecatedTemplates.
+func (cs *CloningService) SetTemplateDeprecated(templateName string, deprecated bool, changedBy string) error {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return fmt.Errorf("template not found: %s", templateName)
+	}
+
+	if err := cs.DatabaseService.SetTemplateDeprecated(templateName, deprecated); err != nil {
+		return err
+	}
+
+	if deprecated != (template.DeprecatedAt != "") {
+		cs.markTemplateUpdated(templateName, changedBy)
+		cs.recordTemplateChange(templateName, TemplateActionDeprecate, changedBy, []FieldChange{
+			{Field: "deprecated", OldValue: !deprecated, NewValue: deprecated},
+		})
+	}
+	return nil
+}
+
+// retireDeprecatedTemplates periodically hides templates deprecated for longer than the
+// hide grace period, and deletes the template and its pool once deprecated for longer
+// than the delete grace period and no pods of the template remain
+func (cs *CloningService) retireDeprecatedTemplates() {
+	ticker := time.NewTicker(cs.Config.DeprecationInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deprecated, err := cs.DatabaseService.GetTemplateDeprecations()
+		if err != nil {
+			log.Printf("Deprecation worker failed to get deprecated templates: %v", err)
+			continue
+		}
+		if len(deprecated) == 0 {
+			continue
+		}
+
+		pods, err := cs.AdminGetPods()
+		if err != nil {
+			log.Printf("Deprecation worker failed to get deployed pods: %v", err)
+			continue
+		}
+
+		for templateName, deprecatedAt := range deprecated {
+			age := time.Since(deprecatedAt)
+
+			if cs.Config.DeprecationDeleteAfter > 0 && age > cs.Config.DeprecationDeleteAfter {
+				if err := cs.deleteDeprecatedTemplate(templateName, pods); err != nil {
+					log.Printf("Deprecation worker failed to delete template %s: %v", templateName, err)
+				}
+				continue
+			}
+
+			if age > cs.Config.DeprecationHideAfter {
+				if err := cs.hideDeprecatedTemplate(templateName); err != nil {
+					log.Printf("Deprecation worker failed to hide template %s: %v", templateName, err)
+				}
+			}
+		}
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (cs *CloningService) hideDeprecatedTemplate(templateName string) error {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if !template.TemplateVisible {
+		return nil
+	}
+
+	log.Printf("Hiding template %s, deprecated since %s", templateName, template.DeprecatedAt)
+	return cs.ToggleTemplateVisibility(templateName, deprecationActor)
+}
+
+// deleteDeprecatedTemplate removes the template pool and the template, unless pods of the
+// template are still deployed since linked clones depend on the template's disks
+func (cs *CloningService) deleteDeprecatedTemplate(templateName string, pods []Pod) error {
+	for _, pod := range pods {
+		if PodTemplateName(pod.Name) == strings.ToLower(templateName) {
+			return nil
+		}
+	}
+
+	poolName := "kamino_template_" + templateName
+	templatePool, err := cs.ProxmoxService.GetPoolVMs(poolName)
+	if err != nil {
+		return fmt.Errorf("failed to get template pool: %w", err)
+	}
+
+	log.Printf("Deleting deprecated template %s and its %d VMs", templateName, len(templatePool))
+	if err := cs.removeTemplatePool(poolName, templatePool); err != nil {
+		return err
+	}
+
+	return cs.DeleteTemplate(templateName, deprecationActor)
+}
@@ -0,0 +1,131 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTemplateImages is the largest number of images in a template's gallery
+const maxTemplateImages = 12
+
+// =================================================
+// Template Gallery Database Operations
+// =================================================
+
+func (c *TemplateClient) SetTemplateImages(templateName string, images []string) error {
+	value, err := marshalStrings(images)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DB.Exec("UPDATE templates SET images = ? WHERE name = ?", value, templateName)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Template Gallery Operations
+// =================================================
+
+// AddTemplateImage stores the image uploaded in the request and appends it to the end of
+// the template's gallery, returning the updated gallery
+func (cs *CloningService) AddTemplateImage(c *gin.Context, templateName string, addedBy string) ([]string, error) {
+	template, err := cs.galleryTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	if len(template.Images) >= maxTemplateImages {
+		return nil, fmt.Errorf("template %s already has the maximum of %d images", templateName, maxTemplateImages)
+	}
+
+	result, err := cs.DatabaseService.UploadTemplateImage(c)
+	if err != nil {
+		return nil, err
+	}
+
+	images := append(slices.Clone(template.Images), result.Filename)
+	if err := cs.saveTemplateImages(template, images, addedBy); err != nil {
+		if err := cs.DatabaseService.DeleteImage(result.Filename); err != nil {
+			log.Printf("Failed to remove unsaved image of template %s: %v", templateName, err)
+		}
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// ReorderTemplateImages sets the order of a template's gallery, the images must be
+// exactly the images already in the gallery
+func (cs *CloningService) ReorderTemplateImages(templateName string, images []string, reorderedBy string) error {
+	template, err := cs.galleryTemplate(templateName)
+	if err != nil {
+		return err
+	}
+
+	current := slices.Sorted(slices.Values(template.Images))
+	requested := slices.Sorted(slices.Values(images))
+	if !slices.Equal(current, requested) {
+		return fmt.Errorf("images must list every image of the gallery exactly once")
+	}
+
+	return cs.saveTemplateImages(template, images, reorderedBy)
+}
+
+// DeleteTemplateImage removes an image from a template's gallery and deletes its file
+func (cs *CloningService) DeleteTemplateImage(templateName string, image string, deletedBy string) error {
+	template, err := cs.galleryTemplate(templateName)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(template.Images, image) {
+		return fmt.Errorf("image %s is not in the gallery of template %s", image, templateName)
+	}
+
+	images := slices.DeleteFunc(slices.Clone(template.Images), func(i string) bool { return i == image })
+	if err := cs.saveTemplateImages(template, images, deletedBy); err != nil {
+		return err
+	}
+
+	// The image is already out of the gallery, a leftover file is only logged
+	if err := cs.DatabaseService.DeleteImage(image); err != nil {
+		log.Printf("Failed to delete gallery image %s of template %s: %v", image, templateName, err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (cs *CloningService) galleryTemplate(templateName string) (KaminoTemplate, error) {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return template, fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return template, fmt.Errorf("template not found: %s", templateName)
+	}
+
+	return template, nil
+}
+
+func (cs *CloningService) saveTemplateImages(template KaminoTemplate, images []string, changedBy string) error {
+	if err := cs.DatabaseService.SetTemplateImages(template.Name, images); err != nil {
+		return err
+	}
+
+	cs.markTemplateUpdated(template.Name, changedBy)
+	cs.recordTemplateChange(template.Name, TemplateActionEdit, changedBy, []FieldChange{
+		{Field: "images", OldValue: strings.Join(template.Images, ","), NewValue: strings.Join(images, ",")},
+	})
+	return nil
+}
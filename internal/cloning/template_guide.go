@@ -0,0 +1,56 @@
+package cloning
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	// guideMarkdown renders guides as GitHub flavored markdown. Raw HTML is left out of
+	// the output, which goldmark does unless it is configured as unsafe.
+	guideMarkdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// guidePolicy strips anything left in the rendered guide that could run code, such as
+	// links with unsafe schemes however they are encoded
+	guidePolicy = bluemonday.UGCPolicy()
+)
+
+// =================================================
+// Template Guide Operations
+// =================================================
+
+// GetPodGuide returns the lab guide of the template the pod was deployed from, rendered
+// from markdown to sanitized HTML
+func (cs *CloningService) GetPodGuide(pod string) (string, error) {
+	templateName := PodTemplateName(pod)
+	if templateName == "" {
+		return "", fmt.Errorf("pod %s was not deployed from a template", pod)
+	}
+
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return "", fmt.Errorf("template not found: %s", templateName)
+	}
+
+	return renderGuide(template.Guide)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// renderGuide renders a markdown guide to HTML that is safe to show in the browser
+func renderGuide(guide string) (string, error) {
+	var rendered bytes.Buffer
+	if err := guideMarkdown.Convert([]byte(guide), &rendered); err != nil {
+		return "", fmt.Errorf("failed to render guide: %w", err)
+	}
+
+	return guidePolicy.Sanitize(rendered.String()), nil
+}
@@ -0,0 +1,66 @@
+package cloning
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRenderGuideBlocksScripts(t *testing.T) {
+	tests := []struct {
+		name  string
+		guide string
+	}{
+		{name: "raw html", guide: `<script>alert(1)</script>`},
+		{name: "html after unmatched backtick", guide: "Run `ls <img src=x onerror=alert(1)>"},
+		{name: "html after indented fence", guide: "    ```\n<img src=x onerror=alert(1)>\n    ```"},
+		{name: "javascript link", guide: `[click](javascript:alert(1))`},
+		{name: "entity encoded javascript link", guide: `[click](&#106;avascript:alert(1))`},
+		{name: "hex entity encoded javascript link", guide: `[click](&#x6A;avascript&#x3A;alert(1))`},
+		{name: "percent encoded javascript link", guide: `[click](%6Aavascript:alert(1))`},
+		{name: "reference javascript link", guide: "[click][x]\n\n[x]: javascript:alert(1)"},
+		{name: "javascript autolink", guide: `<javascript:alert(1)>`},
+		{name: "data link", guide: `[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := renderGuide(tt.guide)
+			if err != nil {
+				t.Fatalf("renderGuide failed: %v", err)
+			}
+
+			// Decode what a browser would before it looks at a link
+			decoded := strings.ToLower(html.UnescapeString(rendered))
+			if unescaped, err := url.PathUnescape(decoded); err == nil {
+				decoded = unescaped
+			}
+			for _, unsafe := range []string{"<script", "<img", "onerror", `href="javascript:`, `href="data:`} {
+				if strings.Contains(decoded, unsafe) {
+					t.Errorf("rendered guide contains %q: %s", unsafe, rendered)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGuideKeepsMarkdown(t *testing.T) {
+	guide := "# Lab 1\n\nOpen [the docs](https://example.com/docs).\n\n```\n<b>not bold</b>\n```\n\nRun `cat <file>`."
+
+	rendered, err := renderGuide(guide)
+	if err != nil {
+		t.Fatalf("renderGuide failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"<h1",
+		`href="https://example.com/docs"`,
+		"&lt;b&gt;not bold&lt;/b&gt;",
+		"<code>cat &lt;file&gt;</code>",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered guide is missing %q: %s", want, rendered)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package cloning
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// =================================================
+// Template Change Database Operations
+// =================================================
+
+func (c *TemplateClient) SetTemplateUpdatedBy(templateName string, username string) error {
+	query := "UPDATE templates SET updated_by = ?, updated_at = UTC_TIMESTAMP() WHERE name = ?"
+	_, err := c.DB.Exec(query, username, templateName)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) InsertTemplateChange(change TemplateChange) error {
+	changes, err := json.Marshal(change.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template changes: %w", err)
+	}
+
+	query := "INSERT INTO template_changes (template, action, changed_by, changes) VALUES (?, ?, ?, ?)"
+	_, err = c.DB.Exec(query, change.Template, change.Action, change.ChangedBy, string(changes))
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateChanges returns the edit history of a template, newest first
+func (c *TemplateClient) GetTemplateChanges(templateName string) ([]TemplateChange, error) {
+	query := "SELECT id, template, action, changed_by, changes, created_at FROM template_changes WHERE template = ? ORDER BY created_at DESC, id DESC"
+	rows, err := c.DB.Query(query, templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildTemplateChanges(rows)
+}
+
+// =================================================
+// Template Change Operations
+// =================================================
+
+// EditTemplate updates a published template and records who changed which fields
+func (cs *CloningService) EditTemplate(template KaminoTemplate, editedBy string) error {
+	previous, err := cs.DatabaseService.GetTemplateInfo(template.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if previous.Name == "" {
+		return fmt.Errorf("template not found: %s", template.Name)
+	}
+
+	if err := cs.validateVMIDRange(template); err != nil {
+		return err
+	}
+	if err := cs.validateTemplateNetworks(template); err != nil {
+		return err
+	}
+	if err := cs.validateTemplateStorage(template); err != nil {
+		return err
+	}
+	if err := cs.validateHAGroup(template.HAGroup); err != nil {
+		return err
+	}
+
+	if err := cs.DatabaseService.EditTemplate(template); err != nil {
+		return err
+	}
+
+	// An empty image path leaves the current image in place
+	if template.ImagePath == "" {
+		template.ImagePath = previous.ImagePath
+	}
+
+	cs.markTemplateUpdated(template.Name, editedBy)
+	cs.recordTemplateChange(template.Name, TemplateActionEdit, editedBy, diffTemplates(previous, template))
+
+	// Disks only count towards full clones, so the footprint follows the clone mode
+	if template.CloneMode != previous.CloneMode {
+		cs.refreshTemplateResources(template.Name)
+	}
+	return nil
+}
+
+// ToggleTemplateVisibility flips whether users can see a template and records who did it
+func (cs *CloningService) ToggleTemplateVisibility(templateName string, toggledBy string) error {
+	previous, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+
+	if err := cs.DatabaseService.ToggleTemplateVisibility(templateName); err != nil {
+		return err
+	}
+
+	cs.markTemplateUpdated(templateName, toggledBy)
+	cs.recordTemplateChange(templateName, TemplateActionVisibility, toggledBy, []FieldChange{
+		{Field: "template_visible", OldValue: previous.TemplateVisible, NewValue: !previous.TemplateVisible},
+	})
+	return nil
+}
+
+// DeleteTemplate removes a template. Its history is kept so the deletion stays attributable.
+func (cs *CloningService) DeleteTemplate(templateName string, deletedBy string) error {
+	if err := cs.DatabaseService.DeleteTemplate(templateName); err != nil {
+		return err
+	}
+
+	cs.recordTemplateChange(templateName, TemplateActionDelete, deletedBy, []FieldChange{})
+	return nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (cs *CloningService) markTemplateUpdated(templateName string, username string) {
+	if err := cs.DatabaseService.SetTemplateUpdatedBy(templateName, username); err != nil {
+		log.Printf("Failed to record %s as the last editor of template %s: %v", username, templateName, err)
+	}
+}
+
+// recordTemplateChange adds an entry to the template's history. A failure is logged
+// rather than failing a change that has already been applied.
+func (cs *CloningService) recordTemplateChange(templateName string, action string, changedBy string, changes []FieldChange) {
+	change := TemplateChange{
+		Template:  templateName,
+		Action:    action,
+		ChangedBy: changedBy,
+		Changes:   changes,
+	}
+	if err := cs.DatabaseService.InsertTemplateChange(change); err != nil {
+		log.Printf("Failed to record %s of template %s by %s: %v", action, templateName, changedBy, err)
+	}
+}
+
+// diffTemplates lists the editable fields that differ between two versions of a template
+func diffTemplates(previous KaminoTemplate, current KaminoTemplate) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field string, oldValue any, newValue any) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	add("description", previous.Description, current.Description)
+	add("image_path", previous.ImagePath, current.ImagePath)
+	add("authors", previous.Authors, current.Authors)
+	add("template_visible", previous.TemplateVisible, current.TemplateVisible)
+	add("vm_count", previous.VMCount, current.VMCount)
+	add("clone_mode", previous.CloneMode, current.CloneMode)
+	add("storage", previous.Storage, current.Storage)
+	add("ha_group", previous.HAGroup, current.HAGroup)
+	add("max_deployments", previous.MaxDeployments, current.MaxDeployments)
+	add("category", previous.Category, current.Category)
+	add("tags", strings.Join(previous.Tags, ","), strings.Join(current.Tags, ","))
+	add("difficulty", previous.Difficulty, current.Difficulty)
+	add("guide", previous.Guide, current.Guide)
+	add("draft", previous.Draft, current.Draft)
+	add("min_vmid", previous.MinVMID, current.MinVMID)
+	add("max_vmid", previous.MaxVMID, current.MaxVMID)
+	add("lan_subnets", strings.Join(previous.LANSubnets, ","), strings.Join(current.LANSubnets, ","))
+	add("segments", segmentsString(previous.Segments), segmentsString(current.Segments))
+	add("rate_limit", previous.RateLimit, current.RateLimit)
+	add("remote_access", remoteAccessString(previous.RemoteAccess), remoteAccessString(current.RemoteAccess))
+
+	return changes
+}
+
+// segmentsString lists segments as alias:bridge:subnet for the change history
+func segmentsString(segments []NetworkSegment) string {
+	var parts []string
+	for _, segment := range segments {
+		parts = append(parts, segment.Alias+":"+segment.Bridge+":"+segment.Subnet)
+	}
+	return strings.Join(parts, ",")
+}
+
+// remoteAccessString lists connections as name:protocol:host:port for the change history,
+// leaving out the credentials
+func remoteAccessString(connections []RemoteAccess) string {
+	var parts []string
+	for _, connection := range connections {
+		parts = append(parts, fmt.Sprintf("%s:%s:%d:%d", connection.Name, connection.Protocol, connection.Host, connection.Port))
+	}
+	return strings.Join(parts, ",")
+}
+
+func buildTemplateChanges(rows *sql.Rows) ([]TemplateChange, error) {
+	changes := []TemplateChange{}
+
+	for rows.Next() {
+		var change TemplateChange
+		var fields string
+		err := rows.Scan(
+			&change.ID,
+			&change.Template,
+			&change.Action,
+			&change.ChangedBy,
+			&fields,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(fields), &change.Changes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal template changes: %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
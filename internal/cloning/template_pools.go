@@ -0,0 +1,228 @@
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
+)
+
+// CreateTemplateFromPod full clones the VMs of a pod into a new kamino_template_<name>
+// pool and converts them to templates, publishing the template when details are given.
+// The pod router is left out, deployments of the new template use the default router
+// unless a router is added to the pool. VMs of the pod that were running are started
+// again afterwards. The work runs in the background as a job whose ID is returned.
+func (cs *CloningService) CreateTemplateFromPod(pod string, templateName string, publish *KaminoTemplate, requestedBy string) (string, error) {
+	templatePools, err := cs.ProxmoxService.GetTemplatePools()
+	if err != nil {
+		return "", fmt.Errorf("failed to get template pools: %w", err)
+	}
+	if slices.Contains(templatePools, "kamino_template_"+templateName) {
+		return "", fmt.Errorf("template pool kamino_template_%s already exists", templateName)
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pool VMs for %s: %w", pod, err)
+	}
+	poolVMs = slices.DeleteFunc(poolVMs, func(vm proxmox.VirtualResource) bool { return routerPattern.MatchString(vm.Name) })
+	if len(poolVMs) == 0 {
+		return "", fmt.Errorf("pod %s has no VMs to build a template from", pod)
+	}
+
+	job := cs.Jobs.Create(jobs.TypeTemplate, requestedBy, fmt.Sprintf("Build template %s from pod %s", templateName, pod), nil, nil)
+	go func() {
+		err := cs.buildTemplatePool(job.ID, templateName, poolVMs, publish, requestedBy)
+		if err != nil {
+			log.Printf("Building template %s from pod %s failed: %v", templateName, pod, err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return job.ID, nil
+}
+
+// DuplicateTemplate full clones every VM of a template pool, router included, into a new
+// kamino_template_<name> pool so authors can branch a template before breaking changes.
+// The copy is left unpublished. The work runs in the background as a job whose ID is
+// returned.
+func (cs *CloningService) DuplicateTemplate(templateName string, newName string, requestedBy string) (string, error) {
+	templatePools, err := cs.ProxmoxService.GetTemplatePools()
+	if err != nil {
+		return "", fmt.Errorf("failed to get template pools: %w", err)
+	}
+	if slices.Contains(templatePools, "kamino_template_"+newName) {
+		return "", fmt.Errorf("template pool kamino_template_%s already exists", newName)
+	}
+
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get template pool: %w", err)
+	}
+	if len(poolVMs) == 0 {
+		return "", fmt.Errorf("template pool %s contains no VMs", templateName)
+	}
+
+	job := cs.Jobs.Create(jobs.TypeTemplate, requestedBy, fmt.Sprintf("Duplicate template %s as %s", templateName, newName), nil, nil)
+	go func() {
+		err := cs.buildTemplatePool(job.ID, newName, poolVMs, nil, requestedBy)
+		if err != nil {
+			log.Printf("Duplicating template %s as %s failed: %v", templateName, newName, err)
+		}
+		cs.Jobs.Finish(job.ID, err)
+	}()
+
+	return job.ID, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// buildTemplatePool full clones the VMs into a new template pool and converts the clones
+// to templates, or publishes the template when details are given
+func (cs *CloningService) buildTemplatePool(jobID string, templateName string, poolVMs []proxmox.VirtualResource, publish *KaminoTemplate, requestedBy string) error {
+	poolName := "kamino_template_" + templateName
+
+	// 1. Shut down running VMs so the clones are consistent, restarting them once cloned
+	cs.Jobs.Update(jobID, 5, "Shutting down VMs")
+	var runningVMs []proxmox.VirtualResource
+	for _, vm := range poolVMs {
+		if vm.RunningStatus != "running" {
+			continue
+		}
+		if err := cs.ProxmoxService.ShutdownVM(vm.NodeName, vm.VmId); err != nil {
+			return fmt.Errorf("failed to shut down VM %s: %w", vm.Name, err)
+		}
+		runningVMs = append(runningVMs, vm)
+	}
+	defer cs.restartSourceVMs(runningVMs)
+
+	for _, vm := range runningVMs {
+		if err := cs.ProxmoxService.WaitForStopped(context.Background(), vm.NodeName, vm.VmId); err != nil {
+			// Guests without ACPI support ignore the shutdown request
+			if err := cs.ProxmoxService.StopVM(vm.NodeName, vm.VmId); err != nil {
+				return fmt.Errorf("failed to stop VM %s: %w", vm.Name, err)
+			}
+		}
+	}
+
+	// 2. Create the template pool owned by the requesting admin
+	cs.Jobs.Update(jobID, 15, "Creating template pool")
+	if err := cs.ProxmoxService.CreateNewPool(poolName); err != nil {
+		return fmt.Errorf("failed to create template pool: %w", err)
+	}
+	if err := cs.ProxmoxService.SetPoolPermission(poolName, requestedBy, false); err != nil {
+		cs.discardTemplatePool(poolName, nil)
+		return fmt.Errorf("failed to set template pool permissions: %w", err)
+	}
+
+	// 3. Full clone every VM next to its source, holding the allocation mutex until every
+	// clone has claimed its VMID
+	cs.vmidMutex.Lock()
+	vmIDs, err := cs.ProxmoxService.GetNextVMIDs(len(poolVMs))
+	if err != nil {
+		cs.vmidMutex.Unlock()
+		cs.discardTemplatePool(poolName, nil)
+		return fmt.Errorf("failed to get next VM IDs: %w", err)
+	}
+
+	var clones []proxmox.VirtualResource
+	var upids []string
+	var errors []string
+	for i, vm := range poolVMs {
+		upid, err := cs.ProxmoxService.CloneVM(context.Background(), proxmox.VMCloneRequest{
+			SourceVM:   proxmox.VM{Name: vm.Name, Node: vm.NodeName, VMID: vm.VmId, Type: vm.Type},
+			PoolName:   poolName,
+			NewVMID:    vmIDs[i],
+			Full:       1,
+			TargetNode: vm.NodeName,
+		})
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to clone VM %s: %v", vm.Name, err))
+			break
+		}
+		clones = append(clones, proxmox.VirtualResource{Name: vm.Name, NodeName: vm.NodeName, VmId: vmIDs[i], Type: vm.Type})
+		upids = append(upids, upid)
+	}
+	cs.vmidMutex.Unlock()
+
+	for i, clone := range clones {
+		cs.Jobs.Update(jobID, 20+60*i/len(clones), fmt.Sprintf("Cloning VM %s", clone.Name))
+		if err := cs.ProxmoxService.TrackTask(context.Background(), upids[i], cs.Config.CloneTimeout); err != nil {
+			errors = append(errors, fmt.Sprintf("failed to clone VM %s: %v", clone.Name, err))
+		}
+	}
+	if len(errors) > 0 {
+		cs.discardTemplatePool(poolName, clones)
+		return fmt.Errorf("failed to clone VMs: %v", errors)
+	}
+
+	// 4. Publishing converts the VMs itself, otherwise they are converted here
+	if publish != nil {
+		cs.Jobs.Update(jobID, 90, "Publishing template")
+		template := *publish
+		template.Name = templateName
+		template.VMCount = len(clones)
+		if err := cs.PublishTemplate(template, requestedBy); err != nil {
+			return fmt.Errorf("template pool %s created but publishing failed: %w", poolName, err)
+		}
+	} else {
+		cs.Jobs.Update(jobID, 90, "Converting VMs to templates")
+		for _, clone := range clones {
+			if err := cs.ProxmoxService.WaitForLock(context.Background(), clone.NodeName, clone.VmId); err != nil {
+				log.Printf("Error waiting for lock to clear on VM %d: %v", clone.VmId, err)
+				continue
+			}
+			if err := cs.ProxmoxService.ConvertVMToTemplate(clone.NodeName, clone.VmId); err != nil {
+				// Deployments fall back to full clones of VMs that aren't templates
+				log.Printf("Error converting VM %d to template: %v", clone.VmId, err)
+			}
+		}
+	}
+
+	log.Printf("Built template pool %s from %d VMs for %s", poolName, len(clones), requestedBy)
+	return nil
+}
+
+// restartSourceVMs starts the VMs that were running before being cloned into a template
+func (cs *CloningService) restartSourceVMs(vms []proxmox.VirtualResource) {
+	for _, vm := range vms {
+		if err := cs.ProxmoxService.StartVM(vm.NodeName, vm.VmId); err != nil {
+			log.Printf("Failed to restart VM %s after building template: %v", vm.Name, err)
+		}
+	}
+}
+
+// discardTemplatePool removes a template pool that could not be completed
+func (cs *CloningService) discardTemplatePool(poolName string, clones []proxmox.VirtualResource) {
+	if err := cs.removeTemplatePool(poolName, clones); err != nil {
+		log.Printf("Failed to remove incomplete template pool: %v", err)
+	}
+}
+
+// removeTemplatePool deletes the given VMs of a template pool, then the pool itself
+func (cs *CloningService) removeTemplatePool(poolName string, vms []proxmox.VirtualResource) error {
+	for _, vm := range vms {
+		if err := cs.ProxmoxService.WaitForLock(context.Background(), vm.NodeName, vm.VmId); err != nil {
+			log.Printf("Warning: timeout waiting for VM %d lock during cleanup: %v", vm.VmId, err)
+		}
+		if err := cs.ProxmoxService.DeleteVM(vm.NodeName, vm.VmId); err != nil {
+			log.Printf("Failed to delete VM %d of template pool %s: %v", vm.VmId, poolName, err)
+		}
+	}
+
+	if len(vms) > 0 {
+		if err := cs.ProxmoxService.WaitForPoolEmpty(context.Background(), poolName, cs.Config.CloneTimeout); err != nil {
+			return fmt.Errorf("template pool %s not empty after removing its VMs: %w", poolName, err)
+		}
+	}
+	if err := cs.ProxmoxService.DeletePool(poolName); err != nil {
+		return fmt.Errorf("failed to delete template pool %s: %w", poolName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cloning
+
+import (
+	"fmt"
+	"log"
+)
+
+// =================================================
+// Template Resources Database Operations
+// =================================================
+
+func (c *TemplateClient) SetTemplateResources(templateName string, resources ResourceRequirement) error {
+	query := "UPDATE templates SET vcpus = ?, memory_bytes = ?, disk_bytes = ? WHERE name = ?"
+
+	_, err := c.DB.Exec(query, resources.VCPUs, resources.MemoryBytes, resources.DiskBytes, templateName)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Template Resources Operations
+// =================================================
+
+// RefreshTemplateResources sums the vCPUs, memory, and disk one deployment of the template
+// uses from the VM configs in its pool and caches the result on the template
+func (cs *CloningService) RefreshTemplateResources(templateName string) (*ResourceRequirement, error) {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return nil, fmt.Errorf("template not found: %s", templateName)
+	}
+
+	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template pool: %w", err)
+	}
+
+	resources, err := cs.templateFootprint(templatePool, template.CloneMode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cs.DatabaseService.SetTemplateResources(templateName, resources); err != nil {
+		return nil, err
+	}
+
+	return &resources, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// refreshTemplateResources refreshes the cached resources after a template changes, a
+// failure is only logged since the template itself was saved
+func (cs *CloningService) refreshTemplateResources(templateName string) {
+	if _, err := cs.RefreshTemplateResources(templateName); err != nil {
+		log.Printf("Failed to refresh resources of template %s: %v", templateName, err)
+	}
+}
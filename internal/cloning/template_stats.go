@@ -0,0 +1,186 @@
+package cloning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Template deployment statuses
+const (
+	DeploymentStatusSucceeded = "succeeded"
+	DeploymentStatusFailed    = "failed"
+	DeploymentStatusCancelled = "cancelled"
+)
+
+// =================================================
+// Template Deployment Database Operations
+// =================================================
+
+func (c *TemplateClient) SaveTemplateDeployment(deployment TemplateDeployment) error {
+	query := "INSERT INTO template_deployments (template, targets, status, duration_ms, error, requested_by) VALUES (?, ?, ?, ?, ?, ?)"
+	_, err := c.DB.Exec(query, deployment.Template, deployment.Targets, deployment.Status, deployment.DurationMS, deployment.Error, deployment.RequestedBy)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateStats returns the deployment statistics of every template that has been deployed
+func (c *TemplateClient) GetTemplateStats() ([]TemplateStats, error) {
+	query := `SELECT template,
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN status = 'succeeded' THEN targets ELSE 0 END), 0),
+			COALESCE(SUM(status = 'failed'), 0),
+			COALESCE(SUM(status = 'cancelled'), 0),
+			COALESCE(AVG(CASE WHEN status = 'succeeded' THEN duration_ms END), 0),
+			MAX(created_at)
+		FROM template_deployments GROUP BY template`
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildTemplateStats(rows)
+}
+
+// =================================================
+// Template Statistics Operations
+// =================================================
+
+// GetTemplateStats returns the deployment statistics and active pod count of a template
+func (cs *CloningService) GetTemplateStats(templateName string) (*TemplateStats, error) {
+	summary, err := cs.GetTemplateStatsSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stats := range summary.Templates {
+		if strings.EqualFold(stats.Template, templateName) {
+			return &stats, nil
+		}
+	}
+
+	return nil, fmt.Errorf("template not found: %s", templateName)
+}
+
+// GetTemplateStatsSummary returns the statistics of every published template along with
+// totals across all of them
+func (cs *CloningService) GetTemplateStatsSummary() (*TemplateStatsSummary, error) {
+	templates, err := cs.DatabaseService.GetTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	recorded, err := cs.DatabaseService.GetTemplateStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template stats: %w", err)
+	}
+
+	pods, err := cs.AdminGetPods()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployed pods: %w", err)
+	}
+	activePods := map[string]int{}
+	for _, pod := range pods {
+		activePods[PodTemplateName(pod.Name)]++
+	}
+
+	summary := &TemplateStatsSummary{Templates: []TemplateStats{}}
+	var totalCloneMS float64
+	succeeded := 0
+	for _, template := range templates {
+		stats := TemplateStats{Template: template.Name}
+		for _, r := range recorded {
+			if strings.EqualFold(r.Template, template.Name) {
+				stats = r
+				stats.Template = template.Name
+				break
+			}
+		}
+		stats.ActivePods = activePods[strings.ToLower(template.Name)]
+
+		summary.Deployments += stats.Deployments
+		summary.PodsDeployed += stats.PodsDeployed
+		summary.Failures += stats.Failures
+		summary.ActivePods += stats.ActivePods
+		templateSucceeded := stats.Deployments - stats.Failures - stats.Cancellations
+		succeeded += templateSucceeded
+		totalCloneMS += float64(stats.AvgCloneMS) * float64(templateSucceeded)
+		summary.Templates = append(summary.Templates, stats)
+	}
+
+	if succeeded > 0 {
+		summary.AvgCloneMS = int64(totalCloneMS / float64(succeeded))
+	}
+
+	return summary, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// recordTemplateDeployment stores the outcome of a clone request for the template's
+// statistics. Requests rejected by a deployment rule never started and aren't recorded.
+func (cs *CloningService) recordTemplateDeployment(req CloneRequest, started time.Time, err error) {
+	var limitErr *DeploymentLimitError
+	if errors.As(err, &limitErr) {
+		return
+	}
+
+	deployment := TemplateDeployment{
+		Template:    req.Template,
+		Targets:     len(req.Targets),
+		Status:      DeploymentStatusSucceeded,
+		DurationMS:  time.Since(started).Milliseconds(),
+		RequestedBy: req.RequestedBy,
+	}
+	if err != nil {
+		deployment.Status = DeploymentStatusFailed
+		if errors.Is(err, context.Canceled) {
+			deployment.Status = DeploymentStatusCancelled
+		}
+		deployment.Error = err.Error()
+	}
+
+	if err := cs.DatabaseService.SaveTemplateDeployment(deployment); err != nil {
+		log.Printf("Failed to record deployment of template %s: %v", req.Template, err)
+	}
+}
+
+func buildTemplateStats(rows *sql.Rows) ([]TemplateStats, error) {
+	stats := []TemplateStats{}
+
+	for rows.Next() {
+		var s TemplateStats
+		var avgCloneMS float64
+		var lastDeployed sql.NullTime
+		err := rows.Scan(
+			&s.Template,
+			&s.Deployments,
+			&s.PodsDeployed,
+			&s.Failures,
+			&s.Cancellations,
+			&avgCloneMS,
+			&lastDeployed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		s.AvgCloneMS = int64(avgCloneMS)
+		if lastDeployed.Valid {
+			s.LastDeployedAt = &lastDeployed.Time
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
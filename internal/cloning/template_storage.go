@@ -0,0 +1,81 @@
+package cloning
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Private Functions
+// =================================================
+
+// validateTemplateStorage checks that a template's target storage, when set, can be used:
+// only full clones copy disks to another storage, and some node must offer it
+func (cs *CloningService) validateTemplateStorage(template KaminoTemplate) error {
+	if template.Storage == "" {
+		return nil
+	}
+	if template.CloneMode != CloneModeFull {
+		return fmt.Errorf("storage %s requires the full clone mode, linked clones stay on the template's storage", template.Storage)
+	}
+
+	storages, err := cs.ProxmoxService.GetClusterResources("type=storage")
+	if err != nil {
+		return fmt.Errorf("failed to get cluster storage: %w", err)
+	}
+	if !slices.ContainsFunc(storages, func(s proxmox.VirtualResource) bool {
+		return s.Storage == template.Storage && s.RunningStatus == "available"
+	}) {
+		return fmt.Errorf("storage %s is not available on any node", template.Storage)
+	}
+
+	return nil
+}
+
+// resolveCloneStorage returns the storage a deployment's full clones are placed on, a
+// request override taking precedence over the template setting. The storage must hold
+// guest disks on every node the request may clone to.
+func (cs *CloningService) resolveCloneStorage(req CloneRequest, template KaminoTemplate, cloneMode string) (string, error) {
+	storage := req.Storage
+	if storage == "" {
+		storage = template.Storage
+	}
+	if storage == "" {
+		return "", nil
+	}
+	if cloneMode != CloneModeFull {
+		return "", fmt.Errorf("storage %s requires the full clone mode, linked clones stay on the template's storage", storage)
+	}
+
+	nodes := req.placementNodes()
+	if len(nodes) == 0 {
+		resources, err := cs.ProxmoxService.GetClusterResources("type=node")
+		if err != nil {
+			return "", fmt.Errorf("failed to get cluster nodes: %w", err)
+		}
+		for _, node := range resources {
+			if node.RunningStatus == "online" {
+				nodes = append(nodes, node.NodeName)
+			}
+		}
+	}
+
+	var missing []string
+	for _, node := range nodes {
+		storages, err := cs.ProxmoxService.GetNodeStorages(node)
+		if err != nil {
+			return "", err
+		}
+		if !slices.ContainsFunc(storages, func(s proxmox.NodeStorage) bool { return s.Storage == storage && s.Active == 1 }) {
+			missing = append(missing, node)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("storage %s cannot hold VM disks on %s, pin the deployment to nodes that have it", storage, strings.Join(missing, ", "))
+	}
+
+	return storage, nil
+}
@@ -0,0 +1,108 @@
+package cloning
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// maxLANSubnets is the largest number of LAN subnets a template can declare
+const maxLANSubnets = 8
+
+// maxSegments is the largest number of extra network segments a template can declare
+const maxSegments = 4
+
+// =================================================
+// Private Functions
+// =================================================
+
+// validateTemplateNetworks checks that the LAN subnets and segment subnets a template
+// declares are private network addresses that don't overlap each other or the pod WAN
+// subnets, and that every segment has its own alias and template bridge
+func (cs *CloningService) validateTemplateNetworks(template KaminoTemplate) error {
+	if len(template.LANSubnets) > maxLANSubnets {
+		return fmt.Errorf("a template can declare at most %d LAN subnets", maxLANSubnets)
+	}
+	if len(template.Segments) > maxSegments {
+		return fmt.Errorf("a template can declare at most %d network segments", maxSegments)
+	}
+
+	wanRange, err := cs.podWANRange()
+	if err != nil {
+		return err
+	}
+
+	subnets := append([]string{}, template.LANSubnets...)
+	aliases := map[string]bool{}
+	bridges := map[string]bool{}
+	for _, segment := range template.Segments {
+		alias := strings.ToLower(segment.Alias)
+		if aliases[alias] {
+			return fmt.Errorf("segment alias %s is used more than once", segment.Alias)
+		}
+		if bridges[segment.Bridge] {
+			return fmt.Errorf("bridge %s is mapped to more than one segment", segment.Bridge)
+		}
+		aliases[alias], bridges[segment.Bridge] = true, true
+
+		if segment.Subnet != "" {
+			subnets = append(subnets, segment.Subnet)
+		}
+	}
+
+	var prefixes []netip.Prefix
+	for _, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			return fmt.Errorf("invalid LAN subnet %q: %w", subnet, err)
+		}
+		if prefix != prefix.Masked() {
+			return fmt.Errorf("LAN subnet %s is not a network address, use %s", subnet, prefix.Masked())
+		}
+		if !prefix.Addr().Is4() || !prefix.Addr().IsPrivate() || prefix.Bits() < 8 || prefix.Bits() > 30 {
+			return fmt.Errorf("LAN subnet %s must be a private IPv4 subnet between /8 and /30", subnet)
+		}
+		if prefix.Overlaps(wanRange) {
+			return fmt.Errorf("LAN subnet %s overlaps the pod WAN range %s", subnet, wanRange)
+		}
+		for _, other := range prefixes {
+			if prefix.Overlaps(other) {
+				return fmt.Errorf("LAN subnet %s overlaps LAN subnet %s", subnet, other)
+			}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return nil
+}
+
+// podWANRange returns the range spanned by the WAN subnets of all pods
+func (cs *CloningService) podWANRange() (netip.Prefix, error) {
+	wanSubnet, err := netip.ParsePrefix(cs.ProxmoxService.PodWANSubnet(1))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("failed to parse pod WAN subnet: %w", err)
+	}
+	// Pod WAN subnets differ in the third octet, so together they span a /16
+	wanRange, err := wanSubnet.Addr().Prefix(16)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("failed to parse pod WAN range: %w", err)
+	}
+
+	return wanRange, nil
+}
+
+// podTemplateNetworks returns the template a pod was deployed from for its network layout,
+// an empty template when it no longer exists
+func (cs *CloningService) podTemplateNetworks(pod string) (KaminoTemplate, error) {
+	templateName := PodTemplateName(pod)
+	if templateName == "" {
+		return KaminoTemplate{}, nil
+	}
+
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return KaminoTemplate{}, fmt.Errorf("failed to get template info: %w", err)
+	}
+
+	return template, nil
+}
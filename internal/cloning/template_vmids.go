@@ -0,0 +1,53 @@
+package cloning
+
+import (
+	"fmt"
+)
+
+// =================================================
+// Private Functions
+// =================================================
+
+// validateVMIDRange checks that a template's reserved VMID block, when set, is well
+// formed and does not overlap the block of another template
+func (cs *CloningService) validateVMIDRange(template KaminoTemplate) error {
+	if template.MinVMID == 0 && template.MaxVMID == 0 {
+		return nil
+	}
+	if template.MinVMID < 100 {
+		return fmt.Errorf("min_vmid %d is below the lowest Proxmox VMID 100", template.MinVMID)
+	}
+	if template.MinVMID > template.MaxVMID {
+		return fmt.Errorf("min_vmid %d is greater than max_vmid %d", template.MinVMID, template.MaxVMID)
+	}
+
+	templates, err := cs.DatabaseService.GetPublishedTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to get published templates: %w", err)
+	}
+	for _, other := range templates {
+		if other.Name == template.Name || (other.MinVMID == 0 && other.MaxVMID == 0) {
+			continue
+		}
+		if template.MinVMID <= other.MaxVMID && other.MinVMID <= template.MaxVMID {
+			return fmt.Errorf("VMID range %d-%d overlaps the range %d-%d of template %s", template.MinVMID, template.MaxVMID, other.MinVMID, other.MaxVMID, other.Name)
+		}
+	}
+
+	return nil
+}
+
+// nextVMIDs allocates VMIDs for clones of a template, from the template's reserved block
+// when it has one and from anywhere in the cluster otherwise
+func (cs *CloningService) nextVMIDs(templateName string, num int) ([]int, error) {
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template info: %w", err)
+	}
+
+	if template.MinVMID != 0 || template.MaxVMID != 0 {
+		return cs.ProxmoxService.GetNextVMIDsInRange(template.MinVMID, template.MaxVMID, num)
+	}
+
+	return cs.ProxmoxService.GetNextVMIDs(num)
+}
@@ -1,7 +1,9 @@
 package cloning
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -9,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/cpp-cyber/proclone/internal/proxmox"
@@ -21,7 +24,7 @@ import (
 // =================================================
 
 func (c *TemplateClient) GetTemplates() ([]KaminoTemplate, error) {
-	query := "SELECT * FROM templates WHERE template_visible = true ORDER BY created_at DESC"
+	query := "SELECT " + templateColumns + " FROM templates WHERE template_visible = true ORDER BY created_at DESC"
 	rows, err := c.DB.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -32,7 +35,7 @@ func (c *TemplateClient) GetTemplates() ([]KaminoTemplate, error) {
 }
 
 func (c *TemplateClient) GetPublishedTemplates() ([]KaminoTemplate, error) {
-	query := "SELECT * FROM templates"
+	query := "SELECT " + templateColumns + " FROM templates"
 	rows, err := c.DB.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -42,6 +45,20 @@ func (c *TemplateClient) GetPublishedTemplates() ([]KaminoTemplate, error) {
 	return c.buildTemplates(rows)
 }
 
+// SearchTemplates returns the visible templates matching the query in their name,
+// description, authors, or tags, most relevant first
+func (c *TemplateClient) SearchTemplates(search string) ([]KaminoTemplate, error) {
+	match := "MATCH (name, description, authors, tags) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	query := "SELECT " + templateColumns + " FROM templates WHERE template_visible = true AND " + match + " ORDER BY " + match + " DESC"
+	rows, err := c.DB.Query(query, search, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return c.buildTemplates(rows)
+}
+
 func (c *TemplateClient) DeleteTemplate(templateName string) error {
 	// Get template image path and delete the image
 	template, err := c.GetTemplateInfo(templateName)
@@ -56,6 +73,11 @@ func (c *TemplateClient) DeleteTemplate(templateName string) error {
 			return fmt.Errorf("failed to delete template image: %w", err)
 		}
 	}
+	for _, image := range template.Images {
+		if err := c.DeleteImage(image); err != nil {
+			return fmt.Errorf("failed to delete template gallery image: %w", err)
+		}
+	}
 
 	//  Delete template from database
 	query := "DELETE FROM templates WHERE name = ?"
@@ -101,8 +123,28 @@ func (c *TemplateClient) GetAllTemplateNames() ([]string, error) {
 }
 
 func (c *TemplateClient) InsertTemplate(template KaminoTemplate) error {
-	query := "INSERT INTO templates (name, description, image_path, authors, template_visible, vm_count) VALUES (?, ?, ?, ?, ?, ?)"
-	_, err := c.DB.Exec(query, template.Name, template.Description, template.ImagePath, template.Authors, template.TemplateVisible, template.VMCount)
+	tags, err := marshalStrings(template.Tags)
+	if err != nil {
+		return err
+	}
+
+	lanSubnets, err := marshalStrings(template.LANSubnets)
+	if err != nil {
+		return err
+	}
+
+	segments, err := marshalSegments(template.Segments)
+	if err != nil {
+		return err
+	}
+
+	remoteAccess, err := marshalRemoteAccess(template.RemoteAccess)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO templates (name, description, image_path, authors, template_visible, vm_count, clone_mode, published_by, max_deployments, category, tags, difficulty, guide, draft, min_vmid, max_vmid, lan_subnets, segments, rate_limit, remote_access, storage, ha_group) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err = c.DB.Exec(query, template.Name, template.Description, template.ImagePath, template.Authors, template.TemplateVisible, template.VMCount, template.CloneMode, template.PublishedBy, template.MaxDeployments, template.Category, tags, template.Difficulty, template.Guide, template.Draft, template.MinVMID, template.MaxVMID, lanSubnets, segments, template.RateLimit, remoteAccess, template.Storage, template.HAGroup)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -136,11 +178,75 @@ func (c *TemplateClient) EditTemplate(template KaminoTemplate) error {
 	setParts = append(setParts, "template_visible = ?")
 	args = append(args, template.TemplateVisible)
 
+	// Always update clone_mode
+	setParts = append(setParts, "clone_mode = ?")
+	args = append(args, template.CloneMode)
+
+	// Always update max_deployments
+	setParts = append(setParts, "max_deployments = ?")
+	args = append(args, template.MaxDeployments)
+
+	// Always update the catalog classification
+	tags, err := marshalStrings(template.Tags)
+	if err != nil {
+		return err
+	}
+	setParts = append(setParts, "category = ?", "tags = ?", "difficulty = ?")
+	args = append(args, template.Category, tags, template.Difficulty)
+
+	// Always update the guide
+	setParts = append(setParts, "guide = ?")
+	args = append(args, template.Guide)
+
+	// Always update draft
+	setParts = append(setParts, "draft = ?")
+	args = append(args, template.Draft)
+
+	// Always update the VMID range
+	setParts = append(setParts, "min_vmid = ?", "max_vmid = ?")
+	args = append(args, template.MinVMID, template.MaxVMID)
+
+	// Always update the LAN subnets
+	lanSubnets, err := marshalStrings(template.LANSubnets)
+	if err != nil {
+		return err
+	}
+	setParts = append(setParts, "lan_subnets = ?")
+	args = append(args, lanSubnets)
+
+	// Always update the network segments
+	segments, err := marshalSegments(template.Segments)
+	if err != nil {
+		return err
+	}
+	setParts = append(setParts, "segments = ?")
+	args = append(args, segments)
+
+	// Always update the rate limit
+	setParts = append(setParts, "rate_limit = ?")
+	args = append(args, template.RateLimit)
+
+	// Always update the remote access connections
+	remoteAccess, err := marshalRemoteAccess(template.RemoteAccess)
+	if err != nil {
+		return err
+	}
+	setParts = append(setParts, "remote_access = ?")
+	args = append(args, remoteAccess)
+
+	// Always update the storage
+	setParts = append(setParts, "storage = ?")
+	args = append(args, template.Storage)
+
+	// Always update the HA group
+	setParts = append(setParts, "ha_group = ?")
+	args = append(args, template.HAGroup)
+
 	// Build and execute the query
 	query := fmt.Sprintf("UPDATE templates SET %s WHERE name = ?", strings.Join(setParts, ", "))
 	args = append(args, template.Name)
 
-	_, err := c.DB.Exec(query, args...)
+	_, err = c.DB.Exec(query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -159,22 +265,10 @@ func (c *TemplateClient) AddDeployment(templateName string, num int) error {
 }
 
 func (c *TemplateClient) GetTemplateInfo(templateName string) (KaminoTemplate, error) {
-	query := "SELECT * FROM templates WHERE name = ?"
+	query := "SELECT " + templateColumns + " FROM templates WHERE name = ?"
 	row := c.DB.QueryRow(query, templateName)
 
-	var template KaminoTemplate
-	err := row.Scan(
-		&template.Name,
-		&template.Description,
-		&template.ImagePath,
-		&template.Authors,
-		&template.TemplateVisible,
-		&template.PodVisible,
-		&template.VMsVisible,
-		&template.VMCount,
-		&template.Deployments,
-		&template.CreatedAt,
-	)
+	template, err := scanTemplate(row)
 	if err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return KaminoTemplate{}, nil // No error, but template not found
@@ -219,7 +313,22 @@ func (cs *CloningService) GetUnpublishedTemplates() ([]string, error) {
 }
 
 // Before publishing we try to convert as many VMs to templates to speed up cloning process
-func (cs *CloningService) PublishTemplate(template KaminoTemplate) error {
+func (cs *CloningService) PublishTemplate(template KaminoTemplate, publishedBy string) error {
+	template.PublishedBy = publishedBy
+
+	if err := cs.validateVMIDRange(template); err != nil {
+		return err
+	}
+	if err := cs.validateTemplateNetworks(template); err != nil {
+		return err
+	}
+	if err := cs.validateTemplateStorage(template); err != nil {
+		return err
+	}
+	if err := cs.validateHAGroup(template.HAGroup); err != nil {
+		return err
+	}
+
 	// 1. Get all VMs in pool
 	// If this fails, the function will error out
 	vms, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + template.Name)
@@ -244,7 +353,7 @@ func (cs *CloningService) PublishTemplate(template KaminoTemplate) error {
 	// 3. Wait for running VMs to be stopped
 	// If a VM cannot be verified as stopped, this function will error out
 	for _, vm := range runningVMs {
-		if err := cs.ProxmoxService.WaitForStopped(vm.NodeName, vm.VmId); err != nil {
+		if err := cs.ProxmoxService.WaitForStopped(context.Background(), vm.NodeName, vm.VmId); err != nil {
 			log.Printf("Error waiting for VM %d to stop: %v", vm.VmId, err)
 			return fmt.Errorf("failed to confirm VM %d is stopped: %w", vm.VmId, err)
 		}
@@ -281,7 +390,7 @@ func (cs *CloningService) PublishTemplate(template KaminoTemplate) error {
 	// full clone if not a template or it is already a template
 	for _, vm := range vms {
 		// Wait for any locks to clear before converting
-		if err := cs.ProxmoxService.WaitForLock(vm.NodeName, vm.VmId); err != nil {
+		if err := cs.ProxmoxService.WaitForLock(context.Background(), vm.NodeName, vm.VmId); err != nil {
 			log.Printf("Error waiting for lock to clear on VM %d: %v", vm.VmId, err)
 			continue
 		}
@@ -300,9 +409,62 @@ func (cs *CloningService) PublishTemplate(template KaminoTemplate) error {
 		return fmt.Errorf("failed to publish to database: %w", err)
 	}
 
+	cs.recordTemplateChange(template.Name, TemplateActionPublish, publishedBy, diffTemplates(KaminoTemplate{}, template))
+	cs.refreshTemplateResources(template.Name)
+
 	return nil
 }
 
+// FilterTemplates returns the templates matching every set field of the filter, in the
+// filter's sort order
+func FilterTemplates(templates []KaminoTemplate, filter TemplateFilter) []KaminoTemplate {
+	search := strings.ToLower(filter.Search)
+
+	matched := []KaminoTemplate{}
+	for _, template := range templates {
+		if filter.Category != "" && !strings.EqualFold(template.Category, filter.Category) {
+			continue
+		}
+		if filter.Difficulty != "" && template.Difficulty != filter.Difficulty {
+			continue
+		}
+		if slices.ContainsFunc(filter.Tags, func(tag string) bool { return !templateHasTag(template, tag) }) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(template.Name), search) &&
+			!strings.Contains(strings.ToLower(template.Description), search) {
+			continue
+		}
+		matched = append(matched, template)
+	}
+
+	var compare func(a, b KaminoTemplate) int
+	switch filter.Sort {
+	case "name":
+		compare = func(a, b KaminoTemplate) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+	case "created_at":
+		compare = func(a, b KaminoTemplate) int { return strings.Compare(a.CreatedAt, b.CreatedAt) }
+	case "deployments":
+		compare = func(a, b KaminoTemplate) int { return a.Deployments - b.Deployments }
+	case "difficulty":
+		compare = func(a, b KaminoTemplate) int {
+			return slices.Index(templateDifficulties, a.Difficulty) - slices.Index(templateDifficulties, b.Difficulty)
+		}
+	default:
+		return matched
+	}
+
+	slices.SortStableFunc(matched, func(a, b KaminoTemplate) int {
+		if filter.Descending {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return matched
+}
+
 // =================================================
 // Template Image Operations
 // =================================================
@@ -353,6 +515,7 @@ func (cl *TemplateClient) UploadTemplateImage(c *gin.Context) (*UploadResult, er
 	if err := c.SaveUploadedFile(header, outPath); err != nil {
 		return nil, fmt.Errorf("unable to save file: %w", err)
 	}
+	generateThumbnails(outPath)
 
 	result := &UploadResult{
 		Message:  "file uploaded successfully",
@@ -373,6 +536,7 @@ func (c *TemplateClient) DeleteImage(imagePath string) error {
 	if err := os.Remove(fullPath); err != nil {
 		return fmt.Errorf("failed to delete image: %w", err)
 	}
+	removeThumbnails(fullPath)
 	return nil
 }
 
@@ -384,19 +548,7 @@ func (c *TemplateClient) buildTemplates(rows *sql.Rows) ([]KaminoTemplate, error
 	templates := []KaminoTemplate{}
 
 	for rows.Next() {
-		var template KaminoTemplate
-		err := rows.Scan(
-			&template.Name,
-			&template.Description,
-			&template.ImagePath,
-			&template.Authors,
-			&template.TemplateVisible,
-			&template.PodVisible,
-			&template.VMsVisible,
-			&template.VMCount,
-			&template.Deployments,
-			&template.CreatedAt,
-		)
+		template, err := scanTemplate(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
@@ -406,6 +558,135 @@ func (c *TemplateClient) buildTemplates(rows *sql.Rows) ([]KaminoTemplate, error
 	return templates, nil
 }
 
+// templateColumns lists the templates table columns in the order scanTemplate expects
+const templateColumns = "name, description, image_path, authors, template_visible, pod_visible, vms_visible, vm_count, deployments, created_at, clone_mode, published_by, updated_by, updated_at, max_deployments, organization, category, tags, difficulty, allowed_groups, deprecated_at, images, vcpus, memory_bytes, disk_bytes, guide, draft, min_vmid, max_vmid, lan_subnets, segments, rate_limit, remote_access, storage, ha_group"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTemplate(row rowScanner) (KaminoTemplate, error) {
+	var template KaminoTemplate
+	var updatedAt, deprecatedAt, images, guide, lanSubnets, segments, remoteAccess sql.NullString
+	var tags, allowedGroups string
+	err := row.Scan(
+		&template.Name,
+		&template.Description,
+		&template.ImagePath,
+		&template.Authors,
+		&template.TemplateVisible,
+		&template.PodVisible,
+		&template.VMsVisible,
+		&template.VMCount,
+		&template.Deployments,
+		&template.CreatedAt,
+		&template.CloneMode,
+		&template.PublishedBy,
+		&template.UpdatedBy,
+		&updatedAt,
+		&template.MaxDeployments,
+		&template.Organization,
+		&template.Category,
+		&tags,
+		&template.Difficulty,
+		&allowedGroups,
+		&deprecatedAt,
+		&images,
+		&template.Resources.VCPUs,
+		&template.Resources.MemoryBytes,
+		&template.Resources.DiskBytes,
+		&guide,
+		&template.Draft,
+		&template.MinVMID,
+		&template.MaxVMID,
+		&lanSubnets,
+		&segments,
+		&template.RateLimit,
+		&remoteAccess,
+		&template.Storage,
+		&template.HAGroup,
+	)
+	if err != nil {
+		return template, err
+	}
+	template.UpdatedAt = updatedAt.String
+	template.DeprecatedAt = deprecatedAt.String
+	template.Guide = guide.String
+	if tags != "" {
+		if err := json.Unmarshal([]byte(tags), &template.Tags); err != nil {
+			return template, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+	}
+	if images.String != "" {
+		if err := json.Unmarshal([]byte(images.String), &template.Images); err != nil {
+			return template, fmt.Errorf("failed to unmarshal images: %w", err)
+		}
+	}
+	if lanSubnets.String != "" {
+		if err := json.Unmarshal([]byte(lanSubnets.String), &template.LANSubnets); err != nil {
+			return template, fmt.Errorf("failed to unmarshal LAN subnets: %w", err)
+		}
+	}
+	if segments.String != "" {
+		if err := json.Unmarshal([]byte(segments.String), &template.Segments); err != nil {
+			return template, fmt.Errorf("failed to unmarshal network segments: %w", err)
+		}
+	}
+	if remoteAccess.String != "" {
+		if err := json.Unmarshal([]byte(remoteAccess.String), &template.RemoteAccess); err != nil {
+			return template, fmt.Errorf("failed to unmarshal remote access: %w", err)
+		}
+	}
+	if allowedGroups != "" {
+		if err := json.Unmarshal([]byte(allowedGroups), &template.AllowedGroups); err != nil {
+			return template, fmt.Errorf("failed to unmarshal allowed groups: %w", err)
+		}
+	}
+	return template, nil
+}
+
+func templateHasTag(template KaminoTemplate, tag string) bool {
+	return slices.ContainsFunc(template.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// marshalStrings encodes a list column such as tags as JSON, an empty list as an empty string
+func marshalStrings(values []string) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal list: %w", err)
+	}
+	return string(data), nil
+}
+
+func marshalSegments(segments []NetworkSegment) (string, error) {
+	if len(segments) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(segments)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal network segments: %w", err)
+	}
+	return string(data), nil
+}
+
+func marshalRemoteAccess(connections []RemoteAccess) (string, error) {
+	if len(connections) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(connections)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal remote access: %w", err)
+	}
+	return string(data), nil
+}
+
 // detectMIME reads a small buffer to determine the file's MIME type
 func detectMIME(f multipart.File) (string, error) {
 	buffer := make([]byte, 512)
@@ -0,0 +1,116 @@
+package cloning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// waitForCloneSlot blocks until the target node has fewer active clone tasks than
+// the configured per-node limit, reporting pacing through the request's SSE stream
+func (cs *CloningService) waitForCloneSlot(ctx context.Context, req CloneRequest, node string) error {
+	// A limit of zero or less disables per-node throttling
+	if cs.Config.ClonesPerNode <= 0 {
+		return nil
+	}
+
+	start := time.Now()
+	notified := false
+
+	for {
+		active, err := cs.ProxmoxService.GetActiveCloneCount(node)
+		if err != nil {
+			// Don't block the deployment if task information is unavailable
+			log.Printf("Warning: unable to check active clones on node %s, continuing: %v", node, err)
+			return nil
+		}
+
+		if active < cs.Config.ClonesPerNode {
+			if notified {
+				log.Printf("Clone slot available on node %s after %v", node, time.Since(start).Round(time.Second))
+			}
+			return nil
+		}
+
+		if time.Since(start) > cs.Config.CloneSlotTimeout {
+			return fmt.Errorf("timed out waiting for clone slot on node %s (%d active clones)", node, active)
+		}
+
+		if !notified {
+			log.Printf("Node %s has %d active clones (limit %d), waiting for a free slot", node, active, cs.Config.ClonesPerNode)
+			req.SSE.Send(
+				ProgressMessage{
+					Message:  fmt.Sprintf("Waiting for clone capacity on %s (%d/%d active)", node, active, cs.Config.ClonesPerNode),
+					Progress: 10,
+				},
+			)
+			notified = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// paceCloneSubmission waits for a free slot on the node and then applies the
+// configured delay between clone submissions to avoid storage IO bursts
+func (cs *CloningService) paceCloneSubmission(ctx context.Context, req CloneRequest, node string) error {
+	if err := cs.waitForCloneSlot(ctx, req, node); err != nil {
+		return err
+	}
+
+	if cs.Config.CloneSubmitDelay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cs.Config.CloneSubmitDelay):
+		}
+	}
+
+	return nil
+}
+
+// submitClone paces and submits a clone to its target node, returning the UPID of the
+// clone task. Submissions to the same node are serialized so concurrent targets can't
+// overshoot the per-node clone limit.
+func (cs *CloningService) submitClone(ctx context.Context, req CloneRequest, cloneReq proxmox.VMCloneRequest) (string, error) {
+	lock, _ := cs.nodeLocks.LoadOrStore(cloneReq.TargetNode, &sync.Mutex{})
+	nodeLock := lock.(*sync.Mutex)
+
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+
+	if err := cs.paceCloneSubmission(ctx, req, cloneReq.TargetNode); err != nil {
+		return "", err
+	}
+
+	return cs.ProxmoxService.CloneVM(ctx, cloneReq)
+}
+
+// waitForCloneTask follows a submitted clone's Proxmox task to completion. Only a task
+// that Proxmox reports as failed fails the clone, when the outcome can't be determined
+// the clone is assumed to still be completing and later steps wait on the VM itself.
+func (cs *CloningService) waitForCloneTask(ctx context.Context, upid string, vmID int) error {
+	err := cs.ProxmoxService.TrackTask(ctx, upid, cs.Config.CloneTimeout)
+	if err == nil {
+		return nil
+	}
+
+	var taskErr *proxmox.TaskError
+	if errors.As(err, &taskErr) {
+		return fmt.Errorf("clone task failed: %s", taskErr.ExitStatus)
+	}
+
+	if ctx.Err() == nil {
+		log.Printf("Warning: unable to confirm clone of VM %d, continuing anyway: %v", vmID, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package cloning
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ThumbnailSizes are the widths in pixels template image thumbnails are generated at
+var ThumbnailSizes = []int{320, 960}
+
+// =================================================
+// Template Image Thumbnails
+// =================================================
+
+// TemplateImagePath returns the path of an uploaded image, or of its thumbnail at the
+// given width when one was generated. A width of zero, or one without a thumbnail
+// because the original is smaller, returns the original.
+func (c *TemplateClient) TemplateImagePath(filename string, width int) (string, error) {
+	original := filepath.Join(c.TemplateConfig.UploadDir, filepath.Base(filename))
+	if width == 0 {
+		return original, nil
+	}
+	if !slices.Contains(ThumbnailSizes, width) {
+		return "", fmt.Errorf("unsupported thumbnail size %d", width)
+	}
+
+	thumbnail := thumbnailPath(original, width)
+	if _, err := os.Stat(thumbnail); err == nil {
+		return thumbnail, nil
+	}
+
+	return original, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// generateThumbnails writes a downscaled copy of the image for every thumbnail size
+// narrower than the image. Failures are logged since the original can always be served.
+func generateThumbnails(imagePath string) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		log.Printf("Failed to open %s for thumbnails: %v", imagePath, err)
+		return
+	}
+	defer file.Close()
+
+	src, format, err := image.Decode(file)
+	if err != nil {
+		log.Printf("Failed to decode %s for thumbnails: %v", imagePath, err)
+		return
+	}
+
+	for _, width := range ThumbnailSizes {
+		if src.Bounds().Dx() <= width {
+			continue
+		}
+
+		if err := writeImage(thumbnailPath(imagePath, width), resizeImage(src, width), format); err != nil {
+			log.Printf("Failed to write %dpx thumbnail of %s: %v", width, imagePath, err)
+		}
+	}
+}
+
+// removeThumbnails deletes every thumbnail generated for the image
+func removeThumbnails(imagePath string) {
+	for _, width := range ThumbnailSizes {
+		if err := os.Remove(thumbnailPath(imagePath, width)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to delete %dpx thumbnail of %s: %v", width, imagePath, err)
+		}
+	}
+}
+
+// thumbnailPath names the thumbnail of image.png at width 320 image_320.png
+func thumbnailPath(imagePath string, width int) string {
+	ext := filepath.Ext(imagePath)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(imagePath, ext), width, ext)
+}
+
+// resizeImage scales the image down to the width, keeping its aspect ratio, by averaging
+// the source pixels each destination pixel covers
+func resizeImage(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := range width {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					r += uint64(pixel.R)
+					g += uint64(pixel.G)
+					b += uint64(pixel.B)
+					a += uint64(pixel.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+
+	return dst
+}
+
+// writeImage encodes the image in the format of its original, png keeps transparency
+func writeImage(path string, img image.Image, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if format == "png" {
+		return png.Encode(file, img)
+	}
+	return jpeg.Encode(file, img, &jpeg.Options{Quality: 85})
+}
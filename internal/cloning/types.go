@@ -2,25 +2,119 @@ package cloning
 
 import (
 	"database/sql"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/cpp-cyber/proclone/internal/ldap"
 	"github.com/cpp-cyber/proclone/internal/proxmox"
+	"github.com/cpp-cyber/proclone/internal/tools/events"
+	"github.com/cpp-cyber/proclone/internal/tools/jobs"
 	"github.com/cpp-cyber/proclone/internal/tools/sse"
 	"github.com/gin-gonic/gin"
 )
 
 // Config holds the configuration for cloning operations
 type Config struct {
-	RouterName        string        `envconfig:"PROXMOX_ROUTER_NAME" default:"1-1NAT-vyos"`
-	RouterVMID        int           `envconfig:"PROXMOX_ROUTER_VMID"`
-	RouterNode        string        `envconfig:"PROXMOX_ROUTER_NODE"`
-	MinPodID          int           `envconfig:"MIN_POD_ID" default:"1001"`
-	MaxPodID          int           `envconfig:"MAX_POD_ID" default:"1250"`
-	CloneTimeout      time.Duration `envconfig:"CLONE_TIMEOUT" default:"3m"`
-	SDNApplyTimeout   time.Duration `envconfig:"SDN_APPLY_TIMEOUT" default:"30s"`
-	RouterWaitTimeout time.Duration `envconfig:"ROUTER_WAIT_TIMEOUT" default:"120s"`
+	RouterName               string        `envconfig:"PROXMOX_ROUTER_NAME" default:"1-1NAT-vyos"`
+	RouterVMID               int           `envconfig:"PROXMOX_ROUTER_VMID"`
+	RouterNode               string        `envconfig:"PROXMOX_ROUTER_NODE"`
+	MinPodID                 int           `envconfig:"MIN_POD_ID" default:"1001"`
+	MaxPodID                 int           `envconfig:"MAX_POD_ID" default:"1250"`
+	CloneTimeout             time.Duration `envconfig:"CLONE_TIMEOUT" default:"3m"`
+	SDNApplyTimeout          time.Duration `envconfig:"SDN_APPLY_TIMEOUT" default:"30s"`
+	RouterWaitTimeout        time.Duration `envconfig:"ROUTER_WAIT_TIMEOUT" default:"120s"`
+	RouterRetryWindow        time.Duration `envconfig:"ROUTER_RETRY_WINDOW" default:"30m"`
+	RouterRetryInterval      time.Duration `envconfig:"ROUTER_RETRY_INTERVAL" default:"1m"`
+	PermissionRetryAttempts  int           `envconfig:"PERMISSION_RETRY_ATTEMPTS" default:"4"` // Attempts made during the clone before deferring
+	PermissionRetryWindow    time.Duration `envconfig:"PERMISSION_RETRY_WINDOW" default:"30m"`
+	PermissionRetryInterval  time.Duration `envconfig:"PERMISSION_RETRY_INTERVAL" default:"1m"`
+	ClonesPerNode            int           `envconfig:"CLONES_PER_NODE" default:"4"`
+	CloneConcurrency         int           `envconfig:"CLONE_CONCURRENCY" default:"4"`
+	CloneSubmitDelay         time.Duration `envconfig:"CLONE_SUBMIT_DELAY" default:"0s"`
+	CloneSlotTimeout         time.Duration `envconfig:"CLONE_SLOT_TIMEOUT" default:"10m"`
+	DeleteFailedClones       bool          `envconfig:"DELETE_FAILED_CLONES" default:"false"` // Deletes VMs of failed clones instead of keeping the pod for repair
+	DefaultPodQuota          int           `envconfig:"DEFAULT_POD_QUOTA" default:"5"`
+	EventPollInterval        time.Duration `envconfig:"EVENT_POLL_INTERVAL" default:"10s"`
+	SchedulerInterval        time.Duration `envconfig:"SCHEDULER_INTERVAL" default:"30s"`
+	ExportRemoteDir          string        `envconfig:"EXPORT_REMOTE_DIR"` // Export area path as seen by Proxmox nodes
+	ExportLocalDir           string        `envconfig:"EXPORT_LOCAL_DIR"`  // Export area path as mounted on this server
+	ExportMaxSize            int64         `envconfig:"EXPORT_MAX_SIZE" default:"21474836480"`
+	ExportTTL                time.Duration `envconfig:"EXPORT_TTL" default:"72h"`
+	ExportTimeout            time.Duration `envconfig:"EXPORT_TIMEOUT" default:"2h"`
+	PinnedMemoryThreshold    float64       `envconfig:"PINNED_MEMORY_THRESHOLD" default:"0.85"`
+	PinnedCPUThreshold       float64       `envconfig:"PINNED_CPU_THRESHOLD" default:"0.8"`
+	FlagFormat               string        `envconfig:"FLAG_FORMAT" default:"FLAG{%s}"`
+	FlagAgentTimeout         time.Duration `envconfig:"FLAG_AGENT_TIMEOUT" default:"5m"`
+	WarmPoolOwner            string        `envconfig:"WARM_POOL_OWNER"` // User that holds warm pods until assignment, empty disables warm pools
+	WarmPoolInterval         time.Duration `envconfig:"WARM_POOL_INTERVAL" default:"2m"`
+	IdempotencyWindow        time.Duration `envconfig:"IDEMPOTENCY_WINDOW" default:"10m"`
+	ArchiveStorage           string        `envconfig:"ARCHIVE_STORAGE"` // Proxmox backup storage for archived pods, ideally a deduplicating one
+	ArchiveTimeout           time.Duration `envconfig:"ARCHIVE_TIMEOUT" default:"2h"`
+	BackupStorage            string        `envconfig:"BACKUP_STORAGE"`               // Proxmox backup storage for template and pod backups, empty disables them
+	BackupInterval           time.Duration `envconfig:"BACKUP_INTERVAL" default:"0s"` // Zero leaves backups to template authors and admins
+	BackupPods               bool          `envconfig:"BACKUP_PODS" default:"false"`  // Scheduled backups include user pods
+	BackupKeep               int           `envconfig:"BACKUP_KEEP" default:"3"`      // Backups kept per VM, zero keeps all of them
+	BackupTimeout            time.Duration `envconfig:"BACKUP_TIMEOUT" default:"2h"`
+	RebalanceMemoryThreshold float64       `envconfig:"REBALANCE_MEMORY_THRESHOLD" default:"0.85"`
+	RebalanceInterval        time.Duration `envconfig:"REBALANCE_INTERVAL" default:"0s"` // Zero leaves rebalancing to admins
+	MigrationTimeout         time.Duration `envconfig:"MIGRATION_TIMEOUT" default:"30m"`
+	SmokeTestTemplate        string        `envconfig:"SMOKE_TEST_TEMPLATE"` // Tiny template cloned by smoke tests
+	SmokeTestUser            string        `envconfig:"SMOKE_TEST_USER"`     // User that owns smoke test pods
+	CapacitySampleInterval   time.Duration `envconfig:"CAPACITY_SAMPLE_INTERVAL" default:"1h"`
+	CapacityHistoryDays      int           `envconfig:"CAPACITY_HISTORY_DAYS" default:"90"`
+	CapacityRateDays         int           `envconfig:"CAPACITY_RATE_DAYS" default:"30"` // Recent days the deployment rate is fitted to
+	DeprecationInterval      time.Duration `envconfig:"DEPRECATION_INTERVAL" default:"1h"`
+	DeprecationHideAfter     time.Duration `envconfig:"DEPRECATION_HIDE_AFTER" default:"168h"`   // Deprecated templates are made invisible after this
+	DeprecationDeleteAfter   time.Duration `envconfig:"DEPRECATION_DELETE_AFTER" default:"720h"` // Zero keeps deprecated template pools
+	ReconcileInterval        time.Duration `envconfig:"RECONCILE_INTERVAL" default:"6h"`         // Zero disables the scheduled template reconciliation
+	IPAMReservedSubnets      []string      `envconfig:"IPAM_RESERVED_SUBNETS"`                   // CIDRs pod WAN subnets must not overlap
+	PodFirewall              bool          `envconfig:"POD_FIREWALL" default:"false"`            // Applies the default deny inbound firewall to new pods
+
+	// ClonePriorityWeights is the share of clone queue turns each priority class gets
+	ClonePriorityWeights map[string]int `envconfig:"CLONE_PRIORITY_WEIGHTS" default:"admin:4,instructor:2,user:1"`
+
+	HeartbeatInterval  time.Duration `envconfig:"HEARTBEAT_INTERVAL" default:"0s"` // Zero disables the activity heartbeat
+	HeartbeatVMPattern string        `envconfig:"HEARTBEAT_VM_PATTERN"`            // VMs polled for logged in users, empty polls every non-router VM
+
+	// WireGuard VPN access goes through a shared gateway VM that routes to the pod WAN subnets
+	WireGuardGatewayNode  string `envconfig:"WIREGUARD_GATEWAY_NODE"` // Empty disables VPN access
+	WireGuardGatewayVMID  int    `envconfig:"WIREGUARD_GATEWAY_VMID"`
+	WireGuardEndpoint     string `envconfig:"WIREGUARD_ENDPOINT"`   // host:port clients connect to
+	WireGuardPublicKey    string `envconfig:"WIREGUARD_PUBLIC_KEY"` // Public key of the gateway interface
+	WireGuardClientSubnet string `envconfig:"WIREGUARD_CLIENT_SUBNET" default:"10.200.0.0/16"`
+	WireGuardPeerScript   string `envconfig:"WIREGUARD_PEER_SCRIPT" default:"/usr/local/bin/kamino-wg-peer"` // Adds or removes a peer and its route on the gateway
+
+	// Pod DNS records are managed through the PowerDNS HTTP API
+	DNSAPIURL    string `envconfig:"DNS_API_URL"` // Such as http://pdns:8081, empty disables pod DNS records
+	DNSAPIKey    string `envconfig:"DNS_API_KEY"`
+	DNSServerID  string `envconfig:"DNS_SERVER_ID" default:"localhost"`
+	DNSZone      string `envconfig:"DNS_ZONE"` // Such as lab.example.com
+	DNSRecordTTL int    `envconfig:"DNS_RECORD_TTL" default:"300"`
+
+	// Defaults for the pod VNets until an admin saves VNet settings
+	VNetPrefix string `envconfig:"VNET_PREFIX" default:"kamino"` // Pod VNets are named <prefix><pod number>
+	VNetZone   string `envconfig:"VNET_ZONE"`                    // SDN zone of the pod VNets, empty accepts any zone
+	VLANBase   int    `envconfig:"VLAN_BASE" default:"1800"`     // Pod VNets are tagged <base>+<pod number>, zero skips the check
+
+	// VNets created for the extra network segments of a pod, in the zone of the pod VNets
+	SegmentVNetPrefix string `envconfig:"SEGMENT_VNET_PREFIX" default:"seg"` // Segment VNets are named <prefix><pod number>s<segment>
+	SegmentVLANBase   int    `envconfig:"SEGMENT_VLAN_BASE" default:"2800"`  // Segments of a pod are tagged from <base>+(<pod number>-1)*4
+
+	// Written to the cloud-init drive of pod VMs that have one, the VM name becomes the hostname
+	CloudInitUser     string `envconfig:"CLOUDINIT_USER"`
+	CloudInitPassword string `envconfig:"CLOUDINIT_PASSWORD"`
+	CloudInitSSHKeys  string `envconfig:"CLOUDINIT_SSH_KEYS"` // Authorized keys, one per line
+
+	// Pod connections are created through the Guacamole REST API by an admin account
+	GuacamoleURL        string `envconfig:"GUACAMOLE_URL"` // Such as http://guacamole:8080/guacamole, empty disables Guacamole connections
+	GuacamoleUsername   string `envconfig:"GUACAMOLE_USERNAME"`
+	GuacamolePassword   string `envconfig:"GUACAMOLE_PASSWORD"`
+	GuacamoleDataSource string `envconfig:"GUACAMOLE_DATA_SOURCE"` // Such as mysql, empty uses the data source of the login
+
+	// Snapshots pod owners take of their own VMs, which hold on to storage until deleted
+	MaxVMSnapshots  int           `envconfig:"MAX_VM_SNAPSHOTS" default:"3"` // Per VM, zero disables user snapshots
+	SnapshotTimeout time.Duration `envconfig:"SNAPSHOT_TIMEOUT" default:"10m"`
 }
 
 // KaminoTemplate represents a template in the system
@@ -35,8 +129,111 @@ type KaminoTemplate struct {
 	VMCount         int    `json:"vm_count" binding:"min=0,max=100"`
 	Deployments     int    `json:"deployments" binding:"min=0"`
 	CreatedAt       string `json:"created_at" binding:"omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CloneMode       string `json:"clone_mode" binding:"omitempty,oneof=linked full"` // Empty lets Proxmox decide
+	Storage         string `json:"storage" binding:"omitempty,max=100"`              // Storage of full clones, empty keeps the template disks' storage
+	HAGroup         string `json:"ha_group" binding:"omitempty,max=100"`             // HA group cloned VMs are registered in, empty leaves them unmanaged
+	PublishedBy     string `json:"published_by"`                                     // Set from the session, never the request
+	UpdatedBy       string `json:"updated_by,omitempty"`
+	UpdatedAt       string `json:"updated_at,omitempty"`
+	DeprecatedAt    string `json:"deprecated_at,omitempty"`
+	Draft           bool   `json:"draft"`                                    // Only the publisher and admins can see and deploy a draft
+	MaxDeployments  int    `json:"max_deployments" binding:"min=0,max=1000"` // Pods of the template deployed at once, zero is unlimited
+	Organization    string `json:"organization" binding:"omitempty,max=100"` // Empty puts the template in the shared catalog
+	MinVMID         int    `json:"min_vmid" binding:"min=0"`                 // Zero range allocates from the whole cluster
+	MaxVMID         int    `json:"max_vmid" binding:"min=0"`
+
+	// Catalog classification used to filter and sort the template list
+	Category   string   `json:"category" binding:"omitempty,max=100"`
+	Tags       []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+	Difficulty string   `json:"difficulty" binding:"omitempty,oneof=beginner intermediate advanced"`
+
+	// Markdown lab guide shown to pod owners, sanitized when the template is saved
+	Guide string `json:"guide" binding:"omitempty,max=20000"`
+
+	// LAN subnets the template's VMs expect, the pod router takes the first address of
+	// each. Empty keeps the LAN layout of the router image.
+	LANSubnets []string `json:"lan_subnets" binding:"omitempty,max=8,dive,cidrv4"`
+
+	// Internal networks besides the pod VNet, such as a DMZ. Each pod gets a VNet per
+	// segment and the NICs the template put on the segment's bridge are moved to it.
+	Segments []NetworkSegment `json:"segments" binding:"omitempty,max=4,dive"`
+
+	// Limit in MB/s on the router's WAN NIC, which carries all of a pod's uplink traffic.
+	// Zero is unlimited, admins can override it per pod.
+	RateLimit float64 `json:"rate_limit" binding:"min=0,max=12500"`
+
+	// Guacamole connections created for the owner of each pod, see RemoteAccess
+	RemoteAccess []RemoteAccess `json:"remote_access" binding:"omitempty,max=20,dive"`
+
+	// Groups allowed to see and clone the template, empty allows every user. Set through
+	// SetTemplateAllowedGroups rather than publishing or editing.
+	AllowedGroups []string `json:"allowed_groups"`
+
+	// Resources one deployment uses, cached by RefreshTemplateResources
+	Resources ResourceRequirement `json:"resources"`
+
+	// Ordered gallery of uploaded images for catalog pages, managed through AddTemplateImage,
+	// ReorderTemplateImages, and DeleteTemplateImage
+	Images []string `json:"images"`
 }
 
+// Template difficulties in increasing order
+var templateDifficulties = []string{"beginner", "intermediate", "advanced"}
+
+// TemplateFilter selects and orders templates in the catalog. Unset fields match every template.
+type TemplateFilter struct {
+	Category   string
+	Tags       []string // Templates must have every tag
+	Difficulty string
+	Search     string // Matched against the name and description
+	Sort       string // One of name, created_at, deployments, or difficulty, empty keeps the catalog order
+	Descending bool
+}
+
+// Template change actions
+const (
+	TemplateActionPublish    = "publish"
+	TemplateActionEdit       = "edit"
+	TemplateActionVisibility = "visibility"
+	TemplateActionDelete     = "delete"
+	TemplateActionDeprecate  = "deprecate"
+)
+
+// TemplateChange is an entry in a template's edit history
+type TemplateChange struct {
+	ID        int64         `json:"id"`
+	Template  string        `json:"template"`
+	Action    string        `json:"action"`
+	ChangedBy string        `json:"changed_by"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// FieldChange records the old and new value of a single template field
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+}
+
+// Placement rule types
+const (
+	PlacementSpread   = "spread"   // Clone the VMs to different nodes
+	PlacementTogether = "together" // Clone the VMs to the same node
+)
+
+// PlacementRule is a template-level hint for the nodes the VMs of each pod are cloned to
+type PlacementRule struct {
+	Type    string   `json:"type" yaml:"type"`
+	VMNames []string `json:"vm_names" yaml:"vm_names"`
+}
+
+// Clone modes supported for template deployments
+const (
+	CloneModeLinked = "linked"
+	CloneModeFull   = "full"
+)
+
 // DatabaseService interface defines the methods for template operations
 type DatabaseService interface {
 	GetTemplates() ([]KaminoTemplate, error)
@@ -51,6 +248,138 @@ type DatabaseService interface {
 	EditTemplate(template KaminoTemplate) error
 	GetAllTemplateNames() ([]string, error)
 	DeleteImage(imagePath string) error
+	GetPodQuotas() ([]PodQuota, error)
+	GetPodQuota(target string, isGroup bool) (*PodQuota, error)
+	SetPodQuota(quota PodQuota) error
+	DeletePodQuota(target string, isGroup bool) error
+	CreateScheduledDeployment(deployment ScheduledDeployment) (int64, error)
+	GetScheduledDeployments() ([]ScheduledDeployment, error)
+	ClaimDueScheduledDeployments() ([]ScheduledDeployment, error)
+	UpdateScheduledDeploymentStatus(id int64, status string, errMsg string) error
+	FailRunningScheduledDeployments(errMsg string) (int64, error)
+	CancelScheduledDeployment(id int64) error
+	CreatePodExport(export PodExport) error
+	GetPodExport(token string) (*PodExport, error)
+	GetPodExports(owner string) ([]PodExport, error)
+	GetExpiredPodExports() ([]PodExport, error)
+	UpdatePodExport(export PodExport) error
+	SavePodCloneRecord(record PodCloneRecord) error
+	GetPodCloneRecords(pod string) ([]PodCloneRecord, error)
+	DeletePodCloneRecords(pod string) error
+	RenamePodCloneRecords(pod string, newPod string, target string, isGroup bool) error
+	GetPodCreationTimes() (map[string]time.Time, error)
+	GetRepairablePods() ([]string, error)
+	SavePodShare(share PodShare) error
+	GetPodShares(pod string) ([]PodShare, error)
+	GetSharesForUser(username string) ([]PodShare, error)
+	DeletePodShare(pod string, sharedWith string) error
+	RenamePodShares(pod string, newPod string) error
+	DeletePodShares(pod string) error
+	GetTemplateFlags(templateName string) ([]TemplateFlag, error)
+	SetTemplateFlags(templateName string, flags []TemplateFlag) error
+	SavePodFlag(flag PodFlag) error
+	GetPodFlags(pod string) ([]PodFlag, error)
+	MarkPodFlagSolved(pod string, name string, username string) error
+	DeletePodFlags(pod string) error
+	SavePodRouterStatus(status PodRouterStatus) error
+	GetPodRouterStatuses() ([]PodRouterStatus, error)
+	GetPodRouterStatus(pod string) (*PodRouterStatus, error)
+	UpdatePodRouterStatus(status PodRouterStatus) (bool, error)
+	RenamePodRouterStatus(pod string, newPod string) error
+	DeletePodRouterStatus(pod string) error
+	SavePodAccessStatus(status PodAccessStatus) error
+	GetPodAccessStatuses() ([]PodAccessStatus, error)
+	GetPodAccessStatus(pod string) (*PodAccessStatus, error)
+	UpdatePodAccessStatus(status PodAccessStatus) (bool, error)
+	RenamePodAccessStatus(pod string, newPod string, target string, isGroup bool) error
+	DeletePodAccessStatus(pod string) error
+	SetTemplateUpdatedBy(templateName string, username string) error
+	InsertTemplateChange(change TemplateChange) error
+	GetTemplateChanges(templateName string) ([]TemplateChange, error)
+	SaveCloneBenchmark(benchmark CloneBenchmark) (int64, error)
+	GetCloneBenchmarks(templateName string, numTargets int, limit int) ([]CloneBenchmark, error)
+	RenamePodFlags(pod string, newPod string) error
+	GetWarmPoolSizes() ([]WarmPoolSize, error)
+	SetWarmPoolSize(size WarmPoolSize) error
+	AddWarmPod(pod string, templateName string) error
+	GetWarmPods(templateName string) ([]string, error)
+	ClaimWarmPod(templateName string) (string, error)
+	DeleteWarmPod(pod string) error
+	SavePodArchiveVM(vm PodArchiveVM) error
+	GetPodArchive(pod string) ([]PodArchiveVM, error)
+	GetArchivedPods() ([]string, error)
+	DeletePodArchive(pod string) error
+	DeletePodArchiveVM(pod string, vmid int) error
+	SavePodRetention(retention PodRetention) error
+	GetPodRetention(pod string) (*PodRetention, error)
+	GetRetainedPods() ([]string, error)
+	DeletePodRetention(pod string) error
+	SavePodActivity(activity PodActivity) error
+	GetPodActivities() ([]PodActivity, error)
+	DeletePodActivity(pod string) error
+	GetPlacementRules(templateName string) ([]PlacementRule, error)
+	SetPlacementRules(templateName string, rules []PlacementRule) error
+	RecordPodIDUsage(used int) error
+	GetPodIDUsage(since time.Time) ([]PodIDUsage, error)
+	GetOrganizations() ([]Organization, error)
+	GetOrganization(name string) (*Organization, error)
+	SaveOrganization(org Organization) error
+	DeleteOrganization(name string) error
+	SetTemplateOrganization(templateName string, organization string) error
+	CreateDeploymentBlackout(blackout DeploymentBlackout) (int64, error)
+	GetDeploymentBlackouts() ([]DeploymentBlackout, error)
+	DeleteDeploymentBlackout(id int64) error
+	ReadTemplateImage(imagePath string) ([]byte, error)
+	SaveTemplateImage(filename string, data []byte) (string, error)
+	TemplateImagePath(filename string, width int) (string, error)
+	ListTemplateImageFiles() ([]string, error)
+	SetTemplateAllowedGroups(templateName string, groups []string) error
+	SetTemplateImages(templateName string, images []string) error
+	SetTemplateResources(templateName string, resources ResourceRequirement) error
+	SearchTemplates(query string) ([]KaminoTemplate, error)
+	SaveSubnetAllocation(allocation SubnetAllocation) error
+	GetSubnetAllocations() ([]SubnetAllocation, error)
+	RenameSubnetAllocation(pod string, newPod string) error
+	DeleteSubnetAllocation(pod string) error
+	InsertPodFirewallRule(rule PodFirewallRule) (int64, error)
+	GetPodFirewallRules(pod string) ([]PodFirewallRule, error)
+	DeletePodFirewallRule(pod string, id int64) error
+	RenamePodFirewallRules(pod string, newPod string) error
+	DeletePodFirewallRules(pod string) error
+	SetTemplateDeprecated(templateName string, deprecated bool) error
+	GetTemplateDeprecations() (map[string]time.Time, error)
+	SaveTemplateDeployment(deployment TemplateDeployment) error
+	GetTemplateStats() ([]TemplateStats, error)
+	InsertPodPortForward(forward PodPortForward) (int64, error)
+	GetPodPortForwards(pod string) ([]PodPortForward, error)
+	DeletePodPortForward(pod string, id int64) error
+	RenamePodPortForwards(pod string, newPod string) error
+	DeletePodPortForwards(pod string) error
+	InsertVPNConfig(config VPNConfig) (int64, error)
+	GetVPNConfigs(pod string) ([]VPNConfig, error)
+	GetVPNAddresses() ([]string, error)
+	DeleteVPNConfig(pod string, id int64) error
+	GetVNetSettings() (*VNetSettings, error)
+	SaveVNetSettings(settings VNetSettings) error
+	InsertVLANReservation(reservation VLANReservation) (int64, error)
+	GetVLANReservations() ([]VLANReservation, error)
+	DeleteVLANReservation(id int64) error
+	SaveVNetAllocation(allocation VNetAllocation) error
+	GetVNetAllocations() ([]VNetAllocation, error)
+	RenameVNetAllocation(pod string, newPod string) error
+	DeleteVNetAllocation(pod string) error
+	SavePodSegment(segment PodSegment) error
+	GetPodSegments(pod string) ([]PodSegment, error)
+	GetSegmentPods() ([]string, error)
+	RenamePodSegments(pod string, newPod string) error
+	DeletePodSegment(pod string, alias string) error
+	GetPodRateLimit(pod string) (*PodRateLimit, error)
+	SavePodRateLimit(limit PodRateLimit) error
+	RenamePodRateLimit(pod string, newPod string) error
+	DeletePodRateLimit(pod string) error
+	InsertPodGuacamoleConnection(connection PodGuacamoleConnection) error
+	GetPodGuacamoleConnections(pod string) ([]PodGuacamoleConnection, error)
+	DeletePodGuacamoleConnections(pod string) error
 }
 
 // TemplateConfig holds template configuration
@@ -79,7 +408,21 @@ type CloningService struct {
 	DatabaseService DatabaseService
 	LDAPService     ldap.Service
 	Config          *Config
+	Events          *events.Bus
+	Jobs            *jobs.Registry
 	vmidMutex       sync.Mutex // Protects resource allocation operations (Pod IDs and VM IDs)
+	nodeLocks       sync.Map   // Per-node mutexes serializing clone submissions
+	sdnMutex        sync.Mutex // Keeps VNet changes and the SDN apply that follows them together
+
+	warmPoolFailures sync.Map // Consecutive failed warm pool replenishments by template
+
+	idempotencyMutex sync.Mutex
+	idempotencyKeys  map[string]*IdempotentClone // Clones by user and idempotency key
+
+	rebalanceMutex sync.Mutex // Held while a rebalance migrates pods
+	smokeTestMutex sync.Mutex // Held while a smoke test runs
+
+	cloneQueue *cloneQueue // Orders clone jobs waiting for resource allocation by priority
 }
 
 // PodResponse represents the response structure for pod operations
@@ -89,9 +432,16 @@ type PodResponse struct {
 
 // Pod represents a pod containing VMs and template information
 type Pod struct {
-	Name     string                    `json:"name"`
-	VMs      []proxmox.VirtualResource `json:"vms"`
-	Template KaminoTemplate            `json:"template"`
+	Name         string                    `json:"name"`
+	VMs          []proxmox.VirtualResource `json:"vms"`
+	Template     KaminoTemplate            `json:"template"`
+	SharedAccess string                    `json:"shared_access,omitempty"` // Set when the pod is shared with the user
+	RouterStatus string                    `json:"router_status,omitempty"` // Set when router configuration was deferred
+	AccessStatus string                    `json:"access_status,omitempty"` // Set when applying the pool permission was deferred
+	Archived     bool                      `json:"archived,omitempty"`      // Set when the VMs are archived to backups
+	Retained     bool                      `json:"retained,omitempty"`      // Set when the pod is retained without access
+	Repairable   bool                      `json:"repairable,omitempty"`    // Set when VM clones failed and RepairPod can re-clone them
+	LastActivity *time.Time                `json:"last_activity,omitempty"` // Last time a user was logged in, set by the heartbeat
 }
 
 var allowedMIMEs = map[string]struct{}{
@@ -100,32 +450,729 @@ var allowedMIMEs = map[string]struct{}{
 }
 
 type CloneTarget struct {
-	Name      string
-	IsGroup   bool
-	Node      string
-	PoolName  string
-	PodID     string
-	PodNumber int
-	VMIDs     []int
+	Name      string `json:"name"`
+	IsGroup   bool   `json:"is_group"`
+	Node      string `json:"node,omitempty"`
+	PoolName  string `json:"pool_name,omitempty"`
+	PodID     string `json:"pod_id,omitempty"`
+	PodNumber int    `json:"pod_number,omitempty"`
+	VMIDs     []int  `json:"vmids,omitempty"`
 }
 
 type CloneRequest struct {
 	Template                 string
 	Targets                  []CloneTarget
-	CheckExistingDeployments bool // Whether to check if templates are already deployed
-	StartingVMID             int  // Optional starting VMID for admin clones
+	CheckExistingDeployments bool          // Whether to check if templates are already deployed
+	StartingVMID             int           // Optional starting VMID for admin clones
+	CloneMode                string        // Optional override of the template's clone mode
+	Storage                  string        // Optional override of the template's storage for full clones
+	HAGroup                  string        // Optional override of the template's HA group
+	Nodes                    []string      // Optional nodes to pin all targets to instead of FindBestNode
+	TargetNode               string        // Optional node every target is cloned to, bypassing node selection
+	VMNames                  []string      // Optional subset of the template's VMs to clone, the router is always cloned
+	UseWarmPool              bool          // Whether targets may be assigned pre-cloned warm pods instead of cloning
+	IdempotencyKey           string        // Optional key claimed with ClaimIdempotencyKey, linked to the job
+	RequestedBy              string        // User who requested the deployment, recorded as the job owner
+	Priority                 ClonePriority // Class the deployment is queued under when clones compete
+	JobID                    string        // Set by CloneTemplate to the job tracking the deployment
 	SSE                      *sse.Writer
 }
 
+// ClonePriority is the class a clone job is scheduled under in the clone queue
+type ClonePriority string
+
 type RouterInfo struct {
 	TargetName string
+	PoolName   string
 	RouterType string
 	PodNumber  int
 	Node       string
 	VMID       int
 }
 
+// PodQuota is the maximum number of pods a user or group may have deployed
+type PodQuota struct {
+	Target  string `json:"target"`
+	IsGroup bool   `json:"is_group"`
+	MaxPods int    `json:"max_pods"`
+}
+
+// PodFilter selects deployed pods for bulk operations. Unset fields match every pod.
+type PodFilter struct {
+	Template string
+	Owner    string
+	MinAge   time.Duration // Pods without clone records have no known age and never match
+	MinPodID int
+	MaxPodID int
+}
+
+// PodArchiveVM is the backup of a VM removed when its pod was archived
+type PodArchiveVM struct {
+	Pod       string    `json:"pod"`
+	VMID      int       `json:"vmid"`
+	Node      string    `json:"node"`
+	Name      string    `json:"name"`
+	VolID     string    `json:"volid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PoolBackup is a backup of a VM of a template pool or pod
+type PoolBackup struct {
+	Pool      string    `json:"pool"`
+	VMID      int       `json:"vmid"`
+	Name      string    `json:"name"`
+	Node      string    `json:"node"` // Node the backup was listed from, any node for shared storage
+	VolID     string    `json:"volid"`
+	Type      string    `json:"type"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PodRetention records a pod retained with its VMs shut down and its access removed
+// Organization scopes templates, quotas, pod IDs and administration to a group of users
+// such as a club, class or department sharing the cluster
+type Organization struct {
+	Name            string `json:"name" binding:"required,min=1,max=100"`
+	Description     string `json:"description" binding:"omitempty,max=1000"`
+	MemberGroup     string `json:"member_group" binding:"omitempty,max=255"`
+	AdminGroup      string `json:"admin_group" binding:"omitempty,max=255"`
+	MinPodID        int    `json:"min_pod_id" binding:"min=0"` // Zero range uses the instance range
+	MaxPodID        int    `json:"max_pod_id" binding:"min=0"`
+	DefaultPodQuota int    `json:"default_pod_quota" binding:"min=0"` // Zero leaves members on the global default
+}
+
+type PodRetention struct {
+	Pod        string    `json:"pod"`
+	Owner      string    `json:"owner"`
+	IsGroup    bool      `json:"is_group"`
+	RetainedBy string    `json:"retained_by"`
+	RetainedAt time.Time `json:"retained_at"`
+}
+
+// PodActivity is the latest heartbeat of a pod's designated VMs
+type PodActivity struct {
+	Pod          string     `json:"pod"`
+	ActiveUsers  int        `json:"active_users"`
+	Uptime       int        `json:"uptime"` // Seconds the longest running designated VM has been up
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+	CheckedAt    time.Time  `json:"checked_at"`
+}
+
+// IdempotentClone is a clone request remembered under its idempotency key
+type IdempotentClone struct {
+	Template  string    `json:"template"`
+	JobID     string    `json:"job_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WarmPoolSize is the number of pre-cloned pods kept ready for a template
+type WarmPoolSize struct {
+	Template string `json:"template"`
+	Size     int    `json:"size"`
+}
+
+// WarmPoolStatus reports how many warm pods of a template are ready for assignment
+type WarmPoolStatus struct {
+	Template string   `json:"template"`
+	Size     int      `json:"size"`
+	Ready    int      `json:"ready"`
+	Pods     []string `json:"pods"`
+}
+
+// RebalancePlan lists the pod migrations that bring overloaded nodes under the threshold
+type RebalancePlan struct {
+	Threshold float64    `json:"threshold"`
+	Nodes     []NodeLoad `json:"nodes"`
+	Moves     []PodMove  `json:"moves"`
+}
+
+// NodeLoad is the memory usage of a node before and after the planned migrations
+type NodeLoad struct {
+	Name          string  `json:"name"`
+	MemoryUsage   float64 `json:"memory_usage"`
+	ProjectedLoad float64 `json:"projected_usage"`
+	Overloaded    bool    `json:"overloaded"` // Still above the threshold after the migrations
+}
+
+// PodMove migrates every VM of a pod that is not already on the target node
+type PodMove struct {
+	Pod    string      `json:"pod"`
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Memory int64       `json:"memory"` // Memory of the running VMs moved off their nodes
+	VMs    []PodMoveVM `json:"vms"`
+}
+
+// PodMoveVM is a VM migrated as part of a pod move
+type PodMoveVM struct {
+	VMID    int    `json:"vmid"`
+	Name    string `json:"name"`
+	From    string `json:"from"`
+	Running bool   `json:"running"` // Running VMs are migrated live
+}
+
+// SmokeTestResult reports the outcome and timing of every step of a smoke test
+type SmokeTestResult struct {
+	Template  string          `json:"template"`
+	Pod       string          `json:"pod,omitempty"`
+	Success   bool            `json:"success"`
+	Steps     []SmokeTestStep `json:"steps"`
+	StartedAt time.Time       `json:"started_at"`
+	Duration  int64           `json:"duration_ms"`
+}
+
+// SmokeTestStep is the outcome of one smoke test step
+type SmokeTestStep struct {
+	Name     string `json:"name"`
+	Success  bool   `json:"success"`
+	Duration int64  `json:"duration_ms"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CapacityReport reports how many more pods the configured pod range can hold
+type CapacityReport struct {
+	Ranges              []CapacityRange `json:"ranges"`
+	Free                int             `json:"free"` // Pods that can still be deployed, limited by the smallest range
+	History             []PodIDUsage    `json:"history"`
+	DailyRate           float64         `json:"daily_rate"`                     // Recent growth in pod IDs used per day
+	ProjectedExhaustion *string         `json:"projected_exhaustion,omitempty"` // Unset when usage is not growing
+}
+
+// CapacityRange is the usage of one resource allocated per pod number
+type CapacityRange struct {
+	Resource string `json:"resource"` // pod_ids, vnets or wan_subnets
+	Range    string `json:"range"`
+	Total    int    `json:"total"`
+	Used     int    `json:"used"`
+	Free     int    `json:"free"`
+}
+
+// PodIDUsage is the peak number of pod IDs used on a day
+type PodIDUsage struct {
+	Date string `json:"date"`
+	Used int    `json:"used"`
+}
+
+// ScheduledDeployment is a clone request persisted to run at a future time
+type ScheduledDeployment struct {
+	ID           int64         `json:"id"`
+	Template     string        `json:"template"`
+	Targets      []CloneTarget `json:"targets"`
+	StartingVMID int           `json:"starting_vmid,omitempty"`
+	RunAt        time.Time     `json:"run_at"`
+	TimeZone     string        `json:"time_zone,omitempty"` // IANA time zone the deployment was scheduled in
+	Status       string        `json:"status"`
+	CreatedBy    string        `json:"created_by"`
+	Error        string        `json:"error,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// TemplateManifest is a portable description of a template definition for moving it
+// between instances. The VMs themselves are not included.
+type TemplateManifest struct {
+	Version         int              `json:"version" yaml:"version"`
+	Name            string           `json:"name" yaml:"name"`
+	Description     string           `json:"description" yaml:"description"`
+	Authors         string           `json:"authors,omitempty" yaml:"authors,omitempty"`
+	TemplateVisible bool             `json:"template_visible" yaml:"template_visible"`
+	CloneMode       string           `json:"clone_mode,omitempty" yaml:"clone_mode,omitempty"`
+	Storage         string           `json:"storage,omitempty" yaml:"storage,omitempty"`
+	HAGroup         string           `json:"ha_group,omitempty" yaml:"ha_group,omitempty"`
+	MaxDeployments  int              `json:"max_deployments,omitempty" yaml:"max_deployments,omitempty"`
+	Category        string           `json:"category,omitempty" yaml:"category,omitempty"`
+	Tags            []string         `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Difficulty      string           `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	Guide           string           `json:"guide,omitempty" yaml:"guide,omitempty"`
+	LANSubnets      []string         `json:"lan_subnets,omitempty" yaml:"lan_subnets,omitempty"`
+	Segments        []NetworkSegment `json:"segments,omitempty" yaml:"segments,omitempty"`
+	RateLimit       float64          `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	RemoteAccess    []RemoteAccess   `json:"remote_access,omitempty" yaml:"remote_access,omitempty"`
+	VMs             []ManifestVM     `json:"vms" yaml:"vms"`
+	Flags           []ManifestFlag   `json:"flags,omitempty" yaml:"flags,omitempty"`
+	PlacementRules  []PlacementRule  `json:"placement_rules,omitempty" yaml:"placement_rules,omitempty"`
+	Image           *ManifestImage   `json:"image,omitempty" yaml:"image,omitempty"`
+	ExportedAt      time.Time        `json:"exported_at" yaml:"exported_at"`
+}
+
+type ManifestVM struct {
+	Name     string            `json:"name" yaml:"name"`
+	Type     string            `json:"type" yaml:"type"`
+	IsRouter bool              `json:"is_router,omitempty" yaml:"is_router,omitempty"`
+	Networks map[string]string `json:"networks,omitempty" yaml:"networks,omitempty"` // Network device to bridge, such as net0: vmbr0
+}
+
+type ManifestFlag struct {
+	Name   string `json:"name" yaml:"name"`
+	VMName string `json:"vm_name" yaml:"vm_name"`
+	Path   string `json:"path" yaml:"path"`
+}
+
+type ManifestImage struct {
+	Filename string `json:"filename" yaml:"filename"`
+	Data     string `json:"data" yaml:"data"` // Base64 encoded image
+}
+
+// DeploymentBlackout is a period during which users may not deploy pods, such as a
+// competition freeze or maintenance window. Admin deployments are not affected.
+type DeploymentBlackout struct {
+	ID        int64     `json:"id"`
+	Reason    string    `json:"reason"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PodExport is a downloadable backup of a single pod VM
+type PodExport struct {
+	Token     string    `json:"token"`
+	Pod       string    `json:"pod"`
+	VMID      int       `json:"vmid"`
+	Owner     string    `json:"owner"`
+	Filename  string    `json:"filename,omitempty"`
+	Size      int64     `json:"size,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PodCloneRecord is the recorded outcome of cloning one VM into a pod
+type PodCloneRecord struct {
+	Pod        string `json:"pod"`
+	VMIndex    int    `json:"vm_index"`
+	Template   string `json:"template"`
+	Target     string `json:"target"`
+	IsGroup    bool   `json:"is_group"`
+	PodNumber  int    `json:"pod_number"`
+	SourceVMID int    `json:"source_vmid"`
+	SourceNode string `json:"source_node"`
+	SourceName string `json:"source_name"`
+	VMID       int    `json:"vmid"`
+	IsRouter   bool   `json:"is_router"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PodShare grants a Kamino user access to a pod they do not own
+type PodShare struct {
+	Pod        string    `json:"pod"`
+	SharedWith string    `json:"shared_with"`
+	Access     string    `json:"access"`
+	SharedBy   string    `json:"shared_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TemplateFlag declares a flag placeholder written into a pod VM at clone time
+type TemplateFlag struct {
+	Template string `json:"template"`
+	Name     string `json:"name"`
+	VMName   string `json:"vm_name"`
+	Path     string `json:"path"`
+}
+
+// PodFlag is the hashed, per-pod value generated for a template flag
+type PodFlag struct {
+	Pod      string     `json:"pod"`
+	Name     string     `json:"name"`
+	Hash     string     `json:"-"`
+	SolvedAt *time.Time `json:"solved_at,omitempty"`
+	SolvedBy string     `json:"solved_by,omitempty"`
+}
+
+// PodRouterStatus tracks a pod router whose configuration is being retried in the background
+type PodRouterStatus struct {
+	Pod        string    `json:"pod"`
+	Node       string    `json:"node"`
+	VMID       int       `json:"vmid"`
+	RouterType string    `json:"router_type"`
+	PodNumber  int       `json:"pod_number"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error,omitempty"`
+	RetryUntil time.Time `json:"retry_until"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// PodAccessStatus tracks a pod whose pool permission is being retried in the background
+type PodAccessStatus struct {
+	Pod        string    `json:"pod"`
+	Target     string    `json:"target"`
+	IsGroup    bool      `json:"is_group"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error,omitempty"`
+	RetryUntil time.Time `json:"retry_until"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// PodRepairResult summarizes a pod repair
+type PodRepairResult struct {
+	Pod      string   `json:"pod"`
+	Recloned []int    `json:"recloned"`
+	Errors   []string `json:"errors"`
+}
+
+// ResourceRequirement is the estimated resources a deployment consumes
+type ResourceRequirement struct {
+	VCPUs       int   `json:"vcpus"`
+	MemoryBytes int64 `json:"memory_bytes"`
+	DiskBytes   int64 `json:"disk_bytes"`
+}
+
+// ResourceAvailability is the free capacity of the cluster
+type ResourceAvailability struct {
+	MemoryBytes  int64   `json:"memory_bytes"`
+	StorageBytes int64   `json:"storage_bytes"`
+	CPUUsage     float64 `json:"cpu_usage"`
+}
+
+// ClonePlan is the feasibility report of a dry-run clone request
+type ClonePlan struct {
+	Template     string               `json:"template"`
+	CloneMode    string               `json:"clone_mode"`
+	Storage      string               `json:"storage,omitempty"`
+	HAGroup      string               `json:"ha_group,omitempty"`
+	VMsPerTarget int                  `json:"vms_per_target"`
+	PerTarget    ResourceRequirement  `json:"per_target"`
+	Targets      []CloneTarget        `json:"targets"`
+	Required     ResourceRequirement  `json:"required"`
+	Available    ResourceAvailability `json:"available"`
+	Feasible     bool                 `json:"feasible"`
+	Problems     []string             `json:"problems"`
+	Warnings     []string             `json:"warnings,omitempty"`
+}
+
+// TemplateDeployment records the outcome of one clone request of a template
+type TemplateDeployment struct {
+	Template    string
+	Targets     int
+	Status      string
+	DurationMS  int64
+	Error       string
+	RequestedBy string
+}
+
+// TemplateStats summarizes how a template has been deployed
+type TemplateStats struct {
+	Template       string     `json:"template"`
+	Deployments    int        `json:"deployments"`   // Clone requests, each may deploy several pods
+	PodsDeployed   int        `json:"pods_deployed"` // Pods created by successful deployments
+	Failures       int        `json:"failures"`
+	Cancellations  int        `json:"cancellations"`
+	AvgCloneMS     int64      `json:"avg_clone_ms"` // Average duration of successful deployments
+	ActivePods     int        `json:"active_pods"`
+	LastDeployedAt *time.Time `json:"last_deployed_at,omitempty"`
+}
+
+// TemplateStatsSummary is the statistics of every template with totals for dashboards
+type TemplateStatsSummary struct {
+	Templates    []TemplateStats `json:"templates"`
+	Deployments  int             `json:"deployments"`
+	PodsDeployed int             `json:"pods_deployed"`
+	Failures     int             `json:"failures"`
+	ActivePods   int             `json:"active_pods"`
+	AvgCloneMS   int64           `json:"avg_clone_ms"`
+}
+
+// TemplateReconciliation reports where the templates table, the template pools in
+// Proxmox, and the uploaded images disagree
+type TemplateReconciliation struct {
+	OrphanedTemplates []string  `json:"orphaned_templates"` // Published templates without a pool
+	UnpublishedPools  []string  `json:"unpublished_pools"`  // Template pools without a published template
+	EmptyPools        []string  `json:"empty_pools"`
+	OrphanedImages    []string  `json:"orphaned_images"` // Uploaded files no template references
+	Clean             bool      `json:"clean"`
+	CheckedAt         time.Time `json:"checked_at"`
+}
+
+// TemplateValidation is the preflight report of a template pool
+type TemplateValidation struct {
+	Template string   `json:"template"`
+	VMCount  int      `json:"vm_count"`
+	Router   string   `json:"router"`
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DeploymentLimitError is returned when a clone request is blocked by a deployment rule
+type DeploymentLimitError struct {
+	Target      string
+	Reason      string
+	NextAllowed time.Time // Set when the rule lifts at a known time, such as a deployment blackout
+}
+
+func (e *DeploymentLimitError) Error() string {
+	return fmt.Sprintf("deployment not allowed for %s: %s", e.Target, e.Reason)
+}
+
 type ProgressMessage struct {
 	Message  string `json:"message"`
 	Progress int    `json:"progress"`
+	JobID    string `json:"job_id,omitempty"`
+}
+
+// CloneBenchmark records the timing of one benchmark clone of a template
+type CloneBenchmark struct {
+	ID         int64            `json:"id"`
+	Label      string           `json:"label"`
+	Template   string           `json:"template"`
+	NumTargets int              `json:"num_targets"`
+	DurationMS int64            `json:"duration_ms"`
+	Stages     map[string]int64 `json:"stages"` // Milliseconds from the start of the clone to each progress message
+	Status     string           `json:"status"`
+	Error      string           `json:"error,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// BenchmarkComparison compares benchmarks against the recent history of the same template
+type BenchmarkComparison struct {
+	MeanMS     int64   `json:"mean_ms"`
+	BaselineMS int64   `json:"baseline_ms"`
+	Samples    int     `json:"samples"`
+	Change     float64 `json:"change"` // Fractional change from the baseline, 0.1 is 10% slower
+	Regression bool    `json:"regression"`
+}
+
+// PodFileCheck names a file inside a pod VM whose hash is compared between pods
+type PodFileCheck struct {
+	VMName string `json:"vm_name" binding:"required,min=1,max=255"`
+	Path   string `json:"path" binding:"required,min=1,max=1024"`
+}
+
+// PodHealth summarizes whether a pod's VMs are running, reachable, and on the right network
+type PodHealth struct {
+	Pod     string        `json:"pod"`
+	Healthy bool          `json:"healthy"`
+	VNet    string        `json:"vnet"`
+	VMs     []VMHealth    `json:"vms"`
+	Router  *RouterHealth `json:"router,omitempty"`
+}
+
+// VMHealth is the health of a single VM in a pod
+type VMHealth struct {
+	Name           string   `json:"name"`
+	VMID           int      `json:"vmid"`
+	Status         string   `json:"status"`
+	AgentReachable bool     `json:"agent_reachable"`
+	Bridge         string   `json:"bridge"`
+	VNetBound      bool     `json:"vnet_bound"`
+	Problems       []string `json:"problems"`
+}
+
+// RouterHealth reports whether the pod router has the WAN address assigned to the pod
+type RouterHealth struct {
+	VMID          int      `json:"vmid"`
+	ExpectedWANIP string   `json:"expected_wan_ip"`
+	Addresses     []string `json:"addresses"`
+	WANIPVerified bool     `json:"wan_ip_verified"`
+}
+
+// PodRouterReport is the state of a pod's router and of its configuration
+type PodRouterReport struct {
+	Pod            string           `json:"pod"`
+	Name           string           `json:"name"`
+	Node           string           `json:"node"`
+	VMID           int              `json:"vmid"`
+	RouterType     string           `json:"router_type"`
+	Status         string           `json:"status"`
+	AgentReachable bool             `json:"agent_reachable"`
+	ExpectedWANIP  string           `json:"expected_wan_ip"`
+	Addresses      []string         `json:"addresses"`
+	WANIPVerified  bool             `json:"wan_ip_verified"`
+	NATStatus      string           `json:"nat_status"` // ok, missing, or unknown when the rules can't be read
+	NATError       string           `json:"nat_error,omitempty"`
+	Configuration  *PodRouterStatus `json:"configuration,omitempty"` // Set while or after configuration was retried in the background
+}
+
+// PodComparison reports the differences between two pods deployed from the same template
+type PodComparison struct {
+	PodA      string         `json:"pod_a"`
+	PodB      string         `json:"pod_b"`
+	Identical bool           `json:"identical"`
+	VMs       []VMComparison `json:"vms"`
+}
+
+// VMComparison pairs the VMs with the same name in both pods
+type VMComparison struct {
+	Name         string          `json:"name"`
+	VMIDA        int             `json:"vmid_a,omitempty"`
+	VMIDB        int             `json:"vmid_b,omitempty"`
+	ConfigDigest [2]string       `json:"config_digest"`
+	Differences  []PodDifference `json:"differences"`
+}
+
+// PodDifference is a single value that differs between the two pods
+type PodDifference struct {
+	Category string `json:"category"` // presence, power, config, snapshots or file
+	Field    string `json:"field,omitempty"`
+	A        string `json:"a"`
+	B        string `json:"b"`
+}
+
+// SubnetAllocation is the WAN subnet recorded for a pod. The pod router takes the first
+// address of the subnet and the pod VIPs the rest of it.
+type SubnetAllocation struct {
+	Pod         string    `json:"pod"`
+	PodNumber   int       `json:"pod_number"`
+	Subnet      string    `json:"subnet"`
+	WANIP       string    `json:"wan_ip"`
+	AllocatedAt time.Time `json:"allocated_at"`
+}
+
+// NetworkDrift is a VM NIC found attached to a bridge other than the pod VNet
+type NetworkDrift struct {
+	Name     string `json:"name"`
+	VMID     int    `json:"vmid"`
+	NIC      string `json:"nic"`
+	Bridge   string `json:"bridge"` // Bridge the NIC was attached to, empty when the NIC is missing
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NetworkRepairResult lists the VM NICs of a pod that drifted from the pod VNet
+type NetworkRepairResult struct {
+	Pod     string         `json:"pod"`
+	VNet    string         `json:"vnet"`
+	DryRun  bool           `json:"dry_run"`
+	Checked int            `json:"checked"`
+	Drift   []NetworkDrift `json:"drift"`
+}
+
+// VNetSettings decides which SDN VNet, and so which VLAN, each pod number is attached to
+type VNetSettings struct {
+	VNetPrefix string    `json:"vnet_prefix"`
+	Zone       string    `json:"zone"`
+	VLANBase   int       `json:"vlan_base"`
+	UpdatedBy  string    `json:"updated_by,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at,omitzero"`
+}
+
+// VLANReservation is a range of VLAN tags kept out of pod deployments
+type VLANReservation struct {
+	ID        int64     `json:"id"`
+	FirstTag  int       `json:"first_tag"`
+	LastTag   int       `json:"last_tag"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VNetAllocation records the VNet a pod was attached to at deployment
+type VNetAllocation struct {
+	Pod         string    `json:"pod"`
+	VNet        string    `json:"vnet"`
+	VLANTag     int       `json:"vlan_tag"`
+	AllocatedAt time.Time `json:"allocated_at"`
+}
+
+// NetworkSegment is an internal network of a template besides the pod VNet
+type NetworkSegment struct {
+	Alias  string `json:"alias" yaml:"alias" binding:"required,min=1,max=20,alphanum"`         // Such as dmz
+	Bridge string `json:"bridge" yaml:"bridge" binding:"required,max=20"`                      // Bridge the template's NICs on the segment use
+	Subnet string `json:"subnet,omitempty" yaml:"subnet,omitempty" binding:"omitempty,cidrv4"` // The router takes its first address, empty leaves the router off the segment
+}
+
+// PodSegment records the VNet created for a network segment of a pod
+type PodSegment struct {
+	Pod       string    `json:"pod"`
+	Alias     string    `json:"alias"`
+	VNet      string    `json:"vnet"`
+	VLANTag   int       `json:"vlan_tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PodRateLimit is a rate limit an admin set on a pod in place of its template's
+type PodRateLimit struct {
+	Pod       string    `json:"pod"`
+	Rate      float64   `json:"rate"` // MB/s, zero is unlimited
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RemoteAccess is a Guacamole connection to a VM of a template. The VM is reached on the
+// pod's WAN subnet, where the router maps the last octet of its LAN address.
+type RemoteAccess struct {
+	Name     string `json:"name" yaml:"name" binding:"required,min=1,max=100"`
+	Protocol string `json:"protocol" yaml:"protocol" binding:"required,oneof=rdp ssh vnc"`
+	Host     int    `json:"host" yaml:"host" binding:"required,min=2,max=254"`        // Last octet of the VM's address
+	Port     int    `json:"port,omitempty" yaml:"port,omitempty" binding:"max=65535"` // Zero uses the protocol's default port
+	Username string `json:"username,omitempty" yaml:"username,omitempty" binding:"omitempty,max=100"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty" binding:"omitempty,max=100"`
+}
+
+// PodGuacamoleConnection records a Guacamole connection created for a pod
+type PodGuacamoleConnection struct {
+	Pod          string    `json:"pod"`
+	ConnectionID string    `json:"connection_id"`
+	Name         string    `json:"name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// VLANUsage is a pod VNet defined in the SDN and the pod using it
+type VLANUsage struct {
+	VNet      string `json:"vnet"`
+	Zone      string `json:"zone"`
+	Tag       int    `json:"tag"`
+	PodNumber int    `json:"pod_number"`
+	Pod       string `json:"pod,omitempty"`
+	Reserved  bool   `json:"reserved"`
+	Conflict  string `json:"conflict,omitempty"`
+}
+
+// VLANReport is the utilization of the pod VNets
+type VLANReport struct {
+	Settings     VNetSettings      `json:"settings"`
+	VNets        []VLANUsage       `json:"vnets"`
+	Reservations []VLANReservation `json:"reservations"`
+	Used         int               `json:"used"`
+	Reserved     int               `json:"reserved"`
+	Free         int               `json:"free"`
+}
+
+// IPAMReport lists the subnet allocations and the conflicts found among them
+type IPAMReport struct {
+	Allocations []SubnetAllocation `json:"allocations"`
+	Reserved    []string           `json:"reserved"` // Ranges kept out of pod allocations
+	Conflicts   []string           `json:"conflicts"`
+}
+
+// PodFirewallRule is a Proxmox firewall rule applied to every VM of a pod
+type PodFirewallRule struct {
+	ID        int64     `json:"id"`
+	Pod       string    `json:"pod"`
+	Direction string    `json:"direction"` // in or out
+	Action    string    `json:"action"`    // ACCEPT, DROP or REJECT
+	Protocol  string    `json:"protocol,omitempty"`
+	Port      string    `json:"port,omitempty"`   // Destination port or range such as 3389 or 8000:8080
+	Source    string    `json:"source,omitempty"` // Source address or CIDR, empty matches any
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VPNConfig is a WireGuard peer giving a user access to a pod's WAN subnet
+type VPNConfig struct {
+	ID         int64     `json:"id"`
+	Pod        string    `json:"pod"`
+	Username   string    `json:"username"`
+	Address    string    `json:"address"` // Client address in the WireGuard client subnet
+	PublicKey  string    `json:"public_key"`
+	ConfigFile string    `json:"config_file,omitempty"` // WireGuard config with the private key, only returned on creation
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PodPortForward forwards a port of a pod's WAN address to a VM inside the pod
+type PodPortForward struct {
+	ID           int64     `json:"id"`
+	Pod          string    `json:"pod"`
+	Protocol     string    `json:"protocol"` // tcp or udp
+	ExternalPort int       `json:"external_port"`
+	InternalIP   string    `json:"internal_ip"`
+	InternalPort int       `json:"internal_port"`
+	Description  string    `json:"description,omitempty"`
+	Slot         int       `json:"-"` // Numbers the forward among those of its pod, routers derive rule numbers from it
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,131 @@
+package cloning
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// diskKeyPattern matches the config keys of guest disks, qemu drives as well as lxc
+// root and mount point volumes
+var diskKeyPattern = regexp.MustCompile(`^(ide|sata|scsi|virtio|mp)\d+$|^(efidisk0|tpmstate0|rootfs)$`)
+
+// ValidateTemplate runs the checks a template pool must pass to clone cleanly: the pool
+// exists and contains VMs, it has at most one router, every qemu VM has the guest agent
+// enabled, and every disk is on storage available to the VM's node. It only reads from
+// Proxmox and works on published and unpublished templates alike.
+func (cs *CloningService) ValidateTemplate(templateName string) (*TemplateValidation, error) {
+	validation := &TemplateValidation{
+		Template: templateName,
+		Problems: []string{},
+	}
+
+	// 1. The pool must exist and contain VMs
+	templatePool, err := cs.ProxmoxService.GetPoolVMs("kamino_template_" + templateName)
+	if err != nil {
+		validation.Problems = append(validation.Problems, fmt.Sprintf("template pool kamino_template_%s not found: %v", templateName, err))
+		return validation, nil
+	}
+
+	var routers []string
+	for _, vm := range templatePool {
+		if routerPattern.MatchString(vm.Name) {
+			routers = append(routers, vm.Name)
+		} else {
+			validation.VMCount++
+		}
+	}
+	if validation.VMCount == 0 {
+		validation.Problems = append(validation.Problems, "template pool contains no VMs")
+	}
+
+	// 2. One router is cloned with the pod, without one the default router is used
+	switch len(routers) {
+	case 0:
+		router, err := cs.findDefaultRouter()
+		if err != nil {
+			validation.Problems = append(validation.Problems, fmt.Sprintf("template has no router and the default router is unavailable: %v", err))
+		} else {
+			validation.Router = router.Name
+			validation.Warnings = append(validation.Warnings, fmt.Sprintf("template has no router, the default router %s will be used", router.Name))
+		}
+	case 1:
+		validation.Router = routers[0]
+	default:
+		validation.Problems = append(validation.Problems, fmt.Sprintf("template has %d routers, expected one: %s", len(routers), strings.Join(routers, ", ")))
+	}
+
+	// 3. Every VM needs the guest agent and its disks on usable storage
+	storages, err := cs.ProxmoxService.GetClusterResources("type=storage")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster storage: %w", err)
+	}
+
+	for _, vm := range templatePool {
+		config, err := cs.ProxmoxService.GetVMConfigValues(vm.NodeName, vm.VmId)
+		if err != nil {
+			validation.Problems = append(validation.Problems, fmt.Sprintf("failed to get config of VM %s: %v", vm.Name, err))
+			continue
+		}
+
+		if vm.Type == proxmox.GuestTypeQEMU && !agentEnabled(config["agent"]) {
+			validation.Problems = append(validation.Problems, fmt.Sprintf("VM %s does not have the qemu guest agent enabled", vm.Name))
+		}
+
+		for key, value := range config {
+			setting, ok := value.(string)
+			if !ok || !diskKeyPattern.MatchString(key) || strings.Contains(setting, "media=cdrom") {
+				continue
+			}
+
+			storage := diskStorage(setting)
+			if storage == "" {
+				continue
+			}
+			if !slices.ContainsFunc(storages, func(s proxmox.VirtualResource) bool {
+				return s.Storage == storage && s.NodeName == vm.NodeName && s.RunningStatus == "available"
+			}) {
+				validation.Problems = append(validation.Problems, fmt.Sprintf("disk %s of VM %s is on storage %s, which is not available on node %s", key, vm.Name, storage, vm.NodeName))
+			}
+		}
+	}
+
+	validation.Valid = len(validation.Problems) == 0
+	return validation, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// agentEnabled reports whether a qemu agent setting such as 1 or enabled=1,fstrim_cloned_disks=1
+// turns the agent on
+func agentEnabled(value any) bool {
+	if value == nil {
+		return false
+	}
+
+	for _, option := range strings.Split(fmt.Sprint(value), ",") {
+		option = strings.TrimPrefix(option, "enabled=")
+		if !strings.Contains(option, "=") {
+			return option == "1"
+		}
+	}
+
+	return false
+}
+
+// diskStorage extracts the storage from a disk setting such as local-lvm:vm-100-disk-0,size=32G.
+// Disks without a storage prefix, like passed through devices, return an empty string.
+func diskStorage(setting string) string {
+	volume, _, _ := strings.Cut(setting, ",")
+	storage, _, found := strings.Cut(volume, ":")
+	if !found || strings.HasPrefix(volume, "/") {
+		return ""
+	}
+
+	return storage
+}
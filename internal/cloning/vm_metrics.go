@@ -0,0 +1,19 @@
+package cloning
+
+import (
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// Pod VM Metrics Operations
+// =================================================
+
+// GetPodVMMetrics returns the usage history of a VM of the pod over a timeframe
+func (cs *CloningService) GetPodVMMetrics(pod string, vmID int, timeframe string) ([]proxmox.VMMetricPoint, error) {
+	vm, err := cs.podVM(pod, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.ProxmoxService.GetVMMetrics(vm, timeframe)
+}
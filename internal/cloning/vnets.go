@@ -0,0 +1,346 @@
+package cloning
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/proxmox"
+)
+
+// =================================================
+// VNet Database Operations
+// =================================================
+
+// GetVNetSettings returns the VNet settings saved by an admin, or nil when none were saved
+func (c *TemplateClient) GetVNetSettings() (*VNetSettings, error) {
+	query := "SELECT vnet_prefix, zone, vlan_base, updated_by, updated_at FROM vnet_settings WHERE id = 1"
+	var settings VNetSettings
+	err := c.DB.QueryRow(query).Scan(&settings.VNetPrefix, &settings.Zone, &settings.VLANBase, &settings.UpdatedBy, &settings.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (c *TemplateClient) SaveVNetSettings(settings VNetSettings) error {
+	query := `INSERT INTO vnet_settings (id, vnet_prefix, zone, vlan_base, updated_by) VALUES (1, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE vnet_prefix = VALUES(vnet_prefix), zone = VALUES(zone), vlan_base = VALUES(vlan_base), updated_by = VALUES(updated_by), updated_at = UTC_TIMESTAMP()`
+
+	_, err := c.DB.Exec(query, settings.VNetPrefix, settings.Zone, settings.VLANBase, settings.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) InsertVLANReservation(reservation VLANReservation) (int64, error) {
+	query := "INSERT INTO vlan_reservations (first_tag, last_tag, reason, created_by) VALUES (?, ?, ?, ?)"
+
+	result, err := c.DB.Exec(query, reservation.FirstTag, reservation.LastTag, reservation.Reason, reservation.CreatedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (c *TemplateClient) GetVLANReservations() ([]VLANReservation, error) {
+	rows, err := c.DB.Query("SELECT id, first_tag, last_tag, reason, created_by, created_at FROM vlan_reservations ORDER BY first_tag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildVLANReservations(rows)
+}
+
+func (c *TemplateClient) DeleteVLANReservation(id int64) error {
+	result, err := c.DB.Exec("DELETE FROM vlan_reservations WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("VLAN reservation %d not found", id)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) SaveVNetAllocation(allocation VNetAllocation) error {
+	query := `INSERT INTO pod_vnets (pod, vnet, vlan_tag) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE vnet = VALUES(vnet), vlan_tag = VALUES(vlan_tag), allocated_at = UTC_TIMESTAMP()`
+
+	_, err := c.DB.Exec(query, allocation.Pod, allocation.VNet, allocation.VLANTag)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) GetVNetAllocations() ([]VNetAllocation, error) {
+	rows, err := c.DB.Query("SELECT pod, vnet, vlan_tag, allocated_at FROM pod_vnets ORDER BY vlan_tag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildVNetAllocations(rows)
+}
+
+func (c *TemplateClient) RenameVNetAllocation(pod string, newPod string) error {
+	_, err := c.DB.Exec("UPDATE pod_vnets SET pod = ? WHERE pod = ?", newPod, pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) DeleteVNetAllocation(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM pod_vnets WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// VNet Operations
+// =================================================
+
+// GetVNetSettings returns the VNet prefix, zone, and VLAN base pods are deployed with,
+// falling back to the environment configuration until an admin saves settings
+func (cs *CloningService) GetVNetSettings() (*VNetSettings, error) {
+	settings, err := cs.DatabaseService.GetVNetSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &VNetSettings{VNetPrefix: cs.Config.VNetPrefix, Zone: cs.Config.VNetZone, VLANBase: cs.Config.VLANBase}
+	}
+
+	return settings, nil
+}
+
+// SetVNetSettings changes the VNets new pods are attached to. Deployed pods keep the VNet
+// recorded in their allocation.
+func (cs *CloningService) SetVNetSettings(settings VNetSettings) error {
+	if settings.VLANBase < 0 || settings.VLANBase+cs.Config.MaxPodID-1000 > 4094 {
+		return fmt.Errorf("VLAN base %d leaves pod VLANs outside 1-4094", settings.VLANBase)
+	}
+
+	return cs.DatabaseService.SaveVNetSettings(settings)
+}
+
+// AddVLANReservation keeps a range of VLAN tags out of pod deployments
+func (cs *CloningService) AddVLANReservation(reservation VLANReservation) (*VLANReservation, error) {
+	if reservation.FirstTag > reservation.LastTag {
+		return nil, fmt.Errorf("first tag %d is greater than last tag %d", reservation.FirstTag, reservation.LastTag)
+	}
+
+	var err error
+	reservation.ID, err = cs.DatabaseService.InsertVLANReservation(reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// DeleteVLANReservation releases a reserved range of VLAN tags
+func (cs *CloningService) DeleteVLANReservation(id int64) error {
+	return cs.DatabaseService.DeleteVLANReservation(id)
+}
+
+// GetVLANReport lists every pod VNet defined in the SDN with its VLAN tag, the pod it is
+// allocated to, and whether it is reserved or misconfigured
+func (cs *CloningService) GetVLANReport() (*VLANReport, error) {
+	settings, err := cs.GetVNetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	vnets, err := cs.ProxmoxService.GetUsedVNets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VNets: %w", err)
+	}
+
+	allocations, err := cs.DatabaseService.GetVNetAllocations()
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := cs.DatabaseService.GetVLANReservations()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VLANReport{
+		Settings:     *settings,
+		VNets:        []VLANUsage{},
+		Reservations: reservations,
+	}
+	if report.Reservations == nil {
+		report.Reservations = []VLANReservation{}
+	}
+
+	for _, vnet := range vnets {
+		podNumber, ok := settings.podNumber(vnet)
+		if !ok {
+			continue
+		}
+
+		usage := VLANUsage{VNet: vnet.Name, Zone: vnet.Zone, Tag: vnet.Tag, PodNumber: podNumber}
+		if i := slices.IndexFunc(allocations, func(a VNetAllocation) bool { return a.VNet == vnet.Name }); i >= 0 {
+			usage.Pod = allocations[i].Pod
+		}
+		usage.Reserved = reservedVLAN(reservations, vnet.Tag) != nil
+		if expected := settings.VLANBase + podNumber; settings.VLANBase != 0 && vnet.Tag != expected {
+			usage.Conflict = fmt.Sprintf("tag %d does not match the expected tag %d", vnet.Tag, expected)
+		}
+
+		switch {
+		case usage.Pod != "":
+			report.Used++
+		case usage.Reserved:
+			report.Reserved++
+		default:
+			report.Free++
+		}
+		report.VNets = append(report.VNets, usage)
+	}
+
+	return report, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// allocatePodVNets records the VNet of every target, refusing the deployment when the VNet
+// is missing from the SDN or its VLAN is reserved
+func (cs *CloningService) allocatePodVNets(targets []CloneTarget) error {
+	settings, err := cs.GetVNetSettings()
+	if err != nil {
+		return err
+	}
+
+	vnets, err := cs.ProxmoxService.GetUsedVNets()
+	if err != nil {
+		return fmt.Errorf("failed to get VNets: %w", err)
+	}
+
+	reservations, err := cs.DatabaseService.GetVLANReservations()
+	if err != nil {
+		return err
+	}
+
+	var requested []VNetAllocation
+	for _, target := range targets {
+		name := settings.vnetName(target.PodNumber)
+		i := slices.IndexFunc(vnets, func(vnet proxmox.VNet) bool {
+			return vnet.Name == name && (settings.Zone == "" || vnet.Zone == settings.Zone)
+		})
+		if i < 0 {
+			return fmt.Errorf("VNet %s for %s does not exist", name, target.PoolName)
+		}
+		if reservation := reservedVLAN(reservations, vnets[i].Tag); reservation != nil {
+			return fmt.Errorf("VLAN %d of VNet %s is reserved: %s", vnets[i].Tag, name, reservation.Reason)
+		}
+
+		requested = append(requested, VNetAllocation{Pod: target.PoolName, VNet: name, VLANTag: vnets[i].Tag})
+	}
+
+	for _, allocation := range requested {
+		if err := cs.DatabaseService.SaveVNetAllocation(allocation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// podVNet returns the VNet a pod was allocated, or the VNet the current settings give its
+// pod number for pods deployed before allocations were recorded
+func (cs *CloningService) podVNet(pod string, podNumber int) (string, error) {
+	allocations, err := cs.DatabaseService.GetVNetAllocations()
+	if err != nil {
+		return "", err
+	}
+	if i := slices.IndexFunc(allocations, func(a VNetAllocation) bool { return a.Pod == pod }); i >= 0 {
+		return allocations[i].VNet, nil
+	}
+
+	settings, err := cs.GetVNetSettings()
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("Pod %s has no VNet allocation, using %s", pod, settings.vnetName(podNumber))
+	return settings.vnetName(podNumber), nil
+}
+
+func (s VNetSettings) vnetName(podNumber int) string {
+	return fmt.Sprintf("%s%d", s.VNetPrefix, podNumber)
+}
+
+// podNumber recovers the pod number of a pod VNet, reporting false for other VNets
+func (s VNetSettings) podNumber(vnet proxmox.VNet) (int, bool) {
+	if !strings.HasPrefix(vnet.Name, s.VNetPrefix) || (s.Zone != "" && vnet.Zone != s.Zone) {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(strings.TrimPrefix(vnet.Name, s.VNetPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+func reservedVLAN(reservations []VLANReservation, tag int) *VLANReservation {
+	for i := range reservations {
+		if tag >= reservations[i].FirstTag && tag <= reservations[i].LastTag {
+			return &reservations[i]
+		}
+	}
+	return nil
+}
+
+func buildVLANReservations(rows *sql.Rows) ([]VLANReservation, error) {
+	var reservations []VLANReservation
+	for rows.Next() {
+		var reservation VLANReservation
+		if err := rows.Scan(&reservation.ID, &reservation.FirstTag, &reservation.LastTag, &reservation.Reason, &reservation.CreatedBy, &reservation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+func buildVNetAllocations(rows *sql.Rows) ([]VNetAllocation, error) {
+	var allocations []VNetAllocation
+	for rows.Next() {
+		var allocation VNetAllocation
+		if err := rows.Scan(&allocation.Pod, &allocation.VNet, &allocation.VLANTag, &allocation.AllocatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations, nil
+}
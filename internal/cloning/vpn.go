@@ -0,0 +1,262 @@
+package cloning
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+)
+
+// =================================================
+// VPN Database Operations
+// =================================================
+
+func (c *TemplateClient) InsertVPNConfig(config VPNConfig) (int64, error) {
+	query := `INSERT INTO pod_vpn_configs (pod, username, address, public_key) VALUES (?, ?, ?, ?)`
+
+	result, err := c.DB.Exec(query, config.Pod, config.Username, config.Address, config.PublicKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (c *TemplateClient) GetVPNConfigs(pod string) ([]VPNConfig, error) {
+	query := `SELECT id, pod, username, address, public_key, created_at
+		FROM pod_vpn_configs WHERE pod = ? ORDER BY id`
+
+	rows, err := c.DB.Query(query, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	return buildVPNConfigs(rows)
+}
+
+func (c *TemplateClient) GetVPNAddresses() ([]string, error) {
+	rows, err := c.DB.Query("SELECT address FROM pod_vpn_configs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var addresses []string
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
+func (c *TemplateClient) DeleteVPNConfig(pod string, id int64) error {
+	_, err := c.DB.Exec("DELETE FROM pod_vpn_configs WHERE pod = ? AND id = ?", pod, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// VPN Operations
+// =================================================
+
+// GetPodVPNConfigs returns the VPN configs the user created for a pod
+func (cs *CloningService) GetPodVPNConfigs(pod string, username string) ([]VPNConfig, error) {
+	configs, err := cs.DatabaseService.GetVPNConfigs(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	userConfigs := []VPNConfig{}
+	for _, config := range configs {
+		if strings.EqualFold(config.Username, username) {
+			userConfigs = append(userConfigs, config)
+		}
+	}
+
+	return userConfigs, nil
+}
+
+// CreatePodVPNConfig generates a WireGuard key pair and address for the user and adds the
+// peer to the gateway, allowing it to reach the pod's WAN subnet. Only the public key is
+// stored, the config file holding the private key is returned once and can't be fetched
+// again, a lost config is revoked and created anew.
+func (cs *CloningService) CreatePodVPNConfig(pod string, username string) (*VPNConfig, error) {
+	if cs.Config.WireGuardGatewayNode == "" {
+		return nil, fmt.Errorf("VPN access is not configured")
+	}
+
+	configs, err := cs.GetPodVPNConfigs(pod, username)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) > 0 {
+		return nil, fmt.Errorf("user %s already has VPN config %d for pod %s", username, configs[0].ID, pod)
+	}
+
+	podNumber, err := podNumberFromPool(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate WireGuard key: %w", err)
+	}
+
+	address, err := cs.nextVPNAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	config := VPNConfig{
+		Pod:       pod,
+		Username:  username,
+		Address:   address,
+		PublicKey: base64.StdEncoding.EncodeToString(privateKey.PublicKey().Bytes()),
+	}
+	config.ID, err = cs.DatabaseService.InsertVPNConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// The peer script is waited on so a failed add isn't handed out as a working config
+	command := []string{cs.Config.WireGuardPeerScript, "add", config.PublicKey, config.Address, cs.ProxmoxService.PodWANSubnet(podNumber)}
+	if _, err := cs.ProxmoxService.AgentExecOutput(cs.Config.WireGuardGatewayNode, cs.Config.WireGuardGatewayVMID, command); err != nil {
+		// The script may have added the peer before failing or timing out
+		removeCommand := []string{cs.Config.WireGuardPeerScript, "remove", config.PublicKey, config.Address}
+		if _, err := cs.ProxmoxService.AgentExecOutput(cs.Config.WireGuardGatewayNode, cs.Config.WireGuardGatewayVMID, removeCommand); err != nil {
+			log.Printf("Failed to remove unapplied VPN peer %s of pod %s: %v", config.Address, pod, err)
+		}
+		if err := cs.DatabaseService.DeleteVPNConfig(pod, config.ID); err != nil {
+			log.Printf("Failed to delete unapplied VPN config %d of pod %s: %v", config.ID, pod, err)
+		}
+		return nil, fmt.Errorf("failed to add VPN peer to gateway: %w", err)
+	}
+
+	config.ConfigFile = cs.renderVPNConfigFile(config, base64.StdEncoding.EncodeToString(privateKey.Bytes()), podNumber)
+	return &config, nil
+}
+
+// RevokePodVPNConfig removes one of the user's VPN peers from the gateway
+func (cs *CloningService) RevokePodVPNConfig(pod string, username string, id int64) error {
+	config, err := cs.findVPNConfig(pod, username, id)
+	if err != nil {
+		return err
+	}
+
+	return cs.revokeVPNConfig(*config)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// renderVPNConfigFile renders the WireGuard config file of a VPN config
+func (cs *CloningService) renderVPNConfigFile(config VPNConfig, privateKey string, podNumber int) string {
+	var file strings.Builder
+	file.WriteString("[Interface]\n")
+	fmt.Fprintf(&file, "PrivateKey = %s\n", privateKey)
+	fmt.Fprintf(&file, "Address = %s/32\n", config.Address)
+	file.WriteString("\n[Peer]\n")
+	fmt.Fprintf(&file, "PublicKey = %s\n", cs.Config.WireGuardPublicKey)
+	fmt.Fprintf(&file, "Endpoint = %s\n", cs.Config.WireGuardEndpoint)
+	fmt.Fprintf(&file, "AllowedIPs = %s\n", cs.ProxmoxService.PodWANSubnet(podNumber))
+	file.WriteString("PersistentKeepalive = 25\n")
+
+	return file.String()
+}
+
+func (cs *CloningService) findVPNConfig(pod string, username string, id int64) (*VPNConfig, error) {
+	configs, err := cs.DatabaseService.GetVPNConfigs(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, config := range configs {
+		if config.ID == id && strings.EqualFold(config.Username, username) {
+			return &config, nil
+		}
+	}
+
+	return nil, fmt.Errorf("VPN config %d not found for user %s on pod %s", id, username, pod)
+}
+
+// revokeVPNConfig removes the peer from the gateway, the record is only deleted once the
+// peer script succeeded so a peer still active on the gateway stays listed
+func (cs *CloningService) revokeVPNConfig(config VPNConfig) error {
+	command := []string{cs.Config.WireGuardPeerScript, "remove", config.PublicKey, config.Address}
+	if _, err := cs.ProxmoxService.AgentExecOutput(cs.Config.WireGuardGatewayNode, cs.Config.WireGuardGatewayVMID, command); err != nil {
+		return fmt.Errorf("failed to remove VPN peer from gateway: %w", err)
+	}
+
+	return cs.DatabaseService.DeleteVPNConfig(config.Pod, config.ID)
+}
+
+// revokePodVPNConfigs removes every VPN peer of a pod, used when the pod is deleted or
+// changes hands so old configs stop working
+func (cs *CloningService) revokePodVPNConfigs(pod string) {
+	configs, err := cs.DatabaseService.GetVPNConfigs(pod)
+	if err != nil {
+		log.Printf("Failed to get VPN configs of pod %s: %v", pod, err)
+		return
+	}
+
+	for _, config := range configs {
+		if err := cs.revokeVPNConfig(config); err != nil {
+			log.Printf("Failed to revoke VPN config %d of pod %s: %v", config.ID, pod, err)
+		}
+	}
+}
+
+// nextVPNAddress returns the lowest address of the client subnet not held by a config. The
+// first address is left to the gateway.
+func (cs *CloningService) nextVPNAddress() (string, error) {
+	subnet, err := netip.ParsePrefix(cs.Config.WireGuardClientSubnet)
+	if err != nil {
+		return "", fmt.Errorf("invalid WireGuard client subnet %q: %w", cs.Config.WireGuardClientSubnet, err)
+	}
+	subnet = subnet.Masked()
+
+	addresses, err := cs.DatabaseService.GetVPNAddresses()
+	if err != nil {
+		return "", err
+	}
+	used := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		used[address] = true
+	}
+
+	address := subnet.Addr().Next().Next()
+	for ; subnet.Contains(address); address = address.Next() {
+		if !used[address.String()] && subnet.Contains(address.Next()) {
+			return address.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no free addresses left in WireGuard client subnet %s", subnet)
+}
+
+func buildVPNConfigs(rows *sql.Rows) ([]VPNConfig, error) {
+	var configs []VPNConfig
+	for rows.Next() {
+		var config VPNConfig
+		if err := rows.Scan(&config.ID, &config.Pod, &config.Username, &config.Address, &config.PublicKey, &config.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,377 @@
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// warmPoolMaxFailures is how many replenishments of a warm pool may fail in a row before
+// the worker stops cloning for it until its size is set again
+const warmPoolMaxFailures = 3
+
+// =================================================
+// Warm Pool Database Operations
+// =================================================
+
+func (c *TemplateClient) GetWarmPoolSizes() ([]WarmPoolSize, error) {
+	rows, err := c.DB.Query("SELECT template, size FROM warm_pool_sizes ORDER BY template")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := []WarmPoolSize{}
+	for rows.Next() {
+		var size WarmPoolSize
+		if err := rows.Scan(&size.Template, &size.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// SetWarmPoolSize sets how many warm pods are kept for a template, a size of zero removes the setting
+func (c *TemplateClient) SetWarmPoolSize(size WarmPoolSize) error {
+	var err error
+	if size.Size == 0 {
+		_, err = c.DB.Exec("DELETE FROM warm_pool_sizes WHERE template = ?", size.Template)
+	} else {
+		query := "INSERT INTO warm_pool_sizes (template, size) VALUES (?, ?) ON DUPLICATE KEY UPDATE size = VALUES(size)"
+		_, err = c.DB.Exec(query, size.Template, size.Size)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TemplateClient) AddWarmPod(pod string, templateName string) error {
+	_, err := c.DB.Exec("INSERT INTO warm_pods (pod, template) VALUES (?, ?)", pod, templateName)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// GetWarmPods returns the warm pods of a template that are ready for assignment, oldest first
+func (c *TemplateClient) GetWarmPods(templateName string) ([]string, error) {
+	rows, err := c.DB.Query("SELECT pod FROM warm_pods WHERE template = ? ORDER BY created_at, pod", templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	pods := []string{}
+	for rows.Next() {
+		var pod string
+		if err := rows.Scan(&pod); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+// ClaimWarmPod removes the oldest warm pod of a template from the pool and returns it,
+// or an empty string when none are ready. Only one caller can claim a given pod.
+func (c *TemplateClient) ClaimWarmPod(templateName string) (string, error) {
+	pods, err := c.GetWarmPods(templateName)
+	if err != nil {
+		return "", err
+	}
+
+	for _, pod := range pods {
+		result, err := c.DB.Exec("DELETE FROM warm_pods WHERE pod = ?", pod)
+		if err != nil {
+			return "", fmt.Errorf("failed to execute query: %w", err)
+		}
+		if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 1 {
+			return pod, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (c *TemplateClient) DeleteWarmPod(pod string) error {
+	_, err := c.DB.Exec("DELETE FROM warm_pods WHERE pod = ?", pod)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Warm Pool Operations
+// =================================================
+
+// GetWarmPoolStatuses returns the configured size and ready pods of every warm pool
+func (cs *CloningService) GetWarmPoolStatuses() ([]WarmPoolStatus, error) {
+	sizes, err := cs.DatabaseService.GetWarmPoolSizes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warm pool sizes: %w", err)
+	}
+
+	statuses := []WarmPoolStatus{}
+	for _, size := range sizes {
+		pods, err := cs.DatabaseService.GetWarmPods(size.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get warm pods of %s: %w", size.Template, err)
+		}
+		statuses = append(statuses, WarmPoolStatus{
+			Template: size.Template,
+			Size:     size.Size,
+			Ready:    len(pods),
+			Pods:     pods,
+		})
+	}
+
+	return statuses, nil
+}
+
+// SetWarmPoolSize sets how many warm pods are kept for a published template. Shrinking a
+// pool deletes the surplus warm pods.
+func (cs *CloningService) SetWarmPoolSize(templateName string, size int) error {
+	if cs.Config.WarmPoolOwner == "" {
+		return fmt.Errorf("warm pools are disabled, WARM_POOL_OWNER is not set")
+	}
+
+	template, err := cs.DatabaseService.GetTemplateInfo(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get template info: %w", err)
+	}
+	if template.Name == "" {
+		return fmt.Errorf("template not found: %s", templateName)
+	}
+
+	if err := cs.DatabaseService.SetWarmPoolSize(WarmPoolSize{Template: templateName, Size: size}); err != nil {
+		return err
+	}
+	cs.warmPoolFailures.Delete(templateName)
+
+	pods, err := cs.DatabaseService.GetWarmPods(templateName)
+	if err != nil {
+		return fmt.Errorf("failed to get warm pods of %s: %w", templateName, err)
+	}
+	for len(pods) > size {
+		pod, err := cs.DatabaseService.ClaimWarmPod(templateName)
+		if err != nil || pod == "" {
+			break
+		}
+		if err := cs.DeletePod(pod); err != nil {
+			log.Printf("Failed to delete surplus warm pod %s: %v", pod, err)
+		}
+		pods = pods[1:]
+	}
+
+	return nil
+}
+
+// =================================================
+// Warm Pool Worker
+// =================================================
+
+// replenishWarmPools periodically clones warm pods for templates below their configured size
+func (cs *CloningService) replenishWarmPools() {
+	if cs.Config.WarmPoolOwner == "" {
+		return
+	}
+
+	ticker := time.NewTicker(cs.Config.WarmPoolInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sizes, err := cs.DatabaseService.GetWarmPoolSizes()
+		if err != nil {
+			log.Printf("Warm pool worker failed to get pool sizes: %v", err)
+			continue
+		}
+
+		for _, size := range sizes {
+			cs.replenishWarmPool(size)
+		}
+	}
+}
+
+func (cs *CloningService) replenishWarmPool(size WarmPoolSize) {
+	pods, err := cs.DatabaseService.GetWarmPods(size.Template)
+	if err != nil {
+		log.Printf("Failed to get warm pods of %s: %v", size.Template, err)
+		return
+	}
+
+	// Forget warm pods that were deleted outside of the warm pool
+	ready := 0
+	for _, pod := range pods {
+		if _, err := cs.ProxmoxService.GetPoolVMs(pod); err != nil {
+			log.Printf("Warm pod %s is no longer available, removing it from the pool: %v", pod, err)
+			if err := cs.DatabaseService.DeleteWarmPod(pod); err != nil {
+				log.Printf("Failed to delete warm pod record for pod %s: %v", pod, err)
+			}
+			continue
+		}
+		ready++
+	}
+
+	missing := size.Size - ready
+	if missing <= 0 {
+		return
+	}
+	if failures, ok := cs.warmPoolFailures.Load(size.Template); ok && failures.(int) >= warmPoolMaxFailures {
+		return
+	}
+
+	log.Printf("Cloning %d warm pods of template %s", missing, size.Template)
+
+	targets := make([]CloneTarget, missing)
+	for i := range targets {
+		targets[i] = CloneTarget{Name: cs.Config.WarmPoolOwner}
+	}
+	req := CloneRequest{
+		Template:    size.Template,
+		Targets:     targets,
+		RequestedBy: cs.Config.WarmPoolOwner,
+	}
+	if err := cs.CloneTemplate(context.Background(), req); err != nil {
+		log.Printf("Failed to clone warm pods of template %s: %v", size.Template, err)
+
+		// Pods that cloned despite the error are not tracked by the pool, so they would be
+		// cloned again on every tick
+		cs.discardWarmPods(req.Targets)
+
+		failures := 1
+		if previous, ok := cs.warmPoolFailures.Load(size.Template); ok {
+			failures += previous.(int)
+		}
+		cs.warmPoolFailures.Store(size.Template, failures)
+		if failures >= warmPoolMaxFailures {
+			log.Printf("Stopped replenishing the warm pool of %s after %d failed attempts, set its size again to resume", size.Template, failures)
+		}
+		return
+	}
+	cs.warmPoolFailures.Delete(size.Template)
+
+	// Warm pods wait stopped so they hold no node memory, CloneTemplate assigns the pool names
+	for _, target := range req.Targets {
+		cs.stopPod(target.PoolName)
+		if err := cs.DatabaseService.AddWarmPod(target.PoolName, size.Template); err != nil {
+			log.Printf("Failed to record warm pod %s: %v", target.PoolName, err)
+		}
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// assignWarmPods transfers a ready warm pod to each target that can take one and returns
+// the targets that still need to be cloned
+func (cs *CloningService) assignWarmPods(req CloneRequest) []CloneTarget {
+	if cs.Config.WarmPoolOwner == "" {
+		return req.Targets
+	}
+
+	var remaining []CloneTarget
+	assigned := 0
+	for _, target := range req.Targets {
+		pod, err := cs.DatabaseService.ClaimWarmPod(req.Template)
+		if err != nil {
+			log.Printf("Failed to claim warm pod of %s for %s: %v", req.Template, target.Name, err)
+		}
+		if pod == "" {
+			remaining = append(remaining, target)
+			continue
+		}
+
+		newPod, err := cs.TransferPod(pod, target.Name, target.IsGroup)
+		if err != nil {
+			log.Printf("Failed to assign warm pod %s to %s, cloning instead: %v", pod, target.Name, err)
+			if newPod == "" {
+				newPod = pod
+			}
+			if err := cs.DeletePod(newPod); err != nil {
+				log.Printf("Failed to delete warm pod %s after failed assignment: %v", newPod, err)
+			}
+			remaining = append(remaining, target)
+			continue
+		}
+
+		cs.startPodRouter(newPod)
+		log.Printf("Assigned warm pod %s to %s as %s", pod, target.Name, newPod)
+		assigned++
+	}
+
+	if assigned > 0 {
+		req.SSE.Send(
+			ProgressMessage{
+				Message:  fmt.Sprintf("Assigned %d pre-provisioned pods", assigned),
+				Progress: 50,
+			},
+		)
+		if err := cs.DatabaseService.AddDeployment(req.Template, assigned); err != nil {
+			log.Printf("Failed to increment template deployments for %s: %v", req.Template, err)
+		}
+	}
+
+	return remaining
+}
+
+// discardWarmPods deletes the pods created for warm pool targets of a failed clone
+func (cs *CloningService) discardWarmPods(targets []CloneTarget) {
+	for _, target := range targets {
+		if target.PoolName == "" {
+			continue
+		}
+		if _, err := cs.ProxmoxService.GetPoolVMs(target.PoolName); err != nil {
+			continue
+		}
+		if err := cs.DeletePod(target.PoolName); err != nil {
+			log.Printf("Failed to delete warm pod %s of a failed clone: %v", target.PoolName, err)
+		}
+	}
+}
+
+// stopPod stops every running VM in the pod
+func (cs *CloningService) stopPod(pod string) {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		log.Printf("Failed to get pool VMs for %s: %v", pod, err)
+		return
+	}
+
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() || vm.RunningStatus != "running" {
+			continue
+		}
+		if err := cs.ProxmoxService.ShutdownVM(vm.NodeName, vm.VmId); err != nil {
+			log.Printf("Failed to shut down VM %s in warm pod %s: %v", vm.Name, pod, err)
+		}
+	}
+}
+
+// startPodRouter starts the router of an assigned warm pod, leaving the pod as a fresh
+// clone would be with only its router running
+func (cs *CloningService) startPodRouter(pod string) {
+	poolVMs, err := cs.ProxmoxService.GetPoolVMs(pod)
+	if err != nil {
+		log.Printf("Failed to get pool VMs for %s: %v", pod, err)
+		return
+	}
+
+	for _, vm := range poolVMs {
+		if !vm.IsGuest() || !routerPattern.MatchString(vm.Name) || vm.RunningStatus == "running" {
+			continue
+		}
+		if err := cs.ProxmoxService.StartVM(vm.NodeName, vm.VmId); err != nil {
+			log.Printf("Failed to start router %s of pod %s: %v", vm.Name, pod, err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package cloning
+
+import (
+	"log"
+)
+
+// StartBackgroundWorkers launches the long-running goroutines owned by the cloning service
+func (cs *CloningService) StartBackgroundWorkers() {
+	log.Println("Starting cloning background workers")
+
+	go cs.watchVMStates()
+	go cs.runScheduler()
+	go cs.sweepExpiredExports()
+	go cs.resumePendingRouters()
+	go cs.resumePendingAccess()
+	go cs.replenishWarmPools()
+	go cs.rebalanceOnSchedule()
+	go cs.backupOnSchedule()
+	go cs.recordCapacityUsage()
+	go cs.collectHeartbeats()
+	go cs.retireDeprecatedTemplates()
+	go cs.reconcileTemplatesOnSchedule()
+}
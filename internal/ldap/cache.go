@@ -0,0 +1,104 @@
+package ldap
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// groupCache is shared by every LDAPService so a membership change made through one
+// service is seen by the authorization checks of the others
+var groupCache = &membershipCache{entries: make(map[string]membershipEntry)}
+
+// GetGroupCacheStats returns the hit and miss counters of the group membership cache
+func GetGroupCacheStats() GroupCacheStats {
+	return groupCache.stats()
+}
+
+// InvalidateGroupCache drops every cached group membership
+func InvalidateGroupCache() {
+	groupCache.invalidateAll()
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (c *membershipCache) setTTL(ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ttl = ttl
+}
+
+func (c *membershipCache) get(userDN string) ([]string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	entry, ok := c.entries[strings.ToLower(userDN)]
+	if !ok || time.Now().After(entry.expiresAt) {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	return slices.Clone(entry.groups), true
+}
+
+func (c *membershipCache) set(userDN string, groups []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	// Expired entries are swept on write so users who never return do not accumulate
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.entries[strings.ToLower(userDN)] = membershipEntry{
+		groups:    slices.Clone(groups),
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+func (c *membershipCache) invalidate(userDN string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, strings.ToLower(userDN))
+	c.invalidations++
+}
+
+func (c *membershipCache) invalidateAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	clear(c.entries)
+	c.invalidations++
+}
+
+func (c *membershipCache) stats() GroupCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := GroupCacheStats{
+		TTLSeconds:    c.ttl.Seconds(),
+		Entries:       len(c.entries),
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Invalidations: c.invalidations,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRatio = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
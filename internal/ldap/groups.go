@@ -20,7 +20,7 @@ func (s *LDAPService) GetGroups() ([]Group, error) {
 		kaminoGroupsOU,
 		ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases, 0, 0, false,
 		"(objectClass=group)",
-		[]string{"cn", "whenCreated", "member"},
+		[]string{"cn", "whenCreated", "member", "managedBy"},
 		nil,
 	)
 
@@ -45,6 +45,13 @@ func (s *LDAPService) GetGroups() ([]Group, error) {
 			UserCount: len(entry.GetAttributeValues("member")),
 		}
 
+		// Record the delegated manager of the group if one is set
+		if managedBy := entry.GetAttributeValue("managedBy"); managedBy != "" {
+			if dn, err := ldapv3.ParseDN(managedBy); err == nil && len(dn.RDNs) > 0 && len(dn.RDNs[0].Attributes) > 0 {
+				group.Manager = dn.RDNs[0].Attributes[0].Value
+			}
+		}
+
 		// Add creation date if available and convert it
 		whenCreated := entry.GetAttributeValue("whenCreated")
 		if whenCreated != "" {
@@ -119,6 +126,8 @@ func (s *LDAPService) RenameGroup(oldGroupName string, newGroupName string) erro
 		return fmt.Errorf("failed to rename group: %v", err)
 	}
 
+	// Cached memberships hold group names, so every entry naming the old group is stale
+	groupCache.invalidateAll()
 	return nil
 }
 
@@ -147,6 +156,7 @@ func (s *LDAPService) DeleteGroup(groupName string) error {
 		return fmt.Errorf("failed to delete group: %v", err)
 	}
 
+	groupCache.invalidateAll()
 	return nil
 }
 
@@ -1,8 +1,14 @@
 package ldap
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -48,13 +54,84 @@ func (c *Client) Connect() error {
 }
 
 func (c *Client) dial() (ldap.Client, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	if strings.HasPrefix(c.config.URL, "ldaps://") {
-		return ldap.DialURL(c.config.URL, ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: c.config.SkipTLSVerify}))
+		return ldap.DialURL(c.config.URL, ldap.DialWithTLSConfig(tlsConfig))
+	} else if strings.HasPrefix(c.config.URL, "ldap://") {
+		if !c.config.StartTLS {
+			return nil, fmt.Errorf("ldap:// requires LDAP_START_TLS to be enabled: %s", c.config.URL)
+		}
+
+		conn, err := ldap.DialURL(c.config.URL)
+		if err != nil {
+			return nil, err
+		}
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %v", err)
+		}
+		return conn, nil
 	} else {
 		return nil, fmt.Errorf("unsupported LDAP URL scheme: %s", c.config.URL)
 	}
 }
 
+// tlsConfig builds the TLS configuration of the connection. A custom CA bundle is trusted
+// alongside the system pool, and a pinned fingerprint replaces chain verification so that
+// self-signed server certificates can be trusted without disabling verification.
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	serverURL, err := url.Parse(c.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL %s: %v", c.config.URL, err)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         serverURL.Hostname(),
+		InsecureSkipVerify: c.config.SkipTLSVerify,
+	}
+
+	if c.config.CAFile != "" {
+		pem, err := os.ReadFile(c.config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LDAP CA file: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in LDAP CA file %s", c.config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.config.TLSFingerprint != "" {
+		fingerprint, err := hex.DecodeString(strings.ReplaceAll(c.config.TLSFingerprint, ":", ""))
+		if err != nil || len(fingerprint) != sha256.Size {
+			return nil, fmt.Errorf("invalid LDAP TLS fingerprint, expected a SHA-256 hex digest: %s", c.config.TLSFingerprint)
+		}
+
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("LDAP server presented no certificate")
+			}
+			digest := sha256.Sum256(rawCerts[0])
+			if subtle.ConstantTimeCompare(digest[:], fingerprint) != 1 {
+				return fmt.Errorf("LDAP server certificate fingerprint %x does not match the pinned fingerprint", digest)
+			}
+			return nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
 func (c *Client) Disconnect() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -8,6 +8,8 @@ func NewLDAPService() (*LDAPService, error) {
 		return nil, fmt.Errorf("failed to load LDAP configuration: %w", err)
 	}
 
+	groupCache.setTTL(config.GroupCacheTTL)
+
 	client := NewClient(config)
 	if err := client.Connect(); err != nil {
 		return nil, fmt.Errorf("failed to connect to LDAP: %w", err)
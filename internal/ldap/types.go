@@ -2,6 +2,7 @@ package ldap
 
 import (
 	"sync"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 )
@@ -48,13 +49,23 @@ type LDAPService struct {
 // =================================================
 
 type Config struct {
-	URL              string `envconfig:"LDAP_URL" default:"ldaps://localhost:636"`
-	BindUser         string `envconfig:"LDAP_BIND_USER"`
-	BindPassword     string `envconfig:"LDAP_BIND_PASSWORD"`
-	SkipTLSVerify    bool   `envconfig:"LDAP_SKIP_TLS_VERIFY" default:"false"`
+	URL           string `envconfig:"LDAP_URL" default:"ldaps://localhost:636"`
+	BindUser      string `envconfig:"LDAP_BIND_USER"`
+	BindPassword  string `envconfig:"LDAP_BIND_PASSWORD"`
+	SkipTLSVerify bool   `envconfig:"LDAP_SKIP_TLS_VERIFY" default:"false"`
+	// CAFile is a PEM bundle of CAs trusted for the server certificate in addition to the system pool
+	CAFile string `envconfig:"LDAP_CA_FILE"`
+	// TLSFingerprint pins the server certificate by its SHA-256 fingerprint in hex, colons optional
+	TLSFingerprint string `envconfig:"LDAP_TLS_FINGERPRINT"`
+	// StartTLS upgrades an ldap:// connection to TLS, plain ldap:// is refused without it
+	StartTLS         bool   `envconfig:"LDAP_START_TLS" default:"false"`
 	AdminGroupName   string `envconfig:"LDAP_ADMIN_GROUP_NAME"`
 	CreatorGroupName string `envconfig:"LDAP_CREATOR_GROUP_NAME"`
 	BaseDN           string `envconfig:"LDAP_BASE_DN"`
+	// GroupCacheTTL is how long a user's group membership is served from the cache, zero disables it
+	GroupCacheTTL time.Duration `envconfig:"LDAP_GROUP_CACHE_TTL" default:"30s"`
+	// UserAttributes maps user fields to directory attributes, e.g. "email:mail,department:department"
+	UserAttributes map[string]string `envconfig:"LDAP_USER_ATTRIBUTES"`
 }
 
 type Client struct {
@@ -64,6 +75,34 @@ type Client struct {
 	connected bool
 }
 
+// =================================================
+// Group Membership Cache
+// =================================================
+
+type membershipCache struct {
+	mutex         sync.Mutex
+	ttl           time.Duration
+	entries       map[string]membershipEntry
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+type membershipEntry struct {
+	groups    []string
+	expiresAt time.Time
+}
+
+// GroupCacheStats reports how effective the group membership cache is
+type GroupCacheStats struct {
+	TTLSeconds    float64 `json:"ttl_seconds"`
+	Entries       int     `json:"entries"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Invalidations uint64  `json:"invalidations"`
+	HitRatio      float64 `json:"hit_ratio"`
+}
+
 // =================================================
 // Groups
 // =================================================
@@ -77,6 +116,7 @@ type Group struct {
 	CanModify bool   `json:"can_modify"`
 	CreatedAt string `json:"created_at,omitempty"`
 	UserCount int    `json:"user_count,omitempty"`
+	Manager   string `json:"manager,omitempty"`
 }
 
 // =================================================
@@ -90,6 +130,8 @@ type User struct {
 	IsAdmin   bool    `json:"is_admin"`
 	IsCreator bool    `json:"is_creator"`
 	Groups    []Group `json:"groups"`
+	// Attributes holds the directory attributes mapped by LDAP_USER_ATTRIBUTES, by field name
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 type UserRegistrationInfo struct {
@@ -21,7 +21,7 @@ func (s *LDAPService) GetUsers() ([]User, error) {
 	searchRequest := ldapv3.NewSearchRequest(
 		s.client.config.BaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases, 0, 0, false,
 		fmt.Sprintf("(&(objectClass=user)(sAMAccountName=*)(memberOf=%s))", kaminoUsersGroupDN), // Filter for users in KaminoUsers group
-		[]string{"sAMAccountName", "dn", "whenCreated", "memberOf", "userAccountControl"},       // Attributes to retrieve
+		s.userSearchAttributes(), // Attributes to retrieve
 		nil,
 	)
 
@@ -33,7 +33,8 @@ func (s *LDAPService) GetUsers() ([]User, error) {
 	var users = []User{}
 	for _, entry := range searchResult.Entries {
 		user := User{
-			Name: entry.GetAttributeValue("sAMAccountName"),
+			Name:       entry.GetAttributeValue("sAMAccountName"),
+			Attributes: s.mapUserAttributes(entry),
 		}
 
 		whenCreated := entry.GetAttributeValue("whenCreated")
@@ -82,7 +83,7 @@ func (s *LDAPService) GetUser(username string) (*User, error) {
 	searchRequest := ldapv3.NewSearchRequest(
 		s.client.config.BaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases, 0, 0, false,
 		fmt.Sprintf("(&(objectClass=user)(sAMAccountName=%s)(memberOf=%s))", username, kaminoUsersGroupDN), // Filter for specific user in KaminoUsers group
-		[]string{"sAMAccountName", "dn", "whenCreated", "memberOf", "userAccountControl"},                  // Attributes to retrieve
+		s.userSearchAttributes(), // Attributes to retrieve
 		nil,
 	)
 
@@ -97,7 +98,8 @@ func (s *LDAPService) GetUser(username string) (*User, error) {
 
 	entry := searchResult.Entries[0]
 	user := User{
-		Name: entry.GetAttributeValue("sAMAccountName"),
+		Name:       entry.GetAttributeValue("sAMAccountName"),
+		Attributes: s.mapUserAttributes(entry),
 	}
 
 	whenCreated := entry.GetAttributeValue("whenCreated")
@@ -222,6 +224,7 @@ func (s *LDAPService) AddToGroup(userDN string, groupDN string) error {
 		return fmt.Errorf("failed to add user to group: %v", err)
 	}
 
+	groupCache.invalidate(userDN)
 	return nil
 }
 
@@ -240,6 +243,7 @@ func (s *LDAPService) RemoveFromGroup(userDN string, groupDN string) error {
 		return fmt.Errorf("failed to remove user from group: %v", err)
 	}
 
+	groupCache.invalidate(userDN)
 	return nil
 }
 
@@ -317,6 +321,7 @@ func (s *LDAPService) RemoveUserFromGroup(username string, groupName string) err
 		return fmt.Errorf("failed to remove user from group: %v", err)
 	}
 
+	groupCache.invalidate(userDN)
 	return nil
 }
 
@@ -332,6 +337,7 @@ func (s *LDAPService) DeleteUser(username string) error {
 		return fmt.Errorf("failed to delete user: %v", err)
 	}
 
+	groupCache.invalidate(userDN)
 	return nil
 }
 
@@ -346,7 +352,13 @@ func (s *LDAPService) DeleteUsers(usernames []string) []error {
 	return errors
 }
 
+// GetUserGroups returns the names of the groups the user is a direct member of. Results
+// are cached for LDAP_GROUP_CACHE_TTL and invalidated when the service changes membership.
 func (s *LDAPService) GetUserGroups(userDN string) ([]string, error) {
+	if groups, ok := groupCache.get(userDN); ok {
+		return groups, nil
+	}
+
 	searchRequest := ldapv3.NewSearchRequest(
 		userDN,
 		ldapv3.ScopeBaseObject,
@@ -379,6 +391,7 @@ func (s *LDAPService) GetUserGroups(userDN string) ([]string, error) {
 		}
 	}
 
+	groupCache.set(userDN, groups)
 	return groups, nil
 }
 
@@ -435,6 +448,30 @@ func (s *LDAPService) SetUserGroups(username string, groups []string) error {
 // Private Functions
 // =================================================
 
+// userSearchAttributes returns the attributes read for users, including the mapped ones
+func (s *LDAPService) userSearchAttributes() []string {
+	attributes := []string{"sAMAccountName", "dn", "whenCreated", "memberOf", "userAccountControl"}
+	for _, attribute := range s.client.config.UserAttributes {
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
+
+// mapUserAttributes reads the mapped attributes of a user entry, skipping those it lacks
+func (s *LDAPService) mapUserAttributes(entry *ldapv3.Entry) map[string]string {
+	if len(s.client.config.UserAttributes) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string)
+	for field, attribute := range s.client.config.UserAttributes {
+		if value := entry.GetAttributeValue(attribute); value != "" {
+			attributes[field] = value
+		}
+	}
+	return attributes
+}
+
 func getUserGroupsFromMemberOf(memberOfValues []string) ([]Group, error) {
 	var groups []Group
 	for _, memberOf := range memberOfValues {
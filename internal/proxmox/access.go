@@ -0,0 +1,48 @@
+package proxmox
+
+import (
+	"fmt"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// GetACLs retrieves every access control entry configured on the cluster
+func (s *ProxmoxService) GetACLs() ([]ACLEntry, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: "/access/acl",
+	}
+
+	var acls []ACLEntry
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &acls); err != nil {
+		return nil, fmt.Errorf("failed to get ACLs: %w", err)
+	}
+
+	return acls, nil
+}
+
+// GetAPITokens retrieves the API tokens of every Proxmox user
+func (s *ProxmoxService) GetAPITokens() ([]APIToken, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: "/access/users?full=1",
+	}
+
+	var users []struct {
+		UserID string     `json:"userid"`
+		Tokens []APIToken `json:"tokens"`
+	}
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &users); err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	var tokens []APIToken
+	for _, user := range users {
+		for _, token := range user.Tokens {
+			token.UserID = user.UserID
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
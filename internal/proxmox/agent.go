@@ -0,0 +1,237 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// AgentFileWriteMaxSize is the largest file the qemu guest agent accepts through file-write
+const AgentFileWriteMaxSize = 61440
+
+// agentExecTimeout is how long AgentExecOutput waits for a command to exit
+const agentExecTimeout = 30 * time.Second
+
+// WaitForAgent waits for the qemu guest agent in the VM to respond to pings
+func (s *ProxmoxService) WaitForAgent(ctx context.Context, node string, vmID int, timeout time.Duration) error {
+	statusReq := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/ping", node, vmID),
+	}
+
+	backoff := time.Second
+	maxBackoff := 30 * time.Second
+	startTime := time.Now()
+
+	for {
+		if time.Since(startTime) > timeout {
+			return fmt.Errorf("timed out waiting for qemu agent on VM %d", vmID)
+		}
+
+		if _, err := s.RequestHelper.MakeRequestWithContext(ctx, statusReq); err == nil {
+			return nil // Agent is responding
+		}
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = time.Duration(math.Min(float64(backoff*2), float64(maxBackoff)))
+	}
+}
+
+// AgentPing checks once whether the qemu guest agent in the VM responds
+func (s *ProxmoxService) AgentPing(node string, vmID int) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/ping", node, vmID),
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("qemu agent on VM %d did not respond: %w", vmID, err)
+	}
+
+	return nil
+}
+
+// AgentExec starts a command in the VM through the qemu guest agent without waiting for it
+// to finish
+func (s *ProxmoxService) AgentExec(node string, vmID int, command []string) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/agent/exec", node, vmID),
+		RequestBody: map[string]any{"command": command},
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AgentExecOutput runs a command in the VM through the qemu guest agent and returns its
+// standard output once it exits, failing when it exits non-zero or runs past the timeout
+func (s *ProxmoxService) AgentExecOutput(node string, vmID int, command []string) (string, error) {
+	result, err := s.agentRun(node, vmID, command, agentExecTimeout)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return result.Stdout, fmt.Errorf("command in VM %d exited with code %d: %s", vmID, result.ExitCode, result.Stderr)
+	}
+
+	return result.Stdout, nil
+}
+
+// AgentRunCommand runs an admin's command in a VM through the qemu guest agent and returns
+// its exit code and output. It is refused unless AGENT_EXEC_ENABLED is set and for VMs in
+// the critical pool.
+func (s *ProxmoxService) AgentRunCommand(node string, vmID int, command []string, timeout time.Duration) (*AgentExecResult, error) {
+	if !s.Config.AgentExecEnabled {
+		return nil, fmt.Errorf("guest agent command execution is disabled")
+	}
+
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
+		return nil, err
+	}
+	if guestType != GuestTypeQEMU {
+		return nil, fmt.Errorf("VMID %d is a container, which has no guest agent", vmID)
+	}
+
+	return s.agentRun(node, vmID, command, timeout)
+}
+
+// AgentNetworkInterfaces returns the network interfaces the guest reports through the qemu guest agent
+func (s *ProxmoxService) AgentNetworkInterfaces(node string, vmID int) ([]AgentNetworkInterface, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/network-get-interfaces", node, vmID),
+	}
+
+	var response struct {
+		Result []AgentNetworkInterface `json:"result"`
+	}
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to get network interfaces of VM %d: %w", vmID, err)
+	}
+
+	return response.Result, nil
+}
+
+// AgentGetUsers returns the users currently logged in to the guest through the qemu guest agent
+func (s *ProxmoxService) AgentGetUsers(node string, vmID int) ([]AgentUser, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/get-users", node, vmID),
+	}
+
+	var response struct {
+		Result []AgentUser `json:"result"`
+	}
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to get logged in users of VM %d: %w", vmID, err)
+	}
+
+	return response.Result, nil
+}
+
+// AgentFileWrite writes content to a file inside the VM through the qemu guest agent
+func (s *ProxmoxService) AgentFileWrite(node string, vmID int, path string, content []byte) error {
+	if len(content) > AgentFileWriteMaxSize {
+		return fmt.Errorf("file size %d bytes exceeds the guest agent limit of %d bytes", len(content), AgentFileWriteMaxSize)
+	}
+
+	// Content is base64 encoded here so binary files survive the request body
+	reqBody := map[string]any{
+		"file":    path,
+		"content": base64.StdEncoding.EncodeToString(content),
+		"encode":  false,
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/agent/file-write", node, vmID),
+		RequestBody: reqBody,
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to write file %s to VM %d: %w", path, vmID, err)
+	}
+
+	return nil
+}
+
+// AgentFileRead reads a file from inside the VM through the qemu guest agent. The
+// returned flag is true when the guest agent truncated the file.
+func (s *ProxmoxService) AgentFileRead(node string, vmID int, path string) ([]byte, bool, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/file-read?file=%s", node, vmID, url.QueryEscape(path)),
+	}
+
+	var response struct {
+		Content   string `json:"content"`
+		Truncated bool   `json:"truncated"`
+	}
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &response); err != nil {
+		return nil, false, fmt.Errorf("failed to read file %s from VM %d: %w", path, vmID, err)
+	}
+
+	return []byte(response.Content), response.Truncated, nil
+}
+
+// agentRun starts a command through agent/exec and polls agent/exec-status until it exits
+func (s *ProxmoxService) agentRun(node string, vmID int, command []string, timeout time.Duration) (*AgentExecResult, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/agent/exec", node, vmID),
+		RequestBody: map[string]any{"command": command},
+	}
+
+	var started struct {
+		PID int `json:"pid"`
+	}
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &started); err != nil {
+		return nil, fmt.Errorf("failed to run command in VM %d: %w", vmID, err)
+	}
+
+	statusReq := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/exec-status?pid=%d", node, vmID, started.PID),
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var status struct {
+			Exited       int    `json:"exited"`
+			ExitCode     int    `json:"exitcode"`
+			OutData      string `json:"out-data"`
+			ErrData      string `json:"err-data"`
+			OutTruncated int    `json:"out-truncated"`
+			ErrTruncated int    `json:"err-truncated"`
+		}
+		if err := s.RequestHelper.MakeRequestAndUnmarshal(statusReq, &status); err != nil {
+			return nil, fmt.Errorf("failed to get command status in VM %d: %w", vmID, err)
+		}
+
+		if status.Exited == 1 {
+			return &AgentExecResult{
+				PID:       started.PID,
+				ExitCode:  status.ExitCode,
+				Stdout:    status.OutData,
+				Stderr:    status.ErrData,
+				Truncated: status.OutTruncated == 1 || status.ErrTruncated == 1,
+			}, nil
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	return nil, fmt.Errorf("timed out waiting for command in VM %d", vmID)
+}
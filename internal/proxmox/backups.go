@@ -0,0 +1,178 @@
+package proxmox
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// BackupVMToDir starts a vzdump backup of a VM into a directory on the node and
+// returns the UPID of the backup task
+func (s *ProxmoxService) BackupVMToDir(node string, vmID int, dumpDir string) (string, error) {
+	if err := s.validateVMID(vmID); err != nil {
+		return "", err
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("/nodes/%s/vzdump", node),
+		RequestBody: map[string]any{
+			"vmid":     vmID,
+			"dumpdir":  dumpDir,
+			"mode":     "snapshot",
+			"compress": "zstd",
+		},
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to start backup of VMID %d: %w", vmID, err)
+	}
+
+	return upid, nil
+}
+
+// BackupVMToStorage starts a vzdump backup of a stopped VM onto a backup storage and
+// returns the UPID of the backup task
+func (s *ProxmoxService) BackupVMToStorage(node string, vmID int, storage string) (string, error) {
+	if err := s.validateVMID(vmID); err != nil {
+		return "", err
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("/nodes/%s/vzdump", node),
+		RequestBody: map[string]any{
+			"vmid":     vmID,
+			"storage":  storage,
+			"mode":     "stop",
+			"compress": "zstd",
+		},
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to start backup of VMID %d: %w", vmID, err)
+	}
+
+	return upid, nil
+}
+
+// BackupVMWithNotes starts a snapshot mode vzdump backup of a VM, running or not, onto a
+// backup storage with notes attached and returns the UPID of the backup task. The notes
+// may use the vzdump placeholders such as {{guestname}}.
+func (s *ProxmoxService) BackupVMWithNotes(node string, vmID int, storage string, notes string) (string, error) {
+	if err := s.validateVMID(vmID); err != nil {
+		return "", err
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("/nodes/%s/vzdump", node),
+		RequestBody: map[string]any{
+			"vmid":           vmID,
+			"storage":        storage,
+			"mode":           "snapshot",
+			"compress":       "zstd",
+			"notes-template": notes,
+		},
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to start backup of VMID %d: %w", vmID, err)
+	}
+
+	return upid, nil
+}
+
+// GetBackups lists the backups on a storage as seen from a node, only those of one VM
+// when vmID is not zero
+func (s *ProxmoxService) GetBackups(node string, storage string, vmID int) ([]Backup, error) {
+	endpoint := fmt.Sprintf("/nodes/%s/storage/%s/content?content=backup", node, storage)
+	if vmID != 0 {
+		endpoint += fmt.Sprintf("&vmid=%d", vmID)
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: endpoint,
+	}
+
+	var backups []Backup
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &backups); err != nil {
+		return nil, fmt.Errorf("failed to list backups on storage %s: %w", storage, err)
+	}
+
+	return backups, nil
+}
+
+// GetLatestBackup returns the volume ID of the newest backup of a VM on a storage
+func (s *ProxmoxService) GetLatestBackup(node string, storage string, vmID int) (string, error) {
+	backups, err := s.GetBackups(node, storage, vmID)
+	if err != nil {
+		return "", err
+	}
+
+	latest := ""
+	var latestTime int64
+	for _, backup := range backups {
+		if backup.CTime >= latestTime {
+			latest = backup.VolID
+			latestTime = backup.CTime
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no backup of VMID %d found on storage %s", vmID, storage)
+	}
+
+	return latest, nil
+}
+
+// RestoreVM starts restoring a VM from a backup archive into a pool and returns the
+// UPID of the restore task. With overwrite set an existing stopped VM with the VMID is
+// replaced by the backup.
+func (s *ProxmoxService) RestoreVM(node string, vmID int, archive string, poolName string, overwrite bool) (string, error) {
+	defer s.resources.invalidate()
+
+	if err := s.validateVMID(vmID); err != nil {
+		return "", err
+	}
+
+	body := map[string]any{
+		"vmid":    vmID,
+		"archive": archive,
+		"pool":    poolName,
+	}
+	if overwrite {
+		body["force"] = 1
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    fmt.Sprintf("/nodes/%s/qemu", node),
+		RequestBody: body,
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to start restore of VMID %d from %s: %w", vmID, archive, err)
+	}
+
+	return upid, nil
+}
+
+// DeleteBackup removes a backup archive from a storage
+func (s *ProxmoxService) DeleteBackup(node string, storage string, volID string) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "DELETE",
+		Endpoint: fmt.Sprintf("/nodes/%s/storage/%s/content/%s", node, storage, url.PathEscape(volID)),
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to delete backup %s: %w", volID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package proxmox
+
+import (
+	"slices"
+	"time"
+)
+
+// GetResourceCacheStats returns the hit and miss counters of the cluster resource cache
+func (s *ProxmoxService) GetResourceCacheStats() ResourceCacheStats {
+	return s.resources.stats()
+}
+
+// InvalidateClusterResources drops every cached cluster resource listing, for changes
+// made to the cluster outside the service
+func (s *ProxmoxService) InvalidateClusterResources() {
+	s.resources.invalidate()
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func newResourceCache(ttl time.Duration) *resourceCache {
+	return &resourceCache{ttl: ttl, entries: make(map[string]resourceEntry)}
+}
+
+func (c *resourceCache) get(getParams string) ([]VirtualResource, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	entry, ok := c.entries[getParams]
+	if !ok || time.Now().After(entry.expiresAt) {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	return slices.Clone(entry.resources), true
+}
+
+// set stores a listing unless the cache was invalidated after fetchedAt, when the listing
+// may predate the change that invalidated it
+func (c *resourceCache) set(getParams string, resources []VirtualResource, fetchedAt time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl <= 0 || fetchedAt.Before(c.invalidatedAt) {
+		return
+	}
+
+	c.entries[getParams] = resourceEntry{
+		resources: slices.Clone(resources),
+		expiresAt: fetchedAt.Add(c.ttl),
+	}
+}
+
+func (c *resourceCache) invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	clear(c.entries)
+	c.invalidatedAt = time.Now()
+	c.invalidations++
+}
+
+func (c *resourceCache) stats() ResourceCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := ResourceCacheStats{
+		TTLSeconds:    c.ttl.Seconds(),
+		Entries:       len(c.entries),
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Invalidations: c.invalidations,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRatio = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
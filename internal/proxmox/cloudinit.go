@@ -0,0 +1,101 @@
+package proxmox
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// HasCloudInit reports whether a VM has a cloud-init drive
+func (s *ProxmoxService) HasCloudInit(node string, vmid int) (bool, error) {
+	config, err := s.GetVMConfigValues(node, vmid)
+	if err != nil {
+		return false, err
+	}
+
+	return hasCloudInitDrive(config), nil
+}
+
+// SetCloudInit writes the user, credentials, and NIC addresses to the cloud-init settings of
+// a VM. Proxmox regenerates the drive when the VM starts and uses the VM name as hostname.
+func (s *ProxmoxService) SetCloudInit(node string, vmid int, config CloudInitConfig) error {
+	body := map[string]string{}
+	if config.User != "" {
+		body["ciuser"] = config.User
+	}
+	if config.Password != "" {
+		body["cipassword"] = config.Password
+	}
+	if config.SSHKeys != "" {
+		// Proxmox expects the keys URL encoded with spaces as %20
+		body["sshkeys"] = strings.ReplaceAll(url.QueryEscape(config.SSHKeys), "+", "%20")
+	}
+	for nic, value := range config.IPConfigs {
+		body[fmt.Sprintf("ipconfig%d", nic)] = value
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "PUT",
+		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/config", node, vmid),
+		RequestBody: body,
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to set cloud-init config for VMID %d: %w", vmid, err)
+	}
+
+	return nil
+}
+
+// PodRouterIPConfigs returns the cloud-init addresses of a pod router: the WAN address on
+// net0, the first LAN subnet on net1, and each segment on its NIC. Cloud-init gives a NIC a
+// single IPv4 address, so further LAN subnets need a router driver.
+func (s *ProxmoxService) PodRouterIPConfigs(podNumber int, lan RouterLAN) (map[int]string, error) {
+	wan := fmt.Sprintf("ip=%s/%d", s.PodRouterWANIP(podNumber), s.Config.WANPrefixLength)
+	if s.Config.WANGateway != "" {
+		wan += ",gw=" + s.Config.WANGateway
+	}
+	ipConfigs := map[int]string{0: wan}
+
+	if len(lan.Subnets) > 1 {
+		return nil, fmt.Errorf("cloud-init can only configure one LAN subnet, got %d", len(lan.Subnets))
+	}
+	if len(lan.Subnets) == 1 {
+		addresses, err := routerLANAddresses(lan.Subnets)
+		if err != nil {
+			return nil, err
+		}
+		ipConfigs[1] = "ip=" + addresses[0]
+	}
+
+	for _, segment := range lan.Segments {
+		addresses, err := routerLANAddresses([]string{segment.Subnet})
+		if err != nil {
+			return nil, err
+		}
+		ipConfigs[segment.NIC] = "ip=" + addresses[0]
+	}
+
+	return ipConfigs, nil
+}
+
+// hasCloudInitDrive reports whether a VM config attaches a cloud-init drive, such as
+// ide2: local-lvm:vm-100-cloudinit,media=cdrom
+func hasCloudInitDrive(config map[string]any) bool {
+	for key, value := range config {
+		definition, ok := value.(string)
+		if !ok || !strings.HasPrefix(key, "ide") && !strings.HasPrefix(key, "sata") && !strings.HasPrefix(key, "scsi") {
+			continue
+		}
+		if strings.Contains(definition, "cloudinit") {
+			return true
+		}
+	}
+
+	return false
+}
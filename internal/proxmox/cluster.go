@@ -1,8 +1,11 @@
 package proxmox
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/cpp-cyber/proclone/internal/tools"
 )
@@ -26,19 +29,24 @@ func (s *ProxmoxService) GetNodeStatus(nodeName string) (*ProxmoxNodeStatus, err
 	return &nodeStatus, nil
 }
 
-// GetClusterResources retrieves all cluster resources from the Proxmox cluster
-func (s *ProxmoxService) GetClusterResources(getParams string) ([]VirtualResource, error) {
+// GetNodeStorages lists the enabled storages of a node that can hold guest disks
+func (s *ProxmoxService) GetNodeStorages(nodeName string) ([]NodeStorage, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
-		Endpoint: fmt.Sprintf("/cluster/resources?%s", getParams),
+		Endpoint: fmt.Sprintf("/nodes/%s/storage?enabled=1&content=images", nodeName),
 	}
 
-	var resources []VirtualResource
-	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &resources); err != nil {
-		return nil, fmt.Errorf("failed to get cluster resources: %w", err)
+	var storages []NodeStorage
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &storages); err != nil {
+		return nil, fmt.Errorf("failed to get storage of node %s: %w", nodeName, err)
 	}
 
-	return resources, nil
+	return storages, nil
+}
+
+// GetClusterResources retrieves all cluster resources from the Proxmox cluster
+func (s *ProxmoxService) GetClusterResources(getParams string) ([]VirtualResource, error) {
+	return s.getClusterResourcesWithContext(context.Background(), getParams)
 }
 
 // GetClusterResourceUsage retrieves resource usage for the Proxmox cluster
@@ -67,6 +75,12 @@ func (s *ProxmoxService) GetClusterResourceUsage() (*ClusterResourceUsageRespons
 
 // FindBestNode finds the node with the most available resources
 func (s *ProxmoxService) FindBestNode() (string, error) {
+	return s.FindBestNodeIn(nil)
+}
+
+// FindBestNodeIn finds the node with the most available resources among the
+// candidate nodes. An empty candidate list considers every node in the cluster.
+func (s *ProxmoxService) FindBestNodeIn(candidates []string) (string, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
 		Endpoint: "/nodes",
@@ -89,6 +103,10 @@ func (s *ProxmoxService) FindBestNode() (string, error) {
 	var lowestLoad float64 = 1.0
 
 	for _, node := range nodesResponse {
+		if len(candidates) > 0 && !slices.Contains(candidates, node.Node) {
+			continue
+		}
+
 		if node.Status == "online" {
 			// Calculate combined load (CPU + Memory)
 			cpuLoad := node.CPU
@@ -235,3 +253,25 @@ func (s *ProxmoxService) syncRealm(scope string) error {
 
 	return nil
 }
+
+// getClusterResourcesWithContext serves listings from the resource cache, which the
+// methods that create, remove, move or power VMs and pools invalidate
+func (s *ProxmoxService) getClusterResourcesWithContext(ctx context.Context, getParams string) ([]VirtualResource, error) {
+	if resources, ok := s.resources.get(getParams); ok {
+		return resources, nil
+	}
+
+	fetchedAt := time.Now()
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/cluster/resources?%s", getParams),
+	}
+
+	var resources []VirtualResource
+	if err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, req, &resources); err != nil {
+		return nil, fmt.Errorf("failed to get cluster resources: %w", err)
+	}
+
+	s.resources.set(getParams, resources, fetchedAt)
+	return resources, nil
+}
@@ -0,0 +1,93 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+	"github.com/gorilla/websocket"
+)
+
+// Console types a ticket can be created for
+const (
+	ConsoleTypeVNC   = "vnc"   // Graphical console for noVNC
+	ConsoleTypeXterm = "xterm" // Serial or container terminal for xterm.js
+)
+
+// CreateConsoleTicket opens a VNC or terminal proxy on the guest's node and returns the
+// ticket for connecting to it through DialConsole. The ticket is also the VNC password.
+func (s *ProxmoxService) CreateConsoleTicket(vm VirtualResource, consoleType string) (*ConsoleTicket, error) {
+	suffix := "/vncproxy"
+	body := map[string]any{"websocket": 1}
+	if consoleType == ConsoleTypeXterm {
+		suffix = "/termproxy"
+		body = nil
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    guestEndpoint(vm.Type, vm.NodeName, vm.VmId, suffix),
+		RequestBody: body,
+	}
+
+	var response struct {
+		Port   any    `json:"port"` // Proxmox returns the port as a string or a number
+		Ticket string `json:"ticket"`
+		User   string `json:"user"`
+	}
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to create %s console ticket for VMID %d: %w", consoleType, vm.VmId, err)
+	}
+
+	return &ConsoleTicket{
+		Type:   consoleType,
+		Port:   fmt.Sprint(response.Port),
+		Ticket: response.Ticket,
+		User:   response.User,
+	}, nil
+}
+
+// DialConsole connects to the websocket of a console proxy opened by CreateConsoleTicket
+func (s *ProxmoxService) DialConsole(ctx context.Context, vm VirtualResource, port string, ticket string) (*websocket.Conn, error) {
+	query := url.Values{}
+	query.Set("port", port)
+	query.Set("vncticket", ticket)
+
+	endpoint := strings.Replace(s.BaseURL, "https://", "wss://", 1) +
+		guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/vncwebsocket") + "?" + query.Encode()
+
+	dialer := websocket.Dialer{
+		TLSClientConfig:  s.tlsConfig(),
+		HandshakeTimeout: s.HTTPClient.Timeout,
+		Subprotocols:     []string{"binary"},
+	}
+	header := http.Header{}
+	header.Set("Authorization", "PVEAPIToken="+s.RequestHelper.CurrentToken())
+
+	conn, _, err := dialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to console of VMID %d: %w", vm.VmId, err)
+	}
+
+	return conn, nil
+}
+
+// CreateSPICEConfig returns the remote-viewer settings for a SPICE connection to a VM
+// through the SPICE proxy of the Proxmox host
+func (s *ProxmoxService) CreateSPICEConfig(vm VirtualResource) (map[string]any, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    guestEndpoint(GuestTypeQEMU, vm.NodeName, vm.VmId, "/spiceproxy"),
+		RequestBody: map[string]any{"proxy": s.Config.Host},
+	}
+
+	var config map[string]any
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &config); err != nil {
+		return nil, fmt.Errorf("failed to create SPICE ticket for VMID %d: %w", vm.VmId, err)
+	}
+
+	return config, nil
+}
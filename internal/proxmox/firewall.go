@@ -0,0 +1,89 @@
+package proxmox
+
+import (
+	"fmt"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// SetGuestFirewallPolicy enables the firewall of a VM or container with the default
+// policies for traffic that no rule matches. Only NICs with firewall=1 are filtered.
+func (s *ProxmoxService) SetGuestFirewallPolicy(vm VirtualResource, policyIn string, policyOut string) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "PUT",
+		Endpoint: guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/firewall/options"),
+		RequestBody: map[string]any{
+			"enable":     1,
+			"policy_in":  policyIn,
+			"policy_out": policyOut,
+		},
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to set firewall options for VMID %d: %w", vm.VmId, err)
+	}
+
+	return nil
+}
+
+// GetGuestFirewallRules returns the firewall rules of a VM or container in evaluation order
+func (s *ProxmoxService) GetGuestFirewallRules(vm VirtualResource) ([]FirewallRule, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/firewall/rules"),
+	}
+
+	var rules []FirewallRule
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &rules); err != nil {
+		return nil, fmt.Errorf("failed to get firewall rules for VMID %d: %w", vm.VmId, err)
+	}
+
+	return rules, nil
+}
+
+// AddGuestFirewallRule adds an enabled rule at the top of the firewall rules of a VM or container
+func (s *ProxmoxService) AddGuestFirewallRule(vm VirtualResource, rule FirewallRule) error {
+	body := map[string]any{
+		"type":   rule.Type,
+		"action": rule.Action,
+		"enable": 1,
+	}
+	for key, value := range map[string]string{
+		"proto":   rule.Proto,
+		"dport":   rule.DPort,
+		"source":  rule.Source,
+		"dest":    rule.Dest,
+		"iface":   rule.Iface,
+		"comment": rule.Comment,
+	} {
+		if value != "" {
+			body[key] = value
+		}
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/firewall/rules"),
+		RequestBody: body,
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to add firewall rule for VMID %d: %w", vm.VmId, err)
+	}
+
+	return nil
+}
+
+// DeleteGuestFirewallRule deletes the firewall rule at a position, the rules after it move up
+func (s *ProxmoxService) DeleteGuestFirewallRule(vm VirtualResource, pos int) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "DELETE",
+		Endpoint: guestEndpoint(vm.Type, vm.NodeName, vm.VmId, fmt.Sprintf("/firewall/rules/%d", pos)),
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to delete firewall rule %d for VMID %d: %w", pos, vm.VmId, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package proxmox
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// HAResourceID returns the HA resource ID of a VM or container, such as vm:100
+func HAResourceID(guestType string, vmID int) string {
+	if guestType == GuestTypeLXC {
+		return fmt.Sprintf("ct:%d", vmID)
+	}
+	return fmt.Sprintf("vm:%d", vmID)
+}
+
+// GetHAGroups returns the HA groups configured on the cluster
+func (s *ProxmoxService) GetHAGroups() ([]HAGroup, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: "/cluster/ha/groups",
+	}
+
+	var groups []HAGroup
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &groups); err != nil {
+		return nil, fmt.Errorf("failed to get HA groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetHAResources returns the guests managed by the HA manager
+func (s *ProxmoxService) GetHAResources() ([]HAResource, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: "/cluster/ha/resources",
+	}
+
+	var resources []HAResource
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &resources); err != nil {
+		return nil, fmt.Errorf("failed to get HA resources: %w", err)
+	}
+
+	return resources, nil
+}
+
+// AddHAResource puts a guest under the HA manager in a group. The state is the one the
+// HA manager keeps the guest in, started guests are recovered on another node of the
+// group when theirs fails.
+func (s *ProxmoxService) AddHAResource(sid string, group string, state string, comment string) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: "/cluster/ha/resources",
+		RequestBody: map[string]any{
+			"sid":     sid,
+			"group":   group,
+			"state":   state,
+			"comment": comment,
+		},
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to add HA resource %s to group %s: %w", sid, group, err)
+	}
+
+	return nil
+}
+
+// RemoveHAResource takes a guest out of the HA manager, leaving the guest itself as it is
+func (s *ProxmoxService) RemoveHAResource(sid string) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "DELETE",
+		Endpoint: "/cluster/ha/resources/" + url.PathEscape(sid),
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to remove HA resource %s: %w", sid, err)
+	}
+
+	return nil
+}
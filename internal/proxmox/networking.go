@@ -1,24 +1,17 @@
 package proxmox
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"math"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cpp-cyber/proclone/internal/tools"
 )
 
-// RouterConfig holds configuration needed for router operations
-type RouterConfig struct {
-	RouterWaitTimeout time.Duration
-	WANScriptPath     string
-	VIPScriptPath     string
-	VYOSScriptPath    string
-	WANIPBase         string
-}
-
+// GetRouterType returns the name of the router driver that recognizes the router's VM config
 func (s *ProxmoxService) GetRouterType(router VM) (string, error) {
 	infoReq := tools.ProxmoxAPIRequest{
 		Method:   "GET",
@@ -29,114 +22,107 @@ func (s *ProxmoxService) GetRouterType(router VM) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("request for router type failed: %v", err)
 	}
-	switch {
-	case strings.Contains(string(infoRsp), "pfsense"):
-		return "pfsense", nil
-	case strings.Contains(string(infoRsp), "vyos"):
-		return "vyos", nil
-	default:
-		return "", fmt.Errorf("router type not defined")
+
+	for _, driver := range s.routerDrivers() {
+		if driver.Detect(string(infoRsp)) {
+			return driver.Name(), nil
+		}
 	}
+
+	return "", fmt.Errorf("router type not defined")
 }
 
 // ConfigurePodRouter configures the pod router with proper networking settings
-func (s *ProxmoxService) ConfigurePodRouter(podNumber int, node string, vmid int, routerType string) error {
-	config := RouterConfig{
-		WANScriptPath:  s.Config.WANScriptPath,
-		VIPScriptPath:  s.Config.VIPScriptPath,
-		VYOSScriptPath: s.Config.VYOSScriptPath,
-		WANIPBase:      s.Config.WANIPBase,
+func (s *ProxmoxService) ConfigurePodRouter(ctx context.Context, podNumber int, node string, vmid int, routerType string, lan RouterLAN) error {
+	driver, err := s.routerDriver(routerType)
+	if err != nil {
+		return err
+	}
+
+	// Cloud-init routers are configured before they boot and may have no agent
+	if routerType == CloudInitRouterType {
+		return nil
 	}
 
 	// Wait for router agent to be pingable
-	statusReq := tools.ProxmoxAPIRequest{
-		Method:   "POST",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/agent/ping", node, vmid),
+	if err := s.WaitForAgent(ctx, node, vmid, 5*time.Minute); err != nil {
+		return fmt.Errorf("router qemu agent timed out: %w", err)
 	}
 
-	backoff := time.Second
-	maxBackoff := 30 * time.Second
-	timeout := 5 * time.Minute
-	startTime := time.Now()
+	router := RouterTarget{Node: node, VMID: vmid, PodNumber: podNumber, LAN: lan}
+	if err := driver.ConfigureWAN(s, router); err != nil {
+		return err
+	}
 
-	for {
-		if time.Since(startTime) > timeout {
-			return fmt.Errorf("router qemu agent timed out")
-		}
+	if err := driver.ConfigureVIP(s, router); err != nil {
+		return err
+	}
 
-		if _, err := s.RequestHelper.MakeRequest(statusReq); err == nil {
-			break // Agent is responding
-		}
+	return driver.ConfigureLAN(s, router)
+}
 
-		time.Sleep(backoff)
-		backoff = time.Duration(math.Min(float64(backoff*2), float64(maxBackoff)))
+// CheckPodRouter verifies through the router's driver that the router holds the WAN
+// address of its pod
+func (s *ProxmoxService) CheckPodRouter(podNumber int, node string, vmid int, routerType string) error {
+	driver, err := s.routerDriver(routerType)
+	if err != nil {
+		return err
 	}
 
-	// Clone depending on router type
-	switch routerType {
-	case "pfsense":
-		// Configure router WAN IP to have correct third octet using qemu agent API call
-		reqBody := map[string]any{
-			"command": []string{
-				config.WANScriptPath,
-				fmt.Sprintf("%s%d.1", config.WANIPBase, podNumber),
-			},
-		}
+	return driver.HealthCheck(s, RouterTarget{Node: node, VMID: vmid, PodNumber: podNumber})
+}
 
-		execReq := tools.ProxmoxAPIRequest{
-			Method:      "POST",
-			Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/agent/exec", node, vmid),
-			RequestBody: reqBody,
-		}
+// CheckPodRouterNAT verifies through the router's driver that the router's NAT rules
+// translate the WAN subnet of its pod
+func (s *ProxmoxService) CheckPodRouterNAT(podNumber int, node string, vmid int, routerType string) error {
+	driver, err := s.routerDriver(routerType)
+	if err != nil {
+		return err
+	}
 
-		_, err := s.RequestHelper.MakeRequest(execReq)
-		if err != nil {
-			return fmt.Errorf("failed to make IP change request: %v", err)
-		}
+	checker, ok := driver.(NATChecker)
+	if !ok {
+		return ErrNATCheckUnsupported
+	}
 
-		// Send agent exec request to change VIP subnet
-		vipReqBody := map[string]any{
-			"command": []string{
-				config.VIPScriptPath,
-				fmt.Sprintf("%s%d.0", config.WANIPBase, podNumber),
-			},
-		}
+	return checker.CheckNAT(s, RouterTarget{Node: node, VMID: vmid, PodNumber: podNumber})
+}
 
-		vipExecReq := tools.ProxmoxAPIRequest{
-			Method:      "POST",
-			Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/agent/exec", node, vmid),
-			RequestBody: vipReqBody,
-		}
+// AddRouterPortForward forwards a port of the pod's WAN address to a VM inside the pod
+func (s *ProxmoxService) AddRouterPortForward(podNumber int, node string, vmid int, routerType string, forward PortForward) error {
+	forwarder, err := s.portForwarder(routerType)
+	if err != nil {
+		return err
+	}
 
-		_, err = s.RequestHelper.MakeRequest(vipExecReq)
-		if err != nil {
-			return fmt.Errorf("failed to make VIP change request: %v", err)
-		}
-	case "vyos":
-		reqBody := map[string]any{
-			"command": []string{
-				"sh",
-				"-c",
-				fmt.Sprintf("sed -i -e 's/{{THIRD_OCTET}}/%d/g;s/{{NETWORK_PREFIX}}/%s/g' %s", podNumber, config.WANIPBase, config.VYOSScriptPath),
-			},
-		}
+	return forwarder.AddPortForward(s, RouterTarget{Node: node, VMID: vmid, PodNumber: podNumber}, forward)
+}
 
-		execReq := tools.ProxmoxAPIRequest{
-			Method:      "POST",
-			Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/agent/exec", node, vmid),
-			RequestBody: reqBody,
-		}
+// RemoveRouterPortForward removes a port forward added by AddRouterPortForward
+func (s *ProxmoxService) RemoveRouterPortForward(podNumber int, node string, vmid int, routerType string, forward PortForward) error {
+	forwarder, err := s.portForwarder(routerType)
+	if err != nil {
+		return err
+	}
 
-		_, err := s.RequestHelper.MakeRequest(execReq)
-		if err != nil {
-			return fmt.Errorf("failed to make IP change request: %v", err)
-		}
+	return forwarder.RemovePortForward(s, RouterTarget{Node: node, VMID: vmid, PodNumber: podNumber}, forward)
+}
 
-	default:
-		return fmt.Errorf("router type invalid")
-	}
+// PodRouterWANIP returns the WAN address ConfigurePodRouter assigns to the router of a pod
+func (s *ProxmoxService) PodRouterWANIP(podNumber int) string {
+	return fmt.Sprintf("%s%d.1", s.Config.WANIPBase, podNumber)
+}
 
-	return nil
+// PodWANAddress returns an address of a pod's WAN subnet, the router maps the VIP to the
+// LAN address with the same last octet
+func (s *ProxmoxService) PodWANAddress(podNumber int, host int) string {
+	return fmt.Sprintf("%s%d.%d", s.Config.WANIPBase, podNumber, host)
+}
+
+// PodWANSubnet returns the WAN subnet of a pod, the router takes its first address and
+// the VIPs the rest
+func (s *ProxmoxService) PodWANSubnet(podNumber int) string {
+	return fmt.Sprintf("%s%d.0/24", s.Config.WANIPBase, podNumber)
 }
 
 func (s *ProxmoxService) SetPodVnet(poolName string, vnetName string, routerVMID int) error {
@@ -169,10 +155,18 @@ func (s *ProxmoxService) SetPodVnet(poolName string, vnetName string, routerVMID
 		reqBody := map[string]string{
 			vnet: fmt.Sprintf("virtio,bridge=%s,firewall=1", vnetName),
 		}
+		if vm.Type == GuestTypeLXC {
+			value, err := s.containerNICWithBridge(vm, vnet, vnetName)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("failed to read network for container %s (VMID: %d): %v", vm.Name, vm.VmId, err))
+				continue
+			}
+			reqBody[vnet] = value
+		}
 
 		req := tools.ProxmoxAPIRequest{
 			Method:      "PUT",
-			Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/config", vm.NodeName, vm.VmId),
+			Endpoint:    guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/config"),
 			RequestBody: reqBody,
 		}
 
@@ -194,6 +188,77 @@ func (s *ProxmoxService) SetPodVnet(poolName string, vnetName string, routerVMID
 	return nil
 }
 
+// SetGuestBridge moves one NIC of a VM or container to another bridge, keeping the rest of
+// the NIC definition such as its model and MAC address
+func (s *ProxmoxService) SetGuestBridge(vm VirtualResource, nic string, bridge string) error {
+	config, err := s.GetVMConfigValues(vm.NodeName, vm.VmId)
+	if err != nil {
+		return err
+	}
+
+	value := fmt.Sprintf("virtio,bridge=%s,firewall=1", bridge)
+	if vm.Type == GuestTypeLXC {
+		value = fmt.Sprintf("name=eth0,bridge=%s,firewall=1,ip=dhcp", bridge)
+	}
+	if current, ok := config[nic].(string); ok && current != "" {
+		var parts []string
+		for _, part := range strings.Split(current, ",") {
+			if !strings.HasPrefix(part, "bridge=") {
+				parts = append(parts, part)
+			}
+		}
+		value = strings.Join(append(parts, "bridge="+bridge), ",")
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "PUT",
+		Endpoint:    guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/config"),
+		RequestBody: map[string]string{nic: value},
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to move %s of VMID %d to %s: %w", nic, vm.VmId, bridge, err)
+	}
+
+	return nil
+}
+
+// SetGuestNICRate limits one NIC of a VM or container to a rate in MB/s, zero removes the
+// limit. The rest of the NIC definition is kept.
+func (s *ProxmoxService) SetGuestNICRate(vm VirtualResource, nic string, rate float64) error {
+	config, err := s.GetVMConfigValues(vm.NodeName, vm.VmId)
+	if err != nil {
+		return err
+	}
+
+	current, ok := config[nic].(string)
+	if !ok || current == "" {
+		return fmt.Errorf("VMID %d has no %s", vm.VmId, nic)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(current, ",") {
+		if !strings.HasPrefix(part, "rate=") {
+			parts = append(parts, part)
+		}
+	}
+	if rate > 0 {
+		parts = append(parts, "rate="+strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "PUT",
+		Endpoint:    guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/config"),
+		RequestBody: map[string]string{nic: strings.Join(parts, ",")},
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to set rate of %s of VMID %d: %w", nic, vm.VmId, err)
+	}
+
+	return nil
+}
+
 func (s *ProxmoxService) GetUsedVNets() ([]VNet, error) {
 	vnets := []VNet{}
 
@@ -208,3 +273,27 @@ func (s *ProxmoxService) GetUsedVNets() ([]VNet, error) {
 
 	return vnets, nil
 }
+
+// containerNICWithBridge returns a container's NIC definition attached to the bridge. A
+// container NIC carries its interface name and addressing, so only the bridge is replaced.
+func (s *ProxmoxService) containerNICWithBridge(vm VirtualResource, nic string, bridge string) (string, error) {
+	config, err := s.GetVMConfigValues(vm.NodeName, vm.VmId)
+	if err != nil {
+		return "", err
+	}
+
+	current, ok := config[nic].(string)
+	if !ok || current == "" {
+		return fmt.Sprintf("name=eth0,bridge=%s,firewall=1,ip=dhcp", bridge), nil
+	}
+
+	var parts []string
+	for _, part := range strings.Split(current, ",") {
+		if strings.HasPrefix(part, "bridge=") {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	parts = append(parts, "bridge="+bridge)
+	return strings.Join(parts, ","), nil
+}
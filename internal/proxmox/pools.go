@@ -1,6 +1,7 @@
 package proxmox
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -14,6 +15,10 @@ import (
 )
 
 func (s *ProxmoxService) GetPoolVMs(poolName string) ([]VirtualResource, error) {
+	return s.getPoolVMsWithContext(context.Background(), poolName)
+}
+
+func (s *ProxmoxService) getPoolVMsWithContext(ctx context.Context, poolName string) ([]VirtualResource, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
 		Endpoint: fmt.Sprintf("/pools/%s", poolName),
@@ -22,14 +27,14 @@ func (s *ProxmoxService) GetPoolVMs(poolName string) ([]VirtualResource, error)
 	var poolResponse struct {
 		Members []VirtualResource `json:"members"`
 	}
-	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &poolResponse); err != nil {
+	if err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, req, &poolResponse); err != nil {
 		return nil, fmt.Errorf("failed to get pool VMs: %w", err)
 	}
 
-	// Filter for VMs only (type=qemu)
+	// Filter for VMs and containers only
 	var vms []VirtualResource
 	for _, member := range poolResponse.Members {
-		if member.Type == "qemu" {
+		if member.IsGuest() {
 			vms = append(vms, member)
 		}
 	}
@@ -38,6 +43,8 @@ func (s *ProxmoxService) GetPoolVMs(poolName string) ([]VirtualResource, error)
 }
 
 func (s *ProxmoxService) CreateNewPool(poolName string) error {
+	defer s.resources.invalidate()
+
 	reqBody := map[string]string{
 		"poolid": poolName,
 	}
@@ -90,6 +97,8 @@ func (s *ProxmoxService) SetPoolPermission(poolName string, targetName string, i
 }
 
 func (s *ProxmoxService) DeletePool(poolName string) error {
+	defer s.resources.invalidate()
+
 	req := tools.ProxmoxAPIRequest{
 		Method:   "DELETE",
 		Endpoint: fmt.Sprintf("/pools/%s", poolName),
@@ -104,7 +113,8 @@ func (s *ProxmoxService) DeletePool(poolName string) error {
 	return nil
 }
 
-func (s *ProxmoxService) GetTemplatePools() ([]string, error) {
+// GetPools returns the names of every pool, including pools that have no VMs yet
+func (s *ProxmoxService) GetPools() ([]string, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
 		Endpoint: "/pools",
@@ -114,13 +124,27 @@ func (s *ProxmoxService) GetTemplatePools() ([]string, error) {
 		Name string `json:"poolid"`
 	}
 	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &poolResponse); err != nil {
+		return nil, fmt.Errorf("failed to get pools: %w", err)
+	}
+
+	var pools []string
+	for _, pool := range poolResponse {
+		pools = append(pools, pool.Name)
+	}
+
+	return pools, nil
+}
+
+func (s *ProxmoxService) GetTemplatePools() ([]string, error) {
+	pools, err := s.GetPools()
+	if err != nil {
 		return nil, fmt.Errorf("failed to get template pools: %w", err)
 	}
 
 	var templatePools []string
-	for _, pool := range poolResponse {
-		if strings.HasPrefix(pool.Name, "kamino_template_") {
-			templatePools = append(templatePools, pool.Name)
+	for _, pool := range pools {
+		if strings.HasPrefix(pool, "kamino_template_") {
+			templatePools = append(templatePools, pool)
 		}
 	}
 
@@ -133,10 +157,10 @@ func (s *ProxmoxService) IsPoolEmpty(poolName string) (bool, error) {
 		return false, fmt.Errorf("failed to check if pool %s is empty: %w", poolName, err)
 	}
 
-	// Count only QEMU VMs (ignore other resource types)
+	// Count only VMs and containers (ignore other resource types)
 	vmCount := 0
 	for _, vm := range poolVMs {
-		if vm.Type == "qemu" {
+		if vm.IsGuest() {
 			vmCount++
 		}
 	}
@@ -144,14 +168,17 @@ func (s *ProxmoxService) IsPoolEmpty(poolName string) (bool, error) {
 	return vmCount == 0, nil
 }
 
-func (s *ProxmoxService) WaitForPoolEmpty(poolName string, timeout time.Duration) error {
+func (s *ProxmoxService) WaitForPoolEmpty(ctx context.Context, poolName string, timeout time.Duration) error {
 	start := time.Now()
 	backoff := 2 * time.Second
 	maxBackoff := 30 * time.Second
 
 	for time.Since(start) < timeout {
-		poolVMs, err := s.GetPoolVMs(poolName)
+		poolVMs, err := s.getPoolVMsWithContext(ctx, poolName)
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			// If we can't get pool VMs, pool might be deleted or empty
 			log.Printf("Error checking pool %s (might be deleted): %v", poolName, err)
 			return nil
@@ -163,13 +190,40 @@ func (s *ProxmoxService) WaitForPoolEmpty(poolName string, timeout time.Duration
 		}
 
 		log.Printf("Pool %s still contains %d VMs, waiting...", poolName, len(poolVMs))
-		time.Sleep(backoff)
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
 		backoff = time.Duration(math.Min(float64(backoff*2), float64(maxBackoff)))
 	}
 
 	return fmt.Errorf("timeout waiting for pool %s to become empty after %v", poolName, timeout)
 }
 
+// WaitForVMRemoved waits until a deleted VM no longer appears in its pool so its VMID can be reused
+func (s *ProxmoxService) WaitForVMRemoved(ctx context.Context, poolName string, vmID int, timeout time.Duration) error {
+	start := time.Now()
+	backoff := 2 * time.Second
+	maxBackoff := 30 * time.Second
+
+	for time.Since(start) < timeout {
+		poolVMs, err := s.getPoolVMsWithContext(ctx, poolName)
+		if err != nil {
+			return fmt.Errorf("failed to check pool %s: %w", poolName, err)
+		}
+
+		if !slices.ContainsFunc(poolVMs, func(vm VirtualResource) bool { return vm.VmId == vmID }) {
+			return nil
+		}
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = time.Duration(math.Min(float64(backoff*2), float64(maxBackoff)))
+	}
+
+	return fmt.Errorf("timeout waiting for VM %d to be removed from pool %s after %v", vmID, poolName, timeout)
+}
+
 func (s *ProxmoxService) GetNextPodID(minPodID int, maxPodID int) (string, int, error) {
 	// Get all existing pools
 	req := tools.ProxmoxAPIRequest{
@@ -210,7 +264,32 @@ func (s *ProxmoxService) GetNextPodID(minPodID int, maxPodID int) (string, int,
 }
 
 func (s *ProxmoxService) GetNextPodIDs(minPodID int, maxPodID int, num int) ([]string, []int, error) {
-	// Get all existing pools
+	usedIDs, err := s.GetUsedPodIDs(minPodID, maxPodID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Find available IDs
+	var podIDs []string
+	var adjustedIDs []int
+
+	for i := minPodID; i <= maxPodID && len(podIDs) < num; i++ {
+		found := slices.Contains(usedIDs, i)
+		if !found {
+			podIDs = append(podIDs, fmt.Sprintf("%04d", i))
+			adjustedIDs = append(adjustedIDs, i-1000)
+		}
+	}
+
+	if len(podIDs) < num {
+		return nil, nil, fmt.Errorf("only found %d available pod IDs out of %d requested in range %d-%d", len(podIDs), num, minPodID, maxPodID)
+	}
+
+	return podIDs, adjustedIDs, nil
+}
+
+// GetUsedPodIDs returns the sorted pod IDs within the range taken by existing pools
+func (s *ProxmoxService) GetUsedPodIDs(minPodID int, maxPodID int) ([]int, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
 		Endpoint: "/pools",
@@ -220,7 +299,7 @@ func (s *ProxmoxService) GetNextPodIDs(minPodID int, maxPodID int, num int) ([]s
 		PoolID string `json:"poolid"`
 	}
 	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &poolsResponse); err != nil {
-		return nil, nil, fmt.Errorf("failed to get existing pools: %w", err)
+		return nil, fmt.Errorf("failed to get existing pools: %w", err)
 	}
 
 	// Extract pod IDs from existing pools
@@ -228,7 +307,7 @@ func (s *ProxmoxService) GetNextPodIDs(minPodID int, maxPodID int, num int) ([]s
 	for _, pool := range poolsResponse {
 		if len(pool.PoolID) >= 4 {
 			if id, err := strconv.Atoi(pool.PoolID[:4]); err == nil {
-				if id >= minPodID && id <= maxPodID {
+				if id >= minPodID && id <= maxPodID && !slices.Contains(usedIDs, id) {
 					usedIDs = append(usedIDs, id)
 				}
 			}
@@ -236,24 +315,7 @@ func (s *ProxmoxService) GetNextPodIDs(minPodID int, maxPodID int, num int) ([]s
 	}
 
 	sort.Ints(usedIDs)
-
-	// Find available IDs
-	var podIDs []string
-	var adjustedIDs []int
-
-	for i := minPodID; i <= maxPodID && len(podIDs) < num; i++ {
-		found := slices.Contains(usedIDs, i)
-		if !found {
-			podIDs = append(podIDs, fmt.Sprintf("%04d", i))
-			adjustedIDs = append(adjustedIDs, i-1000)
-		}
-	}
-
-	if len(podIDs) < num {
-		return nil, nil, fmt.Errorf("only found %d available pod IDs out of %d requested in range %d-%d", len(podIDs), num, minPodID, maxPodID)
-	}
-
-	return podIDs, adjustedIDs, nil
+	return usedIDs, nil
 }
 
 func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRouter bool, vms []VM) error {
@@ -291,8 +353,8 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 		return err
 	}
 
-	// Track cloning UPIDs directly in a map for O(1) lookup
-	pendingUPIDs := make(map[string]bool)
+	// Track the clone tasks so their outcome can be followed to completion
+	var pendingUPIDs []string
 
 	// 4. If addRouter is true, clone router from Config
 	var router VM
@@ -320,11 +382,11 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 		// Remove the first VMID from the list
 		vmIDs = vmIDs[1:]
 
-		upid, err := s.cloneVMWithUPID(routerCloneReq)
+		upid, err := s.CloneVM(context.Background(), routerCloneReq)
 		if err != nil {
 			return err
 		}
-		pendingUPIDs[upid] = true
+		pendingUPIDs = append(pendingUPIDs, upid)
 	}
 
 	// 5. Clone specified templates to newly created pool with the specified names
@@ -337,11 +399,11 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 			TargetNode: bestNode,
 		}
 
-		upid, err := s.cloneVMWithUPID(vmCloneReq)
+		upid, err := s.CloneVM(context.Background(), vmCloneReq)
 		if err != nil {
 			return err
 		}
-		pendingUPIDs[upid] = true
+		pendingUPIDs = append(pendingUPIDs, upid)
 	}
 
 	if len(pendingUPIDs) == 0 {
@@ -350,39 +412,12 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 	}
 
 	log.Printf("Waiting for %d VM clone operation(s) to complete", len(pendingUPIDs))
-	for retries := range 20 {
-		activeTasks, err := s.getActiveCloningTasks(bestNode)
-		if err != nil {
-			return fmt.Errorf("failed to get active tasks: %w", err)
-		}
-
-		// Build set of currently active UPIDs
-		activeUPIDs := make(map[string]bool)
-		for _, task := range activeTasks {
-			activeUPIDs[task.UPID] = true
+	for _, upid := range pendingUPIDs {
+		if err := s.TrackTask(context.Background(), upid, 100*time.Second); err != nil {
+			return fmt.Errorf("VM clone operation did not complete: %w", err)
 		}
-
-		// Check if any of our clone operations are still running
-		stillRunning := false
-		for upid := range pendingUPIDs {
-			if activeUPIDs[upid] {
-				stillRunning = true
-				break
-			}
-		}
-
-		if !stillRunning {
-			log.Printf("All VM clone operations completed")
-			break
-		}
-
-		if retries == 19 {
-			return fmt.Errorf("timed out waiting for VM clone operations to complete")
-		}
-
-		log.Printf("Waiting for %d VM clone operation(s) to complete (retry %d/30)", len(pendingUPIDs), retries+1)
-		time.Sleep(5 * time.Second)
 	}
+	log.Printf("All VM clone operations completed")
 
 	// Return with no error if addRouter is false since all other operations below have to do with routing
 	if !addRouter {
@@ -423,7 +458,7 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 
 	// Wait for router to be running
 	log.Printf("Waiting for router VM to be running")
-	err = s.WaitForRunning(bestNode, routerVMID)
+	err = s.WaitForRunning(context.Background(), bestNode, routerVMID)
 	if err != nil {
 		return fmt.Errorf("router VM failed to start: %w", err)
 	}
@@ -446,7 +481,7 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 	log.Printf("Third octect is %d", octect)
 
 	log.Printf("Configuring router")
-	err = s.ConfigurePodRouter(octect, bestNode, routerVMID, routerType)
+	err = s.ConfigurePodRouter(context.Background(), octect, bestNode, routerVMID, routerType, RouterLAN{})
 	if err != nil {
 		return fmt.Errorf("failed to configure router for %s: %v", routerType, err)
 	}
@@ -458,3 +493,94 @@ func (s *ProxmoxService) CreateTemplatePool(creator string, name string, addRout
 
 	return nil
 }
+
+// RemovePoolPermission removes the pool access granted to a user or group by SetPoolPermission
+func (s *ProxmoxService) RemovePoolPermission(poolName string, targetName string, isGroup bool) error {
+	realm := s.Config.Realm
+
+	reqBody := map[string]any{
+		"path":   fmt.Sprintf("/pool/%s", poolName),
+		"roles":  "PVEVMUser,PVEPoolUser",
+		"delete": true,
+	}
+
+	if isGroup {
+		reqBody["groups"] = fmt.Sprintf("%s-%s", targetName, realm)
+	} else {
+		reqBody["users"] = fmt.Sprintf("%s@%s", targetName, realm)
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "PUT",
+		Endpoint:    "/access/acl",
+		RequestBody: reqBody,
+	}
+
+	_, err := s.RequestHelper.MakeRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to remove pool permissions: %w", err)
+	}
+
+	return nil
+}
+
+// MoveVMsToPool adds the VMs to the pool, moving them out of any pool they are currently in
+func (s *ProxmoxService) MoveVMsToPool(poolName string, vmIDs []int) error {
+	defer s.resources.invalidate()
+
+	var ids []string
+	for _, vmID := range vmIDs {
+		ids = append(ids, strconv.Itoa(vmID))
+	}
+
+	reqBody := map[string]any{
+		"vms":        strings.Join(ids, ","),
+		"allow-move": true,
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "PUT",
+		Endpoint:    fmt.Sprintf("/pools/%s", poolName),
+		RequestBody: reqBody,
+	}
+
+	_, err := s.RequestHelper.MakeRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to move VMs to pool %s: %w", poolName, err)
+	}
+
+	return nil
+}
+
+// SetPoolUserRoles grants a single user the given roles on the pool
+func (s *ProxmoxService) SetPoolUserRoles(poolName string, username string, roles []string) error {
+	return s.updatePoolUserRoles(poolName, username, roles, false)
+}
+
+// RemovePoolUserRoles revokes roles previously granted to a user on the pool
+func (s *ProxmoxService) RemovePoolUserRoles(poolName string, username string, roles []string) error {
+	return s.updatePoolUserRoles(poolName, username, roles, true)
+}
+
+func (s *ProxmoxService) updatePoolUserRoles(poolName string, username string, roles []string, remove bool) error {
+	reqBody := map[string]any{
+		"path":      fmt.Sprintf("/pool/%s", poolName),
+		"roles":     strings.Join(roles, ","),
+		"propagate": true,
+		"users":     fmt.Sprintf("%s@%s", username, s.Config.Realm),
+		"delete":    remove,
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "PUT",
+		Endpoint:    "/access/acl",
+		RequestBody: reqBody,
+	}
+
+	_, err := s.RequestHelper.MakeRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to update pool roles for %s: %w", username, err)
+	}
+
+	return nil
+}
@@ -13,28 +13,49 @@ import (
 
 // NewProxmoxService creates a new Proxmox service with the given configuration
 func NewProxmoxService(config ProxmoxConfig) *ProxmoxService {
-	// Create HTTP client with appropriate TLS settings
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: !config.VerifySSL,
-		},
+	// One pooled client carries every Proxmox API call. Clones and status polling run
+	// many requests at once, so keep enough idle connections per host to reuse them.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: !config.VerifySSL,
 	}
+	transport.MaxIdleConns = config.MaxIdleConns
+	transport.MaxIdleConnsPerHost = config.MaxIdleConns
+	transport.IdleConnTimeout = 90 * time.Second
 
 	client := &http.Client{
 		Transport: transport,
-		Timeout:   30 * time.Second,
+		Timeout:   config.HTTPTimeout,
 	}
 
-	baseURL := fmt.Sprintf("https://%s:%s/api2/json", config.Host, config.Port)
+	// Primary host first, followed by any failover hosts
+	var baseURLs []string
+	for _, host := range append([]string{config.Host}, config.FailoverHosts...) {
+		baseURLs = append(baseURLs, fmt.Sprintf("https://%s:%s/api2/json", host, config.Port))
+	}
+	baseURL := baseURLs[0]
 
 	// Initialize the request helper
-	requestHelper := tools.NewProxmoxRequestHelper(baseURL, config.APIToken, client)
+	requestHelper := tools.NewProxmoxRequestHelper(baseURLs, config.APIToken, client)
+	requestHelper.Retry = tools.RetryPolicy{
+		Attempts:  config.RetryAttempts,
+		BaseDelay: config.RetryBaseDelay,
+		MaxDelay:  config.RetryMaxDelay,
+	}
+	requestHelper.Breaker = tools.BreakerPolicy{
+		Threshold: config.BreakerThreshold,
+		Cooldown:  config.BreakerCooldown,
+	}
+	if config.SecondaryTokenID != "" && config.SecondaryTokenSecret != "" {
+		requestHelper.SetFallbackToken(fmt.Sprintf("%s=%s", config.SecondaryTokenID, config.SecondaryTokenSecret))
+	}
 
 	return &ProxmoxService{
 		Config:        &config,
 		HTTPClient:    client,
 		BaseURL:       baseURL,
 		RequestHelper: requestHelper,
+		resources:     newResourceCache(config.ResourceCacheTTL),
 	}
 }
 
@@ -51,6 +72,26 @@ func (s *ProxmoxService) GetRequestHelper() *tools.ProxmoxRequestHelper {
 	return s.RequestHelper
 }
 
+// tlsConfig returns the TLS settings of the shared client for connections made outside
+// it, such as console websockets
+func (s *ProxmoxService) tlsConfig() *tls.Config {
+	if transport, ok := s.HTTPClient.Transport.(*http.Transport); ok {
+		return transport.TLSClientConfig
+	}
+	return &tls.Config{InsecureSkipVerify: !s.Config.VerifySSL}
+}
+
+// GetEndpointHealth returns the health state of each configured Proxmox API endpoint
+func (s *ProxmoxService) GetEndpointHealth() []tools.ProxmoxEndpoint {
+	return s.RequestHelper.GetEndpoints()
+}
+
+// GetBreakerState returns the state of the circuit breaker that fails requests fast while
+// the cluster is unreachable
+func (s *ProxmoxService) GetBreakerState() tools.BreakerState {
+	return s.RequestHelper.GetBreakerState()
+}
+
 func LoadProxmoxConfig() (*ProxmoxConfig, error) {
 	var config ProxmoxConfig
 	if err := envconfig.Process("", &config); err != nil {
@@ -69,5 +110,15 @@ func LoadProxmoxConfig() (*ProxmoxConfig, error) {
 		}
 	}
 
+	// Parse failover hosts list if provided
+	if config.FailoverHostsStr != "" {
+		for _, host := range strings.Split(config.FailoverHostsStr, ",") {
+			host = strings.TrimSpace(host)
+			if host != "" && host != config.Host {
+				config.FailoverHosts = append(config.FailoverHosts, host)
+			}
+		}
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,467 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RouterDriver configures one kind of pod router image. Supporting a new router image
+// only takes a driver added to routerDrivers, the cloning flow works with any of them
+// through GetRouterType, ConfigurePodRouter, and CheckPodRouter.
+type RouterDriver interface {
+	// Name is the router type recorded for the pod, such as pfsense
+	Name() string
+	// Detect reports whether a router's VM config belongs to this kind of router
+	Detect(vmConfig string) bool
+	// ConfigureWAN gives the router the WAN address of its pod
+	ConfigureWAN(agent RouterAgent, router RouterTarget) error
+	// ConfigureVIP points the router's virtual IPs at the WAN subnet of its pod
+	ConfigureVIP(agent RouterAgent, router RouterTarget) error
+	// ConfigureLAN gives the router the first address of each LAN subnet and segment of its
+	// pod's template, leaving the LAN of the router image alone when there are none
+	ConfigureLAN(agent RouterAgent, router RouterTarget) error
+	// HealthCheck verifies the router holds the WAN address of its pod
+	HealthCheck(agent RouterAgent, router RouterTarget) error
+}
+
+// PortForwarder is implemented by router drivers that can forward ports of the pod's WAN
+// address to VMs inside the pod
+type PortForwarder interface {
+	AddPortForward(agent RouterAgent, router RouterTarget, forward PortForward) error
+	RemovePortForward(agent RouterAgent, router RouterTarget, forward PortForward) error
+}
+
+// NATChecker is implemented by router drivers that can read the router's NAT rules to
+// verify they translate the pod's WAN subnet
+type NATChecker interface {
+	CheckNAT(agent RouterAgent, router RouterTarget) error
+}
+
+// ErrNATCheckUnsupported is returned by CheckPodRouterNAT for routers whose driver can't
+// read NAT rules
+var ErrNATCheckUnsupported = errors.New("router type does not support NAT checks")
+
+// RouterAgent runs commands in and reads the state of a router through its guest agent
+type RouterAgent interface {
+	AgentExec(node string, vmID int, command []string) error
+	AgentExecOutput(node string, vmID int, command []string) (string, error)
+	AgentNetworkInterfaces(node string, vmID int) ([]AgentNetworkInterface, error)
+}
+
+// RouterTarget is the router VM of a pod
+type RouterTarget struct {
+	Node      string
+	VMID      int
+	PodNumber int
+	LAN       RouterLAN
+}
+
+// RouterConfig holds configuration needed for router operations
+type RouterConfig struct {
+	WANScriptPath     string
+	VIPScriptPath     string
+	VYOSScriptPath    string
+	ForwardScriptPath string
+	LANScriptPath     string
+	SegmentScriptPath string
+	WANIPBase         string
+}
+
+// routerDrivers returns a driver for every supported router image, in detection order
+func (s *ProxmoxService) routerDrivers() []RouterDriver {
+	config := RouterConfig{
+		WANScriptPath:     s.Config.WANScriptPath,
+		VIPScriptPath:     s.Config.VIPScriptPath,
+		VYOSScriptPath:    s.Config.VYOSScriptPath,
+		ForwardScriptPath: s.Config.ForwardScriptPath,
+		LANScriptPath:     s.Config.LANScriptPath,
+		SegmentScriptPath: s.Config.SegmentScriptPath,
+		WANIPBase:         s.Config.WANIPBase,
+	}
+
+	return []RouterDriver{
+		&pfSenseDriver{config: config},
+		&vyosDriver{config: config},
+		&cloudInitDriver{config: config},
+	}
+}
+
+func (s *ProxmoxService) routerDriver(routerType string) (RouterDriver, error) {
+	for _, driver := range s.routerDrivers() {
+		if driver.Name() == routerType {
+			return driver, nil
+		}
+	}
+
+	return nil, fmt.Errorf("router type invalid")
+}
+
+func (s *ProxmoxService) portForwarder(routerType string) (PortForwarder, error) {
+	driver, err := s.routerDriver(routerType)
+	if err != nil {
+		return nil, err
+	}
+
+	forwarder, ok := driver.(PortForwarder)
+	if !ok {
+		return nil, fmt.Errorf("router type %s does not support port forwarding", routerType)
+	}
+	return forwarder, nil
+}
+
+// =================================================
+// pfSense
+// =================================================
+
+// pfSenseDriver configures pfSense routers through the WAN and VIP shell scripts baked
+// into the router image
+type pfSenseDriver struct {
+	config RouterConfig
+}
+
+func (d *pfSenseDriver) Name() string {
+	return "pfsense"
+}
+
+func (d *pfSenseDriver) Detect(vmConfig string) bool {
+	return strings.Contains(vmConfig, "pfsense")
+}
+
+func (d *pfSenseDriver) ConfigureWAN(agent RouterAgent, router RouterTarget) error {
+	// Configure router WAN IP to have correct third octet
+	command := []string{d.config.WANScriptPath, fmt.Sprintf("%s%d.1", d.config.WANIPBase, router.PodNumber)}
+	if err := agent.AgentExec(router.Node, router.VMID, command); err != nil {
+		return fmt.Errorf("failed to make IP change request: %v", err)
+	}
+
+	return nil
+}
+
+func (d *pfSenseDriver) ConfigureVIP(agent RouterAgent, router RouterTarget) error {
+	command := []string{d.config.VIPScriptPath, fmt.Sprintf("%s%d.0", d.config.WANIPBase, router.PodNumber)}
+	if err := agent.AgentExec(router.Node, router.VMID, command); err != nil {
+		return fmt.Errorf("failed to make VIP change request: %v", err)
+	}
+
+	return nil
+}
+
+func (d *pfSenseDriver) ConfigureLAN(agent RouterAgent, router RouterTarget) error {
+	if len(router.LAN.Subnets) > 0 {
+		addresses, err := routerLANAddresses(router.LAN.Subnets)
+		if err != nil {
+			return err
+		}
+
+		command := append([]string{d.config.LANScriptPath}, addresses...)
+		if err := agent.AgentExec(router.Node, router.VMID, command); err != nil {
+			return fmt.Errorf("failed to make LAN change request: %v", err)
+		}
+	}
+
+	for _, segment := range router.LAN.Segments {
+		addresses, err := routerLANAddresses([]string{segment.Subnet})
+		if err != nil {
+			return err
+		}
+
+		command := []string{d.config.SegmentScriptPath, strconv.Itoa(segment.NIC), addresses[0]}
+		if err := agent.AgentExec(router.Node, router.VMID, command); err != nil {
+			return fmt.Errorf("failed to make segment change request: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *pfSenseDriver) HealthCheck(agent RouterAgent, router RouterTarget) error {
+	return checkRouterAddress(agent, router, fmt.Sprintf("%s%d.1", d.config.WANIPBase, router.PodNumber))
+}
+
+// AddPortForward runs the port forward script baked into the router image, which adds a
+// NAT rule named after the forward
+func (d *pfSenseDriver) AddPortForward(agent RouterAgent, router RouterTarget, forward PortForward) error {
+	command := []string{
+		d.config.ForwardScriptPath,
+		"add",
+		portForwardName(forward),
+		forward.Protocol,
+		fmt.Sprintf("%s%d.1", d.config.WANIPBase, router.PodNumber),
+		strconv.Itoa(forward.ExternalPort),
+		forward.InternalIP,
+		strconv.Itoa(forward.InternalPort),
+	}
+	if _, err := agent.AgentExecOutput(router.Node, router.VMID, command); err != nil {
+		return fmt.Errorf("failed to add port forward: %w", err)
+	}
+
+	return nil
+}
+
+func (d *pfSenseDriver) RemovePortForward(agent RouterAgent, router RouterTarget, forward PortForward) error {
+	command := []string{d.config.ForwardScriptPath, "delete", portForwardName(forward)}
+	if _, err := agent.AgentExecOutput(router.Node, router.VMID, command); err != nil {
+		return fmt.Errorf("failed to remove port forward: %w", err)
+	}
+
+	return nil
+}
+
+// CheckNAT looks for the pod's WAN subnet in the NAT rules loaded in pf
+func (d *pfSenseDriver) CheckNAT(agent RouterAgent, router RouterTarget) error {
+	output, err := agent.AgentExecOutput(router.Node, router.VMID, []string{"pfctl", "-s", "nat"})
+	if err != nil {
+		return fmt.Errorf("failed to read NAT rules: %w", err)
+	}
+
+	return checkNATRules(output, d.config.WANIPBase, router.PodNumber)
+}
+
+// =================================================
+// VyOS
+// =================================================
+
+// vyosDriver configures VyOS routers by filling in the placeholders of the configuration
+// script in the router image, which sets both the WAN address and the VIPs
+type vyosDriver struct {
+	config RouterConfig
+}
+
+func (d *vyosDriver) Name() string {
+	return "vyos"
+}
+
+func (d *vyosDriver) Detect(vmConfig string) bool {
+	return strings.Contains(vmConfig, "vyos")
+}
+
+func (d *vyosDriver) ConfigureWAN(agent RouterAgent, router RouterTarget) error {
+	command := []string{
+		"sh",
+		"-c",
+		fmt.Sprintf("sed -i -e 's/{{THIRD_OCTET}}/%d/g;s/{{NETWORK_PREFIX}}/%s/g' %s", router.PodNumber, d.config.WANIPBase, d.config.VYOSScriptPath),
+	}
+	if err := agent.AgentExec(router.Node, router.VMID, command); err != nil {
+		return fmt.Errorf("failed to make IP change request: %v", err)
+	}
+
+	return nil
+}
+
+// ConfigureVIP does nothing, the script filled in by ConfigureWAN also sets the VIPs
+func (d *vyosDriver) ConfigureVIP(agent RouterAgent, router RouterTarget) error {
+	return nil
+}
+
+// ConfigureLAN replaces the addresses of the LAN interface, which is on the pod VNet, and
+// of the interfaces on segments. NIC netN of the VM is ethN in VyOS.
+func (d *vyosDriver) ConfigureLAN(agent RouterAgent, router RouterTarget) error {
+	interfaces := map[int][]string{}
+	if len(router.LAN.Subnets) > 0 {
+		interfaces[1] = router.LAN.Subnets
+	}
+	for _, segment := range router.LAN.Segments {
+		interfaces[segment.NIC] = append(interfaces[segment.NIC], segment.Subnet)
+	}
+	if len(interfaces) == 0 {
+		return nil
+	}
+
+	var commands []string
+	for _, nic := range slices.Sorted(maps.Keys(interfaces)) {
+		addresses, err := routerLANAddresses(interfaces[nic])
+		if err != nil {
+			return err
+		}
+
+		commands = append(commands, fmt.Sprintf("delete interfaces ethernet eth%d address", nic))
+		for _, address := range addresses {
+			commands = append(commands, fmt.Sprintf("set interfaces ethernet eth%d address %s", nic, address))
+		}
+	}
+	return d.configure(agent, router, commands...)
+}
+
+func (d *vyosDriver) HealthCheck(agent RouterAgent, router RouterTarget) error {
+	return checkRouterAddress(agent, router, fmt.Sprintf("%s%d.1", d.config.WANIPBase, router.PodNumber))
+}
+
+// AddPortForward adds a destination NAT rule for traffic to the WAN address and commits
+// it to the saved configuration
+func (d *vyosDriver) AddPortForward(agent RouterAgent, router RouterTarget, forward PortForward) error {
+	number, err := vyosForwardRule(forward)
+	if err != nil {
+		return err
+	}
+
+	rule := fmt.Sprintf("set nat destination rule %d", number)
+	return d.configure(agent, router,
+		fmt.Sprintf("%s description %s", rule, portForwardName(forward)),
+		fmt.Sprintf("%s destination address %s%d.1", rule, d.config.WANIPBase, router.PodNumber),
+		fmt.Sprintf("%s destination port %d", rule, forward.ExternalPort),
+		fmt.Sprintf("%s protocol %s", rule, forward.Protocol),
+		fmt.Sprintf("%s translation address %s", rule, forward.InternalIP),
+		fmt.Sprintf("%s translation port %d", rule, forward.InternalPort),
+	)
+}
+
+func (d *vyosDriver) RemovePortForward(agent RouterAgent, router RouterTarget, forward PortForward) error {
+	// Forwards added before slots were numbered 10000 and up, which only newer releases
+	// accept. Older releases never committed the rule, so a failed removal is not an error.
+	if forward.Slot == 0 {
+		if err := d.configure(agent, router, fmt.Sprintf("delete nat destination rule %d", 10000+forward.ID)); err != nil {
+			log.Printf("Failed to remove legacy port forward rule of %s: %v", portForwardName(forward), err)
+		}
+		return nil
+	}
+
+	number, err := vyosForwardRule(forward)
+	if err != nil {
+		return err
+	}
+	return d.configure(agent, router, fmt.Sprintf("delete nat destination rule %d", number))
+}
+
+// CheckNAT looks for the pod's WAN subnet in the NAT rules of the running configuration
+func (d *vyosDriver) CheckNAT(agent RouterAgent, router RouterTarget) error {
+	command := []string{"sh", "-c", "/opt/vyatta/bin/cli-shell-api showConfig nat --show-active-only"}
+	output, err := agent.AgentExecOutput(router.Node, router.VMID, command)
+	if err != nil {
+		return fmt.Errorf("failed to read NAT rules: %w", err)
+	}
+
+	return checkNATRules(output, d.config.WANIPBase, router.PodNumber)
+}
+
+// configure runs configuration commands in a single VyOS configuration session and waits
+// for it, failing when a command or the commit fails. The session is ended either way.
+func (d *vyosDriver) configure(agent RouterAgent, router RouterTarget, commands ...string) error {
+	const wrapper = "/opt/vyatta/sbin/vyatta-cfg-cmd-wrapper"
+
+	script := []string{wrapper + " begin"}
+	for _, command := range commands {
+		script = append(script, wrapper+" "+command)
+	}
+	script = append(script, wrapper+" commit", wrapper+" save")
+
+	command := fmt.Sprintf("%s; status=$?; %s end; exit $status", strings.Join(script, " && "), wrapper)
+	if _, err := agent.AgentExecOutput(router.Node, router.VMID, []string{"sh", "-c", command}); err != nil {
+		return fmt.Errorf("failed to apply VyOS configuration: %w", err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Cloud-init
+// =================================================
+
+// CloudInitRouterType is the router type of routers that get their addresses from a
+// cloud-init drive written before they boot rather than through the guest agent
+const CloudInitRouterType = "cloudinit"
+
+// cloudInitDriver recognizes routers with a cloud-init drive that neither of the agent
+// drivers claims. Their addresses are written with SetCloudInit before the router starts.
+type cloudInitDriver struct {
+	config RouterConfig
+}
+
+func (d *cloudInitDriver) Name() string {
+	return CloudInitRouterType
+}
+
+func (d *cloudInitDriver) Detect(vmConfig string) bool {
+	return strings.Contains(vmConfig, "cloudinit")
+}
+
+// ConfigureWAN does nothing, the WAN address is on the cloud-init drive
+func (d *cloudInitDriver) ConfigureWAN(agent RouterAgent, router RouterTarget) error {
+	return nil
+}
+
+// ConfigureVIP does nothing, cloud-init routers have no VIPs
+func (d *cloudInitDriver) ConfigureVIP(agent RouterAgent, router RouterTarget) error {
+	return nil
+}
+
+// ConfigureLAN does nothing, the LAN and segment addresses are on the cloud-init drive
+func (d *cloudInitDriver) ConfigureLAN(agent RouterAgent, router RouterTarget) error {
+	return nil
+}
+
+// HealthCheck needs a guest agent in the router image to read the addresses
+func (d *cloudInitDriver) HealthCheck(agent RouterAgent, router RouterTarget) error {
+	return checkRouterAddress(agent, router, fmt.Sprintf("%s%d.1", d.config.WANIPBase, router.PodNumber))
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// portForwardName identifies a port forward on the router
+func portForwardName(forward PortForward) string {
+	return fmt.Sprintf("kamino-forward-%d", forward.ID)
+}
+
+// vyosForwardRuleBase keeps the destination NAT rules of port forwards clear of the rules
+// in the router image
+const vyosForwardRuleBase = 5000
+
+// vyosMaxNATRule is the highest NAT rule number every supported VyOS release accepts
+const vyosMaxNATRule = 9999
+
+// vyosForwardRule numbers the destination NAT rule of a port forward from its slot
+func vyosForwardRule(forward PortForward) (int, error) {
+	rule := vyosForwardRuleBase + forward.Slot
+	if forward.Slot < 1 || rule > vyosMaxNATRule {
+		return 0, fmt.Errorf("port forward slot %d is outside the VyOS NAT rule range", forward.Slot)
+	}
+	return rule, nil
+}
+
+// routerLANAddresses gives the router the first host address of each LAN subnet, in CIDR
+// notation such as 10.0.1.1/24
+func routerLANAddresses(subnets []string) ([]string, error) {
+	var addresses []string
+	for _, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LAN subnet %q: %w", subnet, err)
+		}
+		addresses = append(addresses, netip.PrefixFrom(prefix.Masked().Addr().Next(), prefix.Bits()).String())
+	}
+
+	return addresses, nil
+}
+
+// checkNATRules verifies that NAT rules mention addresses of the pod's WAN subnet
+func checkNATRules(rules string, wanIPBase string, podNumber int) error {
+	if !strings.Contains(rules, fmt.Sprintf("%s%d.", wanIPBase, podNumber)) {
+		return fmt.Errorf("no NAT rules translate the WAN subnet %s%d.0/24", wanIPBase, podNumber)
+	}
+
+	return nil
+}
+
+func checkRouterAddress(agent RouterAgent, router RouterTarget, expected string) error {
+	interfaces, err := agent.AgentNetworkInterfaces(router.Node, router.VMID)
+	if err != nil {
+		return fmt.Errorf("failed to read router addresses: %w", err)
+	}
+
+	for _, iface := range interfaces {
+		for _, address := range iface.IPAddresses {
+			if address.Address == expected {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("router does not have WAN address %s", expected)
+}
@@ -0,0 +1,67 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// CreateVNet defines a VLAN VNet in an SDN zone. It can't be used until ApplySDN runs.
+func (s *ProxmoxService) CreateVNet(name string, zone string, tag int, alias string) error {
+	body := map[string]any{
+		"vnet": name,
+		"zone": zone,
+		"tag":  tag,
+	}
+	if alias != "" {
+		body["alias"] = alias
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    "/cluster/sdn/vnets",
+		RequestBody: body,
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to create VNet %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// DeleteVNet removes a VNet definition. It stays on the nodes until ApplySDN runs.
+func (s *ProxmoxService) DeleteVNet(name string) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "DELETE",
+		Endpoint: fmt.Sprintf("/cluster/sdn/vnets/%s", url.PathEscape(name)),
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to delete VNet %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ApplySDN applies pending SDN changes to every node and waits for the reload to finish
+func (s *ProxmoxService) ApplySDN(ctx context.Context, timeout time.Duration) error {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "PUT",
+		Endpoint: "/cluster/sdn",
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return fmt.Errorf("failed to apply SDN configuration: %w", err)
+	}
+
+	if err := s.TrackTask(ctx, upid, timeout); err != nil {
+		return fmt.Errorf("failed to apply SDN configuration: %w", err)
+	}
+
+	return nil
+}
@@ -1,11 +1,174 @@
 package proxmox
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cpp-cyber/proclone/internal/tools"
 )
 
+// GetActiveCloneCount returns the number of clone tasks currently running on a node
+func (s *ProxmoxService) GetActiveCloneCount(node string) (int, error) {
+	tasks, err := s.getActiveCloningTasks(node)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active clone tasks for node %s: %w", node, err)
+	}
+	return len(tasks), nil
+}
+
+// GetTaskStatus retrieves the current status of a task by its UPID
+func (s *ProxmoxService) GetTaskStatus(node string, upid string) (*Task, error) {
+	return s.getTaskStatusWithContext(context.Background(), node, upid)
+}
+
+// WaitForTask polls a task until it stops, returning an error if the task failed, did not
+// finish within the timeout, or the context was cancelled. Cancelling stops the wait, not
+// the task.
+func (s *ProxmoxService) WaitForTask(ctx context.Context, node string, upid string, timeout time.Duration) error {
+	start := time.Now()
+
+	for time.Since(start) < timeout {
+		task, err := s.getTaskStatusWithContext(ctx, node, upid)
+		if err == nil && task.Status == "stopped" {
+			// Clones, migrations and restores change the cluster once their task ends
+			s.resources.invalidate()
+			if task.ExitStatus != "OK" {
+				return fmt.Errorf("task %s failed: %s", upid, task.ExitStatus)
+			}
+			return nil
+		}
+
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("timeout waiting for task %s to complete", upid)
+}
+
+// TrackTask polls a task on the node that runs it, as encoded in its UPID, until it
+// stops. A task that ends with an error is returned as a *TaskError carrying the exit
+// status Proxmox reported, any other error means the outcome is unknown.
+func (s *ProxmoxService) TrackTask(ctx context.Context, upid string, timeout time.Duration) error {
+	node, err := taskNode(upid)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	for {
+		task, err := s.getTaskStatusWithContext(ctx, node, upid)
+		if err == nil && task.Status == "stopped" {
+			s.resources.invalidate()
+			if task.ExitStatus != "OK" {
+				return &TaskError{UPID: upid, ExitStatus: task.ExitStatus}
+			}
+			return nil
+		}
+
+		if time.Since(start) >= timeout {
+			return fmt.Errorf("timeout waiting for task %s to complete", upid)
+		}
+
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return err
+		}
+	}
+}
+
+// GetRecentTasks returns the most recent cluster tasks first, by default only those started
+// with the API token this service uses
+func (s *ProxmoxService) GetRecentTasks(filter TaskFilter) ([]Task, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: "/cluster/tasks",
+	}
+
+	var tasks []Task
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to get cluster tasks: %w", err)
+	}
+
+	tokenID := s.RequestHelper.TokenID()
+	tokenUser, _, _ := strings.Cut(tokenID, "!")
+	matching := []Task{}
+	for _, task := range tasks {
+		if !filter.AllUsers && task.User != tokenID && task.User != tokenUser {
+			continue
+		}
+		if filter.Type != "" && task.Type != filter.Type {
+			continue
+		}
+		if filter.Node != "" && task.Node != filter.Node {
+			continue
+		}
+		if filter.ErrorsOnly && (task.Status == "" || task.Status == "OK" || task.EndTime == 0) {
+			continue
+		}
+		matching = append(matching, task)
+	}
+
+	slices.SortFunc(matching, func(a, b Task) int { return cmp.Compare(b.StartTime, a.StartTime) })
+	if filter.Limit > 0 && len(matching) > filter.Limit {
+		matching = matching[:filter.Limit]
+	}
+
+	return matching, nil
+}
+
+// GetTaskLog returns up to limit lines of the log of a task, starting at line start
+func (s *ProxmoxService) GetTaskLog(upid string, start int, limit int) ([]TaskLogLine, error) {
+	node, err := taskNode(upid)
+	if err != nil {
+		return nil, err
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/nodes/%s/tasks/%s/log?start=%d&limit=%d", node, url.PathEscape(upid), start, limit),
+	}
+
+	var lines []TaskLogLine
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &lines); err != nil {
+		return nil, fmt.Errorf("failed to get log of task %s: %w", upid, err)
+	}
+
+	return lines, nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// taskNode returns the node a task runs on from its UPID, UPID:<node>:<pid>:...
+func taskNode(upid string) (string, error) {
+	parts := strings.Split(upid, ":")
+	if len(parts) < 3 || parts[0] != "UPID" || parts[1] == "" {
+		return "", fmt.Errorf("invalid task UPID %q", upid)
+	}
+
+	return parts[1], nil
+}
+
+func (s *ProxmoxService) getTaskStatusWithContext(ctx context.Context, node string, upid string) (*Task, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("/nodes/%s/tasks/%s/status", node, url.PathEscape(upid)),
+	}
+
+	var task Task
+	if err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, req, &task); err != nil {
+		return nil, fmt.Errorf("failed to get status for task %s: %w", upid, err)
+	}
+
+	return &task, nil
+}
+
 func (s *ProxmoxService) getActiveCloningTasks(node string) ([]Task, error) {
 	activeCloningReq := tools.ProxmoxAPIRequest{
 		Method:   "GET",
@@ -0,0 +1,58 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cpp-cyber/proclone/internal/tools"
+)
+
+// GetTokenStatus returns the IDs of the API tokens requests are signed with
+func (s *ProxmoxService) GetTokenStatus() tools.TokenStatus {
+	return s.RequestHelper.GetTokenStatus()
+}
+
+// RotateAPIToken switches the service to a new API token without a restart. The token is
+// checked first: Proxmox must accept it and grant it every privilege the current token
+// has, so jobs already running do not start failing halfway. The replaced token stays as
+// the fallback until the next rotation.
+func (s *ProxmoxService) RotateAPIToken(ctx context.Context, tokenID string, tokenSecret string) error {
+	token := fmt.Sprintf("%s=%s", tokenID, tokenSecret)
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: "/access/permissions",
+	}
+
+	data, err := s.RequestHelper.MakeRequestWithToken(ctx, req, token)
+	if err != nil {
+		return fmt.Errorf("token %s was not accepted: %w", tokenID, err)
+	}
+	var granted map[string]map[string]int
+	if err := json.Unmarshal(data, &granted); err != nil {
+		return fmt.Errorf("failed to parse permissions of token %s: %w", tokenID, err)
+	}
+
+	var current map[string]map[string]int
+	if err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, req, &current); err != nil {
+		return fmt.Errorf("failed to get permissions of the current token: %w", err)
+	}
+
+	var missing []string
+	for path, privileges := range current {
+		for privilege := range privileges {
+			if _, ok := granted[path][privilege]; !ok {
+				missing = append(missing, path+" "+privilege)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("token %s lacks privileges of the current token: %s", tokenID, strings.Join(missing, ", "))
+	}
+
+	s.RequestHelper.RotateToken(token)
+	return nil
+}
@@ -1,35 +1,57 @@
 package proxmox
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/cpp-cyber/proclone/internal/tools"
+	"github.com/gorilla/websocket"
 )
 
 // ProxmoxConfig holds the configuration for Proxmox API
 type ProxmoxConfig struct {
-	Host              string        `envconfig:"PROXMOX_HOST" required:"true"`
-	Port              string        `envconfig:"PROXMOX_PORT" default:"8006"`
-	TokenID           string        `envconfig:"PROXMOX_TOKEN_ID" required:"true"`
-	TokenSecret       string        `envconfig:"PROXMOX_TOKEN_SECRET" required:"true"`
-	VerifySSL         bool          `envconfig:"PROXMOX_VERIFY_SSL" default:"false"`
-	CriticalPool      string        `envconfig:"PROXMOX_CRITICAL_POOL"`
-	Realm             string        `envconfig:"PROXMOX_REALM"`
-	NodesStr          string        `envconfig:"PROXMOX_NODES"`
-	StorageID         string        `envconfig:"PROXMOX_STORAGE_ID" default:"local-lvm"`
-	CreatorGroupName  string        `envconfig:"PROXMOX_CREATOR_GROUP_NAME" default:"Creator"`
-	VMTemplatePool    string        `envconfig:"PROXMOX_VM_TEMPLATE_POOL" default:"Templates"`
-	RouterName        string        `envconfig:"PROXMOX_ROUTER_NAME" default:"1-1NAT-vyos"`
-	RouterNode        string        `envconfig:"PROXMOX_ROUTER_NODE"`
-	RouterVMID        int           `envconfig:"PROXMOX_ROUTER_VMID"`
-	RouterWaitTimeout time.Duration `envconfig:"ROUTER_WAIT_TIMEOUT" default:"120s"`
-	WANScriptPath     string        `envconfig:"WAN_SCRIPT_PATH" default:"/home/update-wan-ip.sh"`
-	VIPScriptPath     string        `envconfig:"VIP_SCRIPT_PATH" default:"/home/update-wan-vip.sh"`
-	VYOSScriptPath    string        `envconfig:"VYOS_SCRIPT_PATH" default:"/config/scripts/vyos-postconfig-bootup.script"`
-	WANIPBase         string        `envconfig:"WAN_IP_BASE" default:"172.16."`
-	Nodes             []string      // Parsed from NodesStr
-	APIToken          string        // Computed from TokenID and TokenSecret
+	Host                 string        `envconfig:"PROXMOX_HOST" required:"true"`
+	FailoverHostsStr     string        `envconfig:"PROXMOX_FAILOVER_HOSTS"`
+	Port                 string        `envconfig:"PROXMOX_PORT" default:"8006"`
+	TokenID              string        `envconfig:"PROXMOX_TOKEN_ID" required:"true"`
+	TokenSecret          string        `envconfig:"PROXMOX_TOKEN_SECRET" required:"true"`
+	SecondaryTokenID     string        `envconfig:"PROXMOX_SECONDARY_TOKEN_ID"` // Fallback token used when Proxmox rejects the primary one
+	SecondaryTokenSecret string        `envconfig:"PROXMOX_SECONDARY_TOKEN_SECRET"`
+	VerifySSL            bool          `envconfig:"PROXMOX_VERIFY_SSL" default:"false"`
+	HTTPTimeout          time.Duration `envconfig:"PROXMOX_HTTP_TIMEOUT" default:"30s"`
+	MaxIdleConns         int           `envconfig:"PROXMOX_MAX_IDLE_CONNS" default:"32"` // Idle API connections kept open per host
+	RetryAttempts        int           `envconfig:"PROXMOX_RETRY_ATTEMPTS" default:"3"`  // Tries per request on transient errors, 1 disables retries
+	RetryBaseDelay       time.Duration `envconfig:"PROXMOX_RETRY_BASE_DELAY" default:"500ms"`
+	RetryMaxDelay        time.Duration `envconfig:"PROXMOX_RETRY_MAX_DELAY" default:"5s"`
+	BreakerThreshold     int           `envconfig:"PROXMOX_BREAKER_THRESHOLD" default:"5"` // Unreachable requests in a row before failing fast, 0 disables
+	BreakerCooldown      time.Duration `envconfig:"PROXMOX_BREAKER_COOLDOWN" default:"30s"`
+	ResourceCacheTTL     time.Duration `envconfig:"PROXMOX_RESOURCE_CACHE_TTL" default:"5s"` // How long cluster resource listings are reused, 0 disables
+	CriticalPool         string        `envconfig:"PROXMOX_CRITICAL_POOL"`
+	Realm                string        `envconfig:"PROXMOX_REALM"`
+	NodesStr             string        `envconfig:"PROXMOX_NODES"`
+	StorageID            string        `envconfig:"PROXMOX_STORAGE_ID" default:"local-lvm"`
+	CreatorGroupName     string        `envconfig:"PROXMOX_CREATOR_GROUP_NAME" default:"Creator"`
+	VMTemplatePool       string        `envconfig:"PROXMOX_VM_TEMPLATE_POOL" default:"Templates"`
+	RouterName           string        `envconfig:"PROXMOX_ROUTER_NAME" default:"1-1NAT-vyos"`
+	RouterNode           string        `envconfig:"PROXMOX_ROUTER_NODE"`
+	RouterVMID           int           `envconfig:"PROXMOX_ROUTER_VMID"`
+	RouterWaitTimeout    time.Duration `envconfig:"ROUTER_WAIT_TIMEOUT" default:"120s"`
+	WANScriptPath        string        `envconfig:"WAN_SCRIPT_PATH" default:"/home/update-wan-ip.sh"`
+	VIPScriptPath        string        `envconfig:"VIP_SCRIPT_PATH" default:"/home/update-wan-vip.sh"`
+	VYOSScriptPath       string        `envconfig:"VYOS_SCRIPT_PATH" default:"/config/scripts/vyos-postconfig-bootup.script"`
+	ForwardScriptPath    string        `envconfig:"FORWARD_SCRIPT_PATH" default:"/home/update-port-forward.sh"`
+	LANScriptPath        string        `envconfig:"LAN_SCRIPT_PATH" default:"/home/update-lan.sh"`
+	SegmentScriptPath    string        `envconfig:"SEGMENT_SCRIPT_PATH" default:"/home/update-segment.sh"`
+	WANIPBase            string        `envconfig:"WAN_IP_BASE" default:"172.16."`
+	WANPrefixLength      int           `envconfig:"WAN_PREFIX_LENGTH" default:"16"`     // Prefix of router WAN addresses written to cloud-init
+	WANGateway           string        `envconfig:"WAN_GATEWAY"`                        // Default gateway written to cloud-init, empty writes none
+	AgentExecEnabled     bool          `envconfig:"AGENT_EXEC_ENABLED" default:"false"` // Lets admins run commands in VMs through the guest agent
+	Nodes                []string      // Parsed from NodesStr
+	FailoverHosts        []string      // Parsed from FailoverHostsStr
+	APIToken             string        // Computed from TokenID and TokenSecret
 }
 
 // Service interface defines the methods for Proxmox operations
@@ -38,51 +60,129 @@ type Service interface {
 	GetClusterResourceUsage() (*ClusterResourceUsageResponse, error)
 	GetClusterResources(getParams string) ([]VirtualResource, error)
 	GetNodeStatus(nodeName string) (*ProxmoxNodeStatus, error)
+	GetNodeStorages(nodeName string) ([]NodeStorage, error)
 	FindBestNode() (string, error)
+	FindBestNodeIn(candidates []string) (string, error)
 	SyncUsers() error
 	SyncGroups() error
+	WaitForAgent(ctx context.Context, node string, vmID int, timeout time.Duration) error
+	AgentFileWrite(node string, vmID int, path string, content []byte) error
+	AgentFileRead(node string, vmID int, path string) ([]byte, bool, error)
+	AgentRunCommand(node string, vmID int, command []string, timeout time.Duration) (*AgentExecResult, error)
+	AgentPing(node string, vmID int) error
+	AgentNetworkInterfaces(node string, vmID int) ([]AgentNetworkInterface, error)
+	AgentGetUsers(node string, vmID int) ([]AgentUser, error)
+	AgentExec(node string, vmID int, command []string) error
+	AgentExecOutput(node string, vmID int, command []string) (string, error)
+	CreateConsoleTicket(vm VirtualResource, consoleType string) (*ConsoleTicket, error)
+	DialConsole(ctx context.Context, vm VirtualResource, port string, ticket string) (*websocket.Conn, error)
+	CreateSPICEConfig(vm VirtualResource) (map[string]any, error)
+	GetACLs() ([]ACLEntry, error)
+	GetAPITokens() ([]APIToken, error)
 
 	// Pod Management
 	GetNextPodIDs(minPodID int, maxPodID int, num int) ([]string, []int, error)
+	GetUsedPodIDs(minPodID int, maxPodID int) ([]int, error)
 
 	// VM Management
 	GetVMs() ([]VirtualResource, error)
 	GetVMTemplates() ([]VirtualResource, error)
 	GetNextVMIDs(num int) ([]int, error)
+	GetNextVMIDsInRange(minVMID int, maxVMID int, num int) ([]int, error)
 	StartVM(node string, vmID int) error
 	ShutdownVM(node string, vmID int) error
 	RebootVM(node string, vmID int) error
+	HibernateVM(node string, vmID int) error
+	ResumeVM(node string, vmID int) (bool, error)
 	StopVM(node string, vmID int) error
+	BulkVMAction(action string, targets []VMActionTarget) []VMActionResult
 	DeleteVM(node string, vmID int) error
+	MigrateVM(node string, vmID int, target string, online bool) (string, error)
+	ValidateMigration(node string, vmID int, target string, online bool) error
+	ResizeVM(node string, vmID int, resize VMResize) error
 	GetVMSnapshots(node string, vmID int) ([]VMSnapshot, error)
+	GetVMConfigValues(node string, vmID int) (map[string]any, error)
+	GetVMMetrics(vm VirtualResource, timeframe string) ([]VMMetricPoint, error)
 	DeleteVMSnapshot(node string, vmID int, snapshotName string) error
+	CreateVMSnapshot(node string, vmID int, snapshotName string, description string) (string, error)
+	RollbackVMSnapshot(node string, vmID int, snapshotName string) (string, error)
 	ConvertVMToTemplate(node string, vmID int) error
-	CloneVM(req VMCloneRequest) error
-	WaitForDisk(node string, vmID int, maxWait time.Duration) error
-	WaitForLock(node string, vmID int) error
-	WaitForRunning(node string, vmID int) error
-	WaitForStopped(node string, vmID int) error
+	CloneVM(ctx context.Context, req VMCloneRequest) (string, error)
+	WaitForDisk(ctx context.Context, node string, vmID int, maxWait time.Duration) error
+	WaitForLock(ctx context.Context, node string, vmID int) error
+	WaitForRunning(ctx context.Context, node string, vmID int) error
+	WaitForStopped(ctx context.Context, node string, vmID int) error
+	GetActiveCloneCount(node string) (int, error)
+	GetTaskStatus(node string, upid string) (*Task, error)
+	WaitForTask(ctx context.Context, node string, upid string, timeout time.Duration) error
+	TrackTask(ctx context.Context, upid string, timeout time.Duration) error
+	GetRecentTasks(filter TaskFilter) ([]Task, error)
+	GetTaskLog(upid string, start int, limit int) ([]TaskLogLine, error)
+	BackupVMToDir(node string, vmID int, dumpDir string) (string, error)
+	BackupVMToStorage(node string, vmID int, storage string) (string, error)
+	BackupVMWithNotes(node string, vmID int, storage string, notes string) (string, error)
+	GetBackups(node string, storage string, vmID int) ([]Backup, error)
+	GetLatestBackup(node string, storage string, vmID int) (string, error)
+	RestoreVM(node string, vmID int, archive string, poolName string, overwrite bool) (string, error)
+	DeleteBackup(node string, storage string, volID string) error
+
+	// High Availability
+	GetHAGroups() ([]HAGroup, error)
+	GetHAResources() ([]HAResource, error)
+	AddHAResource(sid string, group string, state string, comment string) error
+	RemoveHAResource(sid string) error
 
 	// Pool Management
 	GetPoolVMs(poolName string) ([]VirtualResource, error)
 	CreateNewPool(poolName string) error
 	SetPoolPermission(poolName string, targetName string, isGroup bool) error
+	RemovePoolPermission(poolName string, targetName string, isGroup bool) error
+	MoveVMsToPool(poolName string, vmIDs []int) error
+	SetPoolUserRoles(poolName string, username string, roles []string) error
+	RemovePoolUserRoles(poolName string, username string, roles []string) error
+	GetPools() ([]string, error)
 	DeletePool(poolName string) error
 	IsPoolEmpty(poolName string) (bool, error)
-	WaitForPoolEmpty(poolName string, timeout time.Duration) error
+	WaitForPoolEmpty(ctx context.Context, poolName string, timeout time.Duration) error
+	WaitForVMRemoved(ctx context.Context, poolName string, vmID int, timeout time.Duration) error
 
 	// Template Management
 	GetTemplatePools() ([]string, error)
 
 	// Networking
 	GetRouterType(router VM) (string, error)
-	ConfigurePodRouter(podNumber int, node string, vmid int, routerType string) error
+	ConfigurePodRouter(ctx context.Context, podNumber int, node string, vmid int, routerType string, lan RouterLAN) error
+	CheckPodRouter(podNumber int, node string, vmid int, routerType string) error
+	CheckPodRouterNAT(podNumber int, node string, vmid int, routerType string) error
+	AddRouterPortForward(podNumber int, node string, vmid int, routerType string, forward PortForward) error
+	RemoveRouterPortForward(podNumber int, node string, vmid int, routerType string, forward PortForward) error
 	SetPodVnet(poolName string, vnetName string, routerVMID int) error
+	SetGuestBridge(vm VirtualResource, nic string, bridge string) error
+	SetGuestNICRate(vm VirtualResource, nic string, rate float64) error
+	PodRouterWANIP(podNumber int) string
+	PodWANAddress(podNumber int, host int) string
+	PodWANSubnet(podNumber int) string
 	GetUsedVNets() ([]VNet, error)
+	CreateVNet(name string, zone string, tag int, alias string) error
+	DeleteVNet(name string) error
+	ApplySDN(ctx context.Context, timeout time.Duration) error
+	HasCloudInit(node string, vmid int) (bool, error)
+	SetCloudInit(node string, vmid int, config CloudInitConfig) error
+	PodRouterIPConfigs(podNumber int, lan RouterLAN) (map[int]string, error)
+	SetGuestFirewallPolicy(vm VirtualResource, policyIn string, policyOut string) error
+	GetGuestFirewallRules(vm VirtualResource) ([]FirewallRule, error)
+	AddGuestFirewallRule(vm VirtualResource, rule FirewallRule) error
+	DeleteGuestFirewallRule(vm VirtualResource, pos int) error
 	CreateTemplatePool(creator string, name string, addRouter bool, vms []VM) error
 
 	// Internal access for router functionality
 	GetRequestHelper() *tools.ProxmoxRequestHelper
+	GetEndpointHealth() []tools.ProxmoxEndpoint
+	GetBreakerState() tools.BreakerState
+	GetResourceCacheStats() ResourceCacheStats
+	InvalidateClusterResources()
+	GetTokenStatus() tools.TokenStatus
+	RotateAPIToken(ctx context.Context, tokenID string, tokenSecret string) error
 }
 
 // ProxmoxService implements the Service interface for Proxmox operations
@@ -91,6 +191,34 @@ type ProxmoxService struct {
 	HTTPClient    *http.Client
 	BaseURL       string
 	RequestHelper *tools.ProxmoxRequestHelper
+	resources     *resourceCache
+}
+
+// resourceCache holds recent /cluster/resources listings by query so the lookups a clone
+// makes in quick succession share one request
+type resourceCache struct {
+	mutex         sync.Mutex
+	ttl           time.Duration
+	entries       map[string]resourceEntry
+	invalidatedAt time.Time
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+type resourceEntry struct {
+	resources []VirtualResource
+	expiresAt time.Time
+}
+
+// ResourceCacheStats reports how effective the cluster resource cache is
+type ResourceCacheStats struct {
+	TTLSeconds    float64 `json:"ttl_seconds"`
+	Entries       int     `json:"entries"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Invalidations uint64  `json:"invalidations"`
+	HitRatio      float64 `json:"hit_ratio"`
 }
 
 type ProxmoxNode struct {
@@ -123,10 +251,17 @@ type VNetResponse []struct {
 	VNet string `json:"vnet"`
 }
 
+// Guest types of pool members
+const (
+	GuestTypeQEMU = "qemu"
+	GuestTypeLXC  = "lxc"
+)
+
 type VM struct {
 	Name string `json:"name,omitempty"`
 	Node string `json:"node"`
 	VMID int    `json:"vmid"`
+	Type string `json:"type,omitempty"` // qemu or lxc, empty is treated as qemu
 }
 
 type VMCloneRequest struct {
@@ -136,10 +271,94 @@ type VMCloneRequest struct {
 	NewVMID    int
 	Full       int
 	TargetNode string
+	Storage    string // Storage of a full clone's disks, empty keeps the source's storage
+}
+
+// NodeStorage is a storage as listed for one node
+type NodeStorage struct {
+	Storage string `json:"storage"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Active  int    `json:"active"`
+	Shared  int    `json:"shared"`
+	Avail   int64  `json:"avail"`
+	Total   int64  `json:"total"`
+	Used    int64  `json:"used"`
+}
+
+// HAGroup is a set of nodes the HA manager may run and recover guests on
+type HAGroup struct {
+	Group      string `json:"group"`
+	Nodes      string `json:"nodes"` // Comma separated, each optionally with a :priority
+	Restricted int    `json:"restricted"`
+	Comment    string `json:"comment"`
+}
+
+// HAResource is a guest managed by the HA manager
+type HAResource struct {
+	SID     string `json:"sid"` // vm:<vmid> or ct:<vmid>
+	Group   string `json:"group"`
+	State   string `json:"state"`
+	Comment string `json:"comment"`
+}
+
+// Backup is a vzdump backup archive on a storage
+type Backup struct {
+	VolID   string `json:"volid"`
+	VMID    int    `json:"vmid"`
+	Subtype string `json:"subtype"` // qemu or lxc
+	Format  string `json:"format"`
+	Notes   string `json:"notes"`
+	Size    int64  `json:"size"`
+	CTime   int64  `json:"ctime"`
+}
+
+// VMActionTarget is a VM or container a bulk power action runs on
+type VMActionTarget struct {
+	Node string `json:"node"`
+	VMID int    `json:"vmid"`
+}
+
+// VMActionResult is the outcome of a bulk power action on one VM
+type VMActionResult struct {
+	Node    string `json:"node"`
+	VMID    int    `json:"vmid"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// VMResize is a change of the hardware of a VM or container, zero values are left alone
+type VMResize struct {
+	Cores    int            `json:"cores,omitempty"`
+	MemoryMB int            `json:"memory_mb,omitempty"`
+	Disks    map[string]int `json:"disks,omitempty"` // New size in GiB of disks such as scsi0 or rootfs
+}
+
+// MetricTimeframes are the history lengths Proxmox keeps metrics for
+var MetricTimeframes = []string{"hour", "day", "week", "month", "year"}
+
+// VMMetricPoint is one averaged sample of a VM's usage, the fields are missing for
+// intervals the VM was not running
+type VMMetricPoint struct {
+	Time      int64   `json:"time"` // Unix time at the start of the interval
+	CPU       float64 `json:"cpu,omitempty"`
+	MaxCPU    float64 `json:"maxcpu,omitempty"`
+	Mem       float64 `json:"mem,omitempty"`
+	MaxMem    float64 `json:"maxmem,omitempty"`
+	Disk      float64 `json:"disk,omitempty"`
+	MaxDisk   float64 `json:"maxdisk,omitempty"`
+	DiskRead  float64 `json:"diskread,omitempty"`  // Bytes per second
+	DiskWrite float64 `json:"diskwrite,omitempty"` // Bytes per second
+	NetIn     float64 `json:"netin,omitempty"`     // Bytes per second
+	NetOut    float64 `json:"netout,omitempty"`    // Bytes per second
 }
 
 type VMSnapshot struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parent      string `json:"parent,omitempty"`
+	SnapTime    int64  `json:"snaptime,omitempty"` // Unix time the snapshot was taken
+	VMState     int    `json:"vmstate,omitempty"`  // 1 when the snapshot includes the RAM of a running VM
 }
 
 type VirtualResource struct {
@@ -159,6 +378,12 @@ type VirtualResource struct {
 	Disk          int64   `json:"disk,omitempty"`
 	MaxDisk       int64   `json:"maxdisk,omitempty"`
 	Template      int     `json:"template,omitempty"`
+	Lock          string  `json:"lock,omitempty"`
+}
+
+// IsGuest reports whether the resource is a qemu VM or an lxc container
+func (r VirtualResource) IsGuest() bool {
+	return r.Type == GuestTypeQEMU || r.Type == GuestTypeLXC
 }
 
 type ResourceUsage struct {
@@ -185,11 +410,91 @@ type PendingDiskResponse struct {
 	Size int64 `json:"size"`
 }
 
+// AgentNetworkInterface is a guest network interface as reported by the qemu guest agent
+type AgentNetworkInterface struct {
+	Name            string `json:"name"`
+	HardwareAddress string `json:"hardware-address"`
+	IPAddresses     []struct {
+		Address string `json:"ip-address"`
+		Type    string `json:"ip-address-type"`
+		Prefix  int    `json:"prefix"`
+	} `json:"ip-addresses"`
+}
+
+// AgentUser is a user logged in to the guest as reported by the qemu guest agent
+type AgentUser struct {
+	User      string  `json:"user"`
+	Domain    string  `json:"domain,omitempty"`
+	LoginTime float64 `json:"login-time"` // Seconds since the epoch
+}
+
+// AgentExecResult is the outcome of a command run through the qemu guest agent
+type AgentExecResult struct {
+	PID       int    `json:"pid"`
+	ExitCode  int    `json:"exit_code"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	Truncated bool   `json:"truncated"` // The agent cut the output short
+}
+
 type VNet struct {
 	Name string `json:"vnet"`
+	Zone string `json:"zone"`
 	Tag  int    `json:"tag"`
 }
 
+// ConsoleTicket grants a connection to the console proxy of a guest
+type ConsoleTicket struct {
+	Type   string `json:"type"` // vnc or xterm
+	Port   string `json:"port"`
+	Ticket string `json:"ticket"`
+	User   string `json:"user"` // Sent with the ticket to log in to xterm consoles
+}
+
+// RouterLAN is the internal network layout a pod router is configured for
+type RouterLAN struct {
+	Subnets  []string        // Subnets of the pod LAN on net1, empty keeps the LAN of the router image
+	Segments []RouterSegment // Extra internal networks on other router NICs
+}
+
+// CloudInitConfig is written to the cloud-init drive of a VM, empty fields are left alone
+type CloudInitConfig struct {
+	User      string
+	Password  string
+	SSHKeys   string         // Authorized keys, one per line
+	IPConfigs map[int]string // ipconfigN values by NIC index, such as ip=10.0.0.1/24
+}
+
+// RouterSegment is an extra internal network attached to a router NIC
+type RouterSegment struct {
+	NIC    int    // Index of the router NIC, such as 2 for net2
+	Subnet string // The router takes the first address
+}
+
+// PortForward forwards a port of a pod's WAN address to a VM inside the pod
+type PortForward struct {
+	ID           int64
+	Slot         int    // Numbers the forward among those of its pod, zero for forwards added before slots
+	Protocol     string // tcp or udp
+	ExternalPort int
+	InternalIP   string
+	InternalPort int
+}
+
+// FirewallRule is a rule of a guest's Proxmox firewall
+type FirewallRule struct {
+	Pos     int    `json:"pos"`
+	Type    string `json:"type"`   // in or out
+	Action  string `json:"action"` // ACCEPT, DROP or REJECT
+	Proto   string `json:"proto,omitempty"`
+	DPort   string `json:"dport,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Dest    string `json:"dest,omitempty"`
+	Iface   string `json:"iface,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Enable  int    `json:"enable"`
+}
+
 type Task struct {
 	ID         string `json:"id"`
 	Node       string `json:"node"`
@@ -203,3 +508,44 @@ type Task struct {
 	Status     string `json:"status"`
 	ExitStatus string `json:"exitstatus"`
 }
+
+// TaskFilter narrows the tasks GetRecentTasks returns, zero values match every task
+type TaskFilter struct {
+	Type       string // Such as qmclone, qmdestroy, or qmigrate
+	Node       string
+	ErrorsOnly bool // Only finished tasks that did not end OK
+	AllUsers   bool // Include tasks not started by this service
+	Limit      int
+}
+
+// TaskLogLine is a numbered line of a task log
+type TaskLogLine struct {
+	N int    `json:"n"`
+	T string `json:"t"`
+}
+
+// TaskError is returned when a Proxmox task stopped with an error
+type TaskError struct {
+	UPID       string
+	ExitStatus string
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %s failed: %s", e.UPID, e.ExitStatus)
+}
+
+type ACLEntry struct {
+	Path      string `json:"path"`
+	RoleID    string `json:"roleid"`
+	Type      string `json:"type"` // user, group or token
+	UGID      string `json:"ugid"`
+	Propagate int    `json:"propagate"`
+}
+
+type APIToken struct {
+	UserID  string `json:"userid"`
+	TokenID string `json:"tokenid"`
+	Comment string `json:"comment,omitempty"`
+	Expire  int64  `json:"expire"`
+	PrivSep int    `json:"privsep"`
+}
@@ -1,15 +1,23 @@
 package proxmox
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cpp-cyber/proclone/internal/tools"
 )
 
+// bulkActionConcurrency is the number of power actions BulkVMAction sends at once
+const bulkActionConcurrency = 8
+
 // =================================================
 // Public Functions
 // =================================================
@@ -54,17 +62,253 @@ func (s *ProxmoxService) RebootVM(node string, vmID int) error {
 	return s.vmAction("reboot", node, vmID)
 }
 
+// BulkVMAction runs a power action (start, shutdown, stop or reboot) on every target at
+// once, up to bulkActionConcurrency at a time, and returns a result per target in the
+// order they were given
+func (s *ProxmoxService) BulkVMAction(action string, targets []VMActionTarget) []VMActionResult {
+	results := make([]VMActionResult, len(targets))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, bulkActionConcurrency)
+
+	for i, target := range targets {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, target VMActionTarget) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result := VMActionResult{Node: target.Node, VMID: target.VMID, Success: true}
+			if err := s.vmAction(action, target.Node, target.VMID); err != nil {
+				result.Success = false
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, target)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// MigrateVM moves a VM to the target node and returns the UPID of the migration task.
+// Running VMs are migrated live, local disks move with the VM.
+func (s *ProxmoxService) MigrateVM(node string, vmID int, target string, online bool) (string, error) {
+	defer s.resources.invalidate()
+
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]any{
+		"target": target,
+	}
+	if guestType == GuestTypeLXC {
+		// Containers cannot move live, a running one is restarted on the target
+		if online {
+			body["restart"] = 1
+		}
+	} else {
+		body["with-local-disks"] = 1
+		if online {
+			body["online"] = 1
+		}
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    guestEndpoint(guestType, node, vmID, "/migrate"),
+		RequestBody: body,
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to start migration of VMID %d to %s: %w", vmID, target, err)
+	}
+
+	return upid, nil
+}
+
+// ResizeVM changes the cores and memory of a VM or container and grows its disks. Disks
+// can only grow, and VMs in the critical pool are refused. Every disk is checked before
+// anything is changed.
+func (s *ProxmoxService) ResizeVM(node string, vmID int, resize VMResize) error {
+	defer s.resources.invalidate()
+
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
+		return err
+	}
+
+	var grow []string
+	if len(resize.Disks) > 0 {
+		config, err := s.GetVMConfigValues(node, vmID)
+		if err != nil {
+			return err
+		}
+
+		for _, disk := range slices.Sorted(maps.Keys(resize.Disks)) {
+			setting, ok := config[disk].(string)
+			if !ok || strings.Contains(setting, "media=cdrom") {
+				return fmt.Errorf("VMID %d has no disk %s", vmID, disk)
+			}
+			current, ok := diskSizeBytes(setting)
+			if !ok {
+				return fmt.Errorf("failed to read the size of disk %s of VMID %d", disk, vmID)
+			}
+
+			size := int64(resize.Disks[disk]) << 30
+			if size < current {
+				return fmt.Errorf("disk %s of VMID %d is %d bytes, disks cannot shrink", disk, vmID, current)
+			}
+			if size > current {
+				grow = append(grow, disk)
+			}
+		}
+	}
+
+	body := map[string]any{}
+	if resize.Cores > 0 {
+		body["cores"] = resize.Cores
+	}
+	if resize.MemoryMB > 0 {
+		body["memory"] = resize.MemoryMB
+	}
+	if len(body) > 0 {
+		req := tools.ProxmoxAPIRequest{
+			Method:      "PUT",
+			Endpoint:    guestEndpoint(guestType, node, vmID, "/config"),
+			RequestBody: body,
+		}
+		if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+			return fmt.Errorf("failed to set cores and memory of VMID %d: %w", vmID, err)
+		}
+	}
+
+	for _, disk := range grow {
+		req := tools.ProxmoxAPIRequest{
+			Method:   "PUT",
+			Endpoint: guestEndpoint(guestType, node, vmID, "/resize"),
+			RequestBody: map[string]any{
+				"disk": disk,
+				"size": fmt.Sprintf("%dG", resize.Disks[disk]),
+			},
+		}
+		if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+			return fmt.Errorf("failed to grow disk %s of VMID %d: %w", disk, vmID, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateMigration checks that a VM can be migrated from its node to the target, which
+// must be another online node of the cluster, and that a running VM is migrated online
+func (s *ProxmoxService) ValidateMigration(node string, vmID int, target string, online bool) error {
+	if target == node {
+		return fmt.Errorf("VMID %d is already on node %s", vmID, target)
+	}
+	if len(s.Config.Nodes) > 0 && !slices.Contains(s.Config.Nodes, target) {
+		return fmt.Errorf("node %s is not one of the configured nodes", target)
+	}
+
+	nodes, err := s.GetClusterResources("type=node")
+	if err != nil {
+		return err
+	}
+	targetIndex := slices.IndexFunc(nodes, func(r VirtualResource) bool { return r.NodeName == target })
+	if targetIndex < 0 {
+		return fmt.Errorf("node %s is not part of the cluster", target)
+	}
+	if nodes[targetIndex].RunningStatus != "online" {
+		return fmt.Errorf("node %s is %s", target, nodes[targetIndex].RunningStatus)
+	}
+
+	vms, err := s.GetClusterResources("type=vm")
+	if err != nil {
+		return err
+	}
+	vmIndex := slices.IndexFunc(vms, func(r VirtualResource) bool { return r.VmId == vmID })
+	if vmIndex < 0 {
+		return fmt.Errorf("VMID %d not found", vmID)
+	}
+	vm := vms[vmIndex]
+	if vm.NodeName != node {
+		return fmt.Errorf("VMID %d is on node %s, not %s", vmID, vm.NodeName, node)
+	}
+	if vm.Lock != "" {
+		return fmt.Errorf("VMID %d is locked (%s)", vmID, vm.Lock)
+	}
+	if vm.RunningStatus == "running" && !online {
+		return fmt.Errorf("VMID %d is running, migrate it online or shut it down first", vmID)
+	}
+
+	return nil
+}
+
+// HibernateVM suspends the VM to disk, saving its RAM to a state file so it stops
+// consuming memory on the node while keeping the in-guest state
+func (s *ProxmoxService) HibernateVM(node string, vmID int) error {
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
+		return err
+	}
+	if guestType == GuestTypeLXC {
+		return fmt.Errorf("containers cannot be hibernated")
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/status/suspend", node, vmID),
+		RequestBody: map[string]any{"todisk": true},
+	}
+
+	if _, err := s.RequestHelper.MakeRequest(req); err != nil {
+		return fmt.Errorf("failed to hibernate VM: %w", err)
+	}
+
+	return nil
+}
+
+// ResumeVM resumes a VM suspended to disk or paused in memory and reports whether the
+// VM was suspended. A hibernated VM is stopped with a suspended lock and resumes from
+// its saved state when started.
+func (s *ProxmoxService) ResumeVM(node string, vmID int) (bool, error) {
+	status, err := s.getVMStatus(context.Background(), node, vmID)
+	if err != nil {
+		return false, err
+	}
+
+	if status == "paused" {
+		return true, s.vmAction("resume", node, vmID)
+	}
+
+	config, err := s.getVMConfig(context.Background(), node, vmID)
+	if err != nil {
+		return false, err
+	}
+	if status != "stopped" || config.Lock != "suspended" {
+		return false, nil
+	}
+
+	return true, s.vmAction("start", node, vmID)
+}
+
 func (s *ProxmoxService) DeleteVM(node string, vmID int) error {
-	if err := s.validateVMID(vmID); err != nil {
+	defer s.resources.invalidate()
+
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
 		return err
 	}
 
 	req := tools.ProxmoxAPIRequest{
 		Method:   "DELETE",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d", node, vmID),
+		Endpoint: guestEndpoint(guestType, node, vmID, ""),
 	}
 
-	_, err := s.RequestHelper.MakeRequest(req)
+	_, err = s.RequestHelper.MakeRequest(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete VM: %w", err)
 	}
@@ -75,7 +319,7 @@ func (s *ProxmoxService) DeleteVM(node string, vmID int) error {
 func (s *ProxmoxService) GetVMSnapshots(node string, vmID int) ([]VMSnapshot, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/snapshot", node, vmID),
+		Endpoint: guestEndpoint(s.guestType(vmID), node, vmID, "/snapshot"),
 	}
 
 	var snapshots []VMSnapshot
@@ -86,10 +330,45 @@ func (s *ProxmoxService) GetVMSnapshots(node string, vmID int) ([]VMSnapshot, er
 	return snapshots, nil
 }
 
+// GetVMMetrics returns the averaged CPU, memory, disk, and network history of a VM or
+// container over a timeframe of hour, day, week, month, or year
+func (s *ProxmoxService) GetVMMetrics(vm VirtualResource, timeframe string) ([]VMMetricPoint, error) {
+	if !slices.Contains(MetricTimeframes, timeframe) {
+		return nil, fmt.Errorf("invalid timeframe %q", timeframe)
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: guestEndpoint(vm.Type, vm.NodeName, vm.VmId, "/rrddata?cf=AVERAGE&timeframe="+timeframe),
+	}
+
+	var points []VMMetricPoint
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &points); err != nil {
+		return nil, fmt.Errorf("failed to get metrics for VMID %d on node %s: %w", vm.VmId, vm.NodeName, err)
+	}
+
+	return points, nil
+}
+
+// GetVMConfigValues returns every key of the VM's current configuration
+func (s *ProxmoxService) GetVMConfigValues(node string, vmID int) (map[string]any, error) {
+	req := tools.ProxmoxAPIRequest{
+		Method:   "GET",
+		Endpoint: guestEndpoint(s.guestType(vmID), node, vmID, "/config"),
+	}
+
+	var config map[string]any
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &config); err != nil {
+		return nil, fmt.Errorf("failed to get config for VMID %d on node %s: %w", vmID, node, err)
+	}
+
+	return config, nil
+}
+
 func (s *ProxmoxService) DeleteVMSnapshot(node string, vmID int, snapshotName string) error {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "DELETE",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/snapshot/%s", node, vmID, snapshotName),
+		Endpoint: guestEndpoint(s.guestType(vmID), node, vmID, "/snapshot/"+snapshotName),
 	}
 
 	_, err := s.RequestHelper.MakeRequest(req)
@@ -100,81 +379,98 @@ func (s *ProxmoxService) DeleteVMSnapshot(node string, vmID int, snapshotName st
 	return nil
 }
 
-func (s *ProxmoxService) ConvertVMToTemplate(node string, vmID int) error {
-	if err := s.validateVMID(vmID); err != nil {
-		return err
+// CreateVMSnapshot snapshots the disks of a VM or container and returns the UPID of the
+// snapshot task
+func (s *ProxmoxService) CreateVMSnapshot(node string, vmID int, snapshotName string, description string) (string, error) {
+	body := map[string]any{
+		"snapname": snapshotName,
+	}
+	if description != "" {
+		body["description"] = description
+	}
+
+	req := tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    guestEndpoint(s.guestType(vmID), node, vmID, "/snapshot"),
+		RequestBody: body,
+	}
+
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to create snapshot %s for VMID %d on node %s: %w", snapshotName, vmID, node, err)
 	}
 
+	return upid, nil
+}
+
+// RollbackVMSnapshot returns a VM or container to a snapshot and returns the UPID of the
+// rollback task. A snapshot without RAM state leaves the VM stopped.
+func (s *ProxmoxService) RollbackVMSnapshot(node string, vmID int, snapshotName string) (string, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "POST",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/template", node, vmID),
+		Endpoint: guestEndpoint(s.guestType(vmID), node, vmID, "/snapshot/"+snapshotName+"/rollback"),
 	}
 
-	_, err := s.RequestHelper.MakeRequest(req)
-	if err != nil {
-		if !strings.Contains(err.Error(), "you can't convert a template to a template") {
-			return fmt.Errorf("failed to convert VM to template: %w", err)
-		}
+	var upid string
+	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &upid); err != nil {
+		return "", fmt.Errorf("failed to roll back VMID %d on node %s to snapshot %s: %w", vmID, node, snapshotName, err)
 	}
 
-	return nil
+	return upid, nil
 }
 
-func (s *ProxmoxService) CloneVM(req VMCloneRequest) error {
-	// Clone VM
-	cloneBody := map[string]any{
-		"newid":  req.NewVMID,
-		"name":   req.SourceVM.Name,
-		"pool":   req.PoolName,
-		"full":   req.Full,
-		"target": req.TargetNode,
+func (s *ProxmoxService) ConvertVMToTemplate(node string, vmID int) error {
+	defer s.resources.invalidate()
+
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
+		return err
 	}
 
-	cloneReq := tools.ProxmoxAPIRequest{
-		Method:      "POST",
-		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/clone", req.SourceVM.Node, req.SourceVM.VMID),
-		RequestBody: cloneBody,
+	req := tools.ProxmoxAPIRequest{
+		Method:   "POST",
+		Endpoint: guestEndpoint(guestType, node, vmID, "/template"),
 	}
 
-	_, err := s.RequestHelper.MakeRequest(cloneReq)
+	_, err = s.RequestHelper.MakeRequest(req)
 	if err != nil {
-		return fmt.Errorf("failed to initiate VM clone: %w", err)
+		if !strings.Contains(err.Error(), "you can't convert a template to a template") {
+			return fmt.Errorf("failed to convert VM to template: %w", err)
+		}
 	}
 
 	return nil
 }
 
-func (s *ProxmoxService) cloneVMWithUPID(req VMCloneRequest) (string, error) {
-	// Clone VM
-	cloneBody := map[string]any{
-		"newid":  req.NewVMID,
-		"name":   req.SourceVM.Name,
-		"pool":   req.PoolName,
-		"full":   req.Full,
-		"target": req.TargetNode,
-	}
+// CloneVM submits a clone and returns the UPID of the clone task, which TrackTask
+// follows to completion
+func (s *ProxmoxService) CloneVM(ctx context.Context, req VMCloneRequest) (string, error) {
+	defer s.resources.invalidate()
 
-	cloneReq := tools.ProxmoxAPIRequest{
-		Method:      "POST",
-		Endpoint:    fmt.Sprintf("/nodes/%s/qemu/%d/clone", req.SourceVM.Node, req.SourceVM.VMID),
-		RequestBody: cloneBody,
+	cloneReq := s.cloneAPIRequest(req)
+
+	data, err := s.RequestHelper.MakeRequestWithContext(ctx, cloneReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to initiate VM clone: %w", err)
 	}
 
 	var upid string
-	if err := s.RequestHelper.MakeRequestAndUnmarshal(cloneReq, &upid); err != nil {
-		return "", fmt.Errorf("failed to initiate VM clone: %w", err)
+	if err := json.Unmarshal(data, &upid); err != nil {
+		return "", fmt.Errorf("failed to parse clone task UPID: %w", err)
 	}
 
 	return upid, nil
 }
 
-func (s *ProxmoxService) WaitForDisk(node string, vmID int, maxWait time.Duration) error {
+func (s *ProxmoxService) WaitForDisk(ctx context.Context, node string, vmID int, maxWait time.Duration) error {
 	start := time.Now()
 
 	for time.Since(start) < maxWait {
-		time.Sleep(2 * time.Second)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return err
+		}
 
-		configResp, err := s.getVMConfig(node, vmID)
+		configResp, err := s.getVMConfig(ctx, node, vmID)
 		if err != nil {
 			continue
 		}
@@ -182,17 +478,23 @@ func (s *ProxmoxService) WaitForDisk(node string, vmID int, maxWait time.Duratio
 		log.Printf("%+v", configResp)
 
 		if configResp.HardDisk != "" && configResp.Name != "" {
-			log.Printf("/nodes/%s/storage/%s/content?vmid=%d", s.Config.Nodes[0], s.Config.StorageID, vmID)
+			// The disk may have been cloned to a storage other than STORAGE_ID, which can be
+			// local to the VM's node
+			storage := s.Config.StorageID
+			if volume, _, found := strings.Cut(configResp.HardDisk, ":"); found {
+				storage = volume
+			}
+			log.Printf("/nodes/%s/storage/%s/content?vmid=%d", node, storage, vmID)
 
 			pendingReq := tools.ProxmoxAPIRequest{
 				Method:   "GET",
-				Endpoint: fmt.Sprintf("/nodes/%s/storage/%s/content?vmid=%d", s.Config.Nodes[0], s.Config.StorageID, vmID),
+				Endpoint: fmt.Sprintf("/nodes/%s/storage/%s/content?vmid=%d", node, storage, vmID),
 			}
 
 			log.Printf("%+v", pendingReq)
 
 			var diskResponse []PendingDiskResponse
-			err := s.RequestHelper.MakeRequestAndUnmarshal(pendingReq, &diskResponse)
+			err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, pendingReq, &diskResponse)
 			if err != nil || len(diskResponse) == 0 {
 				log.Printf("Error retrieving pending disk info for VMID %d on node %s: %v", vmID, node, err)
 				continue
@@ -218,12 +520,12 @@ func (s *ProxmoxService) WaitForDisk(node string, vmID int, maxWait time.Duratio
 	return fmt.Errorf("timeout waiting for VM disks to become available")
 }
 
-func (s *ProxmoxService) WaitForStopped(node string, vmID int) error {
-	return s.waitForStatus("stopped", node, vmID)
+func (s *ProxmoxService) WaitForStopped(ctx context.Context, node string, vmID int) error {
+	return s.waitForStatus(ctx, "stopped", node, vmID)
 }
 
-func (s *ProxmoxService) WaitForRunning(node string, vmID int) error {
-	return s.waitForStatus("running", node, vmID)
+func (s *ProxmoxService) WaitForRunning(ctx context.Context, node string, vmID int) error {
+	return s.waitForStatus(ctx, "running", node, vmID)
 }
 
 func (s *ProxmoxService) GetNextVMIDs(num int) ([]int, error) {
@@ -261,24 +563,54 @@ func (s *ProxmoxService) GetNextVMIDs(num int) ([]int, error) {
 	return vmIDs, nil
 }
 
-func (s *ProxmoxService) WaitForLock(node string, vmID int) error {
-	timeout := 1 * time.Minute
-	start := time.Now()
+// GetNextVMIDsInRange returns the lowest block of num consecutive unused VMIDs between
+// minVMID and maxVMID inclusive
+func (s *ProxmoxService) GetNextVMIDsInRange(minVMID int, maxVMID int, num int) ([]int, error) {
+	resources, err := s.GetClusterResources("type=vm")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster resources: %w", err)
+	}
 
-	for time.Since(start) < timeout {
-		config, err := s.getVMConfig(node, vmID)
-		if err != nil {
-			time.Sleep(5 * time.Second)
+	used := make(map[int]bool)
+	for _, vm := range resources {
+		used[vm.VmId] = true
+	}
+
+	start := minVMID
+	for vmID := minVMID; vmID <= maxVMID; vmID++ {
+		if used[vmID] {
+			start = vmID + 1
 			continue
 		}
+		if vmID-start+1 == num {
+			var vmIDs []int
+			for i := range num {
+				vmIDs = append(vmIDs, start+i)
+			}
+			return vmIDs, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no block of %d available VMIDs in range %d-%d", num, minVMID, maxVMID)
+}
 
-		log.Printf("VM %d lock status: '%s'", vmID, config.Lock)
+func (s *ProxmoxService) WaitForLock(ctx context.Context, node string, vmID int) error {
+	timeout := 1 * time.Minute
+	start := time.Now()
+
+	for time.Since(start) < timeout {
+		config, err := s.getVMConfig(ctx, node, vmID)
+		if err == nil {
+			log.Printf("VM %d lock status: '%s'", vmID, config.Lock)
 
-		if config.Lock == "" {
-			return nil // No lock
+			if config.Lock == "" {
+				return nil // No lock
+			}
 		}
 
-		time.Sleep(5 * time.Second)
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			return err
+		}
 	}
 
 	return fmt.Errorf("timeout waiting for VM lock to be cleared")
@@ -289,16 +621,19 @@ func (s *ProxmoxService) WaitForLock(node string, vmID int) error {
 // =================================================
 
 func (s *ProxmoxService) vmAction(action string, node string, vmID int) error {
-	if err := s.validateVMID(vmID); err != nil {
+	defer s.resources.invalidate()
+
+	guestType, err := s.validateGuest(vmID)
+	if err != nil {
 		return err
 	}
 
 	req := tools.ProxmoxAPIRequest{
 		Method:   "POST",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/status/%s", node, vmID, action),
+		Endpoint: guestEndpoint(guestType, node, vmID, "/status/"+action),
 	}
 
-	_, err := s.RequestHelper.MakeRequest(req)
+	_, err = s.RequestHelper.MakeRequest(req)
 	if err != nil {
 		return fmt.Errorf("failed to %s VM: %w", action, err)
 	}
@@ -306,70 +641,184 @@ func (s *ProxmoxService) vmAction(action string, node string, vmID int) error {
 	return nil
 }
 
-func (s *ProxmoxService) waitForStatus(targetStatus string, node string, vmID int) error {
+func (s *ProxmoxService) waitForStatus(ctx context.Context, targetStatus string, node string, vmID int) error {
 	timeout := 2 * time.Minute
 	start := time.Now()
 
 	for time.Since(start) < timeout {
-		currentStatus, err := s.getVMStatus(node, vmID)
-		if err != nil {
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		if currentStatus == targetStatus {
+		currentStatus, err := s.getVMStatus(ctx, node, vmID)
+		if err == nil && currentStatus == targetStatus {
 			return nil
 		}
 
-		time.Sleep(5 * time.Second)
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			return err
+		}
 	}
 
 	return fmt.Errorf("timeout waiting for VM to be %s", targetStatus)
 }
 
+// sleepContext sleeps for d, returning early with the context error if ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (s *ProxmoxService) validateVMID(vmID int) error {
-	// Get VMs
-	vms, err := s.GetClusterResources("type=vm")
+	_, err := s.validateGuest(vmID)
+	return err
+}
+
+// validateGuest checks the VMID exists outside the critical pool and returns whether it
+// is a qemu VM or an lxc container
+func (s *ProxmoxService) validateGuest(vmID int) (string, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		// Get VMs
+		vms, err := s.GetClusterResources("type=vm")
+		if err != nil {
+			return "", err
+		}
+
+		// Check if VMID exists
+		for _, vm := range vms {
+			if vm.VmId == vmID {
+				// Check if VM is in critical pool
+				if vm.ResourcePool == s.Config.CriticalPool {
+					return "", fmt.Errorf("VMID %d is in critical pool", vmID)
+				}
+				return vm.Type, nil
+			}
+		}
+
+		// A guest created outside the service since the listing was cached isn't in it yet
+		s.resources.invalidate()
+	}
+
+	return "", fmt.Errorf("VMID %d not found", vmID)
+}
+
+// guestType returns whether the VMID is a qemu VM or an lxc container, assuming qemu
+// when it cannot be found
+func (s *ProxmoxService) guestType(vmID int) string {
+	return s.guestTypeWithContext(context.Background(), vmID)
+}
+
+func (s *ProxmoxService) guestTypeWithContext(ctx context.Context, vmID int) string {
+	vms, err := s.getClusterResourcesWithContext(ctx, "type=vm")
 	if err != nil {
-		return err
+		return GuestTypeQEMU
 	}
 
-	// Check if VMID exists
 	for _, vm := range vms {
-		if vm.VmId == vmID {
-			// Check if VM is in critical pool
-			if vm.ResourcePool == s.Config.CriticalPool {
-				return fmt.Errorf("VMID %d is in critical pool", vmID)
-			}
-			return nil
+		if vm.VmId == vmID && vm.Type == GuestTypeLXC {
+			return GuestTypeLXC
+		}
+	}
+	return GuestTypeQEMU
+}
+
+// diskSizeBytes reads the size option of a disk setting such as local-lvm:vm-100-disk-0,size=32G
+func diskSizeBytes(setting string) (int64, bool) {
+	for _, option := range strings.Split(setting, ",") {
+		value, found := strings.CutPrefix(option, "size=")
+		if !found || value == "" {
+			continue
 		}
+
+		shift := 0
+		switch value[len(value)-1] {
+		case 'K':
+			shift = 10
+		case 'M':
+			shift = 20
+		case 'G':
+			shift = 30
+		case 'T':
+			shift = 40
+		}
+		if shift > 0 {
+			value = value[:len(value)-1]
+		}
+
+		size, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false
+		}
+		return int64(size * float64(int64(1)<<shift)), true
 	}
 
-	return fmt.Errorf("VMID %d not found", vmID)
+	return 0, false
+}
+
+// guestEndpoint builds the API path of a guest, containers live under lxc instead of qemu
+func guestEndpoint(guestType string, node string, vmID int, suffix string) string {
+	if guestType != GuestTypeLXC {
+		guestType = GuestTypeQEMU
+	}
+	return fmt.Sprintf("/nodes/%s/%s/%d%s", node, guestType, vmID, suffix)
+}
+
+// cloneAPIRequest builds the clone request of a VM or container, looking the guest type
+// up when the source does not carry it. Containers take a hostname instead of a name.
+func (s *ProxmoxService) cloneAPIRequest(req VMCloneRequest) tools.ProxmoxAPIRequest {
+	guestType := req.SourceVM.Type
+	if guestType == "" {
+		guestType = s.guestType(req.SourceVM.VMID)
+	}
+
+	body := map[string]any{
+		"newid":  req.NewVMID,
+		"pool":   req.PoolName,
+		"full":   req.Full,
+		"target": req.TargetNode,
+	}
+	// Proxmox only moves disks to another storage when it copies them
+	if req.Full == 1 && req.Storage != "" {
+		body["storage"] = req.Storage
+	}
+	if guestType == GuestTypeLXC {
+		body["hostname"] = req.SourceVM.Name
+	} else {
+		body["name"] = req.SourceVM.Name
+	}
+
+	return tools.ProxmoxAPIRequest{
+		Method:      "POST",
+		Endpoint:    guestEndpoint(guestType, req.SourceVM.Node, req.SourceVM.VMID, "/clone"),
+		RequestBody: body,
+	}
 }
 
-func (s *ProxmoxService) getVMConfig(node string, VMID int) (*VirtualResourceConfig, error) {
+func (s *ProxmoxService) getVMConfig(ctx context.Context, node string, VMID int) (*VirtualResourceConfig, error) {
 	configReq := tools.ProxmoxAPIRequest{
 		Method:   "GET",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/config", node, VMID),
+		Endpoint: guestEndpoint(s.guestTypeWithContext(ctx, VMID), node, VMID, "/config"),
 	}
 
 	var config VirtualResourceConfig
-	if err := s.RequestHelper.MakeRequestAndUnmarshal(configReq, &config); err != nil {
+	if err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, configReq, &config); err != nil {
 		return nil, fmt.Errorf("failed to get VM config: %w", err)
 	}
 
 	return &config, nil
 }
 
-func (s *ProxmoxService) getVMStatus(node string, VMID int) (string, error) {
+func (s *ProxmoxService) getVMStatus(ctx context.Context, node string, VMID int) (string, error) {
 	req := tools.ProxmoxAPIRequest{
 		Method:   "GET",
-		Endpoint: fmt.Sprintf("/nodes/%s/qemu/%d/status/current", node, VMID),
+		Endpoint: guestEndpoint(s.guestTypeWithContext(ctx, VMID), node, VMID, "/status/current"),
 	}
 
 	var response VirtualResourceStatus
-	if err := s.RequestHelper.MakeRequestAndUnmarshal(req, &response); err != nil {
+	if err := s.RequestHelper.MakeRequestAndUnmarshalWithContext(ctx, req, &response); err != nil {
 		return "", fmt.Errorf("failed to get VM status: %w", err)
 	}
 
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ErrClusterUnreachable is returned without contacting Proxmox while the circuit breaker
+// is open because recent requests could not reach any endpoint
+var ErrClusterUnreachable = errors.New("proxmox cluster unreachable")
+
+// BreakerPolicy controls when the circuit breaker opens. It opens after Threshold requests
+// in a row failed to reach any endpoint and lets a single probe through once Cooldown has
+// passed. A Threshold of 0 disables the breaker.
+type BreakerPolicy struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// BreakerState is a snapshot of the circuit breaker for health reporting
+type BreakerState struct {
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+	RetryAt             time.Time `json:"retry_at,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+type circuitBreaker struct {
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError string
+	probing   bool
+	mutex     sync.Mutex
+}
+
+// allow reports whether a request may be sent. Once the cooldown of an open breaker has
+// passed it moves to half-open and admits one probe, failing everything else until the
+// probe is recorded.
+func (b *circuitBreaker) allow(policy BreakerPolicy) bool {
+	if policy.Threshold <= 0 {
+		return true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < policy.Cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a request. Only failures to reach the
+// cluster count, an error answer from Proxmox shows it is up.
+func (b *circuitBreaker) record(policy BreakerPolicy, err error) {
+	if policy.Threshold <= 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.probing = false
+	if !isOutageError(err) {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == BreakerHalfOpen || b.failures >= policy.Threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release gives up a probe slot taken by allow without recording an outcome, used when
+// the caller's context ended before the request completed
+func (b *circuitBreaker) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.probing = false
+}
+
+func (b *circuitBreaker) snapshot(policy BreakerPolicy) BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state := BreakerState{
+		State:               cmp.Or(b.state, BreakerClosed),
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if state.State != BreakerClosed {
+		state.OpenedAt = b.openedAt
+		state.RetryAt = b.openedAt.Add(policy.Cooldown)
+	}
+	return state
+}
+
+// isOutageError reports whether a request failed because the cluster could not be
+// reached, as opposed to Proxmox answering with an error
+func isOutageError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *ProxmoxAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 595 || apiErr.StatusCode == 596
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
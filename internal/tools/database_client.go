@@ -51,8 +51,9 @@ func (c *DBClient) Connect() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Build the Data Source Name (DSN)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+	// Build the Data Source Name (DSN). Times are stored and read as UTC regardless of
+	// the server time zone so TIMESTAMP defaults and Go times agree.
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&loc=UTC&time_zone=%%27%%2B00%%3A00%%27",
 		c.config.User, c.config.Password, c.config.Host, c.config.Port, c.config.Name)
 
 	// Open database connection
@@ -127,8 +128,9 @@ func (c *DBClient) reconnect() error {
 	// Wait a moment before retrying
 	time.Sleep(100 * time.Millisecond)
 
-	// Build the Data Source Name (DSN)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+	// Build the Data Source Name (DSN). Times are stored and read as UTC regardless of
+	// the server time zone so TIMESTAMP defaults and Go times agree.
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&loc=UTC&time_zone=%%27%%2B00%%3A00%%27",
 		c.config.User, c.config.Password, c.config.Host, c.config.Port, c.config.Name)
 
 	// Open database connection
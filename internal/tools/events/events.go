@@ -0,0 +1,84 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published on the bus
+const (
+	PodCreated     = "pod_created"
+	PodDeleted     = "pod_deleted"
+	VMStateChanged = "vm_state_changed"
+)
+
+// Event describes a change to a pod or one of its VMs
+type Event struct {
+	Type           string    `json:"type"`
+	Pod            string    `json:"pod,omitempty"`
+	VMID           int       `json:"vmid,omitempty"`
+	Node           string    `json:"node,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Bus fans out published events to all current subscribers
+type Bus struct {
+	subscribers map[chan Event]struct{}
+	mutex       sync.RWMutex
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber and returns its event channel
+func (b *Bus) Subscribe() chan Event {
+	ch := make(chan Event, 64)
+
+	b.mutex.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mutex.Unlock()
+
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (b *Bus) Unsubscribe(ch chan Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends an event to every subscriber. Slow subscribers drop events
+// rather than blocking the publisher.
+func (b *Bus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (b *Bus) SubscriberCount() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subscribers)
+}
@@ -0,0 +1,315 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Job types
+const (
+	TypeClone     = "clone"
+	TypeArchive   = "archive"
+	TypeRehydrate = "rehydrate"
+	TypeRebalance = "rebalance"
+	TypeTemplate  = "template"
+	TypeBackup    = "backup"
+	TypeRestore   = "restore"
+)
+
+// finishedRetention is how long finished jobs remain visible before being pruned
+const finishedRetention = 24 * time.Hour
+
+// Job tracks the progress of a long-running operation
+type Job struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Owner       string    `json:"owner"`
+	Description string    `json:"description"`
+	Users       []string  `json:"users,omitempty"`  // Users the operation acts on behalf of
+	Groups      []string  `json:"groups,omitempty"` // Groups the operation acts on behalf of
+	Status      string    `json:"status"`
+	Progress    int       `json:"progress"`
+	Message     string    `json:"message,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	CancelledBy string    `json:"cancelled_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Cancellation is the cause of a cancelled job's context, describing who cancelled it
+// and how the operation should wind down
+type Cancellation struct {
+	By          string
+	KeepPartial bool // Keep the work completed so far instead of cleaning it up
+}
+
+func (c *Cancellation) Error() string {
+	return fmt.Sprintf("cancelled by %s", c.By)
+}
+
+// CancellationFrom returns the cancellation of a job context, or nil if the context
+// was not cancelled through Cancel
+func CancellationFrom(ctx context.Context) *Cancellation {
+	var cancellation *Cancellation
+	if errors.As(context.Cause(ctx), &cancellation) {
+		return cancellation
+	}
+	return nil
+}
+
+// Viewer identifies who is looking at jobs for visibility checks
+type Viewer struct {
+	Username      string
+	IsAdmin       bool
+	ManagedGroups []string
+}
+
+// CanView reports whether the viewer may see the job. Users see jobs they own or
+// that act on their behalf, group managers see jobs for their groups, and admins
+// see every job.
+func (v Viewer) CanView(job Job) bool {
+	if v.IsAdmin {
+		return true
+	}
+
+	if strings.EqualFold(job.Owner, v.Username) || containsFold(job.Users, v.Username) {
+		return true
+	}
+
+	for _, group := range v.ManagedGroups {
+		if containsFold(job.Groups, group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Registry keeps the jobs of this server in memory and notifies subscribers of changes
+type Registry struct {
+	jobs        map[string]*Job
+	cancels     map[string]context.CancelCauseFunc
+	subscribers map[chan Job]struct{}
+	mutex       sync.RWMutex
+}
+
+// NewRegistry creates an empty job registry
+func NewRegistry() *Registry {
+	return &Registry{
+		jobs:        make(map[string]*Job),
+		cancels:     make(map[string]context.CancelCauseFunc),
+		subscribers: make(map[chan Job]struct{}),
+	}
+}
+
+// Create registers a new running job and returns a copy of it
+func (r *Registry) Create(jobType string, owner string, description string, users []string, groups []string) Job {
+	now := time.Now()
+	job := &Job{
+		ID:          uuid.NewString(),
+		Type:        jobType,
+		Owner:       owner,
+		Description: description,
+		Users:       users,
+		Groups:      groups,
+		Status:      StatusRunning,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	r.mutex.Lock()
+	r.pruneLocked()
+	r.jobs[job.ID] = job
+	r.mutex.Unlock()
+
+	r.publish(*job)
+	return *job
+}
+
+// Update records the progress of a running job. Unknown job IDs are ignored.
+func (r *Registry) Update(id string, progress int, message string) {
+	r.modify(id, func(job *Job) {
+		job.Progress = progress
+		job.Message = message
+	})
+}
+
+// WithCancel returns a context derived from parent that is cancelled when Cancel is
+// called for the job. The context is released when the job finishes.
+func (r *Registry) WithCancel(parent context.Context, id string) context.Context {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job, ok := r.jobs[id]; !ok || job.Status != StatusRunning {
+		cancel(nil)
+		return ctx
+	}
+	r.cancels[id] = cancel
+	return ctx
+}
+
+// Cancel requests cancellation of a running job on behalf of a user. The job is marked
+// cancelled once the operation has stopped and called Finish.
+func (r *Registry) Cancel(id string, by string, keepPartial bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	if job.Status != StatusRunning {
+		return fmt.Errorf("job %s is not running (status: %s)", id, job.Status)
+	}
+
+	cancel, ok := r.cancels[id]
+	if !ok {
+		return fmt.Errorf("job %s cannot be cancelled", id)
+	}
+	job.CancelledBy = by
+	cancel(&Cancellation{By: by, KeepPartial: keepPartial})
+	return nil
+}
+
+// Finish marks a job completed, cancelled when err is a context cancellation, or
+// failed when err is any other error
+func (r *Registry) Finish(id string, err error) {
+	r.mutex.Lock()
+	if cancel, ok := r.cancels[id]; ok {
+		cancel(nil)
+		delete(r.cancels, id)
+	}
+	r.mutex.Unlock()
+
+	r.modify(id, func(job *Job) {
+		if errors.Is(err, context.Canceled) {
+			job.Status = StatusCancelled
+			job.Error = err.Error()
+			return
+		}
+		if err != nil {
+			job.Status = StatusFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = StatusCompleted
+		job.Progress = 100
+	})
+}
+
+// Get returns a copy of the job with the given ID
+func (r *Registry) Get(id string) (Job, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// List returns the jobs visible to the viewer, newest first
+func (r *Registry) List(viewer Viewer) []Job {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	jobs := []Job{}
+	for _, job := range r.jobs {
+		if viewer.CanView(*job) {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	slices.SortFunc(jobs, func(a, b Job) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return jobs
+}
+
+// Subscribe returns a channel receiving a copy of every job change
+func (r *Registry) Subscribe() chan Job {
+	ch := make(chan Job, 64)
+
+	r.mutex.Lock()
+	r.subscribers[ch] = struct{}{}
+	r.mutex.Unlock()
+
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (r *Registry) Unsubscribe(ch chan Job) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.subscribers[ch]; ok {
+		delete(r.subscribers, ch)
+		close(ch)
+	}
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (r *Registry) modify(id string, change func(job *Job)) {
+	r.mutex.Lock()
+	job, ok := r.jobs[id]
+	if !ok {
+		r.mutex.Unlock()
+		return
+	}
+	change(job)
+	job.UpdatedAt = time.Now()
+	updated := *job
+	r.mutex.Unlock()
+
+	r.publish(updated)
+}
+
+// publish sends a job change to every subscriber. Slow subscribers drop updates
+// rather than blocking the job.
+func (r *Registry) publish(job Job) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for ch := range r.subscribers {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
+
+// pruneLocked removes finished jobs older than the retention period. The caller must hold the lock.
+func (r *Registry) pruneLocked() {
+	for id, job := range r.jobs {
+		if job.Status != StatusRunning && time.Since(job.UpdatedAt) > finishedRetention {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
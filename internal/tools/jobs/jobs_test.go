@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestViewerCanView(t *testing.T) {
+	job := Job{ID: "job", Owner: "alice", Users: []string{"bob"}, Groups: []string{"blue-team"}}
+
+	tests := []struct {
+		name    string
+		viewer  Viewer
+		visible bool
+	}{
+		{name: "owner", viewer: Viewer{Username: "alice"}, visible: true},
+		{name: "owner with different case", viewer: Viewer{Username: "Alice"}, visible: true},
+		{name: "user acted for", viewer: Viewer{Username: "bob"}, visible: true},
+		{name: "manager of group", viewer: Viewer{Username: "carol", ManagedGroups: []string{"Blue-Team"}}, visible: true},
+		{name: "manager of other group", viewer: Viewer{Username: "carol", ManagedGroups: []string{"red-team"}}, visible: false},
+		{name: "admin", viewer: Viewer{Username: "root", IsAdmin: true}, visible: true},
+		{name: "stranger", viewer: Viewer{Username: "mallory"}, visible: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.viewer.CanView(job); got != tt.visible {
+				t.Errorf("CanView() = %t, want %t", got, tt.visible)
+			}
+		})
+	}
+}
+
+func TestRegistryListMatchesCanView(t *testing.T) {
+	registry := NewRegistry()
+	created := []Job{
+		registry.Create(TypeClone, "alice", "owned by alice", nil, nil),
+		registry.Create(TypeClone, "admin", "for bob", []string{"bob"}, nil),
+		registry.Create(TypeClone, "admin", "for blue-team", nil, []string{"blue-team"}),
+		registry.Create(TypeClone, "dave", "unrelated", nil, nil),
+	}
+
+	viewers := []Viewer{
+		{Username: "alice"},
+		{Username: "bob"},
+		{Username: "carol", ManagedGroups: []string{"blue-team"}},
+		{Username: "root", IsAdmin: true},
+		{Username: "mallory"},
+	}
+
+	for _, viewer := range viewers {
+		t.Run(viewer.Username, func(t *testing.T) {
+			listed := registry.List(viewer)
+			for _, job := range created {
+				inList := slices.ContainsFunc(listed, func(j Job) bool { return j.ID == job.ID })
+				if inList != viewer.CanView(job) {
+					t.Errorf("job %q listed = %t, CanView = %t", job.Description, inList, viewer.CanView(job))
+				}
+			}
+		})
+	}
+}
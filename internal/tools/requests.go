@@ -2,12 +2,22 @@ package tools
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 )
 
+// endpointCooldown is how long an endpoint is skipped after a connection failure
+const endpointCooldown = 30 * time.Second
+
 // ProxmoxAPIRequest represents a request to the Proxmox API
 type ProxmoxAPIRequest struct {
 	Method      string // GET, POST, PUT, DELETE
@@ -20,24 +30,195 @@ type ProxmoxAPIResponse struct {
 	Data json.RawMessage `json:"data"`
 }
 
+// ProxmoxAPIError is returned when Proxmox answers a request with a non-2xx status
+type ProxmoxAPIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Body       string
+}
+
+func (e *ProxmoxAPIError) Error() string {
+	return fmt.Sprintf("proxmox API returned status %d for %s %s, response: %s", e.StatusCode, e.Method, e.Endpoint, e.Body)
+}
+
+// RetryPolicy controls how requests that failed with a transient error are retried.
+// Attempts counts the first try, so 1 or less disables retries.
+type RetryPolicy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// ProxmoxEndpoint tracks the health of a single Proxmox API endpoint
+type ProxmoxEndpoint struct {
+	BaseURL     string    `json:"base_url"`
+	Healthy     bool      `json:"healthy"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"last_failure,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
 // ProxmoxRequestHelper provides a helper for making HTTP requests to Proxmox API
 type ProxmoxRequestHelper struct {
-	BaseURL    string
-	APIToken   string
+	BaseURL    string // Primary endpoint
 	HTTPClient *http.Client
+	Retry      RetryPolicy
+	Breaker    BreakerPolicy
+	breaker    circuitBreaker
+	tokens     tokenPair
+	endpoints  []*ProxmoxEndpoint
+	mutex      sync.Mutex
 }
 
-// NewProxmoxRequestHelper creates a new Proxmox request helper
-func NewProxmoxRequestHelper(baseURL, apiToken string, httpClient *http.Client) *ProxmoxRequestHelper {
+// NewProxmoxRequestHelper creates a new Proxmox request helper. The first base URL is
+// treated as the primary endpoint, with any others used for failover.
+func NewProxmoxRequestHelper(baseURLs []string, apiToken string, httpClient *http.Client) *ProxmoxRequestHelper {
+	endpoints := make([]*ProxmoxEndpoint, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		endpoints = append(endpoints, &ProxmoxEndpoint{BaseURL: baseURL, Healthy: true})
+	}
+
+	primary := ""
+	if len(baseURLs) > 0 {
+		primary = baseURLs[0]
+	}
+
 	return &ProxmoxRequestHelper{
-		BaseURL:    baseURL,
-		APIToken:   apiToken,
+		BaseURL:    primary,
 		HTTPClient: httpClient,
+		tokens:     tokenPair{active: apiToken},
+		endpoints:  endpoints,
+	}
+}
+
+// GetBreakerState returns a snapshot of the circuit breaker guarding the cluster
+func (prh *ProxmoxRequestHelper) GetBreakerState() BreakerState {
+	return prh.breaker.snapshot(prh.Breaker)
+}
+
+// GetEndpoints returns a snapshot of the health state of every configured endpoint
+func (prh *ProxmoxRequestHelper) GetEndpoints() []ProxmoxEndpoint {
+	prh.mutex.Lock()
+	defer prh.mutex.Unlock()
+
+	endpoints := make([]ProxmoxEndpoint, 0, len(prh.endpoints))
+	for _, endpoint := range prh.endpoints {
+		endpoints = append(endpoints, *endpoint)
 	}
+	return endpoints
 }
 
-// MakeRequest performs an HTTP request to the Proxmox API and returns the raw response data
+// MakeRequest performs an HTTP request to the Proxmox API and returns the raw response data.
+// Requests fail over to the next configured endpoint when a node is unreachable, and are
+// retried with backoff under the helper's RetryPolicy when the failure is transient. While
+// the circuit breaker is open they fail with ErrClusterUnreachable without being sent.
 func (prh *ProxmoxRequestHelper) MakeRequest(req ProxmoxAPIRequest) (json.RawMessage, error) {
+	return prh.MakeRequestWithContext(context.Background(), req)
+}
+
+// MakeRequestWithContext is MakeRequest with a context that aborts the request, and any
+// failover to other endpoints, once it is cancelled
+func (prh *ProxmoxRequestHelper) MakeRequestWithContext(ctx context.Context, req ProxmoxAPIRequest) (json.RawMessage, error) {
+	for attempt := 1; ; attempt++ {
+		if !prh.breaker.allow(prh.Breaker) {
+			return nil, fmt.Errorf("%w, not sending %s %s", ErrClusterUnreachable, req.Method, req.Endpoint)
+		}
+
+		data, err := prh.requestWithFailover(ctx, req)
+		if ctx.Err() != nil {
+			prh.breaker.release()
+		} else {
+			prh.breaker.record(prh.Breaker, err)
+		}
+
+		if err == nil || attempt >= prh.Retry.Attempts || ctx.Err() != nil || !isTransientError(req.Method, err) {
+			return data, err
+		}
+
+		delay := prh.Retry.backoff(attempt)
+		log.Printf("Proxmox request %s %s failed (attempt %d of %d), retrying in %s: %v", req.Method, req.Endpoint, attempt, prh.Retry.Attempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// MakeRequestAndUnmarshal performs an HTTP request and unmarshals the response into the provided interface
+func (prh *ProxmoxRequestHelper) MakeRequestAndUnmarshal(req ProxmoxAPIRequest, target any) error {
+	return prh.MakeRequestAndUnmarshalWithContext(context.Background(), req, target)
+}
+
+// MakeRequestAndUnmarshalWithContext is MakeRequestAndUnmarshal with a context that aborts
+// the request once it is cancelled
+func (prh *ProxmoxRequestHelper) MakeRequestAndUnmarshalWithContext(ctx context.Context, req ProxmoxAPIRequest, target any) error {
+	data, err := prh.MakeRequestWithContext(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal response data from %s %s: %w", req.Method, req.Endpoint, err)
+	}
+
+	return nil
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+// requestWithFailover signs the request with the active token and sends it with
+// requestWithToken. When Proxmox rejects the token the request is sent once more with the
+// fallback token, which then replaces it.
+func (prh *ProxmoxRequestHelper) requestWithFailover(ctx context.Context, req ProxmoxAPIRequest) (json.RawMessage, error) {
+	token := prh.tokens.current()
+	data, err := prh.requestWithToken(ctx, req, token)
+
+	var apiErr *ProxmoxAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		if next, ok := prh.tokens.promoteFallback(token); ok {
+			return prh.requestWithToken(ctx, req, next)
+		}
+	}
+
+	return data, err
+}
+
+// requestWithToken tries the request against each endpoint in turn until one answers
+func (prh *ProxmoxRequestHelper) requestWithToken(ctx context.Context, req ProxmoxAPIRequest, token string) (json.RawMessage, error) {
+	var lastErr error
+
+	for _, endpoint := range prh.orderedEndpoints() {
+		data, retryable, err := prh.doRequest(ctx, endpoint.BaseURL, req, token)
+		if err == nil {
+			prh.markHealthy(endpoint)
+			return data, nil
+		}
+
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			return nil, err
+		}
+
+		prh.markUnhealthy(endpoint, err)
+		log.Printf("Proxmox endpoint %s unavailable for %s %s, trying next endpoint: %v", endpoint.BaseURL, req.Method, req.Endpoint, err)
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no proxmox endpoints configured")
+	}
+	return nil, lastErr
+}
+
+// doRequest performs a single request against one endpoint. The returned bool reports
+// whether the failure was a connection problem that is safe to retry elsewhere.
+func (prh *ProxmoxRequestHelper) doRequest(ctx context.Context, baseURL string, req ProxmoxAPIRequest, token string) (json.RawMessage, bool, error) {
 	var reqBody io.Reader
 
 	// Prepare request body for POST/PUT requests
@@ -51,66 +232,141 @@ func (prh *ProxmoxRequestHelper) MakeRequest(req ProxmoxAPIRequest) (json.RawMes
 
 		jsonData, err := json.Marshal(bodyData)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, false, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		reqBody = bytes.NewBuffer(jsonData)
 	}
 
 	// Create the full URL
-	url := prh.BaseURL + req.Endpoint
+	url := baseURL + req.Endpoint
 
 	// Create HTTP request
-	httpReq, err := http.NewRequest(req.Method, url, reqBody)
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create %s request to %s: %w", req.Method, req.Endpoint, err)
+		return nil, false, fmt.Errorf("failed to create %s request to %s: %w", req.Method, req.Endpoint, err)
 	}
 
 	// Set headers
-	httpReq.Header.Add("Authorization", "PVEAPIToken="+prh.APIToken)
+	httpReq.Header.Add("Authorization", "PVEAPIToken="+token)
 	httpReq.Header.Add("Content-Type", "application/json")
 
 	// Execute the request
 	resp, err := prh.HTTPClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute %s request to %s: %w", req.Method, req.Endpoint, err)
+		return nil, isRetryableTransportError(req.Method, err), fmt.Errorf("failed to execute %s request to %s: %w", req.Method, req.Endpoint, err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body first for better error reporting
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from %s %s: %w", req.Method, req.Endpoint, err)
+		return nil, false, fmt.Errorf("failed to read response body from %s %s: %w", req.Method, req.Endpoint, err)
 	}
 
 	// Check response status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("proxmox API returned status %d for %s %s, response: %s", resp.StatusCode, req.Method, req.Endpoint, string(bodyBytes))
+		// 595/596 are returned by pveproxy when it cannot reach the node handling the request
+		retryable := resp.StatusCode == 595 || resp.StatusCode == 596
+		return nil, retryable, &ProxmoxAPIError{StatusCode: resp.StatusCode, Method: req.Method, Endpoint: req.Endpoint, Body: string(bodyBytes)}
 	}
 
 	// Don't try to parse into ProxmoxAPIResponse structure for DELETE operations
 	if req.Method == "DELETE" {
-		return json.RawMessage("nil"), nil
+		return json.RawMessage("nil"), false, nil
 	}
 
 	// Decode the API response for other methods
 	var apiResponse ProxmoxAPIResponse
 	if err := json.Unmarshal(bodyBytes, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response from %s %s: %w", req.Method, req.Endpoint, err)
+		return nil, false, fmt.Errorf("failed to decode response from %s %s: %w", req.Method, req.Endpoint, err)
 	}
 
-	return apiResponse.Data, nil
+	return apiResponse.Data, false, nil
 }
 
-// MakeRequestAndUnmarshal performs an HTTP request and unmarshals the response into the provided interface
-func (prh *ProxmoxRequestHelper) MakeRequestAndUnmarshal(req ProxmoxAPIRequest, target any) error {
-	data, err := prh.MakeRequest(req)
-	if err != nil {
-		return err
+// orderedEndpoints returns healthy endpoints first (in configured order), followed by
+// endpoints still cooling down so a request is never refused outright
+func (prh *ProxmoxRequestHelper) orderedEndpoints() []*ProxmoxEndpoint {
+	prh.mutex.Lock()
+	defer prh.mutex.Unlock()
+
+	var healthy, cooling []*ProxmoxEndpoint
+	for _, endpoint := range prh.endpoints {
+		if !endpoint.Healthy && time.Since(endpoint.LastFailure) > endpointCooldown {
+			endpoint.Healthy = true
+		}
+
+		if endpoint.Healthy {
+			healthy = append(healthy, endpoint)
+		} else {
+			cooling = append(cooling, endpoint)
+		}
 	}
 
-	if err := json.Unmarshal(data, target); err != nil {
-		return fmt.Errorf("failed to unmarshal response data from %s %s: %w", req.Method, req.Endpoint, err)
+	return append(healthy, cooling...)
+}
+
+func (prh *ProxmoxRequestHelper) markHealthy(endpoint *ProxmoxEndpoint) {
+	prh.mutex.Lock()
+	defer prh.mutex.Unlock()
+
+	endpoint.Healthy = true
+	endpoint.Failures = 0
+}
+
+func (prh *ProxmoxRequestHelper) markUnhealthy(endpoint *ProxmoxEndpoint, err error) {
+	prh.mutex.Lock()
+	defer prh.mutex.Unlock()
+
+	endpoint.Healthy = false
+	endpoint.Failures++
+	endpoint.LastFailure = time.Now()
+	endpoint.LastError = err.Error()
+}
+
+// isRetryableTransportError reports whether a transport failure can be safely retried
+// against another endpoint. Dial failures never reached Proxmox, so any method is safe;
+// other failures (e.g. timeouts mid-request) are only retried for reads.
+func isRetryableTransportError(method string, err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
 	}
+	return method == "GET"
+}
 
-	return nil
+// isTransientError reports whether a failed request may succeed if sent again. GET and PUT
+// are retried after any 5xx or transport failure since repeating them has no further effect.
+// POST and DELETE are only retried when Proxmox never handled them: the connection could
+// not be made, or pveproxy could not reach the node (595/596).
+func isTransientError(method string, err error) bool {
+	idempotent := method == "GET" || method == "PUT"
+
+	var apiErr *ProxmoxAPIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == 595 || apiErr.StatusCode == 596 {
+			return true
+		}
+		return idempotent && apiErr.StatusCode >= 500
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return idempotent && !errors.Is(err, context.Canceled)
+}
+
+// backoff returns the delay before the retry following an attempt, doubling from BaseDelay
+// up to MaxDelay with jitter so concurrent clones don't retry in lockstep
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1)
 }
@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 )
 
 type Writer struct {
-	w http.ResponseWriter
-	f http.Flusher
+	w        http.ResponseWriter
+	f        http.Flusher
+	mutex    *sync.Mutex
+	listener func(message any)
 }
 
 func NewWriter(w http.ResponseWriter) (*Writer, error) {
@@ -16,10 +19,43 @@ func NewWriter(w http.ResponseWriter) (*Writer, error) {
 	if !ok {
 		return nil, fmt.Errorf("streaming unsupported")
 	}
-	return &Writer{w: w, f: f}, nil
+	return &Writer{w: w, f: f, mutex: &sync.Mutex{}}, nil
 }
 
+// WithListener returns a writer that streams to the same client and also passes
+// every message to listener, after any listener the writer already has. It may be
+// called on a nil Writer, in which case messages are only passed to the listener.
+func (s *Writer) WithListener(listener func(message any)) *Writer {
+	if s == nil {
+		return &Writer{listener: listener}
+	}
+
+	if previous := s.listener; previous != nil {
+		chained := listener
+		listener = func(message any) {
+			previous(message)
+			chained(message)
+		}
+	}
+	return &Writer{w: s.w, f: s.f, mutex: s.mutex, listener: listener}
+}
+
+// Send writes a message to the stream. A nil Writer discards messages so
+// background operations without a client can share the same code paths.
+// Send is safe for concurrent use.
 func (s *Writer) Send(message any) {
+	if s == nil {
+		return
+	}
+	if s.listener != nil {
+		s.listener(message)
+	}
+	if s.w == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	b, _ := json.Marshal(message)
 	fmt.Fprintf(s.w, "data: %s\n\n", b)
 	s.f.Flush()
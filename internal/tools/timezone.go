@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+)
+
+// localTimeLayouts are accepted for times given without an offset alongside a time zone
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// LoadTimeZone loads an IANA time zone such as America/Los_Angeles. An empty name is UTC.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// ParseTimeInZone parses an ISO-8601 time. A time with an offset is used as is, while a
+// local time without one is interpreted in the given IANA time zone, so a deployment
+// scheduled for 09:00 in a zone still fires at 09:00 local time across DST changes.
+func ParseTimeInZone(value string, timeZone string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if timeZone == "" {
+		return time.Time{}, fmt.Errorf("time %q has no offset, include one or provide a time zone", value)
+	}
+
+	loc, err := LoadTimeZone(timeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("time %q is not a valid ISO-8601 time", value)
+}
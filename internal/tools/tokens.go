@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenStatus describes the API tokens requests are signed with, leaving out their secrets
+type TokenStatus struct {
+	TokenID         string    `json:"token_id"`
+	FallbackTokenID string    `json:"fallback_token_id,omitempty"`
+	RotatedAt       time.Time `json:"rotated_at,omitempty"`
+}
+
+// tokenPair holds the token requests are signed with and the fallback tried when Proxmox
+// rejects it. Tokens are in the "<id>=<secret>" form of the PVEAPIToken header.
+type tokenPair struct {
+	active    string
+	fallback  string
+	rotatedAt time.Time
+	mutex     sync.Mutex
+}
+
+// CurrentToken returns the token requests are currently signed with, for connections made
+// outside the helper such as console websockets
+func (prh *ProxmoxRequestHelper) CurrentToken() string {
+	return prh.tokens.current()
+}
+
+// TokenID returns the ID of the token requests are currently signed with
+func (prh *ProxmoxRequestHelper) TokenID() string {
+	return tokenID(prh.tokens.current())
+}
+
+// SetFallbackToken sets the token tried when Proxmox rejects the active one, an empty
+// token removes the fallback
+func (prh *ProxmoxRequestHelper) SetFallbackToken(token string) {
+	prh.tokens.mutex.Lock()
+	defer prh.tokens.mutex.Unlock()
+
+	prh.tokens.fallback = token
+}
+
+// RotateToken signs every following request with a new token. The replaced token becomes
+// the fallback, so requests keep working if the new token turns out to be rejected.
+// Requests already sent finish with the token they were signed with.
+func (prh *ProxmoxRequestHelper) RotateToken(token string) {
+	prh.tokens.mutex.Lock()
+	defer prh.tokens.mutex.Unlock()
+
+	prh.tokens.fallback = prh.tokens.active
+	prh.tokens.active = token
+	prh.tokens.rotatedAt = time.Now()
+}
+
+// GetTokenStatus returns the IDs of the active and fallback tokens
+func (prh *ProxmoxRequestHelper) GetTokenStatus() TokenStatus {
+	prh.tokens.mutex.Lock()
+	defer prh.tokens.mutex.Unlock()
+
+	return TokenStatus{
+		TokenID:         tokenID(prh.tokens.active),
+		FallbackTokenID: tokenID(prh.tokens.fallback),
+		RotatedAt:       prh.tokens.rotatedAt,
+	}
+}
+
+// MakeRequestWithToken sends a request signed with the given token instead of the active
+// one, without retries or the fallback token. It is used to check a token before rotating
+// to it.
+func (prh *ProxmoxRequestHelper) MakeRequestWithToken(ctx context.Context, req ProxmoxAPIRequest, token string) (json.RawMessage, error) {
+	return prh.requestWithToken(ctx, req, token)
+}
+
+// =================================================
+// Private Functions
+// =================================================
+
+func (t *tokenPair) current() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.active
+}
+
+// promoteFallback replaces a token Proxmox rejected with the fallback and returns the
+// token to retry with. When another request already replaced the rejected token the
+// current one is returned instead.
+func (t *tokenPair) promoteFallback(rejected string) (string, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.active != rejected {
+		return t.active, true
+	}
+	if t.fallback == "" {
+		return "", false
+	}
+
+	log.Printf("Proxmox rejected API token %s, switching to fallback token %s", tokenID(rejected), tokenID(t.fallback))
+	t.active = t.fallback
+	t.fallback = ""
+	t.rotatedAt = time.Now()
+	return t.active, true
+}
+
+// tokenID returns the "<user>!<name>" part of a token
+func tokenID(token string) string {
+	id, _, _ := strings.Cut(token, "=")
+	return id
+}
@@ -0,0 +1,782 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: proclone/v1/proclone.proto
+
+package proclonev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CloneTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	IsGroup       bool                   `protobuf:"varint,2,opt,name=is_group,json=isGroup,proto3" json:"is_group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneTarget) Reset() {
+	*x = CloneTarget{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneTarget) ProtoMessage() {}
+
+func (x *CloneTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneTarget.ProtoReflect.Descriptor instead.
+func (*CloneTarget) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CloneTarget) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CloneTarget) GetIsGroup() bool {
+	if x != nil {
+		return x.IsGroup
+	}
+	return false
+}
+
+type CloneTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Template      string                 `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	Targets       []*CloneTarget         `protobuf:"bytes,2,rep,name=targets,proto3" json:"targets,omitempty"`
+	StartingVmid  int32                  `protobuf:"varint,3,opt,name=starting_vmid,json=startingVmid,proto3" json:"starting_vmid,omitempty"`
+	VmNames       []string               `protobuf:"bytes,4,rep,name=vm_names,json=vmNames,proto3" json:"vm_names,omitempty"`
+	RequestedBy   string                 `protobuf:"bytes,5,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneTemplateRequest) Reset() {
+	*x = CloneTemplateRequest{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneTemplateRequest) ProtoMessage() {}
+
+func (x *CloneTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneTemplateRequest.ProtoReflect.Descriptor instead.
+func (*CloneTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CloneTemplateRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *CloneTemplateRequest) GetTargets() []*CloneTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *CloneTemplateRequest) GetStartingVmid() int32 {
+	if x != nil {
+		return x.StartingVmid
+	}
+	return 0
+}
+
+func (x *CloneTemplateRequest) GetVmNames() []string {
+	if x != nil {
+		return x.VmNames
+	}
+	return nil
+}
+
+func (x *CloneTemplateRequest) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+type CloneTemplateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneTemplateResponse) Reset() {
+	*x = CloneTemplateResponse{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneTemplateResponse) ProtoMessage() {}
+
+func (x *CloneTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneTemplateResponse.ProtoReflect.Descriptor instead.
+func (*CloneTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CloneTemplateResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type DeletePodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pod           string                 `protobuf:"bytes,1,opt,name=pod,proto3" json:"pod,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePodRequest) Reset() {
+	*x = DeletePodRequest{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePodRequest) ProtoMessage() {}
+
+func (x *DeletePodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePodRequest.ProtoReflect.Descriptor instead.
+func (*DeletePodRequest) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeletePodRequest) GetPod() string {
+	if x != nil {
+		return x.Pod
+	}
+	return ""
+}
+
+type DeletePodResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePodResponse) Reset() {
+	*x = DeletePodResponse{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePodResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePodResponse) ProtoMessage() {}
+
+func (x *DeletePodResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePodResponse.ProtoReflect.Descriptor instead.
+func (*DeletePodResponse) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{4}
+}
+
+type ListPodsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPodsRequest) Reset() {
+	*x = ListPodsRequest{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPodsRequest) ProtoMessage() {}
+
+func (x *ListPodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPodsRequest.ProtoReflect.Descriptor instead.
+func (*ListPodsRequest) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListPodsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type VM struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vmid          int32                  `protobuf:"varint,1,opt,name=vmid,proto3" json:"vmid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Node          string                 `protobuf:"bytes,3,opt,name=node,proto3" json:"node,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VM) Reset() {
+	*x = VM{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VM) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VM) ProtoMessage() {}
+
+func (x *VM) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VM.ProtoReflect.Descriptor instead.
+func (*VM) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VM) GetVmid() int32 {
+	if x != nil {
+		return x.Vmid
+	}
+	return 0
+}
+
+func (x *VM) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VM) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *VM) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type Pod struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Template      string                 `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	Vms           []*VM                  `protobuf:"bytes,3,rep,name=vms,proto3" json:"vms,omitempty"`
+	Archived      bool                   `protobuf:"varint,4,opt,name=archived,proto3" json:"archived,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pod) Reset() {
+	*x = Pod{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pod) ProtoMessage() {}
+
+func (x *Pod) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pod.ProtoReflect.Descriptor instead.
+func (*Pod) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Pod) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Pod) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *Pod) GetVms() []*VM {
+	if x != nil {
+		return x.Vms
+	}
+	return nil
+}
+
+func (x *Pod) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+type ListPodsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pods          []*Pod                 `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPodsResponse) Reset() {
+	*x = ListPodsResponse{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPodsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPodsResponse) ProtoMessage() {}
+
+func (x *ListPodsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPodsResponse.ProtoReflect.Descriptor instead.
+func (*ListPodsResponse) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListPodsResponse) GetPods() []*Pod {
+	if x != nil {
+		return x.Pods
+	}
+	return nil
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Owner         string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Progress      int32                  `protobuf:"varint,6,opt,name=progress,proto3" json:"progress,omitempty"`
+	Message       string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_proclone_v1_proclone_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_proclone_v1_proclone_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_proclone_v1_proclone_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Job) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Job) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Job) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *Job) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Job) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+var File_proclone_v1_proclone_proto protoreflect.FileDescriptor
+
+const file_proclone_v1_proclone_proto_rawDesc = "" +
+	"\n" +
+	"\x1aproclone/v1/proclone.proto\x12\vproclone.v1\"<\n" +
+	"\vCloneTarget\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
+	"\bis_group\x18\x02 \x01(\bR\aisGroup\"\xc9\x01\n" +
+	"\x14CloneTemplateRequest\x12\x1a\n" +
+	"\btemplate\x18\x01 \x01(\tR\btemplate\x122\n" +
+	"\atargets\x18\x02 \x03(\v2\x18.proclone.v1.CloneTargetR\atargets\x12#\n" +
+	"\rstarting_vmid\x18\x03 \x01(\x05R\fstartingVmid\x12\x19\n" +
+	"\bvm_names\x18\x04 \x03(\tR\avmNames\x12!\n" +
+	"\frequested_by\x18\x05 \x01(\tR\vrequestedBy\".\n" +
+	"\x15CloneTemplateResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"$\n" +
+	"\x10DeletePodRequest\x12\x10\n" +
+	"\x03pod\x18\x01 \x01(\tR\x03pod\"\x13\n" +
+	"\x11DeletePodResponse\"'\n" +
+	"\x0fListPodsRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\"X\n" +
+	"\x02VM\x12\x12\n" +
+	"\x04vmid\x18\x01 \x01(\x05R\x04vmid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04node\x18\x03 \x01(\tR\x04node\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\"t\n" +
+	"\x03Pod\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\x12!\n" +
+	"\x03vms\x18\x03 \x03(\v2\x0f.proclone.v1.VMR\x03vms\x12\x1a\n" +
+	"\barchived\x18\x04 \x01(\bR\barchived\"8\n" +
+	"\x10ListPodsResponse\x12$\n" +
+	"\x04pods\x18\x01 \x03(\v2\x10.proclone.v1.PodR\x04pods\"\x1f\n" +
+	"\rGetJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x83\x02\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x06 \x01(\x05R\bprogress\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\x03R\tupdatedAt2\xaf\x02\n" +
+	"\bProclone\x12V\n" +
+	"\rCloneTemplate\x12!.proclone.v1.CloneTemplateRequest\x1a\".proclone.v1.CloneTemplateResponse\x12J\n" +
+	"\tDeletePod\x12\x1d.proclone.v1.DeletePodRequest\x1a\x1e.proclone.v1.DeletePodResponse\x12G\n" +
+	"\bListPods\x12\x1c.proclone.v1.ListPodsRequest\x1a\x1d.proclone.v1.ListPodsResponse\x126\n" +
+	"\x06GetJob\x12\x1a.proclone.v1.GetJobRequest\x1a\x10.proclone.v1.JobB<Z:github.com/cpp-cyber/proclone/proto/proclone/v1;proclonev1b\x06proto3"
+
+var (
+	file_proclone_v1_proclone_proto_rawDescOnce sync.Once
+	file_proclone_v1_proclone_proto_rawDescData []byte
+)
+
+func file_proclone_v1_proclone_proto_rawDescGZIP() []byte {
+	file_proclone_v1_proclone_proto_rawDescOnce.Do(func() {
+		file_proclone_v1_proclone_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proclone_v1_proclone_proto_rawDesc), len(file_proclone_v1_proclone_proto_rawDesc)))
+	})
+	return file_proclone_v1_proclone_proto_rawDescData
+}
+
+var file_proclone_v1_proclone_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proclone_v1_proclone_proto_goTypes = []any{
+	(*CloneTarget)(nil),           // 0: proclone.v1.CloneTarget
+	(*CloneTemplateRequest)(nil),  // 1: proclone.v1.CloneTemplateRequest
+	(*CloneTemplateResponse)(nil), // 2: proclone.v1.CloneTemplateResponse
+	(*DeletePodRequest)(nil),      // 3: proclone.v1.DeletePodRequest
+	(*DeletePodResponse)(nil),     // 4: proclone.v1.DeletePodResponse
+	(*ListPodsRequest)(nil),       // 5: proclone.v1.ListPodsRequest
+	(*VM)(nil),                    // 6: proclone.v1.VM
+	(*Pod)(nil),                   // 7: proclone.v1.Pod
+	(*ListPodsResponse)(nil),      // 8: proclone.v1.ListPodsResponse
+	(*GetJobRequest)(nil),         // 9: proclone.v1.GetJobRequest
+	(*Job)(nil),                   // 10: proclone.v1.Job
+}
+var file_proclone_v1_proclone_proto_depIdxs = []int32{
+	0,  // 0: proclone.v1.CloneTemplateRequest.targets:type_name -> proclone.v1.CloneTarget
+	6,  // 1: proclone.v1.Pod.vms:type_name -> proclone.v1.VM
+	7,  // 2: proclone.v1.ListPodsResponse.pods:type_name -> proclone.v1.Pod
+	1,  // 3: proclone.v1.Proclone.CloneTemplate:input_type -> proclone.v1.CloneTemplateRequest
+	3,  // 4: proclone.v1.Proclone.DeletePod:input_type -> proclone.v1.DeletePodRequest
+	5,  // 5: proclone.v1.Proclone.ListPods:input_type -> proclone.v1.ListPodsRequest
+	9,  // 6: proclone.v1.Proclone.GetJob:input_type -> proclone.v1.GetJobRequest
+	2,  // 7: proclone.v1.Proclone.CloneTemplate:output_type -> proclone.v1.CloneTemplateResponse
+	4,  // 8: proclone.v1.Proclone.DeletePod:output_type -> proclone.v1.DeletePodResponse
+	8,  // 9: proclone.v1.Proclone.ListPods:output_type -> proclone.v1.ListPodsResponse
+	10, // 10: proclone.v1.Proclone.GetJob:output_type -> proclone.v1.Job
+	7,  // [7:11] is the sub-list for method output_type
+	3,  // [3:7] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_proclone_v1_proclone_proto_init() }
+func file_proclone_v1_proclone_proto_init() {
+	if File_proclone_v1_proclone_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proclone_v1_proclone_proto_rawDesc), len(file_proclone_v1_proclone_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proclone_v1_proclone_proto_goTypes,
+		DependencyIndexes: file_proclone_v1_proclone_proto_depIdxs,
+		MessageInfos:      file_proclone_v1_proclone_proto_msgTypes,
+	}.Build()
+	File_proclone_v1_proclone_proto = out.File
+	file_proclone_v1_proclone_proto_goTypes = nil
+	file_proclone_v1_proclone_proto_depIdxs = nil
+}
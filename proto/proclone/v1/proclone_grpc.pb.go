@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proclone/v1/proclone.proto
+
+package proclonev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Proclone_CloneTemplate_FullMethodName = "/proclone.v1.Proclone/CloneTemplate"
+	Proclone_DeletePod_FullMethodName     = "/proclone.v1.Proclone/DeletePod"
+	Proclone_ListPods_FullMethodName      = "/proclone.v1.Proclone/ListPods"
+	Proclone_GetJob_FullMethodName        = "/proclone.v1.Proclone/GetJob"
+)
+
+// ProcloneClient is the client API for Proclone service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Proclone exposes the core pod operations of the REST API for integrations such as
+// scoring engines and the CLI. Every call must carry the shared token configured by
+// GRPC_AUTH_TOKEN as "authorization: Bearer <token>" metadata.
+type ProcloneClient interface {
+	// CloneTemplate starts cloning a template for the given targets and returns the ID of
+	// the job tracking it
+	CloneTemplate(ctx context.Context, in *CloneTemplateRequest, opts ...grpc.CallOption) (*CloneTemplateResponse, error)
+	// DeletePod deletes a pod and all of its VMs
+	DeletePod(ctx context.Context, in *DeletePodRequest, opts ...grpc.CallOption) (*DeletePodResponse, error)
+	// ListPods lists deployed pods, optionally only those of one owner
+	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error)
+	// GetJob returns the status of a job
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+}
+
+type procloneClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProcloneClient(cc grpc.ClientConnInterface) ProcloneClient {
+	return &procloneClient{cc}
+}
+
+func (c *procloneClient) CloneTemplate(ctx context.Context, in *CloneTemplateRequest, opts ...grpc.CallOption) (*CloneTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloneTemplateResponse)
+	err := c.cc.Invoke(ctx, Proclone_CloneTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *procloneClient) DeletePod(ctx context.Context, in *DeletePodRequest, opts ...grpc.CallOption) (*DeletePodResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePodResponse)
+	err := c.cc.Invoke(ctx, Proclone_DeletePod_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *procloneClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPodsResponse)
+	err := c.cc.Invoke(ctx, Proclone_ListPods_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *procloneClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Proclone_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProcloneServer is the server API for Proclone service.
+// All implementations must embed UnimplementedProcloneServer
+// for forward compatibility.
+//
+// Proclone exposes the core pod operations of the REST API for integrations such as
+// scoring engines and the CLI. Every call must carry the shared token configured by
+// GRPC_AUTH_TOKEN as "authorization: Bearer <token>" metadata.
+type ProcloneServer interface {
+	// CloneTemplate starts cloning a template for the given targets and returns the ID of
+	// the job tracking it
+	CloneTemplate(context.Context, *CloneTemplateRequest) (*CloneTemplateResponse, error)
+	// DeletePod deletes a pod and all of its VMs
+	DeletePod(context.Context, *DeletePodRequest) (*DeletePodResponse, error)
+	// ListPods lists deployed pods, optionally only those of one owner
+	ListPods(context.Context, *ListPodsRequest) (*ListPodsResponse, error)
+	// GetJob returns the status of a job
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	mustEmbedUnimplementedProcloneServer()
+}
+
+// UnimplementedProcloneServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProcloneServer struct{}
+
+func (UnimplementedProcloneServer) CloneTemplate(context.Context, *CloneTemplateRequest) (*CloneTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloneTemplate not implemented")
+}
+func (UnimplementedProcloneServer) DeletePod(context.Context, *DeletePodRequest) (*DeletePodResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePod not implemented")
+}
+func (UnimplementedProcloneServer) ListPods(context.Context, *ListPodsRequest) (*ListPodsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPods not implemented")
+}
+func (UnimplementedProcloneServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedProcloneServer) mustEmbedUnimplementedProcloneServer() {}
+func (UnimplementedProcloneServer) testEmbeddedByValue()                  {}
+
+// UnsafeProcloneServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProcloneServer will
+// result in compilation errors.
+type UnsafeProcloneServer interface {
+	mustEmbedUnimplementedProcloneServer()
+}
+
+func RegisterProcloneServer(s grpc.ServiceRegistrar, srv ProcloneServer) {
+	// If the following call pancis, it indicates UnimplementedProcloneServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Proclone_ServiceDesc, srv)
+}
+
+func _Proclone_CloneTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcloneServer).CloneTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Proclone_CloneTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcloneServer).CloneTemplate(ctx, req.(*CloneTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Proclone_DeletePod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcloneServer).DeletePod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Proclone_DeletePod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcloneServer).DeletePod(ctx, req.(*DeletePodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Proclone_ListPods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcloneServer).ListPods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Proclone_ListPods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcloneServer).ListPods(ctx, req.(*ListPodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Proclone_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcloneServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Proclone_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcloneServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Proclone_ServiceDesc is the grpc.ServiceDesc for Proclone service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Proclone_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proclone.v1.Proclone",
+	HandlerType: (*ProcloneServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CloneTemplate",
+			Handler:    _Proclone_CloneTemplate_Handler,
+		},
+		{
+			MethodName: "DeletePod",
+			Handler:    _Proclone_DeletePod_Handler,
+		},
+		{
+			MethodName: "ListPods",
+			Handler:    _Proclone_ListPods_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Proclone_GetJob_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proclone/v1/proclone.proto",
+}